@@ -0,0 +1,203 @@
+package examples
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-exec/tfexec"
+)
+
+const (
+	providerSource  = "github.com/bushelpowered/oncall"
+	providerPackage = "github.com/bushelpowered/terraform-provider-oncall"
+
+	// fixturesDir holds the oncall API responses the examples are planned
+	// against, by path without the query, e.g. testdata/oncall/api/v0/roles.json.
+	// api/v0/teams.json answers the health check the provider makes when it is
+	// configured.
+	fixturesDir = "testdata/oncall"
+)
+
+// TestExamplesValidate runs terraform validate against every example directory
+// using a freshly built provider, so the examples can't drift from the schema.
+// It is skipped when no terraform binary is available, set TF_ACC_TERRAFORM_PATH
+// to point at one that is not on the PATH.
+func TestExamplesValidate(t *testing.T) {
+	terraformPath, env := exampleTerraform(t)
+
+	for _, dir := range exampleDirs(t) {
+		dir := dir
+		t.Run(dir, func(t *testing.T) {
+			tf := newExampleTerraform(t, dir, terraformPath, env)
+			out, err := tf.Validate(context.Background())
+			if err != nil {
+				t.Fatalf("Running terraform validate: %s", err)
+			}
+			for _, d := range out.Diagnostics {
+				if d.Severity == "error" {
+					t.Errorf("%s: %s", d.Summary, d.Detail)
+				} else {
+					t.Logf("%s: %s", d.Severity, d.Summary)
+				}
+			}
+			if !out.Valid {
+				t.Errorf("Example %s is not valid", dir)
+			}
+		})
+	}
+}
+
+// TestExamplesPlan runs terraform plan against every example directory with
+// the provider pointed at a fake oncall server answering from the fixtures, so
+// the plan time checks run against the examples too. Nothing exists on the
+// fake server beyond the fixtures, and the plan must not change anything.
+func TestExamplesPlan(t *testing.T) {
+	terraformPath, env := exampleTerraform(t)
+
+	server := httptest.NewServer(fixtureHandler(t))
+	defer server.Close()
+
+	for _, dir := range exampleDirs(t) {
+		dir := dir
+		t.Run(dir, func(t *testing.T) {
+			// An override file points the example's provider block at the fake
+			// server, anonymously as the fake server has no users to log in
+			override := filepath.Join(dir, "examples_test_override.tf")
+			err := ioutil.WriteFile(override, []byte(`provider "oncall" {
+  endpoint  = "`+server.URL+`"
+  auth_type = "none"
+}
+`), 0644)
+			if err != nil {
+				t.Fatalf("Writing provider override: %s", err)
+			}
+			defer os.Remove(override)
+
+			tf := newExampleTerraform(t, dir, terraformPath, env)
+			_, err = tf.Plan(context.Background(), tfexec.Lock(false))
+			if err != nil {
+				t.Fatalf("Running terraform plan: %s", err)
+			}
+		})
+	}
+}
+
+// fixtureHandler answers GETs with the fixture at their path, and 404s for
+// everything else the way oncall does for things that don't exist. Planning
+// must not write to oncall, so any other request fails the test.
+func fixtureHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			t.Errorf("Planning made a %s %s request", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		fixture := filepath.Join(fixturesDir, filepath.FromSlash(path.Clean(r.URL.Path))+".json")
+		body, err := ioutil.ReadFile(fixture)
+		if os.IsNotExist(err) {
+			t.Logf("No fixture for GET %s, answering 404", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title": "Not found"}`))
+			return
+		}
+		if err != nil {
+			t.Errorf("Reading fixture %s: %s", fixture, err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	})
+}
+
+// exampleTerraform builds the provider and returns the terraform binary with
+// the environment pointing it at the build. It skips the test when there is
+// no terraform binary.
+func exampleTerraform(t *testing.T) (string, map[string]string) {
+	terraformPath := os.Getenv("TF_ACC_TERRAFORM_PATH")
+	if terraformPath == "" {
+		var err error
+		terraformPath, err = exec.LookPath("terraform")
+		if err != nil {
+			t.Skip("terraform binary not found, skipping examples")
+		}
+	}
+
+	pluginDir, err := ioutil.TempDir("", "terraform-provider-oncall")
+	if err != nil {
+		t.Fatalf("Creating plugin dir: %s", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(pluginDir) })
+
+	// Built by package path, so it doesn't matter which directory the test runs in
+	build := exec.Command("go", "build", "-o", filepath.Join(pluginDir, "terraform-provider-oncall"), providerPackage)
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("Building provider: %s\n%s", err, out)
+	}
+
+	// dev_overrides points terraform at the provider we just built and lets us skip terraform init
+	cliConfig := filepath.Join(pluginDir, "terraformrc")
+	err = ioutil.WriteFile(cliConfig, []byte(`provider_installation {
+  dev_overrides {
+    "`+providerSource+`" = "`+filepath.ToSlash(pluginDir)+`"
+  }
+  direct {}
+}
+`), 0644)
+	if err != nil {
+		t.Fatalf("Writing terraform cli config: %s", err)
+	}
+
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		split := strings.SplitN(kv, "=", 2)
+		env[split[0]] = split[1]
+	}
+	env = tfexec.CleanEnv(env)
+	env["TF_CLI_CONFIG_FILE"] = cliConfig
+	return terraformPath, env
+}
+
+func newExampleTerraform(t *testing.T, dir, terraformPath string, env map[string]string) *tfexec.Terraform {
+	tf, err := tfexec.NewTerraform(dir, terraformPath)
+	if err != nil {
+		t.Fatalf("Setting up terraform: %s", err)
+	}
+	if err = tf.SetEnv(env); err != nil {
+		t.Fatalf("Setting terraform environment: %s", err)
+	}
+	return tf
+}
+
+// exampleDirs returns every directory under examples/ containing terraform files
+func exampleDirs(t *testing.T) []string {
+	dirs := []string{}
+	err := filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() && strings.HasPrefix(info.Name(), ".") && path != "." {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && filepath.Ext(path) == ".tf" {
+			dir := filepath.Dir(path)
+			if len(dirs) == 0 || dirs[len(dirs)-1] != dir {
+				dirs = append(dirs, dir)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Finding examples: %s", err)
+	}
+	return dirs
+}
@@ -22,6 +22,11 @@ resource "oncall_team" "systems" {
   admins = []
 }
 
+resource "oncall_team_member" "t" {
+  team     = oncall_team.t.name
+  username = "jdoe"
+}
+
 resource "oncall_roster" "t" {
   team = oncall_team.t.name
   name = oncall_team.t.name
@@ -34,7 +39,7 @@ resource "oncall_roster" "t" {
 // 24/7, monday to monday
 resource "oncall_basic_schedule" "t" {
   role      = "primary"
-  roster_id = oncall_roster.t.id
+  roster_id = oncall_roster.t.roster_id
 
   auto_populate_days = 21
 
@@ -47,7 +52,7 @@ resource "oncall_basic_schedule" "t" {
 // Work days, 8-5
 resource "oncall_advanced_schedule" "t" {
   role                  = "secondary"
-  roster_id             = oncall_roster.t.id
+  roster_id             = oncall_roster.t.roster_id
   scheduling_algorithim = "default"
   auto_populate_days    = 21
 
@@ -82,3 +87,5 @@ resource "oncall_advanced_schedule" "t" {
   }
 }
 
+
+data "oncall_roles" "all" {}
@@ -5,11 +5,13 @@ go 1.16
 require (
 	github.com/bushelpowered/oncall-client-go v0.2.8
 	github.com/hashicorp/go-cty v1.4.1-0.20200414143053-d3edf31b6320
+	github.com/hashicorp/terraform-exec v0.13.0
 	github.com/hashicorp/terraform-plugin-sdk/v2 v2.4.4
 	github.com/mattn/go-colorable v0.1.8 // indirect
 	github.com/pkg/errors v0.9.1
 	github.com/zclconf/go-cty v1.7.1 // indirect
-	golang.org/x/text v0.3.5 // indirect
+	golang.org/x/text v0.3.5
+	gopkg.in/yaml.v3 v3.0.1
 	maze.io/x/duration v0.0.0-20160924141736-faac084b6075
 )
 
@@ -23,4 +23,7 @@ func main() {
 			return oncall.Provider()
 		},
 	})
+	// Serve returns once Terraform is done with the provider
+	oncall.FlushNotifications()
+	oncall.FlushTelemetry()
 }
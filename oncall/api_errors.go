@@ -0,0 +1,95 @@
+package oncall
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// apiErrorPattern matches the error the oncall client returns for non 2xx responses
+var apiErrorPattern = regexp.MustCompile(`(?s)HTTP Request failed \((\d+)\) \((.*)\)$`)
+
+// apiError is an error response from oncall, which has a falcon style body
+type apiError struct {
+	status      int
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+// parseAPIError gets the oncall response out of an error from the oncall client
+func parseAPIError(err error) (apiError, bool) {
+	if err == nil {
+		return apiError{}, false
+	}
+	match := apiErrorPattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return apiError{}, false
+	}
+
+	e := apiError{}
+	e.status, _ = strconv.Atoi(match[1])
+	if json.Unmarshal([]byte(match[2]), &e) != nil || e.Description == "" {
+		e.Description = match[2]
+	}
+	return e, true
+}
+
+// isNotFound is whether err is oncall saying what was asked for doesn't exist,
+// which the oncall client also says when a roster has no schedule for a role
+func isNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "(404)")
+}
+
+// alreadyExists is whether oncall rejected a create because of a duplicate
+// name, it uses 422 for that as well as for bad values
+func (e apiError) alreadyExists() bool {
+	return e.status == 422 && strings.Contains(strings.ToLower(e.Description), "already")
+}
+
+// field guesses which of the given fields oncall is complaining about, from
+// whether the field (or the field with spaces) is mentioned in the description
+func (e apiError) field(fields []string) string {
+	description := strings.ToLower(e.Description)
+	for _, f := range fields {
+		if strings.Contains(description, f) || strings.Contains(description, strings.ReplaceAll(f, "_", " ")) {
+			return f
+		}
+	}
+	return ""
+}
+
+// createErrorDiags turns an error from creating something in oncall into
+// diagnostics, telling duplicates (which can be imported) apart from values
+// oncall rejected, and pointing at the offending field when it can be found
+func createErrorDiags(err error, action, importID string, fields ...string) diag.Diagnostics {
+	e, ok := parseAPIError(err)
+	if !ok || e.status != 422 {
+		return diagFromErrf(err, action)
+	}
+
+	if e.alreadyExists() {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf("%s: it already exists, please import using id '%s'", action, importID),
+				Detail:   e.Description,
+			},
+		}
+	}
+
+	d := diag.Diagnostic{
+		Severity: diag.Error,
+		Summary:  fmt.Sprintf("%s: oncall rejected the request", action),
+		Detail:   e.Description,
+	}
+	if field := e.field(fields); field != "" {
+		d.Summary = fmt.Sprintf("%s: oncall rejected %s", action, field)
+		d.AttributePath = cty.GetAttrPath(field)
+	}
+	return diag.Diagnostics{d}
+}
@@ -0,0 +1,54 @@
+package oncall
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func Test_createErrorDiags(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantSummary string
+		wantPath    cty.Path
+	}{
+		{
+			name:        "Duplicate name",
+			err:         errors.New(`Creating roster r: HTTP Request failed (422) ({"title": "IntegrityError", "description": "roster name \"r\" already exists for team t"})`),
+			wantSummary: "already exists, please import using id 't/r'",
+		},
+		{
+			name:        "Bad value for a field",
+			err:         errors.New(`HTTP Request failed (422) ({"title": "Invalid", "description": "Invalid scheduling timezone: Mars/Olympus"})`),
+			wantSummary: "oncall rejected scheduling_timezone",
+			wantPath:    cty.GetAttrPath("scheduling_timezone"),
+		},
+		{
+			name:        "Bad value for an unknown field",
+			err:         errors.New(`HTTP Request failed (422) (something went wrong)`),
+			wantSummary: "oncall rejected the request",
+		},
+		{
+			name:        "Other errors",
+			err:         errors.New(`HTTP Request failed (500) (oops)`),
+			wantSummary: "Creating thing: HTTP Request failed (500)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := createErrorDiags(tt.err, "Creating thing", "t/r", "name", "scheduling_timezone")
+			if len(diags) != 1 {
+				t.Fatalf("createErrorDiags() = %v, want a single diagnostic", diags)
+			}
+			if !strings.Contains(diags[0].Summary, tt.wantSummary) {
+				t.Errorf("createErrorDiags() summary = %q, want it to contain %q", diags[0].Summary, tt.wantSummary)
+			}
+			if !diags[0].AttributePath.Equals(tt.wantPath) {
+				t.Errorf("createErrorDiags() path = %#v, want %#v", diags[0].AttributePath, tt.wantPath)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package oncall
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// auditLog appends a JSON line for every call that changes something in
+// oncall, so what terraform did can be reconstructed without the state file.
+// A nil *auditLog records nothing.
+type auditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// auditEntry is a line of the audit log. Only a hash of the payload is kept,
+// so secrets in it aren't written out, but a payload can still be matched up.
+type auditEntry struct {
+	Time          string `json:"time"`
+	Resource      string `json:"resource,omitempty"`
+	Operation     string `json:"operation,omitempty"`
+	ResourceID    string `json:"resource_id,omitempty"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	PayloadSHA256 string `json:"payload_sha256,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// auditOperation is the resource operation making calls to oncall, carried in
+// the context so its calls can be attributed to it
+type auditOperation struct {
+	resourceType string
+	operation    string
+	resourceID   string
+}
+
+type auditOperationKey struct{}
+
+func openAuditLog(path string) (*auditLog, error) {
+	if path == "" {
+		return nil, nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Opening audit log %s", path)
+	}
+	return &auditLog{file: f}, nil
+}
+
+// auditResourceOperation records which resource operation is calling oncall
+func auditResourceOperation(resourceType, operation string, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		op := &auditOperation{resourceType: resourceType, operation: operation, resourceID: d.Id()}
+		return f(context.WithValue(ctx, auditOperationKey{}, op), d, m)
+	}
+}
+
+// payloadHash is the SHA-256 of the request body, empty if there is none
+func payloadHash(req *http.Request) string {
+	if req.Body == nil || req.Body == http.NoBody {
+		return ""
+	}
+
+	var body []byte
+	var err error
+	if req.GetBody != nil {
+		rc, getErr := req.GetBody()
+		if getErr != nil {
+			return ""
+		}
+		body, err = ioutil.ReadAll(rc)
+		rc.Close()
+	} else {
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if err != nil || len(body) == 0 {
+		return ""
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// auditEntryFor describes a request, taking the payload hash before it is sent
+func auditEntryFor(ctx context.Context, req *http.Request, now time.Time) auditEntry {
+	entry := auditEntry{
+		Time:          now.UTC().Format(time.RFC3339Nano),
+		Method:        req.Method,
+		Path:          req.URL.Path,
+		PayloadSHA256: payloadHash(req),
+	}
+	if op, ok := ctx.Value(auditOperationKey{}).(*auditOperation); ok {
+		entry.Resource = op.resourceType
+		entry.Operation = op.operation
+		entry.ResourceID = op.resourceID
+	}
+	return entry
+}
+
+// auditRoundTripper writes the requests that change something to the audit log
+type auditRoundTripper struct {
+	auditLog *auditLog
+	proxied  http.RoundTripper
+}
+
+func (art auditRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !mutatingMethod(req.Method) {
+		return art.proxied.RoundTrip(req)
+	}
+	entry := auditEntryFor(req.Context(), req, time.Now())
+	resp, err := art.proxied.RoundTrip(req)
+	art.auditLog.record(entry, resp, err)
+	return resp, err
+}
+
+// record appends the entry with the outcome of the request. Reads are left
+// out, as they don't change anything.
+func (a *auditLog) record(entry auditEntry, resp *http.Response, err error) {
+	if a == nil {
+		return
+	}
+	if resp != nil {
+		entry.Status = resp.StatusCode
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	line, marshalErr := json.Marshal(entry)
+	if marshalErr != nil {
+		warnLog("Could not encode audit log entry for %s %s: %s", entry.Method, entry.Path, marshalErr)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, writeErr := a.file.Write(append(line, '\n')); writeErr != nil {
+		warnLog("Could not write audit log entry for %s %s: %s", entry.Method, entry.Path, writeErr)
+	}
+}
+
+// mutatingMethod is whether a request with the method changes something
+func mutatingMethod(method string) bool {
+	return method != http.MethodGet && method != http.MethodHead && method != http.MethodOptions
+}
@@ -0,0 +1,103 @@
+package oncall
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_auditLog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v0/teams/team":
+			w.Write([]byte(`{"name": "team"}`))
+		case "PUT /api/v0/teams/team":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(403)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "audit")
+	if err != nil {
+		t.Fatalf("Creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.jsonl")
+	a, err := openAuditLog(path)
+	if err != nil {
+		t.Fatalf("openAuditLog() error = %v", err)
+	}
+	defer a.file.Close()
+	meta := &providerMeta{client: c, auditLog: a}
+
+	update := auditResourceOperation("oncall_team", "update", func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		c := m.(*providerMeta).clientFor(ctx)
+		if _, err := getTeam(c, "team"); err != nil {
+			return diag.FromErr(err)
+		}
+		description := "The team"
+		if _, err := c.Put("/api/v0/teams/team", teamExtras{Description: &description}, nil); err != nil {
+			return diag.FromErr(err)
+		}
+		c.Delete("/api/v0/teams/team", nil, nil)
+		return nil
+	})
+	d := schema.TestResourceDataRaw(t, resourceTeam().Schema, map[string]interface{}{})
+	d.SetId("team")
+	update(context.Background(), d, meta)
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Reading audit log: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Audit log has %d lines, want 2 (reads are left out):\n%s", len(lines), raw)
+	}
+
+	payload, _ := json.Marshal(teamExtras{Description: stringPtr("The team")})
+	sum := sha256.Sum256(payload)
+	// The oncall client sends a JSON null when there is no body
+	nullSum := sha256.Sum256([]byte("null"))
+	want := []auditEntry{
+		{Resource: "oncall_team", Operation: "update", ResourceID: "team", Method: "PUT", Path: "/api/v0/teams/team", PayloadSHA256: hex.EncodeToString(sum[:]), Status: 200},
+		{Resource: "oncall_team", Operation: "update", ResourceID: "team", Method: "DELETE", Path: "/api/v0/teams/team", PayloadSHA256: hex.EncodeToString(nullSum[:]), Status: 403},
+	}
+	for i, line := range lines {
+		got := auditEntry{}
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("Audit log line %q is not JSON: %v", line, err)
+		}
+		if got.Time == "" {
+			t.Errorf("Audit log line %q has no time", line)
+		}
+		got.Time = ""
+		if !reflect.DeepEqual(got, want[i]) {
+			t.Errorf("Audit log line %d = %+v, want %+v", i, got, want[i])
+		}
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,57 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	// minAutoPopulateDays is a week, oncall populates whole weeks so anything
+	// less, or 0 which leaves the calendar empty, doesn't do what it says
+	minAutoPopulateDays = 7
+	// defaultMaxAutoPopulateDays is the provider's max_auto_populate_days
+	defaultMaxAutoPopulateDays = 365
+	// autoPopulateDaysLimit is the most max_auto_populate_days can allow, as
+	// oncall creates an event per shift up to the horizon every time it
+	// populates, which takes too long to finish any further out
+	autoPopulateDaysLimit = 3650
+)
+
+// autoPopulateDaysDescription is how the bounds of auto_populate_days are
+// explained, in its description and when it is out of them
+func autoPopulateDaysDescription(max int) string {
+	return fmt.Sprintf("At least %d, as oncall populates whole weeks, and at most the provider's %s (%d by default), as oncall creates an event for every shift up to this many days ahead each time it populates", minAutoPopulateDays, providerFieldMaxAutoPopulateDays, max)
+}
+
+func validateAutoPopulateDays(val interface{}, path cty.Path) diag.Diagnostics {
+	days := val.(int)
+	if days >= minAutoPopulateDays && days <= autoPopulateDaysLimit {
+		return nil
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("%s must be between %d and %d, got %d", scheduleFieldAutoPopulateDays, minAutoPopulateDays, autoPopulateDaysLimit, days),
+			Detail:        autoPopulateDaysDescription(defaultMaxAutoPopulateDays) + ".",
+			AttributePath: path,
+		},
+	}
+}
+
+// autoPopulateDaysCustomizeDiff checks auto_populate_days against the
+// provider's max_auto_populate_days, which schema validation can't see
+func autoPopulateDaysCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	meta, ok := m.(*providerMeta)
+	if !ok || meta.maxAutoPopulateDays == 0 || !d.HasChange(scheduleFieldAutoPopulateDays) {
+		return nil
+	}
+	days := d.Get(scheduleFieldAutoPopulateDays).(int)
+	if days <= meta.maxAutoPopulateDays {
+		return nil
+	}
+	return fmt.Errorf("%s is %d, more than the provider's %s of %d. %s. Raise %s if the oncall instance copes with populating that far ahead", scheduleFieldAutoPopulateDays, days, providerFieldMaxAutoPopulateDays, meta.maxAutoPopulateDays, autoPopulateDaysDescription(defaultMaxAutoPopulateDays), providerFieldMaxAutoPopulateDays)
+}
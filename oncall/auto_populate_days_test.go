@@ -0,0 +1,30 @@
+package oncall
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func Test_validateAutoPopulateDays(t *testing.T) {
+	tests := []struct {
+		name    string
+		days    int
+		wantErr bool
+	}{
+		{name: "Default", days: 21},
+		{name: "A week", days: minAutoPopulateDays},
+		{name: "A year", days: 365},
+		{name: "Zero", days: 0, wantErr: true},
+		{name: "Less than a week", days: 5, wantErr: true},
+		{name: "Past the limit", days: 5000, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := validateAutoPopulateDays(tt.days, cty.Path{cty.GetAttrStep{Name: scheduleFieldAutoPopulateDays}})
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("validateAutoPopulateDays() = %v, wantErr %v", diags, tt.wantErr)
+			}
+		})
+	}
+}
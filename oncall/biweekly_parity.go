@@ -0,0 +1,80 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// epochDay is the weekday of 1970-01-01, where week numbers are counted from
+const epochDay = time.Thursday
+
+// offsetWeeksSchema is the schema of offset_weeks. Oncall starts a rotation's
+// epochs at the start of the week it is populated from, so which weeks a
+// bi-weekly schedule hands off on depends on when it happens to be populated.
+// offset_weeks pins it to the weeks numbered from the first week of 1970
+// instead: 0 hands off on even weeks, 1 on odd ones.
+func offsetWeeksSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeInt,
+		Optional:         true,
+		ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(0, 1)),
+		Description:      "For bi-weekly rotations, which weeks the rotation hands off on, 0 or 1, so two staggered bi-weekly schedules (A-week and B-week) stay a week apart however they are populated. Weeks are numbered from the first week of 1970 in the team's scheduling timezone. Unset leaves it to when oncall populates the schedule",
+	}
+}
+
+// weekNumber is how many weeks starting on weekStart there have been between
+// the first week of 1970 and the week of t, in t's location
+func weekNumber(t time.Time, weekStart time.Weekday) int {
+	date := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(date.Unix() / daySeconds)
+	// Day 0 is a Thursday, shift so the week boundaries fall on weekStart
+	return (days + (int(epochDay)-int(weekStart)+7)%7) / 7
+}
+
+// parityPopulateStart is when to populate from so the rotation's epochs start
+// on weeks of the given parity, now if this week has it, or else next week
+func parityPopulateStart(now time.Time, loc *time.Location, weekStart time.Weekday, offsetWeeks int) time.Time {
+	if weekNumber(now.In(loc), weekStart)%2 == offsetWeeks {
+		return now
+	}
+	return now.AddDate(0, 0, 7)
+}
+
+// basicSchedulePopulateStart is when to populate the basic schedule from,
+// keeping it on the parity of offset_weeks when that is set
+func basicSchedulePopulateStart(c *oncall.Client, d *schema.ResourceData, teamName string, weekStart time.Weekday) time.Time {
+	now := time.Now()
+	offset, ok := d.GetOkExists(basicScheduleFieldOffsetWeeks)
+	if !ok {
+		return now
+	}
+
+	loc := time.UTC
+	t, err := getTeam(c, teamName)
+	if err == nil {
+		loc, err = time.LoadLocation(t.SchedulingTimezone)
+	}
+	if err != nil {
+		warnLog("Could not get the timezone of team %s, counting %s in UTC: %s", teamName, basicScheduleFieldOffsetWeeks, err)
+		loc = time.UTC
+	}
+	return parityPopulateStart(now, loc, weekStart, offset.(int))
+}
+
+// offsetWeeksCustomizeDiff checks offset_weeks is only set on bi-weekly
+// rotations, the only ones it means anything for
+func offsetWeeksCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if _, ok := d.GetOkExists(basicScheduleFieldOffsetWeeks); !ok {
+		return nil
+	}
+	weeks := d.Get(basicScheduleFieldRotateEveryWeeks).(int)
+	if weeks == 2 || (weeks == 0 && d.Get(basicScheduleFieldRotateFrequency).(string) == basicScheduleRotationBiWeekly) {
+		return nil
+	}
+	return fmt.Errorf("%s can only be set on bi-weekly rotations, set %s = %q or %s = 2", basicScheduleFieldOffsetWeeks, basicScheduleFieldRotateFrequency, basicScheduleRotationBiWeekly, basicScheduleFieldRotateEveryWeeks)
+}
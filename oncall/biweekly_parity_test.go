@@ -0,0 +1,81 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_weekNumber(t *testing.T) {
+	tests := []struct {
+		name      string
+		t         time.Time
+		weekStart time.Weekday
+		want      int
+	}{
+		{
+			name:      "First week of 1970",
+			t:         time.Date(1970, 1, 1, 12, 0, 0, 0, time.UTC),
+			weekStart: time.Sunday,
+			want:      0,
+		},
+		{
+			name:      "Sunday starts the next week",
+			t:         time.Date(1970, 1, 4, 0, 0, 0, 0, time.UTC),
+			weekStart: time.Sunday,
+			want:      1,
+		},
+		{
+			name:      "Sunday is still the first week with Monday weeks",
+			t:         time.Date(1970, 1, 4, 23, 0, 0, 0, time.UTC),
+			weekStart: time.Monday,
+			want:      0,
+		},
+		{
+			name:      "Monday starts the next week with Monday weeks",
+			t:         time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC),
+			weekStart: time.Monday,
+			want:      1,
+		},
+		{
+			name:      "Counted on the local date",
+			t:         time.Date(1970, 1, 5, 1, 0, 0, 0, time.UTC).In(time.FixedZone("UTC-5", -5*60*60)),
+			weekStart: time.Monday,
+			want:      0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weekNumber(tt.t, tt.weekStart); got != tt.want {
+				t.Errorf("weekNumber() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parityPopulateStart(t *testing.T) {
+	// Week 2795 of weeks starting on Monday
+	now := time.Date(2023, 7, 26, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name        string
+		offsetWeeks int
+		want        time.Time
+	}{
+		{
+			name:        "This week has the parity",
+			offsetWeeks: 1,
+			want:        now,
+		},
+		{
+			name:        "Next week has the parity",
+			offsetWeeks: 0,
+			want:        now.AddDate(0, 0, 7),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parityPopulateStart(now, time.UTC, time.Monday, tt.offsetWeeks); !got.Equal(tt.want) {
+				t.Errorf("parityPopulateStart() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+// clientFor returns a copy of the oncall client whose requests are bound to ctx,
+// so cancelling an apply aborts any in flight calls to oncall. Each of the
+// provider's concerns with the requests is a round tripper of its own, chained
+// with the outermost first:
+//
+//	context → team prefix → not found cache → audit log → telemetry → team admin elevation → session → oncall client auth
+func (p *providerMeta) clientFor(ctx context.Context) *oncall.Client {
+	c := *p.client
+	httpClient := *p.client.Client
+
+	transport := p.client.Client.Transport
+	if p.session != nil {
+		transport = sessionRoundTripper{session: p.session, proxied: transport}
+	}
+	transport = teamAdminRoundTripper{proxied: transport}
+	transport = telemetryRoundTripper{proxied: transport}
+	if p.auditLog != nil {
+		transport = auditRoundTripper{auditLog: p.auditLog, proxied: transport}
+	}
+	if p.missingTeams != nil {
+		transport = missingTeamsRoundTripper{missing: p.missingTeams, proxied: transport}
+	}
+	if p.teamPrefix != "" {
+		transport = teamPrefixRoundTripper{teamPrefix: p.teamPrefix, proxied: transport}
+	}
+	httpClient.Transport = contextRoundTripper{ctx: ctx, proxied: transport}
+
+	c.Client = &httpClient
+	return &c
+}
+
+// contextRoundTripper attaches a context to every request that goes through
+// it, so the round trippers after it can find the operation the request is
+// made for in the request's context
+type contextRoundTripper struct {
+	ctx     context.Context
+	proxied http.RoundTripper
+}
+
+func (crt contextRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := crt.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return crt.proxied.RoundTrip(req.WithContext(crt.ctx))
+}
@@ -0,0 +1,79 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_clientForCancelledContext(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c}
+
+	_, err = meta.clientFor(context.Background()).GetTeams()
+	if err != nil {
+		t.Fatalf("GetTeams() with a live context error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = meta.clientFor(ctx).GetTeams()
+	if err == nil {
+		t.Fatalf("GetTeams() with a cancelled context did not error")
+	}
+	if requests != 1 {
+		t.Errorf("Server saw %d requests, want 1", requests)
+	}
+
+	diags := diagFromErrf(err, "Getting teams")
+	if !strings.Contains(diags[0].Summary, "cancelled") {
+		t.Errorf("diagFromErrf() summary = %q, want it to mention the cancellation", diags[0].Summary)
+	}
+}
+
+func Test_clientForLayers(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c, teamPrefix: "acme-", missingTeams: newMissingTeams()}
+	client := meta.clientFor(context.Background())
+
+	// Refused by the team prefix before anything else sees it
+	if _, err = getTeam(client, "other-team"); err == nil {
+		t.Errorf("getTeam() outside of the team prefix did not error")
+	}
+	// The first lookup of a deleted team reaches oncall, the next is answered
+	// from the not found cache
+	for i := 0; i < 2; i++ {
+		if _, err = getTeam(client, "acme-platform"); !isNotFound(err) {
+			t.Errorf("getTeam() of a deleted team error = %v, want not found", err)
+		}
+	}
+
+	want := []string{"GET /api/v0/teams/acme-platform"}
+	if strings.Join(requests, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Server saw requests %v, want %v", requests, want)
+	}
+}
@@ -0,0 +1,117 @@
+package oncall
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// connectionSettings are the provider arguments for connecting to oncall,
+// which can also be given in a config_file, so runners can mount a single
+// secret file instead of setting an environment variable for each
+type connectionSettings struct {
+	Endpoint           string `yaml:"endpoint"`
+	Username           string `yaml:"username"`
+	Password           string `yaml:"password"`
+	AuthType           string `yaml:"auth_type"`
+	MaxIdleConnections int    `yaml:"max_idle_connections"`
+	IdleTimeout        string `yaml:"idle_connection_timeout"`
+	EnableHTTP2        *bool  `yaml:"enable_http2"`
+	ClientCertificate  string `yaml:"client_certificate"`
+	ClientKey          string `yaml:"client_key"`
+}
+
+// connectionSettingsFor reads the connection arguments of the provider,
+// filling those left unset, or at their defaults, from the config_file
+func connectionSettingsFor(d *schema.ResourceData) (connectionSettings, error) {
+	http2 := d.Get(providerFieldEnableHTTP2).(bool)
+	settings := connectionSettings{
+		Endpoint:           d.Get(providerFieldEndpoint).(string),
+		Username:           d.Get(providerFieldUsername).(string),
+		Password:           d.Get(providerFieldPassword).(string),
+		AuthType:           d.Get(providerFieldAuthType).(string),
+		MaxIdleConnections: d.Get(providerFieldMaxIdleConnections).(int),
+		IdleTimeout:        d.Get(providerFieldIdleTimeout).(string),
+		EnableHTTP2:        &http2,
+		ClientCertificate:  d.Get(providerFieldClientCertificate).(string),
+		ClientKey:          d.Get(providerFieldClientKey).(string),
+	}
+
+	path := d.Get(providerFieldConfigFile).(string)
+	if path == "" {
+		return settings, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return settings, errors.Wrapf(err, "Reading %s", providerFieldConfigFile)
+	}
+	file, err := parseConfigFile(data)
+	if err != nil {
+		return settings, errors.Wrapf(err, "Parsing %s %s", providerFieldConfigFile, path)
+	}
+	traceLog("Read connection settings from %s", path)
+	return settings.withDefaults(file), nil
+}
+
+// parseConfigFile reads connection settings from YAML, or JSON as YAML is a
+// superset of it, e.g.
+//
+//	endpoint: https://oncall.example.com
+//	username: terraform
+//	password: secret
+//	auth_type: api
+func parseConfigFile(data []byte) (connectionSettings, error) {
+	file := connectionSettings{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	// A misspelled setting would otherwise be silently ignored
+	decoder.KnownFields(true)
+	err := decoder.Decode(&file)
+	if err != nil && err != io.EOF {
+		return file, err
+	}
+	return file, nil
+}
+
+// withDefaults fills the settings that are unset or at their defaults from
+// the file's, so arguments set in the provider block or by their environment
+// variables take precedence over the file
+func (s connectionSettings) withDefaults(file connectionSettings) connectionSettings {
+	defaults := Provider().Schema
+	isDefault := func(field string, value interface{}) bool {
+		def, err := defaults[field].DefaultValue()
+		return err == nil && def == value
+	}
+
+	if s.Endpoint == "" {
+		s.Endpoint = file.Endpoint
+	}
+	if s.Username == "" {
+		s.Username = file.Username
+	}
+	if s.Password == "" {
+		s.Password = file.Password
+	}
+	if s.ClientCertificate == "" {
+		s.ClientCertificate = file.ClientCertificate
+	}
+	if s.ClientKey == "" {
+		s.ClientKey = file.ClientKey
+	}
+	if file.AuthType != "" && (s.AuthType == "" || isDefault(providerFieldAuthType, s.AuthType)) {
+		s.AuthType = file.AuthType
+	}
+	if file.MaxIdleConnections != 0 && isDefault(providerFieldMaxIdleConnections, s.MaxIdleConnections) {
+		s.MaxIdleConnections = file.MaxIdleConnections
+	}
+	if file.IdleTimeout != "" && isDefault(providerFieldIdleTimeout, s.IdleTimeout) {
+		s.IdleTimeout = file.IdleTimeout
+	}
+	if file.EnableHTTP2 != nil && isDefault(providerFieldEnableHTTP2, *s.EnableHTTP2) {
+		s.EnableHTTP2 = file.EnableHTTP2
+	}
+	return s
+}
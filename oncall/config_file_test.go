@@ -0,0 +1,84 @@
+package oncall
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_connectionSettingsFor(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatalf("Writing %s: %s", name, err)
+		}
+		return path
+	}
+	yamlFile := write("oncall.yaml", "endpoint: https://oncall.example.com\nusername: terraform\npassword: secret\nauth_type: api\nmax_idle_connections: 50\nenable_http2: false\n")
+	jsonFile := write("oncall.json", `{"endpoint": "https://oncall.example.com", "idle_connection_timeout": "5m"}`)
+	typoFile := write("typo.yaml", "endpoint: https://oncall.example.com\npasword: secret\n")
+
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		want    connectionSettings
+		wantErr bool
+	}{
+		{
+			name:   "No file",
+			config: map[string]interface{}{providerFieldEndpoint: "https://oncall.example.com"},
+			want:   connectionSettings{Endpoint: "https://oncall.example.com", AuthType: "user", MaxIdleConnections: 10, IdleTimeout: "90s"},
+		},
+		{
+			name:   "YAML file",
+			config: map[string]interface{}{providerFieldConfigFile: yamlFile},
+			want:   connectionSettings{Endpoint: "https://oncall.example.com", Username: "terraform", Password: "secret", AuthType: "api", MaxIdleConnections: 50, IdleTimeout: "90s"},
+		},
+		{
+			name:   "JSON file",
+			config: map[string]interface{}{providerFieldConfigFile: jsonFile},
+			want:   connectionSettings{Endpoint: "https://oncall.example.com", AuthType: "user", MaxIdleConnections: 10, IdleTimeout: "5m"},
+		},
+		{
+			name:   "Provider block takes precedence",
+			config: map[string]interface{}{providerFieldConfigFile: yamlFile, providerFieldEndpoint: "https://other.example.com", providerFieldUsername: "ci", providerFieldMaxIdleConnections: 20},
+			want:   connectionSettings{Endpoint: "https://other.example.com", Username: "ci", Password: "secret", AuthType: "api", MaxIdleConnections: 20, IdleTimeout: "90s"},
+		},
+		{
+			name:    "Unknown setting",
+			config:  map[string]interface{}{providerFieldConfigFile: typoFile},
+			wantErr: true,
+		},
+		{
+			name:    "Missing file",
+			config:  map[string]interface{}{providerFieldConfigFile: filepath.Join(dir, "missing.yaml")},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, Provider().Schema, tt.config)
+			got, err := connectionSettingsFor(d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("connectionSettingsFor() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.EnableHTTP2 == nil {
+				t.Fatalf("connectionSettingsFor() left enable_http2 unset")
+			}
+			wantHTTP2 := tt.name != "YAML file" && tt.name != "Provider block takes precedence"
+			if *got.EnableHTTP2 != wantHTTP2 {
+				t.Errorf("connectionSettingsFor() enable_http2 = %v, want %v", *got.EnableHTTP2, wantHTTP2)
+			}
+			got.EnableHTTP2 = nil
+			if got != tt.want {
+				t.Errorf("connectionSettingsFor() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
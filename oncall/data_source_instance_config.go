@@ -0,0 +1,88 @@
+package oncall
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	instanceConfigFieldEndpoint          = "endpoint"
+	instanceConfigFieldTimezones         = "timezones"
+	instanceConfigFieldRoles             = "roles"
+	instanceConfigFieldNotificationModes = "notification_modes"
+)
+
+func dataSourceInstanceConfig() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceInstanceConfigRead,
+		Schema: map[string]*schema.Schema{
+			instanceConfigFieldEndpoint: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Endpoint of the oncall instance the provider is connected to, everything before '/api/v0' in the URL",
+			},
+			instanceConfigFieldTimezones: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Timezones teams can be scheduled in, the supported_timezones setting of the oncall instance",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			instanceConfigFieldRoles: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Names of the roles configured on the oncall instance, ordered by their display order",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+			instanceConfigFieldNotificationModes: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Ways the oncall instance can contact users, e.g. email, sms, or call",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceInstanceConfigRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	timezones, err := getTimezones(c)
+	if err != nil {
+		return diagFromErrf(err, "Getting oncall timezones")
+	}
+
+	roles, err := getRoles(c)
+	if err != nil {
+		return diagFromErrf(err, "Getting oncall roles")
+	}
+	roleNames := make([]string, 0, len(roles))
+	for _, r := range roles {
+		roleNames = append(roleNames, r.Name)
+	}
+
+	modes, err := getNotificationModes(c)
+	if err != nil {
+		return diagFromErrf(err, "Getting oncall notification modes")
+	}
+
+	d.Set(instanceConfigFieldEndpoint, c.Config.Endpoint)
+	d.Set(instanceConfigFieldTimezones, timezones)
+	d.Set(instanceConfigFieldRoles, roleNames)
+	d.Set(instanceConfigFieldNotificationModes, modes)
+
+	// There is only one config per oncall instance
+	d.SetId(c.Config.Endpoint)
+
+	return diags
+}
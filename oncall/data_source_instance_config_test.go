@@ -0,0 +1,53 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceInstanceConfigRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/timezones":
+			w.Write([]byte(`["US/Central", "UTC"]`))
+		case "/api/v0/roles":
+			w.Write([]byte(`[{"name": "secondary", "display_order": 2}, {"name": "primary", "display_order": 1}]`))
+		case "/api/v0/modes":
+			w.Write([]byte(`["call", "email", "sms"]`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL + "/", AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceInstanceConfig().Schema, map[string]interface{}{})
+	diags := dataSourceInstanceConfigRead(context.Background(), d, &providerMeta{client: c})
+	if diags.HasError() {
+		t.Fatalf("dataSourceInstanceConfigRead() = %v", diags)
+	}
+
+	if got := d.Get(instanceConfigFieldEndpoint).(string); got != server.URL {
+		t.Errorf("endpoint = %s, want %s", got, server.URL)
+	}
+	want := map[string][]interface{}{
+		instanceConfigFieldTimezones:         {"US/Central", "UTC"},
+		instanceConfigFieldRoles:             {"primary", "secondary"},
+		instanceConfigFieldNotificationModes: {"call", "email", "sms"},
+	}
+	for field, values := range want {
+		if got := d.Get(field).([]interface{}); !reflect.DeepEqual(got, values) {
+			t.Errorf("%s = %v, want %v", field, got, values)
+		}
+	}
+}
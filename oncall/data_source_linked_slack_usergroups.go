@@ -0,0 +1,81 @@
+package oncall
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	linkedSlackUsergroupsFieldUsergroups = "usergroups"
+)
+
+func dataSourceLinkedSlackUsergroups() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceLinkedSlackUsergroupsRead,
+		Schema: map[string]*schema.Schema{
+			linkedSlackUsergroupFieldTeam: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the team to list the slack usergroup links of",
+			},
+			linkedSlackUsergroupsFieldUsergroups: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Slack usergroups linked to the team's roles, ordered by role",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						linkedSlackUsergroupFieldRole: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Role whose on call the usergroup mirrors",
+						},
+						linkedSlackUsergroupFieldUsergroup: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Handle of the slack usergroup",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceLinkedSlackUsergroupsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName := d.Get(linkedSlackUsergroupFieldTeam).(string)
+	diags = teamPrefixDiags(m, linkedSlackUsergroupFieldTeam, teamName)
+	if diags.HasError() {
+		return diags
+	}
+	usergroups, err := getTeamSlackUsergroups(c, teamName)
+	if err != nil {
+		return diagFromErrf(err, "Getting slack usergroups of team %s", teamName)
+	}
+
+	roles := make([]string, 0, len(usergroups))
+	for role := range usergroups {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+
+	usergroupList := make([]map[string]interface{}, 0, len(roles))
+	for _, role := range roles {
+		usergroupList = append(usergroupList, map[string]interface{}{
+			linkedSlackUsergroupFieldRole:      role,
+			linkedSlackUsergroupFieldUsergroup: usergroups[role],
+		})
+	}
+	d.Set(linkedSlackUsergroupsFieldUsergroups, usergroupList)
+
+	d.SetId(teamName)
+
+	return diags
+}
@@ -0,0 +1,231 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	oncallHistoryFieldTeam          = "team"
+	oncallHistoryFieldRole          = "role"
+	oncallHistoryFieldStart         = "start"
+	oncallHistoryFieldEnd           = "end"
+	oncallHistoryFieldMaxWindowDays = "max_window_days"
+	oncallHistoryFieldPageDays      = "page_days"
+	oncallHistoryFieldAssignments   = "assignments"
+	oncallHistoryFieldHoursByUser   = "hours_by_user"
+
+	oncallAssignmentFieldEventID = "event_id"
+	oncallAssignmentFieldUser    = "user"
+	oncallAssignmentFieldStart   = "start"
+	oncallAssignmentFieldEnd     = "end"
+)
+
+func dataSourceOncallHistory() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOncallHistoryRead,
+		Schema: map[string]*schema.Schema{
+			oncallHistoryFieldTeam: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the team to get the on call history of",
+			},
+			oncallHistoryFieldRole: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Role to get the on call history of, e.g. primary",
+			},
+			oncallHistoryFieldStart: &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+				Description:      "Start of the window (RFC 3339), e.g. 2025-01-01T00:00:00Z",
+			},
+			oncallHistoryFieldEnd: &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+				Description:      "End of the window (RFC 3339), e.g. 2025-02-01T00:00:00Z",
+			},
+			oncallHistoryFieldMaxWindowDays: &schema.Schema{
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          93,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "Longest window that can be asked for, to stop a typo from fetching years of events",
+			},
+			oncallHistoryFieldPageDays: &schema.Schema{
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          7,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "How many days of events to fetch from oncall per request",
+			},
+			oncallHistoryFieldAssignments: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Events of the role that overlap the window, ordered by start. Shifts that straddle the window are included whole",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						oncallAssignmentFieldEventID: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "ID of the calendar event",
+						},
+						oncallAssignmentFieldUser: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Username of who was on call",
+						},
+						oncallAssignmentFieldStart: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Start of the shift (RFC 3339)",
+						},
+						oncallAssignmentFieldEnd: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "End of the shift (RFC 3339)",
+						},
+					},
+				},
+			},
+			oncallHistoryFieldHoursByUser: &schema.Schema{
+				Type:        schema.TypeMap,
+				Computed:    true,
+				Description: "Hours each user was on call within the window, by username",
+				Elem: &schema.Schema{
+					Type: schema.TypeFloat,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceOncallHistoryRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName := d.Get(oncallHistoryFieldTeam).(string)
+	diags = teamPrefixDiags(m, oncallHistoryFieldTeam, teamName)
+	if diags.HasError() {
+		return diags
+	}
+	role := d.Get(oncallHistoryFieldRole).(string)
+	start, err := time.Parse(time.RFC3339, d.Get(oncallHistoryFieldStart).(string))
+	if err != nil {
+		return diagFromErrf(err, "Parsing %s", oncallHistoryFieldStart)
+	}
+	end, err := time.Parse(time.RFC3339, d.Get(oncallHistoryFieldEnd).(string))
+	if err != nil {
+		return diagFromErrf(err, "Parsing %s", oncallHistoryFieldEnd)
+	}
+
+	if !end.After(start) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("%s must be after %s", oncallHistoryFieldEnd, oncallHistoryFieldStart),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: oncallHistoryFieldEnd}},
+			},
+		}
+	}
+	maxWindowDays := d.Get(oncallHistoryFieldMaxWindowDays).(int)
+	if end.Sub(start) > time.Duration(maxWindowDays)*24*time.Hour {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("Window of %s is longer than %d days", end.Sub(start), maxWindowDays),
+				Detail:        fmt.Sprintf("Ask for a shorter window, or raise %s if fetching that much history is intended.", oncallHistoryFieldMaxWindowDays),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: oncallHistoryFieldEnd}},
+			},
+		}
+	}
+
+	events, err := getEventHistory(c, teamName, role, start, end, time.Duration(d.Get(oncallHistoryFieldPageDays).(int))*24*time.Hour)
+	if err != nil {
+		return diagFromErrf(err, "Getting on call history of team %s", teamName)
+	}
+
+	assignments := make([]map[string]interface{}, 0, len(events))
+	for _, e := range events {
+		assignments = append(assignments, map[string]interface{}{
+			oncallAssignmentFieldEventID: e.ID,
+			oncallAssignmentFieldUser:    e.User,
+			oncallAssignmentFieldStart:   time.Unix(e.Start, 0).UTC().Format(time.RFC3339),
+			oncallAssignmentFieldEnd:     time.Unix(e.End, 0).UTC().Format(time.RFC3339),
+		})
+	}
+	d.Set(oncallHistoryFieldAssignments, assignments)
+	d.Set(oncallHistoryFieldHoursByUser, hoursOnCall(events, start, end))
+
+	d.SetId(fmt.Sprintf("%s/%s/%d-%d", escapeIDPart(teamName), escapeIDPart(role), start.Unix(), end.Unix()))
+
+	return diags
+}
+
+// getEventHistory returns the events of the role that overlap the window,
+// fetching a page at a time so long windows don't make oncall return every
+// event in one response. Events spanning pages are only returned once.
+func getEventHistory(c *oncall.Client, team, role string, start, end time.Time, page time.Duration) ([]scheduleEvent, error) {
+	seen := map[int]bool{}
+	events := []scheduleEvent{}
+	for pageStart := start; pageStart.Before(end); pageStart = pageStart.Add(page) {
+		pageEnd := pageStart.Add(page)
+		if pageEnd.After(end) {
+			pageEnd = end
+		}
+
+		pageEvents, err := getEventsBetween(c, team, role, pageStart, pageEnd)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range pageEvents {
+			if !seen[e.ID] {
+				seen[e.ID] = true
+				events = append(events, e)
+			}
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].Start != events[j].Start {
+			return events[i].Start < events[j].Start
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
+
+// hoursOnCall totals how long each user was on call within the window
+func hoursOnCall(events []scheduleEvent, start, end time.Time) map[string]interface{} {
+	seconds := map[string]int64{}
+	for _, e := range events {
+		from, to := e.Start, e.End
+		if from < start.Unix() {
+			from = start.Unix()
+		}
+		if to > end.Unix() {
+			to = end.Unix()
+		}
+		if to > from {
+			seconds[e.User] += to - from
+		}
+	}
+
+	hours := make(map[string]interface{}, len(seconds))
+	for user, s := range seconds {
+		hours[user] = float64(s) / 3600
+	}
+	return hours
+}
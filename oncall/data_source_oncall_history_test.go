@@ -0,0 +1,110 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceOncallHistoryRead(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	// 2025-01-01T00:00:00Z
+	jan1 := int64(1735689600)
+	allEvents := []scheduleEvent{
+		{ID: 1, User: "alice", Start: jan1 - 3*day, End: jan1 + 4*day},
+		{ID: 2, User: "bob", Start: jan1 + 4*day, End: jan1 + 11*day},
+		{ID: 3, User: "alice", Start: jan1 + 11*day, End: jan1 + 18*day},
+	}
+
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/events" || r.URL.Query().Get("team__eq") != "team" || r.URL.Query().Get("role__eq") != "primary" {
+			w.WriteHeader(404)
+			return
+		}
+		requests++
+		start, _ := strconv.ParseInt(r.URL.Query().Get("end__gt"), 10, 64)
+		end, _ := strconv.ParseInt(r.URL.Query().Get("start__lt"), 10, 64)
+		body := "["
+		for _, e := range allEvents {
+			if e.End > start && e.Start < end {
+				if body != "[" {
+					body += ","
+				}
+				body += `{"id":` + strconv.Itoa(e.ID) + `,"user":"` + e.User + `","start":` + strconv.FormatInt(e.Start, 10) + `,"end":` + strconv.FormatInt(e.End, 10) + `}`
+			}
+		}
+		w.Write([]byte(body + "]"))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		end          string
+		wantErr      bool
+		wantUsers    []string
+		wantHours    map[string]interface{}
+		wantRequests int
+	}{
+		{
+			name:         "Two weeks in pages of a week",
+			end:          "2025-01-15T00:00:00Z",
+			wantUsers:    []string{"alice", "bob", "alice"},
+			wantHours:    map[string]interface{}{"alice": float64(7 * 24), "bob": float64(7 * 24)},
+			wantRequests: 2,
+		},
+		{
+			name:    "Window longer than the max",
+			end:     "2025-06-01T00:00:00Z",
+			wantErr: true,
+		},
+		{
+			name:    "Window ending before it starts",
+			end:     "2024-12-01T00:00:00Z",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = 0
+			d := schema.TestResourceDataRaw(t, dataSourceOncallHistory().Schema, map[string]interface{}{
+				oncallHistoryFieldTeam:  "team",
+				oncallHistoryFieldRole:  "primary",
+				oncallHistoryFieldStart: "2025-01-01T00:00:00Z",
+				oncallHistoryFieldEnd:   tt.end,
+			})
+			diags := dataSourceOncallHistoryRead(context.Background(), d, &providerMeta{client: c})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("dataSourceOncallHistoryRead() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			users := []string{}
+			for _, a := range d.Get(oncallHistoryFieldAssignments).([]interface{}) {
+				users = append(users, a.(map[string]interface{})[oncallAssignmentFieldUser].(string))
+			}
+			if !reflect.DeepEqual(users, tt.wantUsers) {
+				t.Errorf("assignments users = %v, want %v", users, tt.wantUsers)
+			}
+			if got := d.Get(oncallHistoryFieldHoursByUser).(map[string]interface{}); !reflect.DeepEqual(got, tt.wantHours) {
+				t.Errorf("hours_by_user = %v, want %v", got, tt.wantHours)
+			}
+			if requests != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", requests, tt.wantRequests)
+			}
+		})
+	}
+}
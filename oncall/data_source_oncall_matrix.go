@@ -0,0 +1,126 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	oncallMatrixFieldTeams  = "teams"
+	oncallMatrixFieldOncall = "oncall"
+
+	oncallMatrixTeamFieldTeam  = "team"
+	oncallMatrixTeamFieldRoles = "roles"
+)
+
+func dataSourceOncallMatrix() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceOncallMatrixRead,
+		Schema: map[string]*schema.Schema{
+			oncallMatrixFieldTeams: &schema.Schema{
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of the teams to get who is on call for",
+			},
+			oncallMatrixFieldOncall: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Who is on call for each team, in the order of teams",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						oncallMatrixTeamFieldTeam: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the team",
+						},
+						oncallMatrixTeamFieldRoles: &schema.Schema{
+							Type:        schema.TypeMap,
+							Computed:    true,
+							Description: "Username on call right now by role, roles nobody is on call for are left out",
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceOncallMatrixRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teams := []string{}
+	for i, raw := range d.Get(oncallMatrixFieldTeams).([]interface{}) {
+		teamName := raw.(string)
+		if stringSliceContains(teams, teamName) {
+			return diag.Diagnostics{
+				diag.Diagnostic{
+					Severity:      diag.Error,
+					Summary:       fmt.Sprintf("Team %s is listed more than once", teamName),
+					AttributePath: cty.Path{cty.GetAttrStep{Name: oncallMatrixFieldTeams}, cty.IndexStep{Key: cty.NumberIntVal(int64(i))}},
+				},
+			}
+		}
+		diags = append(diags, teamPrefixDiags(m, oncallMatrixFieldTeams, teamName)...)
+		teams = append(teams, teamName)
+	}
+	if diags.HasError() {
+		return diags
+	}
+
+	// Every team is asked about the same instant, so a handoff part way
+	// through doesn't show some teams before it and some after
+	now := time.Now()
+	matrix := make([]map[string]interface{}, 0, len(teams))
+	for _, teamName := range teams {
+		events, err := getCurrentEvents(c, teamName, now)
+		if err != nil {
+			return diagFromErrf(err, "Getting who is on call for team %s", teamName)
+		}
+		matrix = append(matrix, map[string]interface{}{
+			oncallMatrixTeamFieldTeam:  teamName,
+			oncallMatrixTeamFieldRoles: oncallByRole(events),
+		})
+	}
+	d.Set(oncallMatrixFieldOncall, matrix)
+
+	ids := make([]string, 0, len(teams))
+	for _, teamName := range teams {
+		ids = append(ids, escapeIDPart(teamName))
+	}
+	d.SetId(strings.Join(ids, ","))
+
+	return diags
+}
+
+// oncallByRole picks who is on call for each role out of the current events.
+// When a role has overlapping events the one that started last wins, as that
+// is usually an override on top of the regular shift.
+func oncallByRole(events []scheduleEvent) map[string]interface{} {
+	latest := map[string]scheduleEvent{}
+	for _, e := range events {
+		l, ok := latest[e.Role]
+		if !ok || e.Start > l.Start || (e.Start == l.Start && e.ID > l.ID) {
+			latest[e.Role] = e
+		}
+	}
+
+	roles := make(map[string]interface{}, len(latest))
+	for role, e := range latest {
+		roles[role] = e.User
+	}
+	return roles
+}
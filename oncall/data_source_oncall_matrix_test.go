@@ -0,0 +1,88 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceOncallMatrixRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/events" || r.URL.Query().Get("start__le") == "" || r.URL.Query().Get("end__gt") == "" {
+			w.WriteHeader(404)
+			return
+		}
+		switch r.URL.Query().Get("team__eq") {
+		case "payments":
+			w.Write([]byte(`[
+				{"id":1,"user":"alice","role":"primary","start":100,"end":200},
+				{"id":2,"user":"bob","role":"secondary","start":100,"end":200},
+				{"id":3,"user":"carol","role":"primary","start":150,"end":160}
+			]`))
+		case "search":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		teams   []interface{}
+		want    []interface{}
+		wantErr bool
+	}{
+		{
+			name:  "Overrides win over the regular shift",
+			teams: []interface{}{"search", "payments"},
+			want: []interface{}{
+				map[string]interface{}{
+					oncallMatrixTeamFieldTeam:  "search",
+					oncallMatrixTeamFieldRoles: map[string]interface{}{},
+				},
+				map[string]interface{}{
+					oncallMatrixTeamFieldTeam:  "payments",
+					oncallMatrixTeamFieldRoles: map[string]interface{}{"primary": "carol", "secondary": "bob"},
+				},
+			},
+		},
+		{
+			name:    "Team listed twice",
+			teams:   []interface{}{"search", "search"},
+			wantErr: true,
+		},
+		{
+			name:    "Missing team",
+			teams:   []interface{}{"search", "missing"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceOncallMatrix().Schema, map[string]interface{}{
+				oncallMatrixFieldTeams: tt.teams,
+			})
+			diags := dataSourceOncallMatrixRead(context.Background(), d, &providerMeta{client: c})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("dataSourceOncallMatrixRead() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.Get(oncallMatrixFieldOncall).([]interface{}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("oncall = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
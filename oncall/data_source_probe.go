@@ -0,0 +1,138 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	probeFieldFailOnError = "fail_on_error"
+	probeFieldEndpoint    = "endpoint"
+	probeFieldReachable   = "reachable"
+	probeFieldError       = "error"
+	probeFieldVersion     = "version"
+	probeFieldAuthType    = "auth_type"
+	probeFieldIdentity    = "identity"
+)
+
+func dataSourceProbe() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceProbeRead,
+		Schema: map[string]*schema.Schema{
+			probeFieldFailOnError: &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Fail the plan with an explanation when oncall can't be reached or rejects the credentials, set to false to only report it in reachable and error",
+			},
+			probeFieldEndpoint: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Endpoint of the oncall instance the provider is connected to",
+			},
+			probeFieldReachable: &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether oncall answered and accepted the provider's credentials",
+			},
+			probeFieldError: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Why oncall isn't reachable, empty when it is",
+			},
+			probeFieldVersion: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Version the oncall instance reports at /api/v0/version. Empty if it doesn't report one, which upstream oncall doesn't",
+			},
+			probeFieldAuthType: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: fmt.Sprintf("How the provider authenticates to oncall, one of: %v", authMethods),
+			},
+			probeFieldIdentity: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "User, or application with API auth, the provider's credentials authenticate as. Empty without credentials",
+			},
+		},
+	}
+}
+
+func dataSourceProbeRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	authType := c.Config.AuthMethod
+	identity := c.Config.Username
+	if c.Config.Password == "" {
+		authType, identity = authMethodNone, ""
+	}
+	d.Set(probeFieldEndpoint, c.Config.Endpoint)
+	d.Set(probeFieldAuthType, string(authType))
+	d.Set(probeFieldIdentity, identity)
+	d.SetId(c.Config.Endpoint)
+
+	err := probeOncall(c)
+	if err != nil {
+		if d.Get(probeFieldFailOnError).(bool) {
+			return diag.Diagnostics{
+				diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("Oncall at %s can't be used", c.Config.Endpoint),
+					Detail:   fmt.Sprintf("%s\n\n%s\n\nSet %s = false to report this without failing.", healthCheckHint(c, err), err, probeFieldFailOnError),
+				},
+			}
+		}
+		warnLog("Oncall at %s failed its probe: %v", c.Config.Endpoint, err)
+		d.Set(probeFieldReachable, false)
+		d.Set(probeFieldError, fmt.Sprintf("%s %s", healthCheckHint(c, err), err))
+		d.Set(probeFieldVersion, "")
+		return diags
+	}
+
+	version, err := getOncallVersion(c)
+	if err != nil {
+		return diagFromErrf(err, "Getting version of oncall at %s", c.Config.Endpoint)
+	}
+
+	d.Set(probeFieldReachable, true)
+	d.Set(probeFieldError, "")
+	d.Set(probeFieldVersion, version)
+
+	return diags
+}
+
+// getOncallVersion returns the version the oncall instance reports, either as
+// a JSON string or an object with a version key, empty if it reports none
+func getOncallVersion(c *oncall.Client) (string, error) {
+	body, err := c.Get("/api/v0/version", nil)
+	if isNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "Fetching oncall version")
+	}
+
+	var version string
+	if json.Unmarshal(body, &version) == nil {
+		return strings.TrimSpace(version), nil
+	}
+	versionInfo := struct {
+		Version string `json:"version"`
+	}{}
+	if json.Unmarshal(body, &versionInfo) == nil {
+		return strings.TrimSpace(versionInfo.Version), nil
+	}
+	debugLog("Oncall's version response isn't understood, ignoring it: %s", body)
+	return "", nil
+}
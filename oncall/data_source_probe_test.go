@@ -0,0 +1,117 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceProbeRead(t *testing.T) {
+	tests := []struct {
+		name          string
+		handler       http.HandlerFunc
+		password      string
+		failOnError   bool
+		wantErr       bool
+		wantReachable bool
+		wantVersion   string
+		wantAuthType  string
+		wantIdentity  string
+	}{
+		{
+			name: "Reports its version",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v0/teams":
+					w.Write([]byte(`["platform"]`))
+				case "/api/v0/version":
+					w.Write([]byte(`{"version": "1.4.2"}`))
+				default:
+					w.WriteHeader(404)
+				}
+			},
+			password:      "key",
+			failOnError:   true,
+			wantReachable: true,
+			wantVersion:   "1.4.2",
+			wantAuthType:  string(oncall.AuthMethodAPI),
+			wantIdentity:  "terraform",
+		},
+		{
+			name: "Doesn't report a version",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v0/teams" {
+					w.WriteHeader(404)
+					return
+				}
+				w.Write([]byte(`[]`))
+			},
+			failOnError:   true,
+			wantReachable: true,
+			wantAuthType:  string(authMethodNone),
+		},
+		{
+			name: "Down without failing",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(503)
+			},
+			password:     "key",
+			wantAuthType: string(oncall.AuthMethodAPI),
+			wantIdentity: "terraform",
+		},
+		{
+			name: "Down",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(503)
+			},
+			password:    "key",
+			failOnError: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			username := ""
+			if tt.password != "" {
+				username = "terraform"
+			}
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, Username: username, Password: tt.password, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+
+			d := schema.TestResourceDataRaw(t, dataSourceProbe().Schema, map[string]interface{}{
+				probeFieldFailOnError: tt.failOnError,
+			})
+			diags := dataSourceProbeRead(context.Background(), d, &providerMeta{client: c})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("dataSourceProbeRead() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.Get(probeFieldReachable).(bool); got != tt.wantReachable {
+				t.Errorf("reachable = %v, want %v", got, tt.wantReachable)
+			}
+			if got := d.Get(probeFieldError).(string); (got == "") != tt.wantReachable {
+				t.Errorf("error = %q, want it set only when unreachable", got)
+			}
+			if got := d.Get(probeFieldVersion).(string); got != tt.wantVersion {
+				t.Errorf("version = %q, want %q", got, tt.wantVersion)
+			}
+			if got := d.Get(probeFieldAuthType).(string); got != tt.wantAuthType {
+				t.Errorf("auth_type = %q, want %q", got, tt.wantAuthType)
+			}
+			if got := d.Get(probeFieldIdentity).(string); got != tt.wantIdentity {
+				t.Errorf("identity = %q, want %q", got, tt.wantIdentity)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package oncall
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	rolesFieldRoles        = "roles"
+	rolesFieldNames        = "names"
+	rolesFieldName         = "name"
+	rolesFieldDisplayOrder = "display_order"
+)
+
+func dataSourceRoles() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRolesRead,
+		Schema: map[string]*schema.Schema{
+			rolesFieldRoles: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Roles configured on the oncall instance, ordered by their display order",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						rolesFieldName: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the role",
+						},
+						rolesFieldDisplayOrder: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Priority of the role, lower roles are shown first",
+						},
+					},
+				},
+			},
+			rolesFieldNames: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Names of the roles configured on the oncall instance, ordered by their display order",
+				Elem: &schema.Schema{
+					Type: schema.TypeString,
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRolesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	roles, err := getRoles(c)
+	if err != nil {
+		return diagFromErrf(err, "Getting oncall roles")
+	}
+
+	roleList := make([]map[string]interface{}, 0, len(roles))
+	names := make([]string, 0, len(roles))
+	for _, r := range roles {
+		roleList = append(roleList, map[string]interface{}{
+			rolesFieldName:         r.Name,
+			rolesFieldDisplayOrder: r.DisplayOrder,
+		})
+		names = append(names, r.Name)
+	}
+	d.Set(rolesFieldRoles, roleList)
+	d.Set(rolesFieldNames, names)
+
+	// There is only one set of roles per oncall instance
+	d.SetId("roles")
+
+	return diags
+}
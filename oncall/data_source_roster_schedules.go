@@ -0,0 +1,159 @@
+package oncall
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	rosterSchedulesFieldRosterID  = "roster_id"
+	rosterSchedulesFieldRoles     = "roles"
+	rosterSchedulesFieldSchedules = "schedules"
+
+	rosterScheduleFieldShifts               = "shifts"
+	rosterScheduleShiftFieldDurationSeconds = "duration_seconds"
+)
+
+func dataSourceRosterSchedules() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRosterSchedulesRead,
+		Schema: map[string]*schema.Schema{
+			rosterSchedulesFieldRosterID: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Roster ID (in team/roster format) to read the schedules of",
+			},
+			rosterSchedulesFieldRoles: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Roles the roster has a schedule for, sorted",
+			},
+			rosterSchedulesFieldSchedules: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The roster's schedules, ordered by role",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						scheduleFieldRole: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Role of the schedule",
+						},
+						scheduleFieldScheduleID: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Numeric ID of the schedule in oncall",
+						},
+						scheduleFieldAdvancedMode: &schema.Schema{
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the schedule is in advanced mode, which only oncall_advanced_schedule and oncall_raw_schedule can manage",
+						},
+						scheduleFieldAutoPopulateDays: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "How many days in advance the schedule is planned",
+						},
+						scheduleFieldSchedulingAlgorithim: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Scheduling algorithim of the schedule",
+						},
+						rosterScheduleFieldShifts: &schema.Schema{
+							Type:        schema.TypeList,
+							Computed:    true,
+							Description: "Shifts of the schedule, ordered by when they start in the week",
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									scheduleFieldStartDayOfWeek: &schema.Schema{
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Day of the week the shift starts on",
+									},
+									scheduleFieldStartTime: &schema.Schema{
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "Time of day the shift starts at, in the team's scheduling timezone",
+									},
+									advancedScheduleFieldDuration: &schema.Schema{
+										Type:        schema.TypeString,
+										Computed:    true,
+										Description: "How long the shift lasts, e.g. 12h",
+									},
+									advancedScheduleFieldStartOffsetSeconds: &schema.Schema{
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "When the shift starts, in seconds from the start of the week",
+									},
+									rosterScheduleShiftFieldDurationSeconds: &schema.Schema{
+										Type:        schema.TypeInt,
+										Computed:    true,
+										Description: "How long the shift lasts in seconds",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRosterSchedulesRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	rosterID := d.Get(rosterSchedulesFieldRosterID).(string)
+	teamName, rosterName, err := parseRosterID(rosterID)
+	if err != nil {
+		return diagFromErrf(err, "Invalid %s %q", rosterSchedulesFieldRosterID, rosterID)
+	}
+	diags := teamPrefixDiags(m, rosterSchedulesFieldRosterID, teamName)
+	if diags.HasError() {
+		return diags
+	}
+
+	schedules, err := getRosterSchedules(c, teamName, rosterName)
+	if err != nil {
+		return diagFromErrf(err, "Getting schedules of roster %s", rosterID)
+	}
+	sort.Slice(schedules, func(i, j int) bool { return strings.ToLower(schedules[i].Role) < strings.ToLower(schedules[j].Role) })
+
+	roles := make([]string, 0, len(schedules))
+	out := make([]map[string]interface{}, 0, len(schedules))
+	for _, s := range schedules {
+		shifts := make([]map[string]interface{}, 0, len(s.Events))
+		for _, e := range sortedScheduleEvents(s.Events) {
+			day, hour, min, sec := secondsToDayTime(e.Start, weekStartFor(m))
+			shifts = append(shifts, map[string]interface{}{
+				scheduleFieldStartDayOfWeek:             daysOfWeek[day],
+				scheduleFieldStartTime:                  formatTimeOfDay(hour, min, sec),
+				advancedScheduleFieldDuration:           prettyPrintDuration(e.Duration),
+				advancedScheduleFieldStartOffsetSeconds: e.Start,
+				rosterScheduleShiftFieldDurationSeconds: e.Duration,
+			})
+		}
+
+		roles = append(roles, s.Role)
+		out = append(out, map[string]interface{}{
+			scheduleFieldRole:                 s.Role,
+			scheduleFieldScheduleID:           s.ID,
+			scheduleFieldAdvancedMode:         s.AdvancedMode != 0,
+			scheduleFieldAutoPopulateDays:     s.AutoPopulateThreshold,
+			scheduleFieldSchedulingAlgorithim: s.Scheduler.Name,
+			rosterScheduleFieldShifts:         shifts,
+		})
+	}
+
+	d.Set(rosterSchedulesFieldRoles, roles)
+	d.Set(rosterSchedulesFieldSchedules, out)
+
+	d.SetId(getRosterID(teamName, rosterName))
+
+	return diags
+}
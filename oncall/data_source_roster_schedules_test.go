@@ -0,0 +1,132 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceRosterSchedulesRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/acme-payments/rosters/oncall/schedules":
+			w.Write([]byte(`[
+				{"id": 12, "role": "secondary", "advanced_mode": 1, "auto_populate_threshold": 21, "scheduler": {"name": "round-robin"},
+				 "events": [{"start": 475200, "duration": 43200}, {"start": 129600, "duration": 43200}]},
+				{"id": 11, "role": "primary", "advanced_mode": 0, "auto_populate_threshold": 30, "scheduler": {"name": "default"},
+				 "events": [{"start": 90000, "duration": 604800}]}
+			]`))
+		case "/api/v0/teams/acme-payments/rosters/empty/schedules":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		rosterID      string
+		wantRoles     []interface{}
+		wantSchedules []interface{}
+		wantErr       bool
+	}{
+		{
+			name:      "Basic and advanced schedules",
+			rosterID:  "acme-payments/oncall",
+			wantRoles: []interface{}{"primary", "secondary"},
+			wantSchedules: []interface{}{
+				map[string]interface{}{
+					scheduleFieldRole:                 "primary",
+					scheduleFieldScheduleID:           11,
+					scheduleFieldAdvancedMode:         false,
+					scheduleFieldAutoPopulateDays:     30,
+					scheduleFieldSchedulingAlgorithim: "default",
+					rosterScheduleFieldShifts: []interface{}{
+						map[string]interface{}{
+							scheduleFieldStartDayOfWeek:             "Monday",
+							scheduleFieldStartTime:                  "01:00",
+							advancedScheduleFieldDuration:           "1w",
+							advancedScheduleFieldStartOffsetSeconds: 90000,
+							rosterScheduleShiftFieldDurationSeconds: 604800,
+						},
+					},
+				},
+				map[string]interface{}{
+					scheduleFieldRole:                 "secondary",
+					scheduleFieldScheduleID:           12,
+					scheduleFieldAdvancedMode:         true,
+					scheduleFieldAutoPopulateDays:     21,
+					scheduleFieldSchedulingAlgorithim: "round-robin",
+					rosterScheduleFieldShifts: []interface{}{
+						map[string]interface{}{
+							scheduleFieldStartDayOfWeek:             "Monday",
+							scheduleFieldStartTime:                  "12:00",
+							advancedScheduleFieldDuration:           "12h",
+							advancedScheduleFieldStartOffsetSeconds: 129600,
+							rosterScheduleShiftFieldDurationSeconds: 43200,
+						},
+						map[string]interface{}{
+							scheduleFieldStartDayOfWeek:             "Friday",
+							scheduleFieldStartTime:                  "12:00",
+							advancedScheduleFieldDuration:           "12h",
+							advancedScheduleFieldStartOffsetSeconds: 475200,
+							rosterScheduleShiftFieldDurationSeconds: 43200,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:          "No schedules",
+			rosterID:      "acme-payments/empty",
+			wantRoles:     []interface{}{},
+			wantSchedules: []interface{}{},
+		},
+		{
+			name:     "Invalid roster ID",
+			rosterID: "acme-payments",
+			wantErr:  true,
+		},
+		{
+			name:     "Outside the prefix",
+			rosterID: "search/oncall",
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown roster",
+			rosterID: "acme-payments/missing",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceRosterSchedules().Schema, map[string]interface{}{
+				rosterSchedulesFieldRosterID: tt.rosterID,
+			})
+			diags := dataSourceRosterSchedulesRead(context.Background(), d, &providerMeta{client: c, teamPrefix: "acme-"})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("dataSourceRosterSchedulesRead() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.Get(rosterSchedulesFieldRoles).([]interface{}); !reflect.DeepEqual(got, tt.wantRoles) {
+				t.Errorf("roles = %v, want %v", got, tt.wantRoles)
+			}
+			if got := d.Get(rosterSchedulesFieldSchedules).([]interface{}); !reflect.DeepEqual(got, tt.wantSchedules) {
+				t.Errorf("schedules = %v, want %v", got, tt.wantSchedules)
+			}
+		})
+	}
+}
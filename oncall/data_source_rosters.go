@@ -0,0 +1,117 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	rostersFieldTeam    = "team"
+	rostersFieldNames   = "names"
+	rostersFieldRosters = "rosters"
+
+	rostersRosterFieldName            = "name"
+	rostersRosterFieldRosterID        = "roster_id"
+	rostersRosterFieldMemberCount     = "member_count"
+	rostersRosterFieldInRotationCount = "in_rotation_count"
+)
+
+func dataSourceRosters() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRostersRead,
+		Schema: map[string]*schema.Schema{
+			rostersFieldTeam: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the team to list the rosters of",
+			},
+			rostersFieldNames: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of the team's rosters, sorted, e.g. for for_each = toset(...)",
+			},
+			rostersFieldRosters: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "The team's rosters with how many members they have, ordered by name",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						rostersRosterFieldName: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the roster",
+						},
+						rostersRosterFieldRosterID: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the roster in team/roster format",
+						},
+						rostersRosterFieldMemberCount: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of members of the roster",
+						},
+						rostersRosterFieldInRotationCount: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "Number of members of the roster who are in rotation",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceRostersRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName := d.Get(rostersFieldTeam).(string)
+	diags := teamPrefixDiags(m, rostersFieldTeam, teamName)
+	if diags.HasError() {
+		return diags
+	}
+
+	// A single request lists every roster with its members, instead of one
+	// per roster as the roster data source would take
+	teamRosters := map[string]userRoster{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters", url.PathEscape(teamName)), &teamRosters)
+	if err != nil {
+		return diagFromErrf(err, "Getting rosters of team %s", teamName)
+	}
+
+	names := make([]string, 0, len(teamRosters))
+	for name := range teamRosters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rosters := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		inRotation := 0
+		for _, member := range teamRosters[name].Users {
+			if member.InRotation {
+				inRotation++
+			}
+		}
+		rosters = append(rosters, map[string]interface{}{
+			rostersRosterFieldName:            name,
+			rostersRosterFieldRosterID:        getRosterID(teamName, name),
+			rostersRosterFieldMemberCount:     len(teamRosters[name].Users),
+			rostersRosterFieldInRotationCount: inRotation,
+		})
+	}
+
+	d.Set(rostersFieldNames, names)
+	d.Set(rostersFieldRosters, rosters)
+
+	d.SetId(teamName)
+
+	return diags
+}
@@ -0,0 +1,98 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceRostersRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/acme-payments/rosters":
+			w.Write([]byte(`{
+				"oncall": {"users": [{"name":"alice","in_rotation":1},{"name":"bob","in_rotation":0}], "schedules": [{"role":"primary"}]},
+				"managers": {"users": [{"name":"carol","in_rotation":1}], "schedules": []}
+			}`))
+		case "/api/v0/teams/acme-empty/rosters":
+			w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		team        string
+		wantNames   []interface{}
+		wantRosters []interface{}
+		wantErr     bool
+	}{
+		{
+			name:      "Rosters with members",
+			team:      "acme-payments",
+			wantNames: []interface{}{"managers", "oncall"},
+			wantRosters: []interface{}{
+				map[string]interface{}{
+					rostersRosterFieldName:            "managers",
+					rostersRosterFieldRosterID:        "acme-payments/managers",
+					rostersRosterFieldMemberCount:     1,
+					rostersRosterFieldInRotationCount: 1,
+				},
+				map[string]interface{}{
+					rostersRosterFieldName:            "oncall",
+					rostersRosterFieldRosterID:        "acme-payments/oncall",
+					rostersRosterFieldMemberCount:     2,
+					rostersRosterFieldInRotationCount: 1,
+				},
+			},
+		},
+		{
+			name:        "No rosters",
+			team:        "acme-empty",
+			wantNames:   []interface{}{},
+			wantRosters: []interface{}{},
+		},
+		{
+			name:    "Outside the prefix",
+			team:    "search",
+			wantErr: true,
+		},
+		{
+			name:    "Unknown team",
+			team:    "acme-missing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceRosters().Schema, map[string]interface{}{
+				rostersFieldTeam: tt.team,
+			})
+			diags := dataSourceRostersRead(context.Background(), d, &providerMeta{client: c, teamPrefix: "acme-"})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("dataSourceRostersRead() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.Get(rostersFieldNames).([]interface{}); !reflect.DeepEqual(got, tt.wantNames) {
+				t.Errorf("names = %v, want %v", got, tt.wantNames)
+			}
+			if got := d.Get(rostersFieldRosters).([]interface{}); !reflect.DeepEqual(got, tt.wantRosters) {
+				t.Errorf("rosters = %v, want %v", got, tt.wantRosters)
+			}
+		})
+	}
+}
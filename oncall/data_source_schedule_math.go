@@ -0,0 +1,93 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// The schedule math is offered as data sources rather than provider functions
+// (provider::oncall::shift_seconds), which need plugin protocol v6 that this
+// provider's SDK doesn't speak. Their inputs are validated like the schedule
+// resources', so mistakes are caught by terraform validate all the same.
+
+const (
+	shiftSecondsFieldDayOfWeek = "day_of_week"
+	shiftSecondsFieldTime      = "time"
+	shiftSecondsFieldSeconds   = "seconds"
+
+	durationSecondsFieldDuration = "duration"
+	durationSecondsFieldSeconds  = "seconds"
+)
+
+func dataSourceShiftSeconds() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceShiftSecondsRead,
+		Schema: map[string]*schema.Schema{
+			shiftSecondsFieldDayOfWeek: &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateStringSliceContains(daysOfWeek),
+				Description:      fmt.Sprintf("Day of week the shift starts on, one of: %v", daysOfWeek),
+			},
+			shiftSecondsFieldTime: &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validate24HourTime,
+				Description:      "Time on that day the shift starts, as HH:MM or HH:MM:SS",
+			},
+			shiftSecondsFieldSeconds: &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Seconds from the start of the week to the start of the shift, as in the start_offset_seconds of schedule shifts, counting from the provider's week_starts_on",
+			},
+		},
+	}
+}
+
+func dataSourceShiftSecondsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	day := d.Get(shiftSecondsFieldDayOfWeek).(string)
+	startTime := d.Get(shiftSecondsFieldTime).(string)
+	seconds, err := weekdayStartTimeToSeconds(day, startTime, weekStartFor(m))
+	if err != nil {
+		return diagFromErrf(err, "Computing shift seconds of %s %s", day, startTime)
+	}
+
+	d.Set(shiftSecondsFieldSeconds, seconds)
+	d.SetId(strconv.Itoa(seconds))
+	return nil
+}
+
+func dataSourceDurationSeconds() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceDurationSecondsRead,
+		Schema: map[string]*schema.Schema{
+			durationSecondsFieldDuration: &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateDuration,
+				Description:      "Duration in the shorthand schedule shifts use, e.g. 1d8h or 1h30m",
+			},
+			durationSecondsFieldSeconds: &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "The duration in seconds, as oncall schedules have it",
+			},
+		},
+	}
+}
+
+func dataSourceDurationSecondsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	in := d.Get(durationSecondsFieldDuration).(string)
+	seconds, err := parseDurationSeconds(in)
+	if err != nil {
+		return diagFromErrf(err, "Computing seconds of duration %s", in)
+	}
+
+	d.Set(durationSecondsFieldSeconds, seconds)
+	d.SetId(strconv.Itoa(seconds))
+	return nil
+}
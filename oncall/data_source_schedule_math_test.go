@@ -0,0 +1,67 @@
+package oncall
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceShiftSecondsRead(t *testing.T) {
+	tests := []struct {
+		name      string
+		day       string
+		time      string
+		weekStart time.Weekday
+		want      int
+	}{
+		{name: "Start of the week", day: "Sunday", time: "00:00", weekStart: time.Sunday, want: 0},
+		{name: "Monday morning", day: "Monday", time: "09:00", weekStart: time.Sunday, want: 33 * 3600},
+		{name: "To the second", day: "Monday", time: "09:00:30", weekStart: time.Sunday, want: 33*3600 + 30},
+		{name: "ISO weeks", day: "Monday", time: "09:00", weekStart: time.Monday, want: 9 * 3600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceShiftSeconds().Schema, map[string]interface{}{
+				shiftSecondsFieldDayOfWeek: tt.day,
+				shiftSecondsFieldTime:      tt.time,
+			})
+			diags := dataSourceShiftSecondsRead(context.Background(), d, &providerMeta{weekStart: tt.weekStart})
+			if diags.HasError() {
+				t.Fatalf("dataSourceShiftSecondsRead() = %v", diags)
+			}
+			if got := d.Get(shiftSecondsFieldSeconds).(int); got != tt.want {
+				t.Errorf("seconds = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_dataSourceDurationSecondsRead(t *testing.T) {
+	tests := []struct {
+		name     string
+		duration string
+		want     int
+		wantErr  bool
+	}{
+		{name: "Days and hours", duration: "1d8h", want: 32 * 3600},
+		{name: "Minutes", duration: "90m", want: 5400},
+		{name: "Fraction of a second", duration: "1.5s", wantErr: true},
+		{name: "Not a duration", duration: "a week", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceDurationSeconds().Schema, map[string]interface{}{
+				durationSecondsFieldDuration: tt.duration,
+			})
+			diags := dataSourceDurationSecondsRead(context.Background(), d, &providerMeta{})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("dataSourceDurationSecondsRead() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if got := d.Get(durationSecondsFieldSeconds).(int); !tt.wantErr && got != tt.want {
+				t.Errorf("seconds = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package oncall
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func dataSourceShiftTemplate() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceShiftTemplateRead,
+		Schema: map[string]*schema.Schema{
+			shiftTemplateFieldName: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of a shift template from the provider's shift_template blocks or shift_templates_file",
+			},
+			shiftTemplateFieldShift: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Shifts of the template, to use in the dynamic shift blocks of an oncall_advanced_schedule",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						scheduleFieldStartDayOfWeek: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The day of week that the shift starts on",
+						},
+						scheduleFieldStartTime: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "The time on that day that the shift starts",
+						},
+						advancedScheduleFieldStartOffsetSeconds: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "When the shift starts in seconds from the start of the week, if it isn't given by day and time",
+						},
+						advancedScheduleFieldDuration: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "How long the shift lasts",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func dataSourceShiftTemplateRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	name := d.Get(shiftTemplateFieldName).(string)
+	templates := m.(*providerMeta).shiftTemplates
+	shifts, ok := templates[name]
+	if !ok {
+		names := make([]string, 0, len(templates))
+		for n := range templates {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return diag.Errorf("There is no shift template %q, the provider has: %v", name, names)
+	}
+
+	d.Set(shiftTemplateFieldShift, shifts)
+	d.SetId(name)
+
+	return diags
+}
@@ -0,0 +1,209 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	userTeamsFieldUsername   = "username"
+	userTeamsFieldTeams      = "teams"
+	userTeamsFieldRosters    = "rosters"
+	userTeamsFieldScheduled  = "scheduled"
+	userTeamsFieldInRotation = "in_rotation"
+
+	userRosterFieldTeam       = "team"
+	userRosterFieldRoster     = "roster"
+	userRosterFieldRosterID   = "roster_id"
+	userRosterFieldInRotation = "in_rotation"
+	userRosterFieldRole       = "role"
+)
+
+func dataSourceUserTeams() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceUserTeamsRead,
+		Schema: map[string]*schema.Schema{
+			userTeamsFieldUsername: &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateUsername,
+				Description:      "Username to list the teams, rosters and schedules of",
+			},
+			userTeamsFieldTeams: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Names of the teams the user is a member of, sorted",
+			},
+			userTeamsFieldRosters: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Rosters of those teams the user is on, ordered by roster ID",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						userRosterFieldTeam: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the team",
+						},
+						userRosterFieldRoster: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the roster",
+						},
+						userRosterFieldRosterID: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the roster in team/roster format",
+						},
+						userRosterFieldInRotation: &schema.Schema{
+							Type:        schema.TypeBool,
+							Computed:    true,
+							Description: "Whether the roster's schedules put the user on call",
+						},
+					},
+				},
+			},
+			userTeamsFieldScheduled: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Roles the user is scheduled for, one per schedule of the rosters they are in rotation on, ordered by roster ID then role",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						userRosterFieldTeam: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Name of the team",
+						},
+						userRosterFieldRosterID: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "ID of the roster in team/roster format",
+						},
+						userRosterFieldRole: &schema.Schema{
+							Type:        schema.TypeString,
+							Computed:    true,
+							Description: "Role of the schedule, e.g. primary",
+						},
+					},
+				},
+			},
+			userTeamsFieldInRotation: &schema.Schema{
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the user is in rotation on any roster, e.g. for offboarding to check they have been taken out of every rotation",
+			},
+		},
+	}
+}
+
+// userRoster is one of a team's rosters as listed by /api/v0/teams/{team}/rosters
+type userRoster struct {
+	Users     []rosterMember `json:"users"`
+	Schedules []struct {
+		Role string `json:"role"`
+	} `json:"schedules"`
+}
+
+func dataSourceUserTeamsRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	username := normalizeUsername(d.Get(userTeamsFieldUsername).(string))
+	teams, err := getUserTeams(c, username)
+	if err != nil {
+		return diagFromErrf(err, "Getting teams of user %s", username)
+	}
+
+	// Teams outside of the provider's team prefix can't be called, so they
+	// are left out rather than failing the read
+	prefix := ""
+	if meta, ok := m.(*providerMeta); ok {
+		prefix = meta.teamPrefix
+	}
+	visible := make([]string, 0, len(teams))
+	for _, teamName := range teams {
+		if checkTeamPrefix(prefix, teamName) != nil {
+			debugLog("Leaving team %s of user %s out, it is outside of the team prefix", teamName, username)
+			continue
+		}
+		visible = append(visible, teamName)
+	}
+	sort.Strings(visible)
+
+	rosters := []map[string]interface{}{}
+	scheduled := []map[string]interface{}{}
+	inAnyRotation := false
+	for _, teamName := range visible {
+		teamRosters := map[string]userRoster{}
+		_, err := c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters", url.PathEscape(teamName)), &teamRosters)
+		if err != nil {
+			return diagFromErrf(err, "Getting rosters of team %s", teamName)
+		}
+
+		rosterNames := make([]string, 0, len(teamRosters))
+		for name := range teamRosters {
+			rosterNames = append(rosterNames, name)
+		}
+		sort.Strings(rosterNames)
+
+		for _, rosterName := range rosterNames {
+			roster := teamRosters[rosterName]
+			for _, member := range roster.Users {
+				if member.Name != username {
+					continue
+				}
+				rosterID := getRosterID(teamName, rosterName)
+				rosters = append(rosters, map[string]interface{}{
+					userRosterFieldTeam:       teamName,
+					userRosterFieldRoster:     rosterName,
+					userRosterFieldRosterID:   rosterID,
+					userRosterFieldInRotation: bool(member.InRotation),
+				})
+				if !member.InRotation {
+					break
+				}
+				inAnyRotation = true
+
+				roles := make([]string, 0, len(roster.Schedules))
+				for _, s := range roster.Schedules {
+					roles = append(roles, s.Role)
+				}
+				sort.Strings(roles)
+				for _, role := range roles {
+					scheduled = append(scheduled, map[string]interface{}{
+						userRosterFieldTeam:     teamName,
+						userRosterFieldRosterID: rosterID,
+						userRosterFieldRole:     role,
+					})
+				}
+				break
+			}
+		}
+	}
+
+	d.Set(userTeamsFieldTeams, visible)
+	d.Set(userTeamsFieldRosters, rosters)
+	d.Set(userTeamsFieldScheduled, scheduled)
+	d.Set(userTeamsFieldInRotation, inAnyRotation)
+
+	d.SetId(username)
+
+	return diags
+}
+
+// getUserTeams lists the names of the teams the user is a member of
+func getUserTeams(c *oncall.Client, username string) ([]string, error) {
+	teams := []string{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/users/%s/teams", url.PathEscape(username)), &teams)
+	return teams, errors.Wrapf(err, "Fetching teams of user %s", username)
+}
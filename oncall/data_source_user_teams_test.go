@@ -0,0 +1,119 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_dataSourceUserTeamsRead(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/users/alice/teams":
+			w.Write([]byte(`["search","acme-payments","acme-billing"]`))
+		case "/api/v0/users/bob/teams":
+			w.Write([]byte(`[]`))
+		case "/api/v0/teams/acme-payments/rosters":
+			w.Write([]byte(`{
+				"oncall": {"users": [{"name":"alice","in_rotation":1},{"name":"bob","in_rotation":1}], "schedules": [{"role":"secondary"},{"role":"primary"}]},
+				"managers": {"users": [{"name":"carol","in_rotation":1}], "schedules": [{"role":"manager"}]}
+			}`))
+		case "/api/v0/teams/acme-billing/rosters":
+			w.Write([]byte(`{"billing": {"users": [{"name":"alice","in_rotation":0}], "schedules": [{"role":"primary"}]}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		username       string
+		wantTeams      []interface{}
+		wantRosters    []interface{}
+		wantScheduled  []interface{}
+		wantInRotation bool
+		wantErr        bool
+	}{
+		{
+			name:      "Member of teams inside and outside the prefix",
+			username:  "alice",
+			wantTeams: []interface{}{"acme-billing", "acme-payments"},
+			wantRosters: []interface{}{
+				map[string]interface{}{
+					userRosterFieldTeam:       "acme-billing",
+					userRosterFieldRoster:     "billing",
+					userRosterFieldRosterID:   "acme-billing/billing",
+					userRosterFieldInRotation: false,
+				},
+				map[string]interface{}{
+					userRosterFieldTeam:       "acme-payments",
+					userRosterFieldRoster:     "oncall",
+					userRosterFieldRosterID:   "acme-payments/oncall",
+					userRosterFieldInRotation: true,
+				},
+			},
+			wantScheduled: []interface{}{
+				map[string]interface{}{
+					userRosterFieldTeam:     "acme-payments",
+					userRosterFieldRosterID: "acme-payments/oncall",
+					userRosterFieldRole:     "primary",
+				},
+				map[string]interface{}{
+					userRosterFieldTeam:     "acme-payments",
+					userRosterFieldRosterID: "acme-payments/oncall",
+					userRosterFieldRole:     "secondary",
+				},
+			},
+			wantInRotation: true,
+		},
+		{
+			name:          "Offboarded",
+			username:      "bob",
+			wantTeams:     []interface{}{},
+			wantRosters:   []interface{}{},
+			wantScheduled: []interface{}{},
+		},
+		{
+			name:     "Unknown user",
+			username: "mallory",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, dataSourceUserTeams().Schema, map[string]interface{}{
+				userTeamsFieldUsername: tt.username,
+			})
+			diags := dataSourceUserTeamsRead(context.Background(), d, &providerMeta{client: c, teamPrefix: "acme-"})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("dataSourceUserTeamsRead() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := d.Get(userTeamsFieldTeams).([]interface{}); !reflect.DeepEqual(got, tt.wantTeams) {
+				t.Errorf("teams = %v, want %v", got, tt.wantTeams)
+			}
+			if got := d.Get(userTeamsFieldRosters).([]interface{}); !reflect.DeepEqual(got, tt.wantRosters) {
+				t.Errorf("rosters = %v, want %v", got, tt.wantRosters)
+			}
+			if got := d.Get(userTeamsFieldScheduled).([]interface{}); !reflect.DeepEqual(got, tt.wantScheduled) {
+				t.Errorf("scheduled = %v, want %v", got, tt.wantScheduled)
+			}
+			if got := d.Get(userTeamsFieldInRotation).(bool); got != tt.wantInRotation {
+				t.Errorf("in_rotation = %v, want %v", got, tt.wantInRotation)
+			}
+		})
+	}
+}
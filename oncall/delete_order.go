@@ -0,0 +1,89 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/pkg/errors"
+)
+
+// When a team, its rosters and their schedules are in different modules
+// without references between them, terraform may destroy them in any order,
+// in parallel. A parent deleted first takes its children with it, which then
+// find themselves already deleted, but oncall can refuse to delete a parent
+// that still has children, so deleting one waits a while for terraform to
+// delete the children before giving up.
+const childDeleteAttempts = 6
+
+// childDeleteRetryDelay is a variable so tests don't have to wait it out
+var childDeleteRetryDelay = 5 * time.Second
+
+// blockedByChildren is whether oncall refused a delete because what is being
+// deleted still has the given children, e.g. a roster that has schedules
+func blockedByChildren(err error, children ...string) bool {
+	e, ok := parseAPIError(err)
+	if !ok || (e.status != 400 && e.status != 409 && e.status != 422) {
+		return false
+	}
+	description := strings.ToLower(e.Description)
+	for _, child := range children {
+		if strings.Contains(description, child) {
+			return true
+		}
+	}
+	return false
+}
+
+// deleteAfterChildren deletes something, retrying while oncall refuses as it
+// still has children, which terraform may be deleting at the same time. What
+// was already deleted along with its parent counts as deleted.
+func deleteAfterChildren(ctx context.Context, what string, children []string, del func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := del()
+		if err == nil || isNotFound(err) {
+			if err != nil {
+				debugLog("%s was already deleted: %s", what, err)
+			}
+			return nil
+		}
+		if !blockedByChildren(err, children...) || attempt >= childDeleteAttempts {
+			return err
+		}
+
+		debugLog("%s still has %s, waiting for them to be deleted: %s", what, childrenNames(children), err)
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "Deleting %s, gave up waiting for its %s after %d attempts", what, childrenNames(children), attempt)
+		case <-time.After(childDeleteRetryDelay/2 + time.Duration(rand.Int63n(int64(childDeleteRetryDelay)))):
+		}
+		recordRetry(ctx)
+	}
+}
+
+// deleteOrderDiags explains a delete that failed as children were left, which
+// is down to the configuration not telling terraform what depends on what
+func deleteOrderDiags(err error, action, what string, children []string, reference string) diag.Diagnostics {
+	if !blockedByChildren(err, children...) {
+		return diagFromErrf(err, action)
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s: %s still has %s", action, what, childrenNames(children)),
+			Detail: fmt.Sprintf("%s\n\nTerraform deleted %s before its %s, so it doesn't know they depend on it, e.g. as they are in another module. "+
+				"Set their %s from this resource's attributes instead of a literal name, or add a depends_on, so they are destroyed first.", err, what, childrenNames(children), reference),
+		},
+	}
+}
+
+func childrenNames(children []string) string {
+	names := make([]string, 0, len(children))
+	for _, child := range children {
+		names = append(names, child+"s")
+	}
+	return strings.Join(names, " or ")
+}
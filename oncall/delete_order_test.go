@@ -0,0 +1,76 @@
+package oncall
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func Test_deleteAfterChildren(t *testing.T) {
+	defer func(delay time.Duration) { childDeleteRetryDelay = delay }(childDeleteRetryDelay)
+	childDeleteRetryDelay = time.Millisecond
+
+	hasSchedules := errors.New(`HTTP Request failed (422) ({"title": "Unprocessable Entity", "description": "Roster has schedules"})`)
+	notFound := errors.New(`HTTP Request failed (404) ({"title": "Not Found", "description": "Roster not found"})`)
+	forbidden := errors.New(`HTTP Request failed (403) ({"title": "Forbidden", "description": "Not allowed"})`)
+
+	tests := []struct {
+		name         string
+		errs         []error
+		wantErr      bool
+		wantAttempts int
+	}{
+		{
+			name:         "Deleted",
+			errs:         []error{nil},
+			wantAttempts: 1,
+		},
+		{
+			name:         "Already deleted with its parent",
+			errs:         []error{notFound},
+			wantAttempts: 1,
+		},
+		{
+			name:         "Deleted once its children are",
+			errs:         []error{hasSchedules, hasSchedules, nil},
+			wantAttempts: 3,
+		},
+		{
+			name:         "Other errors are not retried",
+			errs:         []error{forbidden},
+			wantErr:      true,
+			wantAttempts: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			err := deleteAfterChildren(context.Background(), "roster team/roster", []string{"schedule"}, func() error {
+				err := tt.errs[attempts]
+				attempts++
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("deleteAfterChildren() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("deleteAfterChildren() attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func Test_deleteOrderDiags(t *testing.T) {
+	hasSchedules := errors.New(`HTTP Request failed (422) ({"title": "Unprocessable Entity", "description": "Roster has schedules"})`)
+	diags := deleteOrderDiags(hasSchedules, "Deleting roster", "roster team/roster", []string{"schedule"}, scheduleFieldRosterID)
+	if len(diags) != 1 || diags[0].Summary != "Deleting roster: roster team/roster still has schedules" {
+		t.Errorf("deleteOrderDiags() = %v", diags)
+	}
+
+	other := errors.New(`HTTP Request failed (500) ({"title": "Internal Server Error", "description": "Roster has schedules"})`)
+	diags = deleteOrderDiags(other, "Deleting roster", "roster team/roster", []string{"schedule"}, scheduleFieldRosterID)
+	if len(diags) != 1 || diags[0].Summary == "Deleting roster: roster team/roster still has schedules" {
+		t.Errorf("deleteOrderDiags() = %v, want the error as it is", diags)
+	}
+}
@@ -0,0 +1,162 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// discoverySchemeSRV looks the endpoint up with a DNS SRV query, e.g.
+	// srv://_oncall._tcp.example.com
+	discoverySchemeSRV = "srv"
+	// discoverySchemeConsul looks the endpoint up in the consul catalog, e.g.
+	// consul://oncall-api
+	discoverySchemeConsul = "consul"
+
+	defaultConsulAddr = "127.0.0.1:8500"
+)
+
+// endpointResolver turns service discovery endpoints into the URLs of the
+// oncall instances they currently point at
+type endpointResolver struct {
+	lookupSRV   func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
+	consulAddr  string
+	consulToken string
+	httpClient  *http.Client
+}
+
+func newEndpointResolver() *endpointResolver {
+	consulAddr := os.Getenv("CONSUL_HTTP_ADDR")
+	if consulAddr == "" {
+		consulAddr = defaultConsulAddr
+	}
+	return &endpointResolver{
+		lookupSRV:   net.DefaultResolver.LookupSRV,
+		consulAddr:  consulAddr,
+		consulToken: os.Getenv("CONSUL_HTTP_TOKEN"),
+		httpClient:  http.DefaultClient,
+	}
+}
+
+// resolve replaces every service discovery endpoint with the instances it
+// points at, in order of preference, so they become fallbacks for each other.
+// Other endpoints are passed through as is.
+func (r *endpointResolver) resolve(ctx context.Context, endpoints []string) ([]string, error) {
+	resolved := []string{}
+	for _, e := range endpoints {
+		u, err := url.Parse(e)
+		if err != nil || (u.Scheme != discoverySchemeSRV && u.Scheme != discoverySchemeConsul) {
+			resolved = append(resolved, e)
+			continue
+		}
+
+		// The scheme oncall is reached with can be set with ?scheme=http
+		scheme := u.Query().Get("scheme")
+		if scheme == "" {
+			scheme = "https"
+		}
+
+		var hosts []string
+		switch u.Scheme {
+		case discoverySchemeSRV:
+			hosts, err = r.resolveSRV(ctx, u.Host)
+		case discoverySchemeConsul:
+			hosts, err = r.resolveConsul(ctx, u.Host)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Resolving endpoint %s", e)
+		}
+		if len(hosts) == 0 {
+			return nil, fmt.Errorf("Endpoint %s did not resolve to any instances", e)
+		}
+
+		traceLog("Resolved endpoint %s to %v", e, hosts)
+		for _, host := range hosts {
+			resolved = append(resolved, strings.TrimRight(scheme+"://"+host+u.Path, "/"))
+		}
+	}
+	return resolved, nil
+}
+
+// resolveSRV looks up the SRV records of name, ordered by priority and then
+// weight
+func (r *endpointResolver) resolveSRV(ctx context.Context, name string) ([]string, error) {
+	_, records, err := r.lookupSRV(ctx, "", "", name)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Priority != records[j].Priority {
+			return records[i].Priority < records[j].Priority
+		}
+		return records[i].Weight > records[j].Weight
+	})
+
+	hosts := make([]string, 0, len(records))
+	for _, srv := range records {
+		hosts = append(hosts, net.JoinHostPort(strings.TrimSuffix(srv.Target, "."), fmt.Sprintf("%d", srv.Port)))
+	}
+	return hosts, nil
+}
+
+// consulServiceEntry is the part of an entry of consul's /v1/health/service
+// endpoint needed to connect to the service
+type consulServiceEntry struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		Address string `json:"Address"`
+		Port    int    `json:"Port"`
+	} `json:"Service"`
+}
+
+// resolveConsul looks up the healthy instances of the service in consul
+func (r *endpointResolver) resolveConsul(ctx context.Context, service string) ([]string, error) {
+	addr := r.consulAddr
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/health/service/%s?passing=true", strings.TrimRight(addr, "/"), url.PathEscape(service)), nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "Building consul request")
+	}
+	if r.consulToken != "" {
+		req.Header.Set("X-Consul-Token", r.consulToken)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "Querying consul")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Consul answered with %s", resp.Status)
+	}
+
+	entries := []consulServiceEntry{}
+	err = json.NewDecoder(resp.Body).Decode(&entries)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decoding consul response")
+	}
+
+	hosts := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// Services registered without an address are reached on their node's
+		address := entry.Service.Address
+		if address == "" {
+			address = entry.Node.Address
+		}
+		hosts = append(hosts, net.JoinHostPort(address, fmt.Sprintf("%d", entry.Service.Port)))
+	}
+	return hosts, nil
+}
@@ -0,0 +1,87 @@
+package oncall
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func Test_endpointResolver(t *testing.T) {
+	consul := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/health/service/oncall-api" || r.URL.Query().Get("passing") != "true" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("X-Consul-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`[
+			{"Node": {"Address": "10.0.0.1"}, "Service": {"Address": "", "Port": 8080}},
+			{"Node": {"Address": "10.0.0.2"}, "Service": {"Address": "10.1.0.2", "Port": 8080}}
+		]`))
+	}))
+	defer consul.Close()
+
+	r := &endpointResolver{
+		lookupSRV: func(ctx context.Context, service, proto, name string) (string, []*net.SRV, error) {
+			if name != "_oncall._tcp.example.com" {
+				return "", nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+			}
+			return name, []*net.SRV{
+				{Target: "b.example.com.", Port: 443, Priority: 20, Weight: 10},
+				{Target: "a.example.com.", Port: 443, Priority: 10, Weight: 10},
+			}, nil
+		},
+		consulAddr:  consul.URL,
+		consulToken: "token",
+		httpClient:  consul.Client(),
+	}
+
+	tests := []struct {
+		name      string
+		endpoints []string
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "Plain endpoints",
+			endpoints: []string{"https://oncall.example.com", "https://oncall-dr.example.com"},
+			want:      []string{"https://oncall.example.com", "https://oncall-dr.example.com"},
+		},
+		{
+			name:      "SRV",
+			endpoints: []string{"srv://_oncall._tcp.example.com"},
+			want:      []string{"https://a.example.com:443", "https://b.example.com:443"},
+		},
+		{
+			name:      "Consul with a scheme and fallback",
+			endpoints: []string{"consul://oncall-api?scheme=http", "https://oncall-dr.example.com"},
+			want:      []string{"http://10.0.0.1:8080", "http://10.1.0.2:8080", "https://oncall-dr.example.com"},
+		},
+		{
+			name:      "Unknown SRV name",
+			endpoints: []string{"srv://_oncall._tcp.example.org"},
+			wantErr:   true,
+		},
+		{
+			name:      "Unknown consul service",
+			endpoints: []string{"consul://other"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.resolve(context.Background(), tt.endpoints)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("resolve() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
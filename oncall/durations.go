@@ -0,0 +1,148 @@
+package oncall
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"maze.io/x/duration"
+)
+
+// Lengths of time in seconds, as integers so converting between seconds and
+// schedule times doesn't go through floats
+const (
+	minuteSeconds = 60
+	hourSeconds   = 60 * minuteSeconds
+	daySeconds    = 24 * hourSeconds
+	weekSeconds   = 7 * daySeconds
+)
+
+// iso8601DurationPattern matches ISO 8601 durations of weeks, days, hours,
+// minutes and seconds, e.g. PT8H, P1D or P1DT12H. The last number may have a
+// fraction, e.g. PT1.5H.
+var iso8601DurationPattern = regexp.MustCompile(`^P(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// iso8601DurationUnits are the units of the numbers iso8601DurationPattern
+// captures, in order
+var iso8601DurationUnits = []time.Duration{
+	time.Duration(duration.Week),
+	time.Duration(duration.Day),
+	time.Hour,
+	time.Minute,
+	time.Second,
+}
+
+// parseDuration reads a duration in shorthand, which takes Go duration syntax
+// along with days and weeks, e.g. 1h30m or 3d, or in ISO 8601, e.g. PT8H
+func parseDuration(in string) (time.Duration, error) {
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(in)), "P") {
+		return parseISO8601Duration(in)
+	}
+	d, err := duration.ParseDuration(in)
+	return time.Duration(d), err
+}
+
+// parseISO8601Duration reads an ISO 8601 duration. Years and months are
+// refused, as how long they are depends on when they start.
+func parseISO8601Duration(in string) (time.Duration, error) {
+	s := strings.ToUpper(strings.TrimSpace(in))
+	datePart := strings.SplitN(s, "T", 2)[0]
+	if strings.ContainsAny(datePart, "YM") {
+		return 0, fmt.Errorf("ISO 8601 duration %s has years or months, which have no fixed length, use days or weeks instead", in)
+	}
+
+	match := iso8601DurationPattern.FindStringSubmatch(s)
+	if match == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("Invalid ISO 8601 duration %s, expected e.g. PT8H, P1D or P1DT12H30M", in)
+	}
+
+	var total time.Duration
+	fraction := false
+	for i, value := range match[1:] {
+		if value == "" {
+			continue
+		}
+		if fraction {
+			return 0, fmt.Errorf("Only the last number of ISO 8601 duration %s may have a fraction", in)
+		}
+		fraction = strings.ContainsAny(value, ".,")
+		n, err := strconv.ParseFloat(strings.Replace(value, ",", ".", 1), 64)
+		if err != nil {
+			return 0, fmt.Errorf("Invalid ISO 8601 duration %s: %s", in, err)
+		}
+		total += time.Duration(math.Round(n * float64(iso8601DurationUnits[i])))
+	}
+	return total, nil
+}
+
+// suppressEquivalentDuration ignores a change between two ways of writing the
+// same duration, e.g. 8h, 480m and PT8H
+func suppressEquivalentDuration(k, old, new string, d *schema.ResourceData) bool {
+	oldSeconds, err := parseDurationSeconds(old)
+	if err != nil {
+		return false
+	}
+	newSeconds, err := parseDurationSeconds(new)
+	return err == nil && oldSeconds == newSeconds
+}
+
+func validateDuration(in interface{}, path cty.Path) diag.Diagnostics {
+	_, err := parseDurationSeconds(in.(string))
+	return diagFromErrf(err, "Failed to parse duration")
+}
+
+// parseDurationSeconds reads a duration as whole seconds, which is what
+// oncall schedules are in, rather than rounding away a fraction
+func parseDurationSeconds(in string) (int, error) {
+	d, err := parseDuration(in)
+	if err != nil {
+		return 0, err
+	}
+	if d%time.Second != 0 {
+		return 0, fmt.Errorf("%s is not a whole number of seconds", in)
+	}
+	return int(d / time.Second), nil
+}
+
+// durationStateFunc stores durations the way they are read back from oncall,
+// so 90m, 1h30m and PT1H30M are all kept as 1h30m and don't show up as changes
+func durationStateFunc(val interface{}) string {
+	seconds, err := parseDurationSeconds(val.(string))
+	if err != nil || seconds <= 0 {
+		return val.(string)
+	}
+	return prettyPrintDuration(seconds)
+}
+
+// prettyPrintDuration formats seconds like 1w2d3h4m5s, leaving out the units
+// that are zero. It is called for every shift of every schedule in a plan, so
+// it sticks to integer arithmetic and a single buffer.
+func prettyPrintDuration(dur int) string {
+	units := [...]struct {
+		seconds int
+		suffix  byte
+	}{
+		{weekSeconds, 'w'},
+		{daySeconds, 'd'},
+		{hourSeconds, 'h'},
+		{minuteSeconds, 'm'},
+		{1, 's'},
+	}
+
+	buf := make([]byte, 0, 16)
+	for _, u := range units {
+		n := dur / u.seconds
+		dur -= n * u.seconds
+		if n > 0 {
+			buf = strconv.AppendInt(buf, int64(n), 10)
+			buf = append(buf, u.suffix)
+		}
+	}
+	return string(buf)
+}
@@ -0,0 +1,163 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_parseDuration(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{in: "1h30m", want: 90 * time.Minute},
+		{in: "1.5h", want: 90 * time.Minute},
+		{in: "3d", want: 72 * time.Hour},
+		{in: "PT8H", want: 8 * time.Hour},
+		{in: "P1D", want: 24 * time.Hour},
+		{in: "P1DT12H30M", want: 36*time.Hour + 30*time.Minute},
+		{in: "P2W", want: 14 * 24 * time.Hour},
+		{in: "PT1.5H", want: 90 * time.Minute},
+		{in: "PT0,5M", want: 30 * time.Second},
+		{in: "pt45m", want: 45 * time.Minute},
+		{in: "PT90S", want: 90 * time.Second},
+		{in: "P1M", wantErr: true},
+		{in: "P1Y", wantErr: true},
+		{in: "P", wantErr: true},
+		{in: "PT", wantErr: true},
+		{in: "P1DT", wantErr: true},
+		{in: "PT1.5H30M", wantErr: true},
+		{in: "PT8X", wantErr: true},
+		{in: "soon", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := parseDuration(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseDuration() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseDuration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_suppressEquivalentDuration(t *testing.T) {
+	tests := []struct {
+		old  string
+		new  string
+		want bool
+	}{
+		{old: "8h", new: "PT8H", want: true},
+		{old: "1d", new: "24h", want: true},
+		{old: "1h30m", new: "90m", want: true},
+		{old: "8h", new: "PT9H", want: false},
+		{old: "", new: "8h", want: false},
+		{old: "8h", new: "soon", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.old+" to "+tt.new, func(t *testing.T) {
+			if got := suppressEquivalentDuration("shift.0.duration", tt.old, tt.new, nil); got != tt.want {
+				t.Errorf("suppressEquivalentDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_prettyPrintDuration(t *testing.T) {
+	minuteSeconds := 60
+	hourSeconds := minuteSeconds * 60
+	daySeconds := hourSeconds * 24
+	weekSeconds := daySeconds * 7
+	tests := []struct {
+		name string
+		dur  int
+		want string
+	}{
+		{
+			name: "Test 1 minute",
+			dur:  60,
+			want: "1m",
+		},
+		{
+			name: "Test 1 day",
+			dur:  1 * daySeconds,
+			want: "1d",
+		},
+		{
+			name: "Test 1 week",
+			dur:  1 * weekSeconds,
+			want: "1w",
+		},
+		{
+			name: "Test 1 day 1 hour 1 minute",
+			dur:  daySeconds + hourSeconds + minuteSeconds,
+			want: "1d1h1m",
+		},
+		{
+			name: "Test 1 week 1 day 1 hour 1 minute",
+			dur:  weekSeconds + daySeconds + hourSeconds + minuteSeconds,
+			want: "1w1d1h1m",
+		},
+		{
+			name: "Test zero",
+			dur:  0,
+			want: "",
+		},
+		{
+			name: "Test seconds",
+			dur:  hourSeconds + 5,
+			want: "1h5s",
+		},
+		{
+			name: "Test many weeks",
+			dur:  52*weekSeconds + 6*daySeconds + 23*hourSeconds + 59*minuteSeconds + 59,
+			want: "52w6d23h59m59s",
+		},
+		{
+			name: "Test negative",
+			dur:  -daySeconds,
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := prettyPrintDuration(tt.dur); got != tt.want {
+				t.Errorf("prettyPrintDuration() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Benchmark_prettyPrintDuration(b *testing.B) {
+	dur := weekSeconds + 2*daySeconds + 3*hourSeconds + 4*minuteSeconds + 5
+	for i := 0; i < b.N; i++ {
+		prettyPrintDuration(dur)
+	}
+}
+
+func Test_durationStateFunc(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "6h", want: "6h"},
+		{in: "90m", want: "1h30m"},
+		{in: "6h0m30s", want: "6h30s"},
+		{in: "15m", want: "15m"},
+		{in: "1.5s", want: "1.5s"},
+		{in: "soon", want: "soon"},
+		{in: "PT8H", want: "8h"},
+		{in: "P1DT12H", want: "1d12h"},
+		{in: "P1W", want: "1w"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := durationStateFunc(tt.in); got != tt.want {
+				t.Errorf("durationStateFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package oncall
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// failedCall is a request to oncall that got an error response
+type failedCall struct {
+	method      string
+	path        string
+	status      int
+	description string
+	// team is the team the request was about, if any
+	team string
+	// user is who the provider acts as, the username or API application
+	user string
+}
+
+// lastFailedCall remembers the most recent request of an operation if it
+// failed, so errors can be explained with what oncall was asked to do
+type lastFailedCall struct {
+	mu   sync.Mutex
+	call *failedCall
+}
+
+type lastFailedCallKey struct{}
+
+// recordFailedCall remembers the request if oncall answered it with an error,
+// and forgets the previous one if it succeeded, as a failure the resource
+// recovered from (e.g. a 404 for something missing) isn't why it errored
+func recordFailedCall(ctx context.Context, req *http.Request, resp *http.Response) {
+	last, ok := ctx.Value(lastFailedCallKey{}).(*lastFailedCall)
+	if !ok || resp == nil {
+		return
+	}
+
+	var call *failedCall
+	if resp.StatusCode >= 400 {
+		call = &failedCall{
+			method: req.Method,
+			path:   req.URL.Path,
+			status: resp.StatusCode,
+			team:   requestTeam(req),
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			e := apiError{}
+			if json.Unmarshal(body, &e) != nil || e.Description == "" {
+				e.Description = string(body)
+			}
+			call.description = e.Description
+		}
+	}
+
+	last.mu.Lock()
+	defer last.mu.Unlock()
+	last.call = call
+}
+
+// remediationHint says what to do about common oncall errors
+func remediationHint(call failedCall) string {
+	description := strings.ToLower(call.description)
+	mentions := func(words ...string) bool {
+		for _, w := range words {
+			if strings.Contains(description, w) {
+				return true
+			}
+		}
+		return false
+	}
+	missing := call.status == 404 || mentions("not found", "does not exist", "invalid", "unknown")
+
+	switch {
+	case call.status == 401:
+		return fmt.Sprintf("Oncall did not accept the provider's credentials, check the %s, %s, and %s settings.", providerFieldUsername, providerFieldPassword, providerFieldAuthType)
+	case call.status == 403:
+		return permissionHint(call)
+	case mentions("timezone"):
+		return "Use one of the timezones in the oncall instance's supported_timezones setting, which are listed by the oncall_instance_config data source."
+	case missing && mentions("user"):
+		return "Check the username is right and that the user exists in oncall. Users are normally synced into oncall from a directory, so new users may not be there yet."
+	case missing && mentions("roster"):
+		return "Check the roster exists, and reference the oncall_roster resource so it is created first."
+	case missing && (mentions("team") || strings.HasPrefix(call.path, "/api/v0/teams/")):
+		return "Check the team name is right, and reference the oncall_team resource so the team is created first."
+	}
+	return ""
+}
+
+// permissionHint says who was refused, and what they need to be allowed to
+// make the request
+func permissionHint(call failedCall) string {
+	who := "The provider's user"
+	if call.user != "" {
+		who = fmt.Sprintf("The provider's user %q", call.user)
+	}
+	if call.team == "" {
+		return fmt.Sprintf("%s is not allowed to do this, it needs to be an admin of oncall. With API auth the application needs that access instead.", who)
+	}
+	return fmt.Sprintf("%s is not allowed to change team %s, it needs to be an admin of the team (or of oncall). Add it to the team's admins, or set %s so teams the provider creates keep it as one. With API auth the application needs access to the team instead.", who, call.team, providerFieldAddSelfAsTeamAdmin)
+}
+
+// explainErrors adds a remediation hint and the request which failed to the
+// errors of an operation, when they were caused by an error response from oncall
+func explainErrors(f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		last := &lastFailedCall{}
+		diags := f(context.WithValue(ctx, lastFailedCallKey{}, last), d, m)
+
+		last.mu.Lock()
+		call := last.call
+		last.mu.Unlock()
+		if call == nil {
+			return diags
+		}
+		if meta, ok := m.(*providerMeta); ok && meta.client != nil {
+			call.user = meta.client.Config.Username
+		}
+
+		for i := range diags {
+			if diags[i].Severity != diag.Error {
+				continue
+			}
+			if hint := remediationHint(*call); hint != "" {
+				diags[i].Detail = strings.TrimSpace(diags[i].Detail + "\n\n" + hint)
+			}
+			diags[i].Detail = strings.TrimSpace(fmt.Sprintf("%s\n\nFailed request: %s %s (%d)", diags[i].Detail, call.method, call.path, call.status))
+			break
+		}
+		return diags
+	}
+}
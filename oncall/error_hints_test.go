@@ -0,0 +1,121 @@
+package oncall
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_remediationHint(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     failedCall
+		wantHint string
+	}{
+		{
+			name:     "Unknown user",
+			call:     failedCall{method: "POST", path: "/api/v0/teams/t/rosters/r/users", status: 422, description: "Invalid user: jdoe"},
+			wantHint: "user exists in oncall",
+		},
+		{
+			name:     "Team not found",
+			call:     failedCall{method: "GET", path: "/api/v0/teams/t", status: 404, description: "Team not found"},
+			wantHint: "team name is right",
+		},
+		{
+			name:     "Missing team from path",
+			call:     failedCall{method: "GET", path: "/api/v0/teams/t/rosters", status: 404},
+			wantHint: "team name is right",
+		},
+		{
+			name:     "Invalid timezone",
+			call:     failedCall{method: "PUT", path: "/api/v0/teams/t", status: 422, description: "Invalid scheduling timezone: Mars/Olympus"},
+			wantHint: "supported_timezones",
+		},
+		{
+			name:     "Permission denied",
+			call:     failedCall{method: "PUT", path: "/api/v0/teams/t", status: 403, description: "Forbidden", team: "t", user: "terraform"},
+			wantHint: `user "terraform" is not allowed to change team t, it needs to be an admin of the team`,
+		},
+		{
+			name:     "Permission denied outside of a team",
+			call:     failedCall{method: "POST", path: "/api/v0/users", status: 403, description: "Forbidden"},
+			wantHint: "needs to be an admin of oncall",
+		},
+		{
+			name:     "Bad credentials",
+			call:     failedCall{method: "GET", path: "/api/v0/teams/t", status: 401},
+			wantHint: "credentials",
+		},
+		{
+			name: "Unclassified",
+			call: failedCall{method: "GET", path: "/api/v0/roles", status: 500, description: "oops"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := remediationHint(tt.call)
+			if tt.wantHint == "" && got != "" {
+				t.Errorf("remediationHint() = %q, want no hint", got)
+			}
+			if !strings.Contains(got, tt.wantHint) {
+				t.Errorf("remediationHint() = %q, want it to contain %q", got, tt.wantHint)
+			}
+		})
+	}
+}
+
+func Test_explainErrors(t *testing.T) {
+	failing := func(calls ...int) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			for _, status := range calls {
+				req := httptest.NewRequest("GET", "http://oncall/api/v0/teams/t", nil)
+				resp := &http.Response{StatusCode: status, Body: ioutil.NopCloser(strings.NewReader(`{"title": "Not Found", "description": "Team not found"}`))}
+				recordFailedCall(ctx, req, resp)
+				if body, _ := ioutil.ReadAll(resp.Body); status >= 400 && !strings.Contains(string(body), "Team not found") {
+					t.Errorf("response body was not left for the client to read, got %q", body)
+				}
+			}
+			return diag.Errorf("Getting team t: HTTP Request failed")
+		}
+	}
+
+	tests := []struct {
+		name       string
+		calls      []int
+		wantDetail string
+	}{
+		{
+			name:       "Failed request",
+			calls:      []int{404},
+			wantDetail: "Failed request: GET /api/v0/teams/t (404)",
+		},
+		{
+			name:  "Recovered from failed request",
+			calls: []int{404, 200},
+		},
+		{
+			name: "No requests",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := explainErrors(failing(tt.calls...))(context.Background(), nil, nil)
+			if len(diags) != 1 {
+				t.Fatalf("explainErrors() returned %d diagnostics, want 1", len(diags))
+			}
+			if tt.wantDetail == "" && diags[0].Detail != "" {
+				t.Errorf("explainErrors() detail = %q, want none", diags[0].Detail)
+			}
+			if !strings.Contains(diags[0].Detail, tt.wantDetail) {
+				t.Errorf("explainErrors() detail = %q, want it to contain %q", diags[0].Detail, tt.wantDetail)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package oncall
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// scheduleEvent is a single calendar event as returned by /api/v0/events
+type scheduleEvent struct {
+	ID         int    `json:"id"`
+	Start      int64  `json:"start"`
+	End        int64  `json:"end"`
+	User       string `json:"user"`
+	Team       string `json:"team"`
+	Role       string `json:"role"`
+	ScheduleID *int   `json:"schedule_id"`
+}
+
+// getScheduleEvents returns the events created by the given schedule that end after the since time
+func getScheduleEvents(c *oncall.Client, team, role string, scheduleID int, since time.Time) ([]scheduleEvent, error) {
+	query := url.Values{}
+	query.Set("team__eq", team)
+	query.Set("role__eq", role)
+	query.Set("end__gt", fmt.Sprintf("%d", since.Unix()))
+
+	allEvents := []scheduleEvent{}
+	_, err := c.Get("/api/v0/events?"+query.Encode(), &allEvents)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Fetching %s events for team %s", role, team)
+	}
+
+	events := make([]scheduleEvent, 0, len(allEvents))
+	for _, e := range allEvents {
+		if e.ScheduleID != nil && *e.ScheduleID == scheduleID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// getEventsBetween returns the events of the role that overlap the window,
+// whichever schedule (or override) they came from
+func getEventsBetween(c *oncall.Client, team, role string, start, end time.Time) ([]scheduleEvent, error) {
+	query := url.Values{}
+	query.Set("team__eq", team)
+	query.Set("role__eq", role)
+	query.Set("start__lt", fmt.Sprintf("%d", end.Unix()))
+	query.Set("end__gt", fmt.Sprintf("%d", start.Unix()))
+
+	events := []scheduleEvent{}
+	_, err := c.Get("/api/v0/events?"+query.Encode(), &events)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Fetching %s events for team %s between %s and %s", role, team, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339))
+	}
+	return events, nil
+}
+
+// getCurrentEvents returns the team's events, of any role, that are going on
+// at the given time
+func getCurrentEvents(c *oncall.Client, team string, now time.Time) ([]scheduleEvent, error) {
+	query := url.Values{}
+	query.Set("team__eq", team)
+	query.Set("start__le", fmt.Sprintf("%d", now.Unix()))
+	query.Set("end__gt", fmt.Sprintf("%d", now.Unix()))
+
+	events := []scheduleEvent{}
+	_, err := c.Get("/api/v0/events?"+query.Encode(), &events)
+	return events, errors.Wrapf(err, "Fetching current events for team %s", team)
+}
+
+// deleteEvent removes a single calendar event by id
+func deleteEvent(c *oncall.Client, eventID int) error {
+	_, err := c.Delete(fmt.Sprintf("/api/v0/events/%d", eventID), nil, nil)
+	return errors.Wrapf(err, "Deleting event %d", eventID)
+}
+
+// updateEvent moves a single calendar event to a new start and end
+func updateEvent(c *oncall.Client, change eventChange) error {
+	body := map[string]int64{"start": change.Start, "end": change.End}
+	_, err := c.Put(fmt.Sprintf("/api/v0/events/%d", change.ID), body, nil)
+	return errors.Wrapf(err, "Updating event %d", change.ID)
+}
+
+// getEvent returns a single calendar event by id, false if it no longer exists
+func getEvent(c *oncall.Client, eventID int) (scheduleEvent, bool, error) {
+	e := scheduleEvent{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/events/%d", eventID), &e)
+	if apiErr, ok := parseAPIError(err); ok && apiErr.status == 404 {
+		return e, false, nil
+	}
+	return e, err == nil, errors.Wrapf(err, "Fetching event %d", eventID)
+}
+
+// swapEvents exchanges the users of two calendar events
+func swapEvents(c *oncall.Client, a, b int) error {
+	body := map[string]interface{}{
+		"events": []map[string]interface{}{
+			{"id": a, "linked": false},
+			{"id": b, "linked": false},
+		},
+	}
+	_, err := c.Post("/api/v0/events/swap", body, nil)
+	return errors.Wrapf(err, "Swapping events %d and %d", a, b)
+}
+
+// nextHandoff is the start of the first event after now, which is when the
+// schedule next hands off to someone. False if nothing is scheduled after now.
+func nextHandoff(events []scheduleEvent, now time.Time) (time.Time, bool) {
+	var next int64
+	for _, e := range events {
+		if e.Start > now.Unix() && (next == 0 || e.Start < next) {
+			next = e.Start
+		}
+	}
+	if next == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(next, 0).UTC(), true
+}
+
+// populationStatus reports how far ahead the calendar is populated, and
+// whether that is as far as the schedule's auto_populate_days asks for. Oncall
+// tops calendars up periodically, so up to a week short still counts as populated.
+func populationStatus(events []scheduleEvent, now time.Time, autoPopulateDays int) (scheduledUntil time.Time, status string) {
+	var until int64
+	for _, e := range events {
+		if e.End > until {
+			until = e.End
+		}
+	}
+	if until <= now.Unix() {
+		return time.Time{}, populationStatusEmpty
+	}
+
+	scheduledUntil = time.Unix(until, 0).UTC()
+	wantUntil := now.AddDate(0, 0, autoPopulateDays-7)
+	if scheduledUntil.Before(wantUntil) {
+		return scheduledUntil, populationStatusBehind
+	}
+	return scheduledUntil, populationStatusPopulated
+}
+
+// readScheduleCalendar sets the computed attributes that come from the
+// schedule's populated calendar
+func readScheduleCalendar(c *oncall.Client, d *schema.ResourceData, team string, schedule rosterSchedule) {
+	d.Set(scheduleFieldLastEpochScheduled, schedule.LastEpochScheduled)
+	if schedule.ID == 0 {
+		d.Set(scheduleFieldNextRotationAt, "")
+		d.Set(scheduleFieldScheduledUntil, "")
+		d.Set(scheduleFieldPopulationStatus, populationStatusEmpty)
+		return
+	}
+
+	now := time.Now()
+	events, err := getScheduleEvents(c, team, schedule.Role, schedule.ID, now)
+	if err != nil {
+		warnLog("Could not get upcoming events of %s/%s/%s: %s", team, schedule.Roster, schedule.Role, err)
+		return
+	}
+
+	d.Set(scheduleFieldNextRotationAt, "")
+	if next, ok := nextHandoff(events, now); ok {
+		d.Set(scheduleFieldNextRotationAt, next.Format(time.RFC3339))
+	}
+
+	scheduledUntil, status := populationStatus(events, now, schedule.AutoPopulateThreshold)
+	d.Set(scheduleFieldScheduledUntil, "")
+	if !scheduledUntil.IsZero() {
+		d.Set(scheduleFieldScheduledUntil, scheduledUntil.Format(time.RFC3339))
+	}
+	d.Set(scheduleFieldPopulationStatus, status)
+}
@@ -0,0 +1,94 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+)
+
+func Test_nextHandoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tests := []struct {
+		name   string
+		events []scheduleEvent
+		want   time.Time
+		wantOK bool
+	}{
+		{
+			name:   "Nothing populated",
+			events: []scheduleEvent{},
+		},
+		{
+			name:   "Only the current shift",
+			events: []scheduleEvent{{ID: 1, Start: 500, End: 1500}},
+		},
+		{
+			name: "Next shift after the current one",
+			events: []scheduleEvent{
+				{ID: 3, Start: 2500, End: 3500},
+				{ID: 1, Start: 500, End: 1500},
+				{ID: 2, Start: 1500, End: 2500},
+			},
+			want:   time.Unix(1500, 0).UTC(),
+			wantOK: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOK := nextHandoff(tt.events, now)
+			if gotOK != tt.wantOK || !got.Equal(tt.want) {
+				t.Errorf("nextHandoff() = %v, %v, want %v, %v", got, gotOK, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_populationStatus(t *testing.T) {
+	now := time.Unix(1000000, 0)
+	day := int64(24 * 60 * 60)
+	tests := []struct {
+		name               string
+		events             []scheduleEvent
+		autoPopulateDays   int
+		wantScheduledUntil time.Time
+		wantStatus         string
+	}{
+		{
+			name:             "Nothing populated",
+			events:           []scheduleEvent{},
+			autoPopulateDays: 21,
+			wantStatus:       populationStatusEmpty,
+		},
+		{
+			name: "Populated three weeks ahead",
+			events: []scheduleEvent{
+				{Start: now.Unix() - day, End: now.Unix() + 6*day},
+				{Start: now.Unix() + 6*day, End: now.Unix() + 21*day},
+			},
+			autoPopulateDays:   21,
+			wantScheduledUntil: now.Add(21 * 24 * time.Hour).UTC(),
+			wantStatus:         populationStatusPopulated,
+		},
+		{
+			name:               "Within a week of auto_populate_days",
+			events:             []scheduleEvent{{Start: now.Unix() - day, End: now.Unix() + 15*day}},
+			autoPopulateDays:   21,
+			wantScheduledUntil: now.Add(15 * 24 * time.Hour).UTC(),
+			wantStatus:         populationStatusPopulated,
+		},
+		{
+			name:               "Running dry",
+			events:             []scheduleEvent{{Start: now.Unix() - day, End: now.Unix() + 2*day}},
+			autoPopulateDays:   21,
+			wantScheduledUntil: now.Add(2 * 24 * time.Hour).UTC(),
+			wantStatus:         populationStatusBehind,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotScheduledUntil, gotStatus := populationStatus(tt.events, now, tt.autoPopulateDays)
+			if !gotScheduledUntil.Equal(tt.wantScheduledUntil) || gotStatus != tt.wantStatus {
+				t.Errorf("populationStatus() = %v, %s, want %v, %s", gotScheduledUntil, gotStatus, tt.wantScheduledUntil, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package oncall
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const scheduleFieldExcludeMembers = "exclude_members"
+
+// excludeMembersSchema is the schema of exclude_members, shared by the
+// schedule resources that populate their calendar through populateOrPreview
+func excludeMembersSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeSet,
+		Optional:    true,
+		Elem:        usernameElem(),
+		Set:         hashUsername,
+		Description: "Usernames of roster members this schedule's populate skips, e.g. new hires still ramping up, without taking them off the roster. Oncall only has in rotation per roster, so they are taken out of rotation while the schedule populates and put back after, other schedules on the roster still schedule them",
+	}
+}
+
+// excludedMembers are the usernames in exclude_members
+func excludedMembers(d *schema.ResourceData) []string {
+	raw, ok := d.GetOk(scheduleFieldExcludeMembers)
+	if !ok {
+		return nil
+	}
+	exclude := []string{}
+	for _, name := range raw.(*schema.Set).List() {
+		exclude = append(exclude, normalizeUsername(name.(string)))
+	}
+	sort.Strings(exclude)
+	return exclude
+}
+
+// excludeFromRotation takes the excluded members of the roster out of
+// rotation, returning the function that puts them back. It must be called
+// holding the roster's populate lock, so no other schedule on the roster
+// populates while they are out.
+func excludeFromRotation(c *oncall.Client, team, roster string, exclude []string) (restore func() error, err error) {
+	restore = func() error { return nil }
+	if len(exclude) == 0 {
+		return restore, nil
+	}
+
+	members, err := getRosterMembers(c, team, roster)
+	if err != nil {
+		return restore, err
+	}
+	excluded := map[string]bool{}
+	for _, name := range exclude {
+		excluded[name] = true
+	}
+	toggle, left := []string{}, 0
+	for _, m := range members {
+		switch {
+		case !bool(m.InRotation):
+		case excluded[m.Name]:
+			toggle = append(toggle, m.Name)
+		default:
+			left++
+		}
+	}
+	if len(toggle) == 0 {
+		traceLog("None of %v are in rotation on roster %s/%s, nothing to exclude", exclude, team, roster)
+		return restore, nil
+	}
+	if left == 0 {
+		return restore, fmt.Errorf("%s %v leaves nobody in rotation on roster %s/%s", scheduleFieldExcludeMembers, exclude, team, roster)
+	}
+
+	path := fmt.Sprintf("/api/v0/teams/%s/rosters/%s/users/", url.PathEscape(team), url.PathEscape(roster))
+	setInRotation := func(names []string, inRotation bool) error {
+		for _, name := range names {
+			traceLog("Going to set %s in rotation on roster %s/%s to %v", name, team, roster, inRotation)
+			_, err := c.Put(path+url.PathEscape(name), map[string]interface{}{"in_rotation": inRotation}, nil)
+			if err != nil {
+				return errors.Wrapf(err, "Setting %s in rotation on roster %s/%s to %v", name, team, roster, inRotation)
+			}
+		}
+		return nil
+	}
+
+	restore = func() error {
+		err := setInRotation(toggle, true)
+		if err != nil {
+			warnLog("Could not put excluded members %v back in rotation on roster %s/%s: %s", toggle, team, roster, err)
+		}
+		return err
+	}
+	debugLog("Taking %v out of rotation on roster %s/%s while populating", toggle, team, roster)
+	if err := setInRotation(toggle, false); err != nil {
+		// Put back whoever was already taken out before giving up
+		restore()
+		return func() error { return nil }, err
+	}
+	return restore, nil
+}
+
+// scheduleExclusions are the members to exclude when populating the roster,
+// only the schedule's own roster and not its fallback's
+func scheduleExclusions(d *schema.ResourceData, team, roster string) []string {
+	if getRosterID(team, roster) != d.Get(scheduleFieldRosterID).(string) {
+		return nil
+	}
+	return excludedMembers(d)
+}
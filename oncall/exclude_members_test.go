@@ -0,0 +1,93 @@
+package oncall
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_excludeFromRotation(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			w.Write([]byte(`{"id":1,"name":"roster","users":[
+				{"name":"alice","in_rotation":1,"roster_priority":0},
+				{"name":"bob","in_rotation":1,"roster_priority":1},
+				{"name":"carol","in_rotation":0,"roster_priority":2}
+			]}`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`null`))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		exclude     []string
+		wantExclude []string
+		wantRestore []string
+		wantErr     bool
+	}{
+		{
+			name:        "Nothing excluded",
+			wantExclude: []string{},
+			wantRestore: []string{},
+		},
+		{
+			name:    "Excluded member in rotation",
+			exclude: []string{"bob"},
+			wantExclude: []string{
+				`PUT /api/v0/teams/team/rosters/roster/users/bob {"in_rotation":false}`,
+			},
+			wantRestore: []string{
+				`PUT /api/v0/teams/team/rosters/roster/users/bob {"in_rotation":true}`,
+			},
+		},
+		{
+			name:        "Excluded members out of rotation or not on the roster",
+			exclude:     []string{"carol", "dave"},
+			wantExclude: []string{},
+			wantRestore: []string{},
+		},
+		{
+			name:        "Nobody left in rotation",
+			exclude:     []string{"alice", "bob"},
+			wantExclude: []string{},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = []string{}
+			restore, err := excludeFromRotation(c, "team", "roster", tt.exclude)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("excludeFromRotation() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(requests, tt.wantExclude) {
+				t.Errorf("excludeFromRotation() requests = %v, want %v", requests, tt.wantExclude)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			requests = []string{}
+			if err := restore(); err != nil {
+				t.Fatalf("restore() error = %v", err)
+			}
+			if !reflect.DeepEqual(requests, tt.wantRestore) {
+				t.Errorf("restore() requests = %v, want %v", requests, tt.wantRestore)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package oncall
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// parseEndpoints splits the comma separated endpoint setting into the primary
+// endpoint followed by its fallbacks
+func parseEndpoints(endpoint string) []string {
+	endpoints := []string{}
+	for _, e := range strings.Split(endpoint, ",") {
+		e = strings.TrimRight(strings.TrimSpace(e), "/")
+		if e != "" {
+			endpoints = append(endpoints, e)
+		}
+	}
+	return endpoints
+}
+
+// failoverRoundTripper sends requests meant for the primary endpoint to the
+// first endpoint that can be connected to. Once it has failed over it sticks
+// with that endpoint rather than trying the primary again on every request.
+type failoverRoundTripper struct {
+	endpoints []string
+	proxied   http.RoundTripper
+
+	mu     sync.Mutex
+	active int
+}
+
+func newFailoverRoundTripper(endpoints []string, proxied http.RoundTripper) *failoverRoundTripper {
+	if proxied == nil {
+		proxied = http.DefaultTransport
+	}
+	return &failoverRoundTripper{
+		endpoints: endpoints,
+		proxied:   proxied,
+	}
+}
+
+func (frt *failoverRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	primary := frt.endpoints[0]
+	if !strings.HasPrefix(req.URL.String(), primary) {
+		return frt.proxied.RoundTrip(req)
+	}
+	path := strings.TrimPrefix(req.URL.String(), primary)
+
+	frt.mu.Lock()
+	start := frt.active
+	frt.mu.Unlock()
+
+	var lastErr error
+	for i := 0; i < len(frt.endpoints); i++ {
+		index := (start + i) % len(frt.endpoints)
+		attempt, err := frt.requestFor(req, frt.endpoints[index]+path, i > 0)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := frt.proxied.RoundTrip(attempt)
+		if err == nil {
+			frt.setActive(index)
+			return resp, nil
+		}
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+
+		if !safeToResend(req.Method, err) {
+			return nil, err
+		}
+
+		warnLog("Could not connect to oncall at %s: %s", frt.endpoints[index], err)
+		lastErr = err
+	}
+	return nil, errors.Wrapf(lastErr, "Could not connect to any of the %d oncall endpoints", len(frt.endpoints))
+}
+
+// safeToResend is whether a request that failed with err can be sent to the
+// next endpoint. Reads can always be, but a request that changes something
+// only when it never reached oncall, as a timeout after oncall received e.g.
+// a create would make the next endpoint create it again.
+func safeToResend(method string, err error) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// requestFor clones the request to go to target, rewinding its body if the
+// request has already been attempted against another endpoint
+func (frt *failoverRoundTripper) requestFor(req *http.Request, target string, retry bool) (*http.Request, error) {
+	u, err := url.Parse(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Parsing oncall endpoint URL %s", target)
+	}
+
+	attempt := req.Clone(req.Context())
+	attempt.URL = u
+	attempt.Host = ""
+	if retry && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, errors.New("Cannot fail over a request whose body cannot be replayed")
+		}
+		attempt.Body, err = req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "Rewinding request body to fail over")
+		}
+	}
+	return attempt, nil
+}
+
+func (frt *failoverRoundTripper) setActive(index int) {
+	frt.mu.Lock()
+	defer frt.mu.Unlock()
+	if frt.active != index {
+		infoLog("Failed over to oncall at %s", frt.endpoints[index])
+		frt.active = index
+	}
+}
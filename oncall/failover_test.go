@@ -0,0 +1,131 @@
+package oncall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_parseEndpoints(t *testing.T) {
+	tests := []struct {
+		name     string
+		endpoint string
+		want     []string
+	}{
+		{
+			name:     "Single endpoint",
+			endpoint: "https://oncall.example.com/",
+			want:     []string{"https://oncall.example.com"},
+		},
+		{
+			name:     "Fallbacks",
+			endpoint: "https://dc1.example.com, https://dc2.example.com/oncall/,",
+			want:     []string{"https://dc1.example.com", "https://dc2.example.com/oncall"},
+		},
+		{
+			name:     "Empty",
+			endpoint: " , ",
+			want:     []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseEndpoints(tt.endpoint); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseEndpoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_failoverRoundTripper(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	down.Close()
+
+	requests := []string{}
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`["team"]`))
+	}))
+	defer up.Close()
+
+	frt := newFailoverRoundTripper([]string{down.URL, up.URL + "/oncall"}, nil)
+	c, err := oncall.New(&http.Client{Transport: frt}, oncall.Config{Endpoint: down.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	teams := []string{}
+	_, err = c.Get("/api/v0/teams", &teams)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_, err = c.Post("/api/v0/teams", map[string]string{"name": "team"}, nil)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+
+	want := []string{"GET /oncall/api/v0/teams", "POST /oncall/api/v0/teams"}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("requests = %v, want %v", requests, want)
+	}
+	if frt.active != 1 {
+		t.Errorf("active endpoint = %d, want 1", frt.active)
+	}
+
+	up.Close()
+	_, err = c.Get("/api/v0/teams", &teams)
+	if err == nil {
+		t.Errorf("Get() with every endpoint down did not error")
+	}
+}
+
+func Test_failoverRoundTripperNoResend(t *testing.T) {
+	// The primary takes requests in but never answers them
+	hangUp := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+	}))
+	defer hangUp.Close()
+
+	requests := []string{}
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte(`[]`))
+	}))
+	defer up.Close()
+
+	tests := []struct {
+		method       string
+		wantErr      bool
+		wantRequests []string
+	}{
+		{method: http.MethodGet, wantRequests: []string{"GET /api/v0/teams"}},
+		{method: http.MethodPost, wantErr: true, wantRequests: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			requests = []string{}
+			frt := newFailoverRoundTripper([]string{hangUp.URL, up.URL}, nil)
+			req, err := http.NewRequest(tt.method, hangUp.URL+"/api/v0/teams", strings.NewReader(`{"name": "team"}`))
+			if err != nil {
+				t.Fatalf("NewRequest() error = %v", err)
+			}
+			resp, err := frt.RoundTrip(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RoundTrip() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
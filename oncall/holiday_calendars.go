@@ -0,0 +1,191 @@
+package oncall
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// Like slack usergroups, oncall has nowhere to keep holidays, so each holiday
+// calendar is kept as an annotation line of JSON at the end of the team's
+// description, e.g. `holiday-calendar: {"name":"us-2025","regions":["US"],...}`
+const holidayCalendarAnnotationPrefix = "holiday-calendar:"
+
+// holidayDateFormat is how holiday dates are written, e.g. 2025-12-25
+const holidayDateFormat = "2006-01-02"
+
+type holidayCalendar struct {
+	Name     string    `json:"name"`
+	Regions  []string  `json:"regions"`
+	Holidays []holiday `json:"holidays"`
+}
+
+type holiday struct {
+	Date string `json:"date"`
+	Name string `json:"name"`
+}
+
+func init() {
+	registerTeamAnnotation(teamAnnotationKind{
+		prefix: holidayCalendarAnnotationPrefix,
+		parse: func(rest string) (string, string, bool) {
+			cal, err := decodeHolidayCalendar(rest)
+			if err != nil || cal.Name == "" {
+				return "", "", false
+			}
+			return cal.Name, strings.TrimSpace(rest), true
+		},
+		format: func(name, cal string) string {
+			return cal
+		},
+	})
+}
+
+func decodeHolidayCalendar(annotation string) (holidayCalendar, error) {
+	cal := holidayCalendar{}
+	err := json.Unmarshal([]byte(annotation), &cal)
+	return cal, errors.Wrap(err, "Decoding holiday calendar")
+}
+
+// getTeamHolidayCalendar returns the named holiday calendar of the team, false
+// if the team has no calendar by that name
+func getTeamHolidayCalendar(c *oncall.Client, teamName, name string) (holidayCalendar, bool, error) {
+	calendars, err := getTeamAnnotations(c, teamName, holidayCalendarAnnotationPrefix)
+	if err != nil {
+		return holidayCalendar{}, false, err
+	}
+	annotation, ok := calendars[name]
+	if !ok {
+		return holidayCalendar{}, false, nil
+	}
+	cal, err := decodeHolidayCalendar(annotation)
+	return cal, err == nil, err
+}
+
+// setTeamHolidayCalendar stores the holiday calendar in the team's description,
+// a calendar without a name removes the one called name
+func setTeamHolidayCalendar(c *oncall.Client, teamName, name string, cal holidayCalendar) error {
+	return updateTeamAnnotations(c, teamName, holidayCalendarAnnotationPrefix, func(calendars map[string]string) error {
+		if cal.Name == "" {
+			delete(calendars, name)
+			return nil
+		}
+		return putHolidayCalendar(calendars, cal)
+	})
+}
+
+// addTeamHolidayCalendar stores the holiday calendar in the team's description
+// unless the team has a calendar by its name already, returning whether it had
+func addTeamHolidayCalendar(c *oncall.Client, teamName string, cal holidayCalendar) (found bool, err error) {
+	err = updateTeamAnnotations(c, teamName, holidayCalendarAnnotationPrefix, func(calendars map[string]string) error {
+		if _, found = calendars[cal.Name]; found {
+			return nil
+		}
+		return putHolidayCalendar(calendars, cal)
+	})
+	return found, err
+}
+
+func putHolidayCalendar(calendars map[string]string, cal holidayCalendar) error {
+	encoded, err := json.Marshal(cal)
+	if err != nil {
+		return errors.Wrapf(err, "Encoding holiday calendar %s", cal.Name)
+	}
+	calendars[cal.Name] = string(encoded)
+	return nil
+}
+
+// eventChange is a new start and end for a calendar event
+type eventChange struct {
+	ID    int
+	Start int64
+	End   int64
+}
+
+// holidayHandoffChanges moves handoffs that fall on a holiday to the same time
+// on the next day which isn't one, by ending the outgoing event and starting
+// the incoming event later. Days are as seen in the team's timezone. Handoffs
+// that can't be moved without swallowing the incoming event are left alone.
+func holidayHandoffChanges(events []scheduleEvent, holidays map[string]bool, loc *time.Location) []eventChange {
+	sorted := make([]scheduleEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	changes := map[int]*eventChange{}
+	change := func(e scheduleEvent) *eventChange {
+		if changes[e.ID] == nil {
+			changes[e.ID] = &eventChange{ID: e.ID, Start: e.Start, End: e.End}
+		}
+		return changes[e.ID]
+	}
+
+	for i := 1; i < len(sorted); i++ {
+		outgoing, incoming := sorted[i-1], sorted[i]
+		if outgoing.End != incoming.Start {
+			continue
+		}
+
+		handoff := time.Unix(incoming.Start, 0).In(loc)
+		moved := handoff
+		for holidays[moved.Format(holidayDateFormat)] {
+			moved = moved.AddDate(0, 0, 1)
+		}
+		if moved.Equal(handoff) || moved.Unix() >= incoming.End {
+			continue
+		}
+
+		change(outgoing).End = moved.Unix()
+		change(incoming).Start = moved.Unix()
+		sorted[i].Start = moved.Unix()
+	}
+
+	out := make([]eventChange, 0, len(changes))
+	for _, c := range changes {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// shiftHandoffsOffHolidays moves the upcoming handoffs of a schedule that fall
+// on one of the holidays of the calendar
+func shiftHandoffsOffHolidays(c *oncall.Client, teamName, role string, scheduleID int, calendarName string) error {
+	cal, found, err := getTeamHolidayCalendar(c, teamName, calendarName)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return errors.Errorf("Team %s has no holiday calendar %s", teamName, calendarName)
+	}
+	holidays := map[string]bool{}
+	for _, h := range cal.Holidays {
+		holidays[h.Date] = true
+	}
+
+	t, err := getTeam(c, teamName)
+	if err != nil {
+		return err
+	}
+	loc, err := time.LoadLocation(t.SchedulingTimezone)
+	if err != nil {
+		warnLog("Could not load timezone %q of team %s, using UTC for holidays: %s", t.SchedulingTimezone, teamName, err)
+		loc = time.UTC
+	}
+
+	events, err := getScheduleEvents(c, teamName, role, scheduleID, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, change := range holidayHandoffChanges(events, holidays, loc) {
+		traceLog("Moving event %d of %s/%s to %s - %s for holiday calendar %s", change.ID, teamName, role, time.Unix(change.Start, 0).UTC(), time.Unix(change.End, 0).UTC(), calendarName)
+		err = updateEvent(c, change)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_holidayCalendarAnnotations(t *testing.T) {
+	cal := holidayCalendar{
+		Name:     "us-2025",
+		Regions:  []string{"US"},
+		Holidays: []holiday{{Date: "2025-12-25", Name: "Christmas Day"}},
+	}
+
+	parsed := parseTeamDescription("Platform team")
+	err := putHolidayCalendar(parsed.annotations[holidayCalendarAnnotationPrefix], cal)
+	if err != nil {
+		t.Fatalf("putHolidayCalendar() error = %v", err)
+	}
+	description := parsed.String()
+	want := `Platform team
+
+holiday-calendar: {"name":"us-2025","regions":["US"],"holidays":[{"date":"2025-12-25","name":"Christmas Day"}]}`
+	if description != want {
+		t.Errorf("String() = %q, want %q", description, want)
+	}
+
+	// Slack usergroup annotations and holiday calendars are kept side by side
+	parsed = parseTeamDescription(description + "\n\nslack-usergroup: primary @platform-oncall")
+	if parsed.text != "Platform team" {
+		t.Errorf("parseTeamDescription() text = %q, want %q", parsed.text, "Platform team")
+	}
+	gotCal, err := decodeHolidayCalendar(parsed.annotations[holidayCalendarAnnotationPrefix]["us-2025"])
+	if err != nil {
+		t.Fatalf("decodeHolidayCalendar() error = %v", err)
+	}
+	if !reflect.DeepEqual(gotCal, cal) {
+		t.Errorf("decodeHolidayCalendar() = %v, want %v", gotCal, cal)
+	}
+	if usergroups := parsed.annotations[slackUsergroupAnnotationPrefix]; usergroups["primary"] != "@platform-oncall" {
+		t.Errorf("parseTeamDescription() usergroups = %v, want primary linked", usergroups)
+	}
+}
+
+func Test_holidayHandoffChanges(t *testing.T) {
+	loc, _ := time.LoadLocation("America/Chicago")
+	at := func(date string) int64 {
+		tm, _ := time.ParseInLocation("2006-01-02 15:04", date, loc)
+		return tm.Unix()
+	}
+	weekly := []scheduleEvent{
+		{ID: 1, Start: at("2025-12-18 09:00"), End: at("2025-12-25 09:00"), User: "a"},
+		{ID: 2, Start: at("2025-12-25 09:00"), End: at("2026-01-01 09:00"), User: "b"},
+		{ID: 3, Start: at("2026-01-01 09:00"), End: at("2026-01-08 09:00"), User: "c"},
+	}
+
+	tests := []struct {
+		name     string
+		events   []scheduleEvent
+		holidays map[string]bool
+		want     []eventChange
+	}{
+		{
+			name:     "No holidays",
+			events:   weekly,
+			holidays: map[string]bool{},
+			want:     []eventChange{},
+		},
+		{
+			name:     "Handoffs on holidays",
+			events:   weekly,
+			holidays: map[string]bool{"2025-12-25": true, "2025-12-26": true, "2026-01-01": true},
+			want: []eventChange{
+				{ID: 1, Start: at("2025-12-18 09:00"), End: at("2025-12-27 09:00")},
+				{ID: 2, Start: at("2025-12-27 09:00"), End: at("2026-01-02 09:00")},
+				{ID: 3, Start: at("2026-01-02 09:00"), End: at("2026-01-08 09:00")},
+			},
+		},
+		{
+			name: "Handoff that can't be moved",
+			events: []scheduleEvent{
+				{ID: 1, Start: at("2025-12-24 09:00"), End: at("2025-12-25 09:00")},
+				{ID: 2, Start: at("2025-12-25 09:00"), End: at("2025-12-25 17:00")},
+			},
+			holidays: map[string]bool{"2025-12-25": true},
+			want:     []eventChange{},
+		},
+		{
+			name: "Gap between events",
+			events: []scheduleEvent{
+				{ID: 1, Start: at("2025-12-18 09:00"), End: at("2025-12-24 17:00")},
+				{ID: 2, Start: at("2025-12-25 09:00"), End: at("2026-01-01 09:00")},
+			},
+			holidays: map[string]bool{"2025-12-25": true},
+			want:     []eventChange{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := holidayHandoffChanges(tt.events, tt.holidays, loc); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("holidayHandoffChanges() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// canonicalName resolves a name from an import ID against the names oncall
+// has, forgiving stray whitespace and a different case, e.g. from copying it
+// out of the UI. An exact match wins, otherwise it must match exactly one name
+// ignoring case. Names that match nothing are returned trimmed, so the read
+// that follows reports that they don't exist.
+func canonicalName(kind, name string, names []string) (string, error) {
+	name = strings.TrimSpace(name)
+	matches := []string{}
+	for _, n := range names {
+		if n == name {
+			return n, nil
+		}
+		if strings.EqualFold(n, name) {
+			matches = append(matches, n)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return name, nil
+	case 1:
+		if matches[0] != name {
+			debugLog("Importing %s %q as %q", kind, name, matches[0])
+		}
+		return matches[0], nil
+	}
+	return "", fmt.Errorf("%s %q is ambiguous, it could be any of %s", kind, name, strings.Join(matches, ", "))
+}
+
+func canonicalTeamName(c *oncall.Client, team string) (string, error) {
+	teams, err := c.GetTeams()
+	if err != nil {
+		return "", errors.Wrap(err, "Getting teams")
+	}
+	return canonicalName("team", team, teams)
+}
+
+func canonicalRosterID(c *oncall.Client, team, roster string) (string, string, error) {
+	team, err := canonicalTeamName(c, team)
+	if err != nil {
+		return "", "", err
+	}
+	rosters, err := c.GetRosters(team)
+	if err != nil && !isNotFound(err) {
+		return "", "", errors.Wrapf(err, "Getting rosters of team %s", team)
+	}
+	roster, err = canonicalName("roster", roster, rosters)
+	return team, roster, err
+}
+
+// canonicalScheduleImportID parses a schedule's import ID with
+// parseScheduleImportID and resolves its parts against oncall
+func canonicalScheduleImportID(ctx context.Context, importID string, m interface{}) (team, roster, role string, err error) {
+	team, roster, role, err = parseScheduleImportID(importID)
+	if err != nil {
+		return
+	}
+	c := m.(*providerMeta).clientFor(ctx)
+	team, roster, err = canonicalRosterID(c, team, roster)
+	if err != nil {
+		return
+	}
+
+	schedules, err := getRosterSchedules(c, team, roster)
+	if err != nil && !isNotFound(err) {
+		return "", "", "", errors.Wrapf(err, "Getting schedules of roster %s", getRosterID(team, roster))
+	}
+	roles := make([]string, 0, len(schedules))
+	for _, s := range schedules {
+		roles = append(roles, s.Role)
+	}
+	role, err = canonicalName("role", role, roles)
+	return
+}
+
+func canonicalTeamMemberID(c *oncall.Client, team, username string) (string, string, error) {
+	team, err := canonicalTeamName(c, team)
+	if err != nil {
+		return "", "", err
+	}
+	members, err := c.GetTeamUsers(team)
+	if err != nil && !isNotFound(err) {
+		return "", "", errors.Wrapf(err, "Getting members of team %s", team)
+	}
+	username, err = canonicalName("username", username, members)
+	return team, username, err
+}
@@ -0,0 +1,82 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_canonicalName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		names   []string
+		want    string
+		wantErr bool
+	}{
+		{name: "Exact", input: "platform", names: []string{"platform", "sre"}, want: "platform"},
+		{name: "Whitespace", input: " platform ", names: []string{"platform"}, want: "platform"},
+		{name: "Case", input: "Platform Team", names: []string{"platform team"}, want: "platform team"},
+		{name: "Exact match wins", input: "SRE", names: []string{"sre", "SRE"}, want: "SRE"},
+		{name: "Ambiguous", input: "Sre", names: []string{"sre", "SRE"}, wantErr: true},
+		{name: "Not found", input: " missing", names: []string{"platform"}, want: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalName("team", tt.input, tt.names)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("canonicalName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("canonicalName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_canonicalScheduleImportID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams":
+			w.Write([]byte(`["Platform Team", "sre"]`))
+		case "/api/v0/teams/Platform Team/rosters":
+			w.Write([]byte(`{"Primary Rotation": {"id": 3, "users": [], "schedules": []}}`))
+		case "/api/v0/teams/Platform Team/rosters/Primary Rotation/schedules":
+			w.Write([]byte(`[{"id": 7, "role": "primary"}]`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c}
+
+	tests := []struct {
+		name     string
+		importID string
+		want     string
+	}{
+		{name: "Canonical", importID: "Platform Team/Primary Rotation/primary", want: "Platform Team/Primary Rotation/primary"},
+		{name: "Copied with stray spaces and case", importID: "platform team /primary rotation/ Primary", want: "Platform Team/Primary Rotation/primary"},
+		{name: "Attributes", importID: "team=PLATFORM TEAM, roster=primary rotation, role=PRIMARY", want: "Platform Team/Primary Rotation/primary"},
+		{name: "Missing team", importID: "Other/roster/primary", want: "Other/roster/primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team, roster, role, err := canonicalScheduleImportID(context.Background(), tt.importID, meta)
+			if err != nil {
+				t.Fatalf("canonicalScheduleImportID() error = %v", err)
+			}
+			if got := getScheduleID(team, roster, role); got != tt.want {
+				t.Errorf("canonicalScheduleImportID() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package oncall
+
+import (
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// getTimezones returns the timezones the oncall instance supports scheduling
+// in, which is the supported_timezones setting of its config
+func getTimezones(c *oncall.Client) ([]string, error) {
+	timezones := []string{}
+	_, err := c.Get("/api/v0/timezones", &timezones)
+	return timezones, errors.Wrap(err, "Fetching timezones")
+}
+
+// getNotificationModes returns the ways the oncall instance can contact users,
+// e.g. email, sms, or call
+func getNotificationModes(c *oncall.Client) ([]string, error) {
+	modes := []string{}
+	_, err := c.Get("/api/v0/modes", &modes)
+	return modes, errors.Wrap(err, "Fetching notification modes")
+}
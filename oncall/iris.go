@@ -0,0 +1,110 @@
+package oncall
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const irisRequestTimeout = 30 * time.Second
+
+// irisClient looks plans up in the Iris instance oncall pages through, so a
+// team's iris_plan can be checked to exist before pages to it silently fail
+type irisClient struct {
+	endpoint string
+	// app and key sign requests with Iris' HMAC auth, requests are sent
+	// unsigned if app is empty
+	app  string
+	key  string
+	http *http.Client
+	now  func() time.Time
+}
+
+// newIrisClient is nil if no endpoint is set, as checking plans is optional
+func newIrisClient(endpoint, app, key string) *irisClient {
+	if endpoint == "" {
+		return nil
+	}
+	return &irisClient{
+		endpoint: strings.TrimRight(endpoint, "/"),
+		app:      app,
+		key:      key,
+		http:     &http.Client{Timeout: irisRequestTimeout},
+		now:      time.Now,
+	}
+}
+
+// sign sets the Authorization header Iris' API expects, an HMAC-SHA512 over
+// the 5 second window, method, path and body of the request
+func (ic *irisClient) sign(req *http.Request, body string) {
+	if ic.app == "" {
+		return
+	}
+	window := ic.now().Unix() / 5
+	mac := hmac.New(sha512.New, []byte(ic.key))
+	fmt.Fprintf(mac, "%d %s %s %s", window, req.Method, req.URL.RequestURI(), body)
+	req.Header.Set("Authorization", fmt.Sprintf("hmac %s:%s", ic.app, base64.URLEncoding.EncodeToString(mac.Sum(nil))))
+}
+
+// planExists is whether Iris has an active plan with the name
+func (ic *irisClient) planExists(ctx context.Context, plan string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ic.endpoint+"/v0/plans/"+url.PathEscape(plan), nil)
+	if err != nil {
+		return false, errors.Wrap(err, "Building iris request")
+	}
+	ic.sign(req, "")
+
+	traceLog("Checking iris plan %s exists", plan)
+	resp, err := ic.http.Do(req)
+	if err != nil {
+		return false, errors.Wrapf(err, "Getting iris plan %s", plan)
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 400:
+		return false, fmt.Errorf("Getting iris plan %s failed with %s", plan, resp.Status)
+	}
+	return true, nil
+}
+
+// checkIrisPlan fails if Iris is configured and has no plan with the name
+func checkIrisPlan(ctx context.Context, m interface{}, plan string) error {
+	ic := m.(*providerMeta).iris
+	if ic == nil || plan == "" {
+		return nil
+	}
+	exists, err := ic.planExists(ctx, plan)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("Iris plan %s does not exist in %s, pages to it would fail", plan, ic.endpoint)
+	}
+	return nil
+}
+
+// irisPlanCustomizeDiff checks at plan time that a changed iris plan exists,
+// when the provider has an iris_endpoint to look it up in
+func irisPlanCustomizeDiff(field string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if !d.HasChange(field) || !d.NewValueKnown(field) {
+			return nil
+		}
+		return checkIrisPlan(ctx, m, d.Get(field).(string))
+	}
+}
@@ -0,0 +1,120 @@
+package oncall
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func newTestIrisServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mac := hmac.New(sha512.New, []byte("secret"))
+		mac.Write([]byte("320000000 GET " + r.URL.RequestURI() + " "))
+		want := "hmac oncall:" + base64.URLEncoding.EncodeToString(mac.Sum(nil))
+		if got := r.Header.Get("Authorization"); got != want {
+			t.Errorf("Authorization = %q, want %q", got, want)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v0/plans/page-payments":
+			w.Write([]byte(`{"name": "page-payments", "active": 1}`))
+		case "/v0/plans/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"title": "Plan not found"}`))
+		}
+	}))
+}
+
+func Test_irisClient_planExists(t *testing.T) {
+	server := newTestIrisServer(t)
+	defer server.Close()
+	ic := newIrisClient(server.URL+"/", "oncall", "secret")
+	ic.now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	tests := []struct {
+		name    string
+		plan    string
+		want    bool
+		wantErr bool
+	}{
+		{
+			name: "Existing plan",
+			plan: "page-payments",
+			want: true,
+		},
+		{
+			name: "Missing plan",
+			plan: "page-payment",
+			want: false,
+		},
+		{
+			name:    "Iris failing",
+			plan:    "broken",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ic.planExists(context.Background(), tt.plan)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("planExists() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("planExists() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_irisPlanCustomizeDiff(t *testing.T) {
+	server := newTestIrisServer(t)
+	defer server.Close()
+	ic := newIrisClient(server.URL, "oncall", "secret")
+	ic.now = func() time.Time { return time.Unix(1600000000, 0) }
+
+	r := resourceTeamIrisPlanBinding()
+	tests := []struct {
+		name    string
+		meta    *providerMeta
+		plan    string
+		wantErr bool
+	}{
+		{
+			name: "Existing plan",
+			meta: &providerMeta{iris: ic},
+			plan: "page-payments",
+		},
+		{
+			name:    "Missing plan",
+			meta:    &providerMeta{iris: ic},
+			plan:    "page-payment",
+			wantErr: true,
+		},
+		{
+			name: "Iris not configured",
+			meta: &providerMeta{},
+			plan: "page-payment",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+				irisPlanBindingFieldTeam: "payments",
+				irisPlanBindingFieldPlan: tt.plan,
+			}), tt.meta)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Diff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package oncall
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// managerRole is the role oncall escalates a team's unacknowledged pages to
+const managerRole = "manager"
+
+func validateNotifyManagerAfter(in interface{}, path cty.Path) diag.Diagnostics {
+	seconds, err := parseDurationSeconds(in.(string))
+	if err != nil {
+		return diagFromErrf(err, "Failed to parse duration")
+	}
+	if seconds < 0 {
+		return diag.Errorf("%s can't be negative, use 0s to never notify the manager", teamFieldNotifyManagerAfter)
+	}
+	return nil
+}
+
+// formatNotifyManagerAfter is the notify_manager_after for seconds read back
+// from oncall, 0s rather than nothing when the manager is never notified
+func formatNotifyManagerAfter(seconds int) string {
+	if seconds == 0 {
+		return "0s"
+	}
+	return prettyPrintDuration(seconds)
+}
+
+// notifyManagerAfterStateFunc stores notify_manager_after the way it is read back
+func notifyManagerAfterStateFunc(val interface{}) string {
+	seconds, err := parseDurationSeconds(val.(string))
+	if err != nil || seconds < 0 {
+		return val.(string)
+	}
+	return formatNotifyManagerAfter(seconds)
+}
+
+// teamHasRoleSchedule is whether any of the team's rosters has a schedule for the role
+func teamHasRoleSchedule(c *oncall.Client, team, role string) (bool, error) {
+	rosters := map[string]userRoster{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters", url.PathEscape(team)), &rosters)
+	if err != nil {
+		return false, errors.Wrapf(err, "Fetching rosters of team %s", team)
+	}
+	for _, r := range rosters {
+		for _, s := range r.Schedules {
+			if s.Role == role {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// managerEscalationDiags warns when the team escalates to its manager role but
+// has no schedule putting anyone on call for it, so escalations reach nobody
+func managerEscalationDiags(c *oncall.Client, d *schema.ResourceData, team string) diag.Diagnostics {
+	seconds, err := parseDurationSeconds(d.Get(teamFieldNotifyManagerAfter).(string))
+	if err != nil || seconds <= 0 {
+		return nil
+	}
+
+	found, err := teamHasRoleSchedule(c, team, managerRole)
+	if err != nil {
+		return diagFromErrf(err, "Checking team %s has a %s schedule", team, managerRole)
+	}
+	if found {
+		return nil
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Team %s escalates to its %s role, but none of its rosters has a %s schedule", team, managerRole, managerRole),
+			Detail:   fmt.Sprintf("Pages left unacknowledged for %s won't reach anyone until a roster of the team gets a schedule for the %s role.", d.Get(teamFieldNotifyManagerAfter).(string), managerRole),
+		},
+	}
+}
@@ -0,0 +1,86 @@
+package oncall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_notifyManagerAfterStateFunc(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "30m", want: "30m"},
+		{in: "90m", want: "1h30m"},
+		{in: "0", want: "0s"},
+		{in: "0m", want: "0s"},
+		{in: "-5m", want: "-5m"},
+		{in: "soon", want: "soon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := notifyManagerAfterStateFunc(tt.in); got != tt.want {
+				t.Errorf("notifyManagerAfterStateFunc() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_managerEscalationDiags(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/managed/rosters":
+			w.Write([]byte(`{"oncall": {"users": [], "schedules": [{"role":"primary"}]}, "leads": {"users": [], "schedules": [{"role":"manager"}]}}`))
+		case "/api/v0/teams/unmanaged/rosters":
+			w.Write([]byte(`{"oncall": {"users": [], "schedules": [{"role":"primary"}]}}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		team        string
+		notifyAfter string
+		wantWarning bool
+		wantErr     bool
+	}{
+		{name: "Has a manager schedule", team: "managed", notifyAfter: "30m"},
+		{name: "No manager schedule", team: "unmanaged", notifyAfter: "30m", wantWarning: true},
+		{name: "Never notifies the manager", team: "unmanaged", notifyAfter: "0s"},
+		{name: "Not set", team: "unmanaged"},
+		{name: "Missing team", team: "missing", notifyAfter: "30m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				teamFieldName:   tt.team,
+				teamFieldAdmins: []interface{}{"alice"},
+			}
+			if tt.notifyAfter != "" {
+				config[teamFieldNotifyManagerAfter] = tt.notifyAfter
+			}
+			d := schema.TestResourceDataRaw(t, resourceTeam().Schema, config)
+
+			diags := managerEscalationDiags(c, d, tt.team)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("managerEscalationDiags() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			gotWarning := len(diags) == 1 && diags[0].Severity == diag.Warning
+			if !tt.wantErr && (gotWarning != tt.wantWarning || len(diags) > 1) {
+				t.Errorf("managerEscalationDiags() = %v, wantWarning %v", diags, tt.wantWarning)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package oncall
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// missingTeams remembers the teams found to be deleted from oncall during an
+// operation. After a team is deleted outside of terraform, each of its
+// rosters and schedules would otherwise look itself up and fail on its own,
+// so instead the first 404 checks whether the team is still there, and once
+// it isn't, requests for anything of the team get a 404 without calling oncall.
+type missingTeams struct {
+	mu    sync.Mutex
+	teams map[string]*teamCheck
+}
+
+// teamCheck is a check of whether a team exists, made once however many
+// requests find something of the team missing at the same time
+type teamCheck struct {
+	once    sync.Once
+	missing bool
+}
+
+func newMissingTeams() *missingTeams {
+	return &missingTeams{teams: map[string]*teamCheck{}}
+}
+
+func (mt *missingTeams) check(team string) *teamCheck {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	tc, ok := mt.teams[team]
+	if !ok {
+		tc = &teamCheck{}
+		mt.teams[team] = tc
+	}
+	return tc
+}
+
+// isMissing is whether the team has been found to be missing
+func (mt *missingTeams) isMissing(team string) bool {
+	if mt == nil {
+		return false
+	}
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	tc, ok := mt.teams[team]
+	return ok && tc.missing
+}
+
+// notFound checks whether the team is missing after a request for something
+// of it got a 404, calling exists only once per team
+func (mt *missingTeams) notFound(team string, exists func() (bool, error)) {
+	if mt == nil {
+		return
+	}
+	tc := mt.check(team)
+	tc.once.Do(func() {
+		found, err := exists()
+		if err != nil {
+			debugLog("Could not check whether team %s still exists: %s", team, err)
+			return
+		}
+		if !found {
+			debugLog("Team %s no longer exists, not looking up anything else of it", team)
+			mt.mu.Lock()
+			tc.missing = true
+			mt.mu.Unlock()
+		}
+	})
+}
+
+// reset forgets the missing teams after something was changed in oncall,
+// which may have created them again
+func (mt *missingTeams) reset() {
+	if mt == nil {
+		return
+	}
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+	mt.teams = map[string]*teamCheck{}
+}
+
+// missingTeamsRoundTripper answers requests for teams found to be deleted
+// without calling oncall, and forgets the missing teams once something changes
+type missingTeamsRoundTripper struct {
+	missing *missingTeams
+	proxied http.RoundTripper
+}
+
+func (mrt missingTeamsRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	team, isTeam := pathTeam(req)
+	if req.Method == http.MethodGet && team != "" && mrt.missing.isMissing(team) {
+		traceLog("Team %s no longer exists, not requesting %s", team, req.URL.Path)
+		return teamNotFoundResponse(req, team), nil
+	}
+
+	resp, err := mrt.proxied.RoundTrip(req)
+	if err == nil && req.Method == http.MethodGet && team != "" && resp.StatusCode == http.StatusNotFound {
+		if isTeam {
+			mrt.missing.notFound(team, func() (bool, error) { return false, nil })
+		} else {
+			mrt.missing.notFound(team, func() (bool, error) { return teamExists(mrt.proxied.RoundTrip, req, team) })
+		}
+	}
+	if err == nil && mutatingMethod(req.Method) && resp.StatusCode < 400 {
+		mrt.missing.reset()
+	}
+	return resp, err
+}
+
+// pathTeam is the team of a request for something of a team, from its path
+// /api/v0/teams/{team}/..., and whether the request is for the team itself
+func pathTeam(req *http.Request) (team string, isTeam bool) {
+	path := strings.TrimPrefix(req.URL.EscapedPath(), "/")
+	i := strings.Index(path, "api/v0/teams/")
+	if i == -1 {
+		return "", false
+	}
+	parts := strings.SplitN(path[i+len("api/v0/teams/"):], "/", 2)
+	team, err := url.PathUnescape(parts[0])
+	if err != nil {
+		team = parts[0]
+	}
+	return team, len(parts) == 1 || strings.Trim(parts[1], "/") == ""
+}
+
+// teamNotFoundResponse is the 404 given for requests for something of a team
+// that is missing, in the same form as oncall's own
+func teamNotFoundResponse(req *http.Request, team string) *http.Response {
+	body := fmt.Sprintf(`{"title": "Not Found", "description": "Team %s not found"}`, team)
+	return &http.Response{
+		Status:        "404 Not Found",
+		StatusCode:    http.StatusNotFound,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// teamExists looks the team up to tell a missing team apart from something
+// missing from a team that is still there
+func teamExists(send func(*http.Request) (*http.Response, error), req *http.Request, team string) (bool, error) {
+	escaped := req.URL.EscapedPath()
+	teamPath := escaped[:strings.Index(escaped, "api/v0/teams/")+len("api/v0/teams/")] + url.PathEscape(team)
+	teamURL := *req.URL
+	teamURL.RawQuery = ""
+	teamURL.RawPath = teamPath
+	teamURL.Path, _ = url.PathUnescape(teamPath)
+	check, err := http.NewRequestWithContext(req.Context(), http.MethodGet, teamURL.String(), nil)
+	if err != nil {
+		return false, err
+	}
+	resp, err := send(check)
+	if err != nil {
+		return false, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 400:
+		return false, fmt.Errorf("Getting team %s failed with %s", team, resp.Status)
+	}
+	return true, nil
+}
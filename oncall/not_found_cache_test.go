@@ -0,0 +1,133 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_missingTeams(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	deleted := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[r.Method+" "+r.URL.Path]++
+		switch {
+		case r.Method == http.MethodPost:
+			deleted = false
+			w.WriteHeader(201)
+		case r.URL.Path == "/api/v0/teams/platform":
+			w.Write([]byte(`{"name": "platform"}`))
+		case r.URL.Path == "/api/v0/teams/gone" && !deleted:
+			w.Write([]byte(`{"name": "gone"}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c, missingTeams: newMissingTeams()}
+
+	getSchedules := func(team string, rosters int) {
+		var wg sync.WaitGroup
+		for i := 0; i < rosters; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_, err := meta.clientFor(context.Background()).Get(fmt.Sprintf("/api/v0/teams/%s/rosters/r%d/schedules", team, i), nil)
+				if !isNotFound(err) {
+					t.Errorf("Get() error = %v, want a 404", err)
+				}
+			}(i)
+		}
+		wg.Wait()
+	}
+	total := func(team string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		n := 0
+		for call, count := range calls {
+			if call == "GET /api/v0/teams/"+team || strings.HasPrefix(call, "GET /api/v0/teams/"+team+"/") {
+				n += count
+			}
+		}
+		return n
+	}
+
+	// The first schedule looked up concurrently can't know the team is gone
+	getSchedules("gone", 1)
+	getSchedules("gone", 10)
+	if got := total("gone"); got != 2 {
+		t.Errorf("Made %d calls for the schedules of a deleted team, want 2 (one schedule and the team)", got)
+	}
+
+	// Missing rosters of a team that exists are each looked up
+	getSchedules("platform", 3)
+	if got := total("platform"); got != 4 {
+		t.Errorf("Made %d calls for missing rosters of a team, want 4 (three rosters and the team)", got)
+	}
+
+	// Creating the team again means it has to be asked about again
+	if _, err := meta.clientFor(context.Background()).Post("/api/v0/teams", map[string]string{"name": "gone"}, nil); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if _, err := meta.clientFor(context.Background()).Get("/api/v0/teams/gone", nil); err != nil {
+		t.Errorf("Get() error = %v after the team was created again", err)
+	}
+}
+
+func Test_missingTeamsRefresh(t *testing.T) {
+	var mu sync.Mutex
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		// The team was deleted outside of terraform, rosters and all
+		w.WriteHeader(404)
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c, missingTeams: newMissingTeams()}
+
+	team := resourceTeam().TestResourceData()
+	team.SetId("gone")
+	if diags := resourceTeamRead(context.Background(), team, meta); diags.HasError() {
+		t.Fatalf("resourceTeamRead() error = %v", diags)
+	}
+	if team.Id() != "" {
+		t.Errorf("resourceTeamRead() kept deleted team %s in state", team.Id())
+	}
+
+	for i, oncallID := range []int{0, 42} {
+		roster := resourceRoster().TestResourceData()
+		roster.SetId(fmt.Sprintf("gone/r%d", i))
+		roster.Set(rosterFieldOncallID, oncallID)
+		if diags := resourceRosterRead(context.Background(), roster, meta); diags.HasError() {
+			t.Fatalf("resourceRosterRead() error = %v", diags)
+		}
+		if roster.Id() != "" {
+			t.Errorf("resourceRosterRead() kept roster %s of a deleted team in state", roster.Id())
+		}
+	}
+
+	if want := []string{"GET /api/v0/teams/gone"}; strings.Join(requests, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Refresh made requests %v, want %v", requests, want)
+	}
+}
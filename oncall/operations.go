@@ -0,0 +1,17 @@
+package oncall
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// wrapResource wraps the CRUD functions of a resource or data source in what
+// the provider does around every operation, outermost first: a telemetry span
+// with the api calls under it, the operation for the audit log, the slack
+// notification of changes, act_as_team_admin, and hints on errors
+func wrapResource(resourceType string, r *schema.Resource) *schema.Resource {
+	r.CreateContext = instrumentOperation(resourceType, "create", auditResourceOperation(resourceType, "create", notifySlack(resourceType, "create", r.Schema, elevateTeamAdmin(explainErrors(r.CreateContext)))))
+	r.ReadContext = instrumentOperation(resourceType, "read", auditResourceOperation(resourceType, "read", explainErrors(r.ReadContext)))
+	r.UpdateContext = instrumentOperation(resourceType, "update", auditResourceOperation(resourceType, "update", notifySlack(resourceType, "update", r.Schema, elevateTeamAdmin(explainErrors(r.UpdateContext)))))
+	r.DeleteContext = instrumentOperation(resourceType, "delete", auditResourceOperation(resourceType, "delete", notifySlack(resourceType, "delete", r.Schema, elevateTeamAdmin(explainErrors(r.DeleteContext)))))
+	return r
+}
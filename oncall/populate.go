@@ -0,0 +1,304 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	populateAttempts   = 3
+	populateRetryDelay = 5 * time.Second
+
+	// How many upcoming assignments a dry run populate lists
+	dryRunPreviewAssignments = 10
+)
+
+// populateLocks stops schedules on the same roster populating at the same time,
+// which oncall doesn't handle well and answers with errors or a mangled calendar
+var populateLocks = newKeyedMutex()
+
+// keyedMutex hands out a mutex per key
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newKeyedMutex() *keyedMutex {
+	return &keyedMutex{locks: map[string]*sync.Mutex{}}
+}
+
+// lock locks the mutex for key, returning the function to unlock it. The
+// zero keyedMutex is ready to use.
+func (k *keyedMutex) lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[string]*sync.Mutex{}
+	}
+	l, ok := k.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		k.locks[key] = l
+	}
+	k.mu.Unlock()
+
+	l.Lock()
+	return l.Unlock
+}
+
+// populateRetryWait is how long to wait before retrying a populate, jittered so
+// populates that failed together don't retry in lockstep
+func populateRetryWait() time.Duration {
+	return populateRetryDelay/2 + time.Duration(rand.Int63n(int64(populateRetryDelay)))
+}
+
+// populateRosterSchedule runs the scheduler for a roster schedule, being careful
+// not to double book the calendar. A populate that errors out (e.g. a timeout)
+// may still have gone through on the server, so before re-issuing it we check
+// whether the calendar already holds freshly created events for the schedule.
+// Members in exclude are kept out of rotation while it populates. Oncall
+// populates from the start of the week of from.
+func populateRosterSchedule(ctx context.Context, c *oncall.Client, team, roster, role string, exclude []string, from time.Time) (err error) {
+	traceLog("Waiting for other populates of roster %s/%s to finish", team, roster)
+	unlock := populateLocks.lock(getRosterID(team, roster))
+	defer unlock()
+
+	restore, err := excludeFromRotation(c, team, roster, exclude)
+	if err != nil {
+		return errors.Wrap(err, "Excluding members from populate")
+	}
+	defer func() {
+		if restoreErr := restore(); restoreErr != nil && err == nil {
+			err = errors.Wrap(restoreErr, "Putting excluded members back in rotation after populate")
+		}
+	}()
+
+	schedule, found, err := getRosterSchedule(c, team, roster, role)
+	if err != nil {
+		return errors.Wrapf(err, "Getting roster schedule %s/%s/%s for populate", team, roster, role)
+	}
+	if !found {
+		return fmt.Errorf("Did not find roster schedule %s/%s/%s to populate", team, roster, role)
+	}
+
+	// Every event created by this populate will have a higher id than the ones that exist now
+	existingEvents, err := getScheduleEvents(c, team, role, schedule.ID, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "Checking calendar before populate")
+	}
+	lastExistingID := maxEventID(existingEvents)
+
+	for attempt := 1; ; attempt++ {
+		traceLog("Populating roster schedule %s/%s/%s, attempt %d", team, roster, role, attempt)
+		err = populateSchedule(c, schedule.ID, from)
+		if err == nil {
+			break
+		}
+		warnLog("Populating roster schedule %s/%s/%s failed: %s", team, roster, role, err)
+
+		events, checkErr := getScheduleEvents(c, team, role, schedule.ID, time.Now())
+		if checkErr == nil && maxEventID(events) > lastExistingID {
+			infoLog("Populate of %s/%s/%s reported an error but the calendar was populated, not re-issuing it", team, roster, role)
+			err = nil
+			break
+		}
+
+		if attempt >= populateAttempts {
+			return errors.Wrapf(err, "Populating roster schedule after %d attempts", attempt)
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "Populating roster schedule, gave up after %d attempts", attempt)
+		case <-time.After(populateRetryWait()):
+		}
+		recordRetry(ctx)
+	}
+
+	events, err := getScheduleEvents(c, team, role, schedule.ID, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "Checking calendar after populate")
+	}
+
+	trimmed := map[int]bool{}
+	for _, dup := range duplicateScheduleEvents(events) {
+		infoLog("Trimming duplicate event %d (%s, %s) from %s/%s/%s", dup.ID, dup.User, time.Unix(dup.Start, 0).UTC(), team, roster, role)
+		err = deleteEvent(c, dup.ID)
+		if err != nil {
+			return errors.Wrap(err, "Trimming duplicate populated events")
+		}
+		trimmed[dup.ID] = true
+	}
+
+	populated := make([]scheduleEvent, 0, len(events))
+	for _, e := range events {
+		if !trimmed[e.ID] {
+			populated = append(populated, e)
+		}
+	}
+	logPopulatedEvents(team, roster, role, populated)
+	return nil
+}
+
+// populateSchedule runs the scheduler for a schedule by its numeric ID
+func populateSchedule(c *oncall.Client, scheduleID int, from time.Time) error {
+	_, err := c.Post(fmt.Sprintf("/api/v0/schedules/%d/populate", scheduleID), map[string]int64{"start": from.Unix()}, nil)
+	return errors.Wrapf(err, "Populating schedule %d", scheduleID)
+}
+
+// duplicateScheduleEvents returns every event that starts at the same time as a newer event
+// of the same schedule, which only happens when a populate was applied more than once
+func duplicateScheduleEvents(events []scheduleEvent) []scheduleEvent {
+	newestByStart := map[int64]scheduleEvent{}
+	for _, e := range events {
+		if newest, ok := newestByStart[e.Start]; !ok || e.ID > newest.ID {
+			newestByStart[e.Start] = e
+		}
+	}
+
+	dups := []scheduleEvent{}
+	for _, e := range events {
+		if newestByStart[e.Start].ID != e.ID {
+			dups = append(dups, e)
+		}
+	}
+	sort.Slice(dups, func(i, j int) bool { return dups[i].ID < dups[j].ID })
+	return dups
+}
+
+func maxEventID(events []scheduleEvent) int {
+	max := 0
+	for _, e := range events {
+		if e.ID > max {
+			max = e.ID
+		}
+	}
+	return max
+}
+
+func logPopulatedEvents(team, roster, role string, events []scheduleEvent) {
+	if len(events) == 0 {
+		infoLog("Populated %s/%s/%s, no upcoming events are scheduled", team, roster, role)
+		return
+	}
+
+	var until int64
+	shiftsByUser := map[string]int{}
+	for _, e := range events {
+		shiftsByUser[e.User]++
+		if e.End > until {
+			until = e.End
+		}
+	}
+	infoLog("Populated %s/%s/%s through %s with %d events: %v", team, roster, role, time.Unix(until, 0).UTC().Format(time.RFC3339), len(events), shiftsByUser)
+}
+
+// populateOrPreview populates the roster schedule, or with dry_run_populate set
+// previews what populating it would schedule and reports that as a warning
+func populateOrPreview(ctx context.Context, c *oncall.Client, d *schema.ResourceData, team, roster, role string, from time.Time) diag.Diagnostics {
+	if !d.Get(scheduleFieldDryRunPopulate).(bool) {
+		err := populateRosterSchedule(ctx, c, team, roster, role, scheduleExclusions(d, team, roster), from)
+		if err != nil {
+			return diagFromErrf(err, "Populating roster schedule %s/%s/%s", team, roster, role)
+		}
+
+		// Holidays only apply to the schedule's own roster, not its fallback
+		calendar := d.Get(scheduleFieldRespectHolidayCalendar).(string)
+		if calendar == "" || getRosterID(team, roster) != d.Get(scheduleFieldRosterID).(string) {
+			return nil
+		}
+		err = shiftHandoffsOffHolidays(c, team, role, d.Get(scheduleFieldScheduleID).(int), calendar)
+		return diagFromErrf(err, "Moving handoffs of %s/%s/%s off holidays", team, roster, role)
+	}
+
+	events, err := previewRosterSchedule(c, team, roster, role, scheduleExclusions(d, team, roster), from)
+	if err != nil {
+		return diagFromErrf(err, "Previewing roster schedule %s/%s/%s", team, roster, role)
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Roster schedule %s/%s/%s was not populated as %s is set", team, roster, role, scheduleFieldDryRunPopulate),
+			Detail:   fmt.Sprintf("Populating it would schedule:\n%s\nSet %s = false and apply again to populate it.", formatAssignments(events, dryRunPreviewAssignments), scheduleFieldDryRunPopulate),
+		},
+	}
+}
+
+// previewRosterSchedule runs the scheduler for the roster schedule from the
+// given time without committing anything, returning the events it would create
+func previewRosterSchedule(c *oncall.Client, team, roster, role string, exclude []string, from time.Time) (events []scheduleEvent, err error) {
+	if len(exclude) > 0 {
+		unlock := populateLocks.lock(getRosterID(team, roster))
+		defer unlock()
+		restore, err := excludeFromRotation(c, team, roster, exclude)
+		if err != nil {
+			return nil, errors.Wrap(err, "Excluding members from preview")
+		}
+		defer func() {
+			if restoreErr := restore(); restoreErr != nil && err == nil {
+				err = errors.Wrap(restoreErr, "Putting excluded members back in rotation after preview")
+			}
+		}()
+	}
+
+	schedule, found, err := getRosterSchedule(c, team, roster, role)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("Did not find roster schedule %s/%s/%s", team, roster, role)
+	}
+
+	until := from.AddDate(0, 0, schedule.AutoPopulateThreshold)
+	query := url.Values{}
+	query.Set("start", fmt.Sprintf("%d", from.Unix()))
+	query.Set("start__lt", fmt.Sprintf("%d", until.Unix()))
+	query.Set("end__ge", fmt.Sprintf("%d", from.Unix()))
+	query.Set("team__eq", team)
+
+	allEvents := []scheduleEvent{}
+	_, err = c.Post(fmt.Sprintf("/api/v0/schedules/%d/preview?%s", schedule.ID, query.Encode()), map[string]int64{"start": from.Unix()}, &allEvents)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Previewing schedule %d", schedule.ID)
+	}
+
+	// The preview includes the team's other events, only keep this schedule's
+	events = make([]scheduleEvent, 0, len(allEvents))
+	for _, e := range allEvents {
+		if (e.ScheduleID == nil || *e.ScheduleID == schedule.ID) && strings.EqualFold(e.Role, role) {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+// formatAssignments lists the first n events in order, one per line
+func formatAssignments(events []scheduleEvent, n int) string {
+	sorted := append([]scheduleEvent{}, events...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	if len(sorted) == 0 {
+		return "  nothing\n"
+	}
+
+	b := strings.Builder{}
+	for i, e := range sorted {
+		if i >= n {
+			b.WriteString(fmt.Sprintf("  ... and %d more\n", len(sorted)-n))
+			break
+		}
+		b.WriteString(fmt.Sprintf("  %s - %s: %s\n",
+			time.Unix(e.Start, 0).UTC().Format("Mon 2006-01-02 15:04 MST"),
+			time.Unix(e.End, 0).UTC().Format("Mon 2006-01-02 15:04 MST"),
+			e.User))
+	}
+	return b.String()
+}
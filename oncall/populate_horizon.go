@@ -0,0 +1,76 @@
+package oncall
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// populateHorizon is how far ahead oncall populates a schedule, which rounds
+// auto_populate_days up to whole weeks
+func populateHorizon(now time.Time, autoPopulateDays int) time.Time {
+	return now.AddDate(0, 0, roundUpToWeek(autoPopulateDays))
+}
+
+// eventsPastHorizon returns the schedule's events that start at or after the horizon
+func eventsPastHorizon(c *oncall.Client, team, role string, scheduleID int, horizon time.Time) ([]scheduleEvent, error) {
+	events, err := getScheduleEvents(c, team, role, scheduleID, horizon)
+	if err != nil {
+		return nil, err
+	}
+
+	past := make([]scheduleEvent, 0, len(events))
+	for _, e := range events {
+		if e.Start >= horizon.Unix() {
+			past = append(past, e)
+		}
+	}
+	return past, nil
+}
+
+// shrinkPopulateHorizon handles auto_populate_days being lowered. Populating
+// only ever adds events, so the ones already scheduled past the new horizon
+// stay on the calendar unless trim is set, in which case they are deleted.
+func shrinkPopulateHorizon(c *oncall.Client, d *schema.ResourceData, team, role string, scheduleID int, trim bool) diag.Diagnostics {
+	if !d.HasChange(scheduleFieldAutoPopulateDays) {
+		return nil
+	}
+	o, n := d.GetChange(scheduleFieldAutoPopulateDays)
+	oldDays, newDays := o.(int), n.(int)
+	if roundUpToWeek(newDays) >= roundUpToWeek(oldDays) {
+		return nil
+	}
+
+	horizon := populateHorizon(time.Now(), newDays)
+	events, err := eventsPastHorizon(c, team, role, scheduleID, horizon)
+	if err != nil {
+		return diagFromErrf(err, "Getting events of %s/%s past the new populate horizon", team, role)
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	if !trim {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Lowering %s to %d leaves %d %s events of team %s on the calendar", scheduleFieldAutoPopulateDays, newDays, len(events), role, team),
+				Detail:   fmt.Sprintf("Oncall doesn't unpopulate the calendar, the events starting after %s stay until they pass. Set %s = true and apply again to delete them.", horizon.UTC().Format(time.RFC3339), scheduleFieldTrimFutureEvents),
+			},
+		}
+	}
+
+	for _, e := range events {
+		traceLog("Going to delete event %d of %s/%s starting past the populate horizon", e.ID, team, role)
+		err = deleteEvent(c, e.ID)
+		if err != nil {
+			return diagFromErrf(errors.Wrapf(err, "Trimming events past %s", horizon.UTC().Format(time.RFC3339)), "Trimming %s events of team %s", role, team)
+		}
+	}
+	infoLog("Deleted %d %s events of team %s past the populate horizon %s", len(events), role, team, horizon.UTC().Format(time.RFC3339))
+	return nil
+}
@@ -0,0 +1,102 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_shrinkPopulateHorizon(t *testing.T) {
+	now := time.Now()
+	day := int64(24 * time.Hour / time.Second)
+	scheduleID := 5
+	otherScheduleID := 6
+	events := []scheduleEvent{
+		{ID: 1, Start: now.Unix() + 2*day, End: now.Unix() + 9*day, ScheduleID: &scheduleID},
+		{ID: 2, Start: now.Unix() + 9*day, End: now.Unix() + 16*day, ScheduleID: &scheduleID},
+		{ID: 3, Start: now.Unix() + 16*day, End: now.Unix() + 23*day, ScheduleID: &scheduleID},
+		{ID: 4, Start: now.Unix() + 16*day, End: now.Unix() + 23*day, ScheduleID: &otherScheduleID},
+	}
+
+	var mu sync.Mutex
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v0/events":
+			json.NewEncoder(w).Encode(events)
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/api/v0/events/"):
+			mu.Lock()
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/api/v0/events/"))
+			mu.Unlock()
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	r := resourceRotation()
+	state := &terraform.InstanceState{ID: "t/r/primary", Attributes: map[string]string{
+		"team": "t", "role": "primary", "roster_name": "r", "auto_populate_days": "21",
+	}}
+
+	tests := []struct {
+		name        string
+		days        int
+		trim        bool
+		wantWarning bool
+		wantDeleted []string
+	}{
+		{name: "Unchanged", days: 21, wantDeleted: []string{}},
+		{name: "Raised", days: 28, wantDeleted: []string{}},
+		{name: "Rounded to the same weeks", days: 15, wantDeleted: []string{}},
+		{name: "Lowered", days: 7, wantWarning: true, wantDeleted: []string{}},
+		{name: "Lowered and trimmed", days: 7, trim: true, wantDeleted: []string{"2", "3"}},
+		{name: "Lowered and trimmed to two weeks", days: 14, trim: true, wantDeleted: []string{"3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deleted = []string{}
+			config := terraform.NewResourceConfigRaw(map[string]interface{}{
+				"team": "t", "role": "primary", "roster_name": "r", "auto_populate_days": tt.days, "trim_future_events": tt.trim,
+			})
+			diff, err := r.Diff(context.Background(), state, config, nil)
+			if err != nil {
+				t.Fatalf("Diff() error = %v", err)
+			}
+			d, err := schema.InternalMap(r.Schema).Data(state, diff)
+			if err != nil {
+				t.Fatalf("Data() error = %v", err)
+			}
+
+			diags := shrinkPopulateHorizon(c, d, "t", "primary", scheduleID, tt.trim)
+			if diags.HasError() {
+				t.Fatalf("shrinkPopulateHorizon() = %v", diags)
+			}
+			gotWarning := len(diags) == 1 && diags[0].Severity == diag.Warning
+			if gotWarning != tt.wantWarning || (!tt.wantWarning && len(diags) != 0) {
+				t.Errorf("shrinkPopulateHorizon() = %v, wantWarning %v", diags, tt.wantWarning)
+			}
+			sort.Strings(deleted)
+			if !reflect.DeepEqual(deleted, tt.wantDeleted) {
+				t.Errorf("shrinkPopulateHorizon() deleted events %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
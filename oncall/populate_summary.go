@@ -0,0 +1,85 @@
+package oncall
+
+import (
+	"context"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+const (
+	scheduleFieldPopulatedThrough = "populated_through"
+	scheduleFieldAssignments      = "assignments"
+)
+
+func populatedThroughSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeString,
+		Computed:    true,
+		Description: "End of the last event the scheduler created when the provider last populated the calendar (RFC 3339), so the apply output shows how far it got. Empty until an update populates it",
+	}
+}
+
+func assignmentsSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:        schema.TypeMap,
+		Computed:    true,
+		Elem:        &schema.Schema{Type: schema.TypeInt},
+		Description: "Number of shifts each user got when the provider last populated the calendar, by username. Only the schedule's own roster is counted, not a fallback roster",
+	}
+}
+
+// populateSummary is how far the events go and how many of them each user has
+func populateSummary(events []scheduleEvent) (through time.Time, assignments map[string]int) {
+	assignments = map[string]int{}
+	var until int64
+	for _, e := range events {
+		assignments[e.User]++
+		if e.End > until {
+			until = e.End
+		}
+	}
+	if until == 0 {
+		return time.Time{}, assignments
+	}
+	return time.Unix(until, 0).UTC(), assignments
+}
+
+// recordPopulateSummary reads back the events of the schedule from when it was
+// populated and sets what the scheduler did, only warning when they can't be
+// read as the calendar was populated anyway
+func recordPopulateSummary(c *oncall.Client, d *schema.ResourceData, team, role string, since time.Time) diag.Diagnostics {
+	events, err := getScheduleEvents(c, team, role, d.Get(scheduleFieldScheduleID).(int), since)
+	if err != nil {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  "Could not read back the populated events of " + d.Id(),
+				Detail:   err.Error(),
+			},
+		}
+	}
+
+	through, assignments := populateSummary(events)
+	d.Set(scheduleFieldPopulatedThrough, "")
+	if !through.IsZero() {
+		d.Set(scheduleFieldPopulatedThrough, through.Format(time.RFC3339))
+	}
+	d.Set(scheduleFieldAssignments, assignments)
+	return nil
+}
+
+// populateSummaryCustomizeDiff plans the populate summary as unknown when the
+// schedule is going to be updated, as updating it populates the calendar
+func populateSummaryCustomizeDiff() schema.CustomizeDiffFunc {
+	willPopulate := func(ctx context.Context, d *schema.ResourceDiff, m interface{}) bool {
+		return d.Id() != "" && len(d.GetChangedKeysPrefix("")) > 0 && !d.Get(scheduleFieldDryRunPopulate).(bool)
+	}
+	return customdiff.All(
+		customdiff.ComputedIf(scheduleFieldPopulatedThrough, willPopulate),
+		customdiff.ComputedIf(scheduleFieldAssignments, willPopulate),
+	)
+}
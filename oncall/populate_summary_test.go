@@ -0,0 +1,46 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_populateSummary(t *testing.T) {
+	week := int64(7 * 24 * 60 * 60)
+	monday := int64(4 * 24 * 60 * 60)
+	tests := []struct {
+		name            string
+		events          []scheduleEvent
+		wantThrough     time.Time
+		wantAssignments map[string]int
+	}{
+		{
+			name:            "Nothing",
+			events:          []scheduleEvent{},
+			wantThrough:     time.Time{},
+			wantAssignments: map[string]int{},
+		},
+		{
+			name: "Shifts by user",
+			events: []scheduleEvent{
+				{Start: monday + 2*week, End: monday + 3*week, User: "alice"},
+				{Start: monday, End: monday + week, User: "alice"},
+				{Start: monday + week, End: monday + 2*week, User: "bob"},
+			},
+			wantThrough:     time.Unix(monday+3*week, 0).UTC(),
+			wantAssignments: map[string]int{"alice": 2, "bob": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotThrough, gotAssignments := populateSummary(tt.events)
+			if !gotThrough.Equal(tt.wantThrough) {
+				t.Errorf("populateSummary() through = %v, want %v", gotThrough, tt.wantThrough)
+			}
+			if !reflect.DeepEqual(gotAssignments, tt.wantAssignments) {
+				t.Errorf("populateSummary() assignments = %v, want %v", gotAssignments, tt.wantAssignments)
+			}
+		})
+	}
+}
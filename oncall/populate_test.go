@@ -0,0 +1,233 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_duplicateScheduleEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		events  []scheduleEvent
+		wantIDs []int
+	}{
+		{
+			name:    "No events",
+			events:  []scheduleEvent{},
+			wantIDs: []int{},
+		},
+		{
+			name: "No duplicates",
+			events: []scheduleEvent{
+				{ID: 1, Start: 100},
+				{ID: 2, Start: 200},
+			},
+			wantIDs: []int{},
+		},
+		{
+			name: "Populated twice keeps the newest",
+			events: []scheduleEvent{
+				{ID: 1, Start: 100},
+				{ID: 2, Start: 200},
+				{ID: 3, Start: 100},
+				{ID: 4, Start: 200},
+			},
+			wantIDs: []int{1, 2},
+		},
+		{
+			name: "Populated three times",
+			events: []scheduleEvent{
+				{ID: 7, Start: 100},
+				{ID: 5, Start: 100},
+				{ID: 6, Start: 100},
+			},
+			wantIDs: []int{5, 6},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotIDs := []int{}
+			for _, e := range duplicateScheduleEvents(tt.events) {
+				gotIDs = append(gotIDs, e.ID)
+			}
+			if !reflect.DeepEqual(gotIDs, tt.wantIDs) {
+				t.Errorf("duplicateScheduleEvents() = %v, want %v", gotIDs, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func Test_formatAssignments(t *testing.T) {
+	week := int64(7 * 24 * 60 * 60)
+	monday := int64(4 * 24 * 60 * 60)
+	tests := []struct {
+		name   string
+		events []scheduleEvent
+		n      int
+		want   string
+	}{
+		{
+			name:   "Nothing",
+			events: []scheduleEvent{},
+			n:      10,
+			want:   "  nothing\n",
+		},
+		{
+			name: "In order",
+			events: []scheduleEvent{
+				{Start: monday + week, End: monday + 2*week, User: "bob"},
+				{Start: monday, End: monday + week, User: "alice"},
+			},
+			n: 10,
+			want: "  Mon 1970-01-05 00:00 UTC - Mon 1970-01-12 00:00 UTC: alice\n" +
+				"  Mon 1970-01-12 00:00 UTC - Mon 1970-01-19 00:00 UTC: bob\n",
+		},
+		{
+			name: "Truncated",
+			events: []scheduleEvent{
+				{Start: monday, End: monday + week, User: "alice"},
+				{Start: monday + week, End: monday + 2*week, User: "bob"},
+				{Start: monday + 2*week, End: monday + 3*week, User: "carol"},
+			},
+			n: 1,
+			want: "  Mon 1970-01-05 00:00 UTC - Mon 1970-01-12 00:00 UTC: alice\n" +
+				"  ... and 2 more\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAssignments(tt.events, tt.n); got != tt.want {
+				t.Errorf("formatAssignments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_keyedMutex(t *testing.T) {
+	k := newKeyedMutex()
+	unlock := k.lock("team/roster")
+
+	// A different key must not wait on the held one
+	k.lock("team/other")()
+
+	locked := make(chan struct{})
+	go func() {
+		k.lock("team/roster")()
+		close(locked)
+	}()
+
+	select {
+	case <-locked:
+		t.Fatal("lock() on a held key did not wait for it to be unlocked")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	unlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("lock() on a key was not released by unlocking it")
+	}
+}
+
+func Test_populateRetryWait(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		wait := populateRetryWait()
+		if wait < populateRetryDelay/2 || wait >= populateRetryDelay*3/2 {
+			t.Fatalf("populateRetryWait() = %s, want between %s and %s", wait, populateRetryDelay/2, populateRetryDelay*3/2)
+		}
+	}
+}
+
+func Test_populateRosterSchedule(t *testing.T) {
+	var mu sync.Mutex
+	populates := []string{}
+	populated := false
+	failPopulate := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v0/teams/platform/rosters/sre/schedules":
+			w.Write([]byte(`[{"id": 7, "role": "primary", "advanced_mode": 0}]`))
+		case r.Method == "GET" && r.URL.Path == "/api/v0/events":
+			if !populated {
+				w.Write([]byte(`[]`))
+				return
+			}
+			end := time.Now().Add(7 * 24 * time.Hour).Unix()
+			fmt.Fprintf(w, `[{"id": 3, "user": "alice", "role": "primary", "start": 100, "end": %d, "schedule_id": 7}]`, end)
+		case r.Method == "POST" && r.URL.Path == "/api/v0/schedules/7/populate":
+			body, _ := ioutil.ReadAll(r.Body)
+			populates = append(populates, string(body))
+			populated = true
+			if failPopulate {
+				w.WriteHeader(500)
+				return
+			}
+			w.Write([]byte(`null`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	from := time.Unix(1735732800, 0)
+	tests := []struct {
+		name          string
+		roster        string
+		failPopulate  bool
+		wantPopulates []string
+		wantErr       bool
+	}{
+		{
+			name:          "Populated",
+			roster:        "sre",
+			wantPopulates: []string{`{"start":1735732800}`},
+		},
+		{
+			// The populate went through even though it errored, so it isn't re-issued
+			name:          "Error after populating",
+			roster:        "sre",
+			failPopulate:  true,
+			wantPopulates: []string{`{"start":1735732800}`},
+		},
+		{
+			name:          "Missing schedule",
+			roster:        "gone",
+			wantPopulates: []string{},
+			wantErr:       true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			populates, populated, failPopulate = []string{}, false, tt.failPopulate
+			mu.Unlock()
+
+			err := populateRosterSchedule(context.Background(), c, "platform", tt.roster, "primary", nil, from)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("populateRosterSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if !reflect.DeepEqual(populates, tt.wantPopulates) {
+				t.Errorf("populateRosterSchedule() populates = %v, want %v", populates, tt.wantPopulates)
+			}
+		})
+	}
+}
@@ -3,16 +3,25 @@ package oncall
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
+	"time"
 
 	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
 )
 
+// authMethodNone talks to oncall anonymously, e.g. for read only mirrors
+const authMethodNone oncall.AuthMethod = "none"
+
 var authMethods = []oncall.AuthMethod{
 	oncall.AuthMethodAPI,
 	oncall.AuthMethodUser,
+	authMethodNone,
 }
 
 const (
@@ -20,16 +29,90 @@ const (
 	providerFieldUsername = "username"
 	providerFieldPassword = "password"
 	providerFieldAuthType = "auth_type"
+
+	providerFieldSkipHealthCheck     = "skip_health_check"
+	providerFieldSelfEscalationCheck = "self_escalation_check"
+	providerFieldOtelEndpoint        = "otel_endpoint"
+	providerFieldValidateReferences  = "validate_references"
+	providerFieldDefaultTeamPrefix   = "default_team_prefix"
+	providerFieldShiftTemplate       = "shift_template"
+	providerFieldShiftTemplatesFile  = "shift_templates_file"
+	providerFieldAddSelfAsTeamAdmin  = "add_self_as_team_admin"
+	providerFieldActAsTeamAdmin      = "act_as_team_admin"
+	providerFieldMaxAutoPopulateDays = "max_auto_populate_days"
+	providerFieldAuditLogFile        = "audit_log_file"
+	providerFieldNotifySlackWebhook  = "notify_slack_webhook"
+	providerFieldWeekStartsOn        = "week_starts_on"
+	providerFieldMaxIdleConnections  = "max_idle_connections"
+	providerFieldIdleTimeout         = "idle_connection_timeout"
+	providerFieldEnableHTTP2         = "enable_http2"
+	providerFieldStrictMode          = "strict_mode"
+	providerFieldConfigFile          = "config_file"
+	providerFieldIrisEndpoint        = "iris_endpoint"
+	providerFieldIrisApp             = "iris_app"
+	providerFieldIrisKey             = "iris_key"
+	providerFieldClientCertificate   = "client_certificate"
+	providerFieldClientKey           = "client_key"
 )
 
+// providerMeta is handed to every resource as its meta argument
+type providerMeta struct {
+	client *oncall.Client
+	// session is the login shared by every resource with user auth, nil otherwise
+	session *authSession
+	// missingTeams are the teams found to be deleted from oncall, whose
+	// resources are then read as gone without asking oncall about each
+	missingTeams *missingTeams
+
+	selfEscalationCheck string
+	plannedSchedules    *scheduleRegistry
+	telemetry           *telemetry
+	auditLog            *auditLog
+	slackNotifier       *slackNotifier
+
+	validateReferences bool
+	plannedReferences  *referenceRegistry
+
+	// teamPrefix namespaces the teams of a tenant of a shared oncall instance,
+	// teams outside of it can't be planned or called
+	teamPrefix string
+
+	// weekStart is the day schedule event starts count seconds from
+	weekStart time.Weekday
+
+	// maxAutoPopulateDays is the most auto_populate_days schedules may have
+	maxAutoPopulateDays int
+
+	// strictMode fails reads that can't normalize what oncall returned into
+	// the resource's attributes, instead of logging a warning
+	strictMode bool
+
+	// iris looks up the plans teams page through, nil without an iris_endpoint
+	iris *irisClient
+
+	// addSelfAsTeamAdmin keeps the provider's user an admin of the teams it
+	// manages, so setting the admins doesn't lock the provider out
+	addSelfAsTeamAdmin bool
+
+	// actAsTeamAdmin makes the provider's user, an instance admin, an admin of
+	// the teams it changes for as long as each change takes
+	actAsTeamAdmin bool
+	// teamAdminGrants are the teams actAsTeamAdmin made the user an admin of
+	teamAdminGrants teamAdminGrants
+
+	// shiftTemplates are the provider's shift templates by name, each as a list
+	// of shift blocks
+	shiftTemplates map[string][]map[string]interface{}
+}
+
 // Provider - returns the oncall provider
 func Provider() *schema.Provider {
 	return &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			providerFieldEndpoint: {
 				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Oncall endpoint to connect to, everything before '/api/v0' in the URL",
+				Optional:    true,
+				Description: "Oncall endpoint to connect to, everything before '/api/v0' in the URL. Required, unless it is set in config_file. Fallback endpoints can follow it separated by commas, requests fail over to them in order when the endpoint can't be connected to. Requests that change something only fail over when they never reached the endpoint, so a change is never made twice. Endpoints can be looked up when the provider starts with srv://_oncall._tcp.example.com (DNS SRV) or consul://oncall-api (consul catalog, using CONSUL_HTTP_ADDR and CONSUL_HTTP_TOKEN), add ?scheme=http if oncall isn't served over https",
 				DefaultFunc: schema.EnvDefaultFunc("ONCALL_ENDPOINT", ""),
 			},
 			providerFieldUsername: {
@@ -48,31 +131,211 @@ func Provider() *schema.Provider {
 			providerFieldAuthType: {
 				Type:        schema.TypeString,
 				Default:     string(oncall.AuthMethodUser),
-				Description: fmt.Sprintf("Auth method for your username/password; one of: %v", authMethods),
+				Description: fmt.Sprintf("Auth method for your username/password; one of: %v. With %s no credentials are sent, which is only useful for read only endpoints", authMethods, authMethodNone),
 				Optional:    true,
 				DefaultFunc: schema.EnvDefaultFunc("ONCALL_AUTH_TYPE", ""),
 			},
+			providerFieldClientCertificate: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Client certificate to present to oncall for mutual TLS, e.g. inside a service mesh that requires one. Either PEM or the path of a PEM file, set along with client_key",
+				DefaultFunc: schema.EnvDefaultFunc("ONCALL_CLIENT_CERTIFICATE", ""),
+			},
+			providerFieldClientKey: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Private key of the client_certificate, either PEM or the path of a PEM file",
+				DefaultFunc: schema.EnvDefaultFunc("ONCALL_CLIENT_KEY", ""),
+			},
+			providerFieldConfigFile: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: fmt.Sprintf("Path of a YAML or JSON file with the settings for connecting to oncall: %s, %s, %s, %s, %s, %s, %s, %s and %s. Arguments set in the provider block or by their environment variables take precedence over the file, which fills in those left unset or at their defaults", providerFieldEndpoint, providerFieldUsername, providerFieldPassword, providerFieldAuthType, providerFieldMaxIdleConnections, providerFieldIdleTimeout, providerFieldEnableHTTP2, providerFieldClientCertificate, providerFieldClientKey),
+				DefaultFunc: schema.EnvDefaultFunc("ONCALL_CONFIG_FILE", ""),
+			},
+			providerFieldSkipHealthCheck: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Skip checking that oncall can be reached with the configured credentials when the provider starts",
+			},
+			providerFieldSelfEscalationCheck: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          selfEscalationCheckOff,
+				ValidateDiagFunc: validateStringSliceContains(selfEscalationChecks),
+				Description:      fmt.Sprintf("What to do when a roster backs both the primary and secondary schedules with only one member in rotation, so primary would escalate to themselves; one of: %v", selfEscalationChecks),
+			},
+			providerFieldValidateReferences: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Check at plan time that the rosters schedules refer to exist in oncall or in the configuration, catching roster IDs that point at the wrong team. Rosters that can't be found are logged as warnings rather than failing the plan, as a roster written out literally may be planned after the schedules referring to it",
+			},
+			providerFieldDefaultTeamPrefix: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Prefix every team name must start with, e.g. payments-- on an oncall instance shared between tenants. Teams without it are refused at plan time, and requests about them are never sent to oncall",
+				DefaultFunc: schema.EnvDefaultFunc("ONCALL_DEFAULT_TEAM_PREFIX", ""),
+			},
+			providerFieldShiftTemplate: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Description: "Named sets of shifts for the oncall_shift_template data source, so schedules across teams can share a company standard pattern",
+				Elem:        shiftTemplateResource(),
+			},
+			providerFieldShiftTemplatesFile: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Path of a YAML or JSON file of more shift templates, mapping each name to a list of shifts with the fields of a shift block",
+			},
+			providerFieldAddSelfAsTeamAdmin: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: fmt.Sprintf("Keep the provider's user an admin of the teams it creates and updates, without listing it in their admins, so it doesn't lose permission to change them. Only with %s %s", providerFieldAuthType, oncall.AuthMethodUser),
+			},
+			providerFieldActAsTeamAdmin: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: fmt.Sprintf("When the provider's user is an instance admin, make it an admin of each team for as long as a resource of the team is being changed, and remove it again afterwards, so team admin lists stay as configured. Only with %s %s", providerFieldAuthType, oncall.AuthMethodUser),
+			},
+			providerFieldAuditLogFile: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "File to append a JSON line to for every call that creates, updates or deletes something in oncall, with when it was made, by which resource operation, its method, path, status, and the SHA-256 of its payload. Kept independently of the state file, so what terraform changed can be reconstructed",
+				DefaultFunc: schema.EnvDefaultFunc("ONCALL_AUDIT_LOG_FILE", ""),
+			},
+			providerFieldNotifySlackWebhook: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Sensitive:        true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.Any(validation.StringIsEmpty, validation.IsURLWithHTTPorHTTPS)),
+				Description:      fmt.Sprintf("Slack incoming webhook to post a summary to of the resources terraform creates, updates or deletes in oncall, a message per team naming each resource and the attributes changed, e.g. a schedule's shifts before and after. A team's summary is posted in the background once it has had no changes for a couple of seconds, an apply never waits on Slack. Messages name the Terraform Cloud run when %s is set. Nothing is posted if empty, and a failed post only logs a warning", slackNotifyRunIDEnv),
+				DefaultFunc:      schema.EnvDefaultFunc("ONCALL_NOTIFY_SLACK_WEBHOOK", ""),
+			},
+			providerFieldMaxIdleConnections: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          10,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntAtLeast(1)),
+				Description:      "How many idle connections to oncall to keep open for reuse. Raise it along with terraform's -parallelism if applies open many short lived connections",
+			},
+			providerFieldIdleTimeout: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "90s",
+				ValidateDiagFunc: validateDuration,
+				Description:      "How long an idle connection to oncall is kept open for reuse before it is closed, e.g. 90s or 5m",
+			},
+			providerFieldEnableHTTP2: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Use HTTP/2 when oncall supports it, so requests share one connection instead of each needing their own. Only applies to https endpoints",
+			},
+			providerFieldWeekStartsOn: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "Sunday",
+				ValidateDiagFunc: validateStringSliceContains(daysOfWeek),
+				Description:      fmt.Sprintf("Day the oncall instance starts its weeks on, which schedule shifts are counted in seconds from. Set it to Monday if oncall has been changed to use ISO weeks, so a shift on Monday 09:00 starts at Monday 09:00 in oncall's calendar. One of: %v", daysOfWeek),
+			},
+			providerFieldMaxAutoPopulateDays: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          defaultMaxAutoPopulateDays,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(minAutoPopulateDays, autoPopulateDaysLimit)),
+				Description:      fmt.Sprintf("The most auto_populate_days a schedule or rotation may have. Oncall creates an event for every shift up to that many days ahead each time it populates, which gets slow far out. At most %d", autoPopulateDaysLimit),
+			},
+			providerFieldStrictMode: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fail reading resources when what oncall returns can't be represented in their attributes, e.g. a schedule with an unknown role or several events in a rotation, instead of logging a warning and leaving the attributes empty",
+			},
+			providerFieldIrisEndpoint: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Iris API to check at plan time that the iris plans of teams exist in, e.g. https://iris-api.example.com, as pages to a missing plan silently fail. Plans aren't checked if empty",
+				DefaultFunc: schema.EnvDefaultFunc("IRIS_ENDPOINT", ""),
+			},
+			providerFieldIrisApp: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Iris application to sign requests to iris_endpoint as, along with iris_key",
+				DefaultFunc: schema.EnvDefaultFunc("IRIS_APP", ""),
+			},
+			providerFieldIrisKey: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Key of the iris_app",
+				DefaultFunc: schema.EnvDefaultFunc("IRIS_KEY", ""),
+			},
+			providerFieldOtelEndpoint: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "OTLP/HTTP collector to send traces and metrics about calls to oncall to, e.g. http://localhost:4318. They are sent in the background shortly after each operation, and what is left when terraform stops the provider, a slow collector never holds up an apply. Nothing is sent if empty",
+				DefaultFunc: schema.EnvDefaultFunc("OTEL_EXPORTER_OTLP_ENDPOINT", ""),
+			},
 		},
+		// There is no oncall_global_admin as oncall's API can't grant or revoke
+		// global ("god") admin, it is only set on users in oncall's database
 		ResourcesMap: map[string]*schema.Resource{
-			"oncall_team":              resourceTeam(),
-			"oncall_roster":            resourceRoster(),
-			"oncall_basic_schedule":    resourceBasicSchedule(),
-			"oncall_advanced_schedule": resourceAdvancedSchedule(),
+			"oncall_team":                   wrapResource("oncall_team", resourceTeam()),
+			"oncall_team_member":            wrapResource("oncall_team_member", resourceTeamMember()),
+			"oncall_roster":                 wrapResource("oncall_roster", resourceRoster()),
+			"oncall_basic_schedule":         wrapResource("oncall_basic_schedule", resourceBasicSchedule()),
+			"oncall_advanced_schedule":      wrapResource("oncall_advanced_schedule", resourceAdvancedSchedule()),
+			"oncall_raw_schedule":           wrapResource("oncall_raw_schedule", resourceRawSchedule()),
+			"oncall_rotation":               wrapResource("oncall_rotation", resourceRotation()),
+			"oncall_linked_slack_usergroup": wrapResource("oncall_linked_slack_usergroup", resourceLinkedSlackUsergroup()),
+			"oncall_holiday_calendar":       wrapResource("oncall_holiday_calendar", resourceHolidayCalendar()),
+			"oncall_schedule_swap":          wrapResource("oncall_schedule_swap", resourceScheduleSwap()),
+			"oncall_team_iris_plan_binding": wrapResource("oncall_team_iris_plan_binding", resourceTeamIrisPlanBinding()),
+			"oncall_ical_subscription":      wrapResource("oncall_ical_subscription", resourceICalSubscription()),
 		},
 		DataSourcesMap: map[string]*schema.Resource{
-			//	"hashicups_coffees":     dataSourceCoffees(),
-			//	"hashicups_ingredients": dataSourceIngredients(),
-			//	"hashicups_order":       dataSourceOrder(),
+			"oncall_roles":                   wrapResource("oncall_roles", dataSourceRoles()),
+			"oncall_linked_slack_usergroups": wrapResource("oncall_linked_slack_usergroups", dataSourceLinkedSlackUsergroups()),
+			"oncall_instance_config":         wrapResource("oncall_instance_config", dataSourceInstanceConfig()),
+			"oncall_oncall_history":          wrapResource("oncall_oncall_history", dataSourceOncallHistory()),
+			"oncall_oncall_matrix":           wrapResource("oncall_oncall_matrix", dataSourceOncallMatrix()),
+			"oncall_user_teams":              wrapResource("oncall_user_teams", dataSourceUserTeams()),
+			"oncall_rosters":                 wrapResource("oncall_rosters", dataSourceRosters()),
+			"oncall_roster_schedules":        wrapResource("oncall_roster_schedules", dataSourceRosterSchedules()),
+			"oncall_probe":                   wrapResource("oncall_probe", dataSourceProbe()),
+			"oncall_shift_template":          wrapResource("oncall_shift_template", dataSourceShiftTemplate()),
+			"oncall_shift_seconds":           wrapResource("oncall_shift_seconds", dataSourceShiftSeconds()),
+			"oncall_duration_seconds":        wrapResource("oncall_duration_seconds", dataSourceDurationSeconds()),
 		},
 		ConfigureContextFunc: providerConfigure,
 	}
 }
 
 func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-	endpoint := d.Get(providerFieldEndpoint).(string)
-	username := d.Get(providerFieldUsername).(string)
-	password := d.Get(providerFieldPassword).(string)
-	requestedAuthMethod := d.Get(providerFieldAuthType).(string)
+	settings, err := connectionSettingsFor(d)
+	if err != nil {
+		return nil, diagFromErrf(err, "Loading %s", providerFieldConfigFile)
+	}
+	if settings.Endpoint == "" {
+		return nil, diag.Errorf("%s must be set in the provider block, ONCALL_ENDPOINT or %s", providerFieldEndpoint, providerFieldConfigFile)
+	}
+
+	endpoints, err := newEndpointResolver().resolve(ctx, parseEndpoints(settings.Endpoint))
+	if err != nil {
+		return nil, diagFromErrf(err, "Discovering oncall endpoint")
+	}
+	if len(endpoints) == 0 {
+		return nil, diag.Errorf("%s must contain at least one endpoint", providerFieldEndpoint)
+	}
+	endpoint := endpoints[0]
+	username := settings.Username
+	password := settings.Password
+	requestedAuthMethod := settings.AuthType
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
@@ -88,9 +351,50 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		return nil, diag.FromErr(fmt.Errorf("%s of %s is not valid, must be one of: %v", providerFieldAuthType, requestedAuthMethod, authMethods))
 	}
 
+	if authMethod == authMethodNone {
+		if username != "" || password != "" {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("%s and %s are ignored when %s is %s", providerFieldUsername, providerFieldPassword, providerFieldAuthType, authMethodNone),
+			})
+		}
+		// API auth only signs requests when there is a password to sign them with
+		authMethod = oncall.AuthMethodAPI
+		username, password = "", ""
+	}
+
+	diags = append(diags, checkCredentials(authMethod, username, password)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+
 	traceLog("Going to create oncall client for %s with auth method %s, username %s", endpoint, authMethod, username)
 
-	oncallClient, err := oncall.New(nil, oncall.Config{
+	idleTimeout, err := parseDuration(settings.IdleTimeout)
+	if err != nil {
+		return nil, diagFromErrf(err, "Invalid %s", providerFieldIdleTimeout)
+	}
+	if settings.MaxIdleConnections < 1 {
+		return nil, diag.Errorf("%s must be at least 1, got %d", providerFieldMaxIdleConnections, settings.MaxIdleConnections)
+	}
+	clientCert, err := loadClientCertificate(settings.ClientCertificate, settings.ClientKey)
+	if err != nil {
+		return nil, diagFromErrf(err, "Invalid %s or %s", providerFieldClientCertificate, providerFieldClientKey)
+	}
+	transport := newTransport(transportSettings{
+		maxIdleConns: settings.MaxIdleConnections,
+		idleTimeout:  idleTimeout,
+		http2:        *settings.EnableHTTP2,
+		clientCert:   clientCert,
+	})
+	// The oncall client installs its auth on the http client it is given, so
+	// hand it its own rather than letting it modify http.DefaultClient
+	httpClient := &http.Client{Transport: transport}
+	if len(endpoints) > 1 {
+		traceLog("Going to fail over to %v when %s can't be connected to", endpoints[1:], endpoint)
+		httpClient.Transport = newFailoverRoundTripper(endpoints, transport)
+	}
+	oncallClient, err := oncall.New(httpClient, oncall.Config{
 		Endpoint:   endpoint,
 		Username:   username,
 		Password:   password,
@@ -100,5 +404,180 @@ func providerConfigure(ctx context.Context, d *schema.ResourceData) (interface{}
 		return nil, diag.FromErr(errors.Wrap(err, "Initializing oncall client"))
 	}
 
-	return oncallClient, diags
+	shiftTemplates, err := loadShiftTemplates(d)
+	if err != nil {
+		return nil, diagFromErrf(err, "Loading shift templates")
+	}
+
+	weekStart, err := parseWeekStart(d.Get(providerFieldWeekStartsOn).(string))
+	if err != nil {
+		return nil, diagFromErrf(err, "Invalid %s", providerFieldWeekStartsOn)
+	}
+
+	auditLog, err := openAuditLog(d.Get(providerFieldAuditLogFile).(string))
+	if err != nil {
+		return nil, diagFromErrf(err, "Setting up %s", providerFieldAuditLogFile)
+	}
+
+	irisApp, irisKey := d.Get(providerFieldIrisApp).(string), d.Get(providerFieldIrisKey).(string)
+	if (irisApp == "") != (irisKey == "") {
+		return nil, diag.Errorf("%s and %s must be set together", providerFieldIrisApp, providerFieldIrisKey)
+	}
+
+	meta := &providerMeta{
+		client:              oncallClient,
+		session:             newAuthSession(oncallClient),
+		missingTeams:        newMissingTeams(),
+		selfEscalationCheck: d.Get(providerFieldSelfEscalationCheck).(string),
+		plannedSchedules:    newScheduleRegistry(),
+		telemetry:           newTelemetry(d.Get(providerFieldOtelEndpoint).(string)),
+		auditLog:            auditLog,
+		slackNotifier:       newSlackNotifier(d.Get(providerFieldNotifySlackWebhook).(string)),
+		validateReferences:  d.Get(providerFieldValidateReferences).(bool),
+		plannedReferences:   newReferenceRegistry(),
+		teamPrefix:          d.Get(providerFieldDefaultTeamPrefix).(string),
+		shiftTemplates:      shiftTemplates,
+		addSelfAsTeamAdmin:  d.Get(providerFieldAddSelfAsTeamAdmin).(bool),
+		actAsTeamAdmin:      d.Get(providerFieldActAsTeamAdmin).(bool),
+		weekStart:           weekStart,
+		maxAutoPopulateDays: d.Get(providerFieldMaxAutoPopulateDays).(int),
+		strictMode:          d.Get(providerFieldStrictMode).(bool),
+		iris:                newIrisClient(d.Get(providerFieldIrisEndpoint).(string), irisApp, irisKey),
+	}
+
+	if meta.addSelfAsTeamAdmin && authMethod != oncall.AuthMethodUser {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s is ignored unless %s is %s", providerFieldAddSelfAsTeamAdmin, providerFieldAuthType, oncall.AuthMethodUser),
+			Detail:   "With API auth the provider acts as an application, which can't be a team admin.",
+		})
+		meta.addSelfAsTeamAdmin = false
+	}
+	if meta.actAsTeamAdmin && authMethod != oncall.AuthMethodUser {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("%s is ignored unless %s is %s", providerFieldActAsTeamAdmin, providerFieldAuthType, oncall.AuthMethodUser),
+			Detail:   "With API auth the provider acts as an application, which can't be a team admin.",
+		})
+		meta.actAsTeamAdmin = false
+	}
+
+	// Log in once up front, rather than in every resource read in parallel
+	if meta.session != nil {
+		err = meta.session.login()
+		if err != nil {
+			return nil, diag.Diagnostics{
+				diag.Diagnostic{
+					Severity: diag.Error,
+					Summary:  fmt.Sprintf("Logging into oncall at %s failed", endpoint),
+					Detail:   fmt.Sprintf("%s\n\n%s", healthCheckHint(oncallClient, err), err),
+				},
+			}
+		}
+	}
+
+	if !d.Get(providerFieldSkipHealthCheck).(bool) {
+		diags = append(diags, checkOncallHealth(meta.clientFor(ctx))...)
+		if diags.HasError() {
+			return nil, diags
+		}
+	}
+
+	if meta.actAsTeamAdmin {
+		admin, err := isInstanceAdmin(meta.clientFor(ctx), username)
+		if err != nil {
+			return nil, diagFromErrf(err, "Checking whether %s can act as a team admin for %s", username, providerFieldActAsTeamAdmin)
+		}
+		if !admin {
+			return nil, diag.Diagnostics{
+				diag.Diagnostic{
+					Severity:      diag.Error,
+					Summary:       fmt.Sprintf("%s needs the provider's user to be an instance admin", providerFieldActAsTeamAdmin),
+					Detail:        fmt.Sprintf("%s is not a global admin of oncall, so it can't make itself a team admin. Global admin can only be set in oncall's database.", username),
+					AttributePath: cty.Path{cty.GetAttrStep{Name: providerFieldActAsTeamAdmin}},
+				},
+			}
+		}
+	}
+
+	meta.telemetry.exportOnStop(ctx)
+	return meta, diags
+}
+
+// checkCredentials makes sure the username and password are set together. This
+// can't be done with RequiredWith in the schema as it only sees what is in the
+// configuration, not what came from the environment.
+func checkCredentials(authMethod oncall.AuthMethod, username, password string) diag.Diagnostics {
+	if (username == "") == (password == "") {
+		return nil
+	}
+
+	set, missing := providerFieldUsername, providerFieldPassword
+	if username == "" {
+		set, missing = providerFieldPassword, providerFieldUsername
+	}
+	detail := fmt.Sprintf("With %s %s oncall needs both a username and password.", providerFieldAuthType, authMethod)
+	if authMethod == oncall.AuthMethodAPI {
+		detail = fmt.Sprintf("With %s %s the %s is the application name and the %s is its API key, oncall needs both.", providerFieldAuthType, authMethod, providerFieldUsername, providerFieldPassword)
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("%s is set but %s is not", set, missing),
+			Detail:        fmt.Sprintf("%s Set %s in the provider configuration or with %s, or set %s = %q to connect without credentials.", detail, missing, "ONCALL_"+strings.ToUpper(missing), providerFieldAuthType, authMethodNone),
+			AttributePath: cty.Path{cty.GetAttrStep{Name: missing}},
+		},
+	}
+}
+
+// checkOncallHealth does a cheap request against oncall so a bad endpoint or
+// bad credentials are reported up front rather than midway through an apply
+func checkOncallHealth(c *oncall.Client) diag.Diagnostics {
+	err := probeOncall(c)
+	if err == nil {
+		return nil
+	}
+
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Oncall at %s failed its health check", c.Config.Endpoint),
+			Detail:   fmt.Sprintf("%s\n\n%s\n\nSet %s = true to skip this check.", healthCheckHint(c, err), err, providerFieldSkipHealthCheck),
+		},
+	}
+}
+
+// probeOncall is the cheapest request that needs oncall up and the credentials accepted
+func probeOncall(c *oncall.Client) error {
+	traceLog("Going to check health of oncall at %s", c.Config.Endpoint)
+	teams := []string{}
+	_, err := c.Get("/api/v0/teams?limit=1", &teams)
+	return err
+}
+
+// healthCheckHint explains what is likely wrong given the error of probeOncall
+func healthCheckHint(c *oncall.Client, err error) string {
+	hint := "Check that oncall is up and reachable from here."
+	switch msg := err.Error(); {
+	case strings.Contains(msg, "Logging into"), strings.Contains(msg, "Failed to login"),
+		strings.Contains(msg, "(401)"), strings.Contains(msg, "(403)"):
+		hint = fmt.Sprintf("Oncall rejected the credentials, check the %s, %s, and %s settings.", providerFieldUsername, providerFieldPassword, providerFieldAuthType)
+		if c.Config.Password == "" {
+			hint = fmt.Sprintf("Oncall requires authentication, set %s and %s.", providerFieldUsername, providerFieldPassword)
+		}
+	case strings.Contains(msg, "(404)"), strings.Contains(msg, "JSON Unmarshal Error"):
+		hint = fmt.Sprintf("The %s does not look like an oncall API, it should be everything before '/api/v0' in the URL.", providerFieldEndpoint)
+	case strings.Contains(msg, "Failed to do http request"):
+		hint = fmt.Sprintf("Could not connect, check the %s setting and your network.", providerFieldEndpoint)
+	}
+	return hint
+}
+
+// selfTeamAdmin is the provider's own user if it should be kept an admin of
+// the teams it manages, otherwise empty
+func (p *providerMeta) selfTeamAdmin() string {
+	if !p.addSelfAsTeamAdmin || p.client == nil {
+		return ""
+	}
+	return p.client.Config.Username
 }
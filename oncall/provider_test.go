@@ -0,0 +1,243 @@
+package oncall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func TestProvider(t *testing.T) {
+	if err := Provider().InternalValidate(); err != nil {
+		t.Fatalf("err: %s", err)
+	}
+}
+
+func TestProvider_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  map[string]interface{}
+		wantErr bool
+	}{
+		{
+			name:   "Endpoint only",
+			config: map[string]interface{}{providerFieldEndpoint: "https://oncall.example.com"},
+		},
+		{
+			name: "Every option",
+			config: map[string]interface{}{
+				providerFieldEndpoint:            "https://oncall.example.com",
+				providerFieldUsername:            "terraform",
+				providerFieldPassword:            "secret",
+				providerFieldAuthType:            string(oncall.AuthMethodAPI),
+				providerFieldSkipHealthCheck:     true,
+				providerFieldSelfEscalationCheck: selfEscalationCheckWarn,
+				providerFieldValidateReferences:  true,
+				providerFieldDefaultTeamPrefix:   "payments--",
+				providerFieldShiftTemplate: []interface{}{map[string]interface{}{
+					shiftTemplateFieldName: "business-hours",
+					shiftTemplateFieldShift: []interface{}{map[string]interface{}{
+						scheduleFieldStartDayOfWeek:   "Monday",
+						scheduleFieldStartTime:        "09:00",
+						advancedScheduleFieldDuration: "8h",
+					}},
+				}},
+				providerFieldShiftTemplatesFile:  "templates.yaml",
+				providerFieldAddSelfAsTeamAdmin:  true,
+				providerFieldActAsTeamAdmin:      true,
+				providerFieldAuditLogFile:        "oncall-audit.jsonl",
+				providerFieldNotifySlackWebhook:  "https://hooks.slack.com/services/T000/B000/XXXX",
+				providerFieldWeekStartsOn:        "Monday",
+				providerFieldMaxAutoPopulateDays: 90,
+				providerFieldMaxIdleConnections:  50,
+				providerFieldIdleTimeout:         "5m",
+				providerFieldEnableHTTP2:         false,
+				providerFieldOtelEndpoint:        "http://localhost:4318",
+				providerFieldConfigFile:          "oncall.yaml",
+			},
+		},
+		{
+			name: "Unknown self escalation check",
+			config: map[string]interface{}{
+				providerFieldEndpoint:            "https://oncall.example.com",
+				providerFieldSelfEscalationCheck: "sometimes",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Bad idle connection timeout",
+			config: map[string]interface{}{
+				providerFieldEndpoint:    "https://oncall.example.com",
+				providerFieldIdleTimeout: "soon",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Slack webhook not a URL",
+			config: map[string]interface{}{
+				providerFieldEndpoint:           "https://oncall.example.com",
+				providerFieldNotifySlackWebhook: "hooks.slack.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown week start",
+			config: map[string]interface{}{
+				providerFieldEndpoint:     "https://oncall.example.com",
+				providerFieldWeekStartsOn: "Funday",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Shift template without shifts",
+			config: map[string]interface{}{
+				providerFieldEndpoint: "https://oncall.example.com",
+				providerFieldShiftTemplate: []interface{}{map[string]interface{}{
+					shiftTemplateFieldName: "business-hours",
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Unknown option",
+			config: map[string]interface{}{
+				providerFieldEndpoint: "https://oncall.example.com",
+				"endpoints":           "https://oncall.example.com",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Wrong type",
+			config: map[string]interface{}{
+				providerFieldEndpoint:        "https://oncall.example.com",
+				providerFieldSkipHealthCheck: []interface{}{"yes"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := Provider().Validate(terraform.NewResourceConfigRaw(tt.config))
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("Validate() = %v, wantErr %v", diags, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_checkOncallHealth(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		anonymous  bool
+		wantErr    bool
+		wantDetail string
+	}{
+		{
+			name:    "Healthy",
+			status:  200,
+			body:    `["team"]`,
+			wantErr: false,
+		},
+		{
+			name:       "Wrong endpoint",
+			status:     404,
+			body:       "not found",
+			wantErr:    true,
+			wantDetail: "does not look like an oncall API",
+		},
+		{
+			name:       "Bad credentials",
+			status:     403,
+			body:       "forbidden",
+			wantErr:    true,
+			wantDetail: "rejected the credentials",
+		},
+		{
+			name:       "Anonymous access not allowed",
+			status:     401,
+			body:       "unauthorized",
+			anonymous:  true,
+			wantErr:    true,
+			wantDetail: "requires authentication",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			config := oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI, Username: "user", Password: "secret"}
+			if tt.anonymous {
+				config.Username, config.Password = "", ""
+			}
+			c, err := oncall.New(&http.Client{}, config, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+
+			diags := checkOncallHealth(c)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("checkOncallHealth() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(diags[0].Detail, tt.wantDetail) {
+				t.Errorf("checkOncallHealth() detail = %q, want it to contain %q", diags[0].Detail, tt.wantDetail)
+			}
+		})
+	}
+}
+
+func Test_checkCredentials(t *testing.T) {
+	tests := []struct {
+		name        string
+		authMethod  oncall.AuthMethod
+		username    string
+		password    string
+		wantErr     bool
+		wantMissing string
+	}{
+		{
+			name:       "Both set",
+			authMethod: oncall.AuthMethodUser,
+			username:   "user",
+			password:   "secret",
+		},
+		{
+			name:       "Neither set",
+			authMethod: oncall.AuthMethodAPI,
+		},
+		{
+			name:        "Missing password",
+			authMethod:  oncall.AuthMethodUser,
+			username:    "user",
+			wantErr:     true,
+			wantMissing: providerFieldPassword,
+		},
+		{
+			name:        "Missing application name",
+			authMethod:  oncall.AuthMethodAPI,
+			password:    "key",
+			wantErr:     true,
+			wantMissing: providerFieldUsername,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := checkCredentials(tt.authMethod, tt.username, tt.password)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("checkCredentials() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if tt.wantErr && !diags[0].AttributePath.Equals(cty.GetAttrPath(tt.wantMissing)) {
+				t.Errorf("checkCredentials() path = %v, want %s", diags[0].AttributePath, tt.wantMissing)
+			}
+		})
+	}
+}
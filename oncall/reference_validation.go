@@ -0,0 +1,144 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// referenceRegistry remembers the teams and rosters planned by this provider,
+// so references to ones which don't exist yet but are in the configuration
+// aren't flagged
+type referenceRegistry struct {
+	mu      sync.Mutex
+	teams   map[string]bool
+	rosters map[string]bool
+}
+
+func newReferenceRegistry() *referenceRegistry {
+	return &referenceRegistry{
+		teams:   map[string]bool{},
+		rosters: map[string]bool{},
+	}
+}
+
+func (r *referenceRegistry) addTeam(team string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.teams[team] = true
+}
+
+func (r *referenceRegistry) hasTeam(team string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.teams[team]
+}
+
+func (r *referenceRegistry) addRoster(rosterID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rosters[rosterID] = true
+}
+
+func (r *referenceRegistry) hasRoster(rosterID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rosters[rosterID]
+}
+
+// teamReferencesCustomizeDiff registers the team, and any roster blocks, as
+// planned so schedules can refer to them
+func teamReferencesCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	meta := m.(*providerMeta)
+	if !meta.validateReferences || !d.NewValueKnown(teamFieldName) {
+		return nil
+	}
+
+	teamName := d.Get(teamFieldName).(string)
+	meta.plannedReferences.addTeam(teamName)
+	for _, rosterRaw := range d.Get(teamFieldRoster).(*schema.Set).List() {
+		roster := rosterRaw.(map[string]interface{})
+		meta.plannedReferences.addRoster(getRosterID(teamName, roster[rosterFieldName].(string)))
+	}
+	return nil
+}
+
+// rosterReferencesCustomizeDiff registers the roster as planned so schedules can refer to it
+func rosterReferencesCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	meta := m.(*providerMeta)
+	if !meta.validateReferences || !d.NewValueKnown(rosterFieldTeam) || !d.NewValueKnown(rosterFieldName) {
+		return nil
+	}
+
+	teamName := d.Get(rosterFieldTeam).(string)
+	rosterName := d.Get(rosterFieldName).(string)
+	if rosterName == "" {
+		rosterName = teamName
+	}
+	meta.plannedReferences.addRoster(getRosterID(teamName, rosterName))
+	return nil
+}
+
+// scheduleReferencesCustomizeDiff checks that the rosters in the given roster ID
+// fields exist, or are planned elsewhere in the configuration. Roster IDs that
+// come from resources which haven't been created yet are unknown and not checked.
+// Terraform plans resources without a dependency between them in any order, so
+// a roster written out literally may only be planned after the schedule. A
+// roster that can't be found is therefore only warned about, failing the plan
+// would depend on the order it happened to be planned in.
+func scheduleReferencesCustomizeDiff(rosterIDFields ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		meta := m.(*providerMeta)
+		if !meta.validateReferences {
+			return nil
+		}
+
+		for _, field := range rosterIDFields {
+			rosterID := d.Get(field).(string)
+			if !d.NewValueKnown(field) || rosterID == "" {
+				continue
+			}
+			warning, err := checkRosterReference(ctx, meta, rosterID)
+			if err != nil {
+				return errors.Wrapf(err, "Invalid %s", field)
+			}
+			if warning != "" {
+				warnLog("%s %s: %s", d.Id(), field, warning)
+			}
+		}
+		return nil
+	}
+}
+
+// checkRosterReference looks for the roster in the planned rosters and oncall,
+// returning a warning explaining what is wrong if it can't be found
+func checkRosterReference(ctx context.Context, meta *providerMeta, rosterID string) (string, error) {
+	teamName, rosterName, err := parseRosterID(rosterID)
+	if err != nil {
+		return "", err
+	}
+	if meta.plannedReferences.hasRoster(rosterID) {
+		return "", nil
+	}
+
+	c := meta.clientFor(ctx)
+	_, err = c.GetRoster(teamName, rosterName)
+	if err == nil {
+		return "", nil
+	}
+	if !strings.Contains(err.Error(), "(404)") {
+		return "", errors.Wrapf(err, "Checking roster %s exists", rosterID)
+	}
+
+	if !meta.plannedReferences.hasTeam(teamName) {
+		_, err = getTeam(c, teamName)
+		if err != nil && strings.Contains(err.Error(), "(404)") {
+			return fmt.Sprintf("Team %s does not exist in oncall or in what has been planned so far, check the team part of roster ID %s", teamName, rosterID), nil
+		}
+	}
+	return fmt.Sprintf("Team %s has no roster %s in oncall or in what has been planned so far. If the roster is created in this configuration, refer to its id attribute rather than writing out %s", teamName, rosterName, rosterID), nil
+}
@@ -0,0 +1,86 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_checkRosterReference(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/team/rosters/roster":
+			w.Write([]byte(`{"users": [], "schedules": []}`))
+		case "/api/v0/teams/team":
+			w.Write([]byte(`{"name": "team"}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c, validateReferences: true, plannedReferences: newReferenceRegistry()}
+	meta.plannedReferences.addTeam("new-team")
+	meta.plannedReferences.addRoster("new-team/planned")
+
+	tests := []struct {
+		name        string
+		rosterID    string
+		wantWarning string
+		wantErr     string
+	}{
+		{
+			name:     "Existing roster",
+			rosterID: "team/roster",
+		},
+		{
+			name:     "Roster planned in the configuration",
+			rosterID: "new-team/planned",
+		},
+		{
+			name:        "Wrong team",
+			rosterID:    "other-team/roster",
+			wantWarning: "Team other-team does not exist",
+		},
+		{
+			name:        "Missing roster on an existing team",
+			rosterID:    "team/other-roster",
+			wantWarning: "has no roster other-roster",
+		},
+		{
+			name:        "Missing roster on a planned team",
+			rosterID:    "new-team/other-roster",
+			wantWarning: "has no roster other-roster",
+		},
+		{
+			name:     "Malformed",
+			rosterID: "team",
+			wantErr:  "Only team name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warning, err := checkRosterReference(context.Background(), meta, tt.rosterID)
+			if (warning == "") != (tt.wantWarning == "") || !strings.Contains(warning, tt.wantWarning) {
+				t.Errorf("checkRosterReference() warning = %q, want it to contain %q", warning, tt.wantWarning)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("checkRosterReference() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("checkRosterReference() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -3,22 +3,59 @@ package oncall
 import (
 	"context"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/bushelpowered/oncall-client-go/oncall"
-	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
-	"maze.io/x/duration"
 )
 
 const (
-	advancedScheduleFieldShift    = "shift"
-	advancedScheduleFieldDuration = "duration"
+	advancedScheduleFieldShift              = "shift"
+	advancedScheduleFieldDuration           = "duration"
+	advancedScheduleFieldShiftPattern       = "shift_pattern"
+	advancedScheduleFieldStartOffsetSeconds = "start_offset_seconds"
+
+	advancedScheduleFieldFallbackRosterID = "fallback_roster_id"
+	advancedScheduleFieldFallbackWindow   = "fallback_window"
+
+	shiftPatternWeekdayBusinessHours = "weekday_business_hours"
+	shiftPatternWeeknights           = "weeknights"
+	shiftPatternWeekends             = "weekends"
 )
 
+// shiftPatterns are the shift_pattern presets, between the three of them the
+// whole week is covered without any overlap: business hours are 09:00 - 17:00
+// Monday to Friday, weeknights hand back at 09:00 the next morning, and the
+// weekend runs from Friday 17:00 until Monday 09:00
+var shiftPatterns = map[string][]map[string]interface{}{
+	shiftPatternWeekdayBusinessHours: {
+		shiftBlock("Monday", "09:00", "8h"),
+		shiftBlock("Tuesday", "09:00", "8h"),
+		shiftBlock("Wednesday", "09:00", "8h"),
+		shiftBlock("Thursday", "09:00", "8h"),
+		shiftBlock("Friday", "09:00", "8h"),
+	},
+	shiftPatternWeeknights: {
+		shiftBlock("Monday", "17:00", "16h"),
+		shiftBlock("Tuesday", "17:00", "16h"),
+		shiftBlock("Wednesday", "17:00", "16h"),
+		shiftBlock("Thursday", "17:00", "16h"),
+	},
+	shiftPatternWeekends: {
+		shiftBlock("Friday", "17:00", "2d16h"),
+	},
+}
+
+var shiftPatternNames = []string{
+	shiftPatternWeekdayBusinessHours,
+	shiftPatternWeeknights,
+	shiftPatternWeekends,
+}
+
 func resourceAdvancedSchedule() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceAdvancedScheduleCreate,
@@ -28,6 +65,23 @@ func resourceAdvancedSchedule() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceAdvancedScheduleImport,
 		},
+		CustomizeDiff: customdiff.All(
+			scheduleRosterCustomizeDiff,
+			teamPrefixCustomizeDiff(scheduleFieldTeam),
+			deferredPopulateCustomizeDiff(scheduleFieldRosterID, advancedScheduleFieldFallbackRosterID),
+			resourceAdvancedScheduleCustomizeDiff,
+			scheduleAdvancedModeCustomizeDiff(true),
+			shiftStartsCustomizeDiff,
+			scheduleSelfEscalationCustomizeDiff,
+			scheduleOverlapCustomizeDiff,
+			scheduleReferencesCustomizeDiff(scheduleFieldRosterID, advancedScheduleFieldFallbackRosterID),
+			scheduleDefinitionCustomizeDiff(advancedScheduleFromResource),
+			revisionCustomizeDiff(),
+			autoPopulateDaysCustomizeDiff,
+			populateSummaryCustomizeDiff(),
+			customdiff.ComputedIf(scheduleFieldCalendarURL, scheduleURLsChanged),
+			customdiff.ComputedIf(scheduleFieldICalURL, scheduleURLsChanged),
+		),
 
 		Schema: map[string]*schema.Schema{
 			scheduleFieldRole: {
@@ -38,16 +92,38 @@ func resourceAdvancedSchedule() *schema.Resource {
 				Description:      fmt.Sprintf("Name of the role, one of %v", roleNames),
 			},
 			scheduleFieldRosterID: {
-				Type:        schema.TypeString,
-				ForceNew:    false,
-				Required:    true,
-				Description: "Roster ID (in team/roster format) to map this schedule to",
+				Type:         schema.TypeString,
+				ForceNew:     false,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{scheduleFieldRosterID, scheduleFieldTeam},
+				Description:  fmt.Sprintf("Roster ID (in team/roster format) to map this schedule to, or set %s and %s instead", scheduleFieldTeam, scheduleFieldRoster),
+			},
+			scheduleFieldTeam: {
+				Type:          schema.TypeString,
+				ForceNew:      false,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{scheduleFieldRosterID},
+				RequiredWith:  []string{scheduleFieldTeam, scheduleFieldRoster},
+				Description:   fmt.Sprintf("Name of the team of the roster to map this schedule to, an alternative to %s", scheduleFieldRosterID),
+			},
+			scheduleFieldRoster: {
+				Type:          schema.TypeString,
+				ForceNew:      false,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{scheduleFieldRosterID},
+				RequiredWith:  []string{scheduleFieldTeam, scheduleFieldRoster},
+				Description:   fmt.Sprintf("Name of the roster to map this schedule to, an alternative to %s", scheduleFieldRosterID),
 			},
 			scheduleFieldAutoPopulateDays: {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Default:     21,
-				Description: "How many days in advance to plan the schedule",
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          21,
+				DiffSuppressFunc: suppressRoundedAutoPopulateDays,
+				ValidateDiagFunc: validateAutoPopulateDays,
+				Description:      "How many days in advance to plan the schedule. Oncall rounds this up to a whole number of weeks. " + autoPopulateDaysDescription(defaultMaxAutoPopulateDays),
 			},
 			scheduleFieldSchedulingAlgorithim: {
 				Type:             schema.TypeString,
@@ -56,33 +132,161 @@ func resourceAdvancedSchedule() *schema.Resource {
 				ValidateDiagFunc: validateStringSliceContains(schedulingAlgorithms),
 				Description:      fmt.Sprintf("Scheduling algorithim to use, one of: %v", schedulingAlgorithms),
 			},
+			scheduleFieldNextRotationAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the schedule next hands off to the next person (RFC 3339), from the populated calendar. Empty if nothing upcoming has been populated",
+			},
+			scheduleFieldAllowEmptyRoster: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow the schedule to be created or updated while its roster has nobody in rotation, which oncall populates as an empty calendar",
+			},
+			scheduleFieldTrimFutureEvents: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When auto_populate_days is lowered, delete the events this schedule already populated that start past the new horizon instead of leaving them on the calendar with a warning",
+			},
+			scheduleFieldDryRunPopulate: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Instead of populating the calendar when the schedule is updated, preview who would be scheduled and report it as a warning",
+			},
+			scheduleFieldExcludeMembers: excludeMembersSchema(),
+			scheduleFieldDescription:    scheduleDescriptionSchema(),
+			scheduleFieldDeferPopulate: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When the roster has nobody in rotation, e.g. because it is created in the same apply, warn and hold off populating the calendar instead of failing. The next plan after the roster gets members updates the schedule to populate it",
+			},
+			scheduleFieldPopulatePending: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether populating the calendar was deferred as the roster had nobody in rotation",
+			},
+			scheduleFieldAdvancedMode: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the schedule is in advanced mode in oncall. Always true once applied, a schedule imported from basic mode is converted to advanced mode by the next apply",
+			},
+			scheduleFieldRespectHolidayCalendar: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Experimental. Name of an oncall_holiday_calendar of the team, handoffs that fall on one of its holidays are moved to the next day when the provider populates the calendar",
+			},
+			scheduleFieldPopulatedThrough: populatedThroughSchema(),
+			scheduleFieldAssignments:      assignmentsSchema(),
+			scheduleFieldScheduledUntil: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "How far into the future the calendar is populated for this schedule (RFC 3339). Empty if nothing upcoming has been populated",
+			},
+			scheduleFieldCalendarURL: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the calendar of the schedule's team in the oncall UI",
+			},
+			scheduleFieldICalURL: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the iCal feed of the team's on call events for the schedule's role",
+			},
+			fieldRevision: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the schedule as read from oncall, which changes whenever it is changed, including outside of terraform e.g. in the oncall UI. For replace_triggered_by and postconditions to react to such changes",
+			},
+			scheduleFieldScheduleID: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Numeric ID of the schedule in oncall, used to update and delete it even if its role is renamed",
+			},
+			scheduleFieldLastEpochScheduled: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Unix time up to which oncall's scheduler has scheduled this schedule",
+			},
+			scheduleFieldChangeSummary: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Planned when the schedule's normalized definition changes, describing what changed for people reviewing the plan, e.g. handoff moved from Mon 09:00 to Tue 10:00. Only meaningful in plans that change the schedule",
+			},
+			scheduleFieldNormalizedDefinitionJSON: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "JSON of the schedule's role, roster, scheduling settings and events sorted by start, in the same shape for basic and advanced schedules, for policy as code to check",
+			},
+			scheduleFieldPopulationStatus: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: fmt.Sprintf("Whether the calendar is populated as far ahead as auto_populate_days asks, one of: %v. Behind means it covers over a week less than asked for", populationStatuses),
+			},
+			advancedScheduleFieldShiftPattern: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ExactlyOneOf:     []string{advancedScheduleFieldShift, advancedScheduleFieldShiftPattern},
+				ValidateDiagFunc: validateStringSliceContains(shiftPatternNames),
+				Description:      fmt.Sprintf("Preset set of shifts to use instead of shift blocks, one of: %v. Business hours are 09:00 - 17:00 Monday to Friday, weeknights run from 17:00 to 09:00 Monday to Thursday, and weekends from Friday 17:00 to Monday 09:00", shiftPatternNames),
+			},
 			advancedScheduleFieldShift: {
-				Type:        schema.TypeList,
-				Required:    true,
-				ForceNew:    false,
-				Description: "The various shifts that make up a rotation of this role",
-				Elem: &schema.Resource{
-					Schema: map[string]*schema.Schema{
-						scheduleFieldStartDayOfWeek: {
-							Type:             schema.TypeString,
-							ValidateDiagFunc: validateStringSliceContains(daysOfWeek),
-							Required:         true,
-							Description:      "The day of week that this shift should start on",
-						},
-						scheduleFieldStartTime: {
-							Type:             schema.TypeString,
-							ValidateDiagFunc: validate24HourTime,
-							Required:         true,
-							Description:      "The time on this day that this shift should start",
-						},
-						advancedScheduleFieldDuration: {
-							Type:             schema.TypeString,
-							ValidateDiagFunc: validateDuration,
-							Required:         true,
-							Description:      "How long this shift should be in duration shorthand, e.g. 24h, 8h, 1h30m, 3d",
-						},
-					},
-				},
+				Type:         schema.TypeList,
+				Optional:     true,
+				Computed:     true,
+				ForceNew:     false,
+				ExactlyOneOf: []string{advancedScheduleFieldShift, advancedScheduleFieldShiftPattern},
+				Description:  "The various shifts that make up a rotation of this role",
+				Elem:         shiftResource(),
+			},
+			advancedScheduleFieldFallbackRosterID: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				RequiredWith: []string{advancedScheduleFieldFallbackWindow},
+				Description:  "Roster ID (in team/roster format) that is on call for this role during the fallback windows instead of roster_id",
+			},
+			advancedScheduleFieldFallbackWindow: {
+				Type:         schema.TypeList,
+				Optional:     true,
+				RequiredWith: []string{advancedScheduleFieldFallbackRosterID},
+				Description:  "Weekly windows during which the fallback roster covers the shifts of this schedule",
+				Elem:         shiftResource(),
+			},
+		},
+	}
+}
+
+func shiftResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			scheduleFieldStartDayOfWeek: {
+				Type:             schema.TypeString,
+				ValidateDiagFunc: validateStringSliceContains(daysOfWeek),
+				Optional:         true,
+				Description:      "The day of week that this shift should start on. Required unless using start_offset_seconds",
+			},
+			scheduleFieldStartTime: {
+				Type:             schema.TypeString,
+				ValidateDiagFunc: validate24HourTime,
+				StateFunc:        timeOfDayStateFunc,
+				Optional:         true,
+				Description:      "The time on this day that this shift should start, as HH:MM or HH:MM:SS. Required unless using start_offset_seconds",
+			},
+			advancedScheduleFieldStartOffsetSeconds: {
+				Type:             schema.TypeInt,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(0, weekSeconds-1)),
+				Optional:         true,
+				Description:      "When this shift starts in seconds from the start of the week (Sunday 00:00, or 00:00 on the provider's week_starts_on), instead of start_day_of_week and start_time",
+			},
+			advancedScheduleFieldDuration: {
+				Type:             schema.TypeString,
+				ValidateDiagFunc: validateDuration,
+				StateFunc:        durationStateFunc,
+				DiffSuppressFunc: suppressEquivalentDuration,
+				Required:         true,
+				Description:      "How long this shift should be, down to the second, in duration shorthand, e.g. 24h, 8h, 1h30m, 3d, which takes anything Go's time.ParseDuration does, or in ISO 8601, e.g. PT8H or P1D. Kept in state in shorthand, so equivalent durations don't show up as changes",
 			},
 		},
 	}
@@ -90,7 +294,7 @@ func resourceAdvancedSchedule() *schema.Resource {
 
 func resourceAdvancedScheduleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	diags := diag.Diagnostics{}
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	rosterID := d.Get(scheduleFieldRosterID).(string)
 	teamName, rosterName, err := parseRosterID(rosterID)
@@ -100,34 +304,64 @@ func resourceAdvancedScheduleCreate(ctx context.Context, d *schema.ResourceData,
 	scheduleName := d.Get(scheduleFieldRole).(string)
 
 	traceLog("Going to create roster schedule: %s/%s/%s", teamName, rosterName, scheduleName)
-	sched, err := advancedScheduleFromResource(d)
+	sched, err := advancedScheduleFromResource(d, weekStartFor(m))
 	if err != nil {
 		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
 	}
 
+	fallback, err := fallbackFromResource(d, sched.Events, weekStartFor(m))
+	if err != nil {
+		return diagFromErrf(err, "Failed to split schedule with the fallback roster")
+	}
+	if fallback != nil {
+		sched.Events = fallback.primaryEvents
+	}
+
+	deferred, diags := checkRosterNotEmpty(c, d, scheduleFieldRosterID, teamName, rosterName)
+	if diags.HasError() {
+		return diags
+	}
+	if fallback != nil {
+		fallbackDeferred, fallbackDiags := checkRosterNotEmpty(c, d, advancedScheduleFieldFallbackRosterID, fallback.team, fallback.roster)
+		deferred = deferred || fallbackDeferred
+		diags = append(diags, fallbackDiags...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
 	resourceID := getScheduleID(teamName, rosterName, scheduleName)
 	err = c.AddRosterSchedule(teamName, rosterName, sched)
 	if err != nil {
-		if strings.Contains(err.Error(), "(422)") {
-			return diagFromErrf(err, "Roster schedule already exists, please import using id '%s", resourceID)
-		}
-		return diagFromErrf(err, "Creating oncall roster")
+		return createErrorDiags(err, "Creating oncall roster schedule", resourceID, scheduleFieldRole, scheduleFieldRosterID, scheduleFieldAutoPopulateDays, scheduleFieldSchedulingAlgorithim)
 	}
 
 	d.SetId(resourceID)
+	d.Set(scheduleFieldPopulatePending, deferred)
+	diags = append(diags, updateScheduleDescription(ctx, d, m, teamName, rosterName)...)
+
+	if fallback != nil {
+		traceLog("Going to create fallback roster schedule: %s/%s/%s", fallback.team, fallback.roster, scheduleName)
+		err = c.AddRosterSchedule(fallback.team, fallback.roster, fallback.schedule(sched))
+		if err != nil {
+			return diagFromErrf(err, "Creating fallback roster schedule on %s", getRosterID(fallback.team, fallback.roster))
+		}
+	}
+
 	resourceAdvancedScheduleRead(ctx, d, m)
 	return diags
 }
 
 func resourceAdvancedScheduleImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
-	teamName, rosterName, scheduleName, err := parseScheduleID(d.Id())
+	teamName, rosterName, scheduleName, err := canonicalScheduleImportID(ctx, d.Id(), m)
 	if err != nil {
-		return nil, errors.Wrap(err, "Parsing roster ID, this is an internal error")
+		return nil, errors.Wrap(err, "Parsing import ID")
 	}
+	d.SetId(getScheduleID(teamName, rosterName, scheduleName))
 
 	rosterID := getRosterID(teamName, rosterName)
 
-	traceLog("Going to import roster schedule %q as team: %s, roster: %s, role: ", d.Id(), teamName, rosterName, scheduleName)
+	traceLog("Going to import roster schedule %q as team: %s, roster: %s, role: %s", d.Id(), teamName, rosterName, scheduleName)
 	d.Set(scheduleFieldRole, scheduleName)
 	d.Set(scheduleFieldRosterID, rosterID)
 
@@ -135,11 +369,14 @@ func resourceAdvancedScheduleImport(ctx context.Context, d *schema.ResourceData,
 	if len(readErr) > 0 {
 		err = errors.New(readErr[0].Summary)
 	}
+	if err == nil && d.Id() == "" {
+		err = fmt.Errorf("Roster schedule %s does not exist", getScheduleID(teamName, rosterName, scheduleName))
+	}
 	return []*schema.ResourceData{d}, errors.Wrap(err, "Reading resource for import")
 }
 
 func resourceAdvancedScheduleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
@@ -149,68 +386,170 @@ func resourceAdvancedScheduleRead(ctx context.Context, d *schema.ResourceData, m
 		return diagFromErrf(err, "Parsing roster ID, this is an internal error")
 	}
 
-	schedule, err := c.GetRosterSchedule(teamName, rosterName, scheduleName)
+	schedule, found, err := getRosterScheduleByID(c, teamName, rosterName, d.Get(scheduleFieldScheduleID).(int), scheduleName)
 	if err != nil {
-		if strings.Contains(err.Error(), "Did not find schedule") {
-			schedule = oncall.Schedule{
-				Role: scheduleName,
-				Scheduler: oncall.ScheduleScheduler{
-					Name: "default",
-				},
-			}
-		} else {
-			return diagFromErrf(err, "Getting roster schedule %s/%s/%s", teamName, rosterName, scheduleName)
-		}
+		return diagFromErrf(err, "Getting roster schedule %s/%s/%s", teamName, rosterName, scheduleName)
+	}
+	if !found {
+		warnLog("Roster schedule %s/%s/%s no longer exists, removing it from state", teamName, rosterName, scheduleName)
+		d.SetId("")
+		return diags
 	}
 
+	// The role may have been renamed since the schedule was created, the
+	// numeric ID is what identifies it so keep the resource ID in step with it
+	d.SetId(getScheduleID(teamName, rosterName, schedule.Role))
+	d.Set(scheduleFieldScheduleID, schedule.ID)
+	d.Set(fieldRevision, scheduleRevision(schedule.Schedule))
+	d.Set(scheduleFieldCalendarURL, teamCalendarURL(c.Config.Endpoint, teamName))
+	d.Set(scheduleFieldICalURL, teamICalURL(c.Config.Endpoint, teamName, schedule.Role))
 	d.Set(scheduleFieldRole, schedule.Role)
+	diags = append(diags, checkReadRole(m, d.Id(), schedule.Role)...)
 	d.Set(scheduleFieldRosterID, getRosterID(teamName, rosterName))
+	d.Set(scheduleFieldTeam, teamName)
+	d.Set(scheduleFieldRoster, rosterName)
 	d.Set(scheduleFieldAutoPopulateDays, schedule.AutoPopulateThreshold)
 	d.Set(scheduleFieldSchedulingAlgorithim, schedule.Scheduler.Name)
+	d.Set(scheduleFieldAdvancedMode, schedule.AdvancedMode != 0)
+	readScheduleCalendar(c, d, teamName, schedule)
+	readScheduleDescription(c, d, teamName, rosterName, schedule.Role)
 
+	// Shifts are read back in whichever form the configuration writes them in
+	useOffsets := shiftsUseStartOffsets(d.Get(advancedScheduleFieldShift).([]interface{}))
 	events := make([]map[string]interface{}, 0, len(schedule.Events))
 	for _, event := range schedule.Events {
-		dayOfWeekIndex, startHour, startMin := secondsToDayHourMinute(event.Start)
 		ev := map[string]interface{}{
-			scheduleFieldStartDayOfWeek:   daysOfWeek[dayOfWeekIndex],
-			scheduleFieldStartTime:        fmt.Sprintf("%02d:%02d", startHour, startMin),
 			advancedScheduleFieldDuration: prettyPrintDuration(event.Duration),
 		}
+		if useOffsets {
+			ev[advancedScheduleFieldStartOffsetSeconds] = event.Start
+		} else {
+			dayOfWeekIndex, startHour, startMin, startSec := secondsToDayTime(event.Start, weekStartFor(m))
+			ev[scheduleFieldStartDayOfWeek] = daysOfWeek[dayOfWeekIndex]
+			ev[scheduleFieldStartTime] = formatTimeOfDay(startHour, startMin, startSec)
+		}
 		events = append(events, ev)
 	}
-	d.Set(advancedScheduleFieldShift, events)
+
+	// With a fallback roster the shifts are split across two schedules, so only
+	// overwrite the shifts when the split doesn't match what is expected anymore
+	fallbackMatches, fallbackDiags := readScheduleFallback(c, d, schedule.Schedule, m)
+	diags = append(diags, fallbackDiags...)
+	if !fallbackMatches {
+		d.Set(advancedScheduleFieldShift, events)
+
+		shiftPattern := d.Get(advancedScheduleFieldShiftPattern).(string)
+		if shiftPattern != "" && !eventsMatchShiftPattern(schedule.Events, shiftPattern, weekStartFor(m)) {
+			diags = append(diags, normalizationDiags(m, "Schedule %s/%s/%s no longer matches shift pattern %s", teamName, rosterName, scheduleName, shiftPattern)...)
+			d.Set(advancedScheduleFieldShiftPattern, "")
+		}
+	}
+
+	diags = append(diags, readScheduleDefinition(d, advancedScheduleFromResource, m)...)
 	return diags
 }
 
+func resourceAdvancedScheduleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	shiftPattern := d.Get(advancedScheduleFieldShiftPattern).(string)
+	if shiftPattern == "" || !d.HasChange(advancedScheduleFieldShiftPattern) {
+		return nil
+	}
+
+	shifts, ok := shiftPatterns[shiftPattern]
+	if !ok {
+		return fmt.Errorf("Unknown shift pattern %q", shiftPattern)
+	}
+	shiftList := make([]interface{}, 0, len(shifts))
+	for _, shift := range shifts {
+		shiftList = append(shiftList, shift)
+	}
+	return d.SetNew(advancedScheduleFieldShift, shiftList)
+}
+
 func resourceAdvancedScheduleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	traceLog("Going to update schedule %q", d.Id())
 	teamName, rosterName, schedulename, err := parseScheduleID(d.Id())
 	if err != nil {
 		return diagFromErrf(err, "Parsing roster schedule ID, this is an internal error")
 	}
+	teamName, rosterName, err = followMigratedSchedule(c, d, teamName, rosterName, schedulename)
+	if err != nil {
+		return diagFromErrf(err, "Finding schedule %s on its roster's new team", d.Id())
+	}
 
 	traceLog("Going to update roster schedule %s/%s/%s", teamName, rosterName, schedulename)
-	sched, err := advancedScheduleFromResource(d)
+	sched, err := advancedScheduleFromResource(d, weekStartFor(m))
 	if err != nil {
 		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
 	}
 
-	err = c.UpdateRosterSchedule(teamName, rosterName, sched.Role, sched)
+	fallback, err := fallbackFromResource(d, sched.Events, weekStartFor(m))
+	if err != nil {
+		return diagFromErrf(err, "Failed to split schedule with the fallback roster")
+	}
+	if fallback != nil {
+		sched.Events = fallback.primaryEvents
+	}
+
+	deferred, diags := checkRosterNotEmpty(c, d, scheduleFieldRosterID, teamName, rosterName)
+	if diags.HasError() {
+		return diags
+	}
+	if fallback != nil {
+		fallbackDeferred, fallbackDiags := checkRosterNotEmpty(c, d, advancedScheduleFieldFallbackRosterID, fallback.team, fallback.roster)
+		deferred = deferred || fallbackDeferred
+		diags = append(diags, fallbackDiags...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	err = updateRosterSchedule(c, d.Get(scheduleFieldScheduleID).(int), teamName, rosterName, schedulename, sched)
 	if err != nil {
 		return diagFromErrf(err, "Updating oncall roster schedule")
 	}
-	err = c.PopulateRosterSchedule(teamName, rosterName, sched.Role, time.Now())
+
+	err = updateScheduleFallback(ctx, c, d, sched, fallback)
 	if err != nil {
-		return diagFromErrf(err, "Populating oncall roster schedule")
+		return diagFromErrf(err, "Updating fallback roster schedule")
+	}
+
+	d.Set(scheduleFieldPopulatePending, deferred)
+	if !deferred {
+		populateStart := time.Now()
+		diags = append(diags, populateOrPreview(ctx, c, d, teamName, rosterName, sched.Role, time.Now())...)
+		if diags.HasError() {
+			return diags
+		}
+		if !d.Get(scheduleFieldDryRunPopulate).(bool) {
+			diags = append(diags, recordPopulateSummary(c, d, teamName, sched.Role, populateStart)...)
+		}
+		if fallback != nil {
+			diags = append(diags, populateOrPreview(ctx, c, d, fallback.team, fallback.roster, sched.Role, time.Now())...)
+			if diags.HasError() {
+				return diags
+			}
+		}
+	}
+
+	diags = append(diags, updateScheduleDescription(ctx, d, m, teamName, rosterName)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	trim := d.Get(scheduleFieldTrimFutureEvents).(bool) && !d.Get(scheduleFieldDryRunPopulate).(bool)
+	diags = append(diags, shrinkPopulateHorizon(c, d, teamName, sched.Role, d.Get(scheduleFieldScheduleID).(int), trim)...)
+	if diags.HasError() {
+		return diags
 	}
 
-	return resourceAdvancedScheduleRead(ctx, d, m)
+	return append(diags, resourceAdvancedScheduleRead(ctx, d, m)...)
 }
 
 func resourceAdvancedScheduleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	traceLog("Going to update roster %q", d.Id())
 	teamName, rosterName, scheduleName, err := parseScheduleID(d.Id())
@@ -218,22 +557,31 @@ func resourceAdvancedScheduleDelete(ctx context.Context, d *schema.ResourceData,
 		return diagFromErrf(err, "Parsing roster schedule ID, this is an internal error")
 	}
 
+	// Removed first, so a failure leaves the schedule in state to try again
+	diags := removeScheduleDescription(ctx, d, m, teamName, rosterName, scheduleName)
+	if diags.HasError() {
+		return diags
+	}
+
 	traceLog("Going to delete roster schedule %s/%s/%s", teamName, rosterName, scheduleName)
-	err = c.RemoveRosterSchedule(teamName, rosterName, scheduleName)
+	err = removeRosterSchedule(ctx, c, d.Get(scheduleFieldScheduleID).(int), teamName, rosterName, scheduleName)
 	if err != nil {
-		if !strings.Contains(err.Error(), "Did not find schedule") {
-			return diagFromErrf(err, "Removing roster %s/%s/%s", teamName, rosterName, scheduleName)
-		}
+		return diagFromErrf(err, "Removing roster %s/%s/%s", teamName, rosterName, scheduleName)
+	}
+
+	err = removeScheduleFallback(ctx, c, d.Get(advancedScheduleFieldFallbackRosterID).(string), scheduleName)
+	if err != nil {
+		return diagFromErrf(err, "Removing fallback roster schedule")
 	}
 
 	// d.SetId("") is automatically called assuming delete returns no errors, but
 	// it is added here for explicitness.
 	d.SetId("")
 
-	return diag.Diagnostics{}
+	return diags
 }
 
-func advancedScheduleFromResource(d *schema.ResourceData) (oncall.Schedule, error) {
+func advancedScheduleFromResource(d resourceGetter, weekStart time.Weekday) (oncall.Schedule, error) {
 	role := d.Get(scheduleFieldRole).(string)
 	rosterID := d.Get(scheduleFieldRosterID).(string)
 	autoPopulateDays := d.Get(scheduleFieldAutoPopulateDays).(int)
@@ -255,73 +603,120 @@ func advancedScheduleFromResource(d *schema.ResourceData) (oncall.Schedule, erro
 	sched.Team = team
 	sched.Roster = roster
 
-	shiftInterfaces := d.Get(advancedScheduleFieldShift).([]interface{})
+	shifts := []map[string]interface{}{}
+	if shiftPattern := d.Get(advancedScheduleFieldShiftPattern).(string); shiftPattern != "" {
+		shifts = shiftPatterns[shiftPattern]
+	} else {
+		for _, shiftRaw := range d.Get(advancedScheduleFieldShift).([]interface{}) {
+			shifts = append(shifts, shiftRaw.(map[string]interface{}))
+		}
+	}
 
-	for _, shiftRaw := range shiftInterfaces {
-		shift := shiftRaw.(map[string]interface{})
+	events, err := shiftsToEvents(shifts, weekStart)
+	if err != nil {
+		return sched, err
+	}
+	sched.Events = events
+	return sched, nil
+}
 
+func shiftsToEvents(shifts []map[string]interface{}, weekStart time.Weekday) ([]oncall.ScheduleEvent, error) {
+	events := make([]oncall.ScheduleEvent, 0, len(shifts))
+	for _, shift := range shifts {
 		durationString := shift[advancedScheduleFieldDuration].(string)
-		startDayOfWeek := shift[scheduleFieldStartDayOfWeek].(string)
-		startTime := shift[scheduleFieldStartTime].(string)
 
-		startSeconds, err := weekdayStartTimeToSeconds(startDayOfWeek, startTime)
+		startSeconds, err := shiftStartSeconds(shift, weekStart)
 		if err != nil {
-			return sched, errors.Wrapf(err, "Parsing start weekday and time")
+			return events, err
 		}
 
-		duration, err := duration.ParseDuration(durationString)
+		durationSeconds, err := parseDurationSeconds(durationString)
 		if err != nil {
-			return sched, errors.Wrapf(err, "Failed to parse duration")
+			return events, errors.Wrapf(err, "Failed to parse duration")
 		}
 		event := oncall.ScheduleEvent{
 			Start:    startSeconds,
-			Duration: int(duration.Seconds()),
+			Duration: durationSeconds,
 		}
 
-		sched.Events = append(sched.Events, event)
+		events = append(events, event)
 	}
-	return sched, nil
+	return events, nil
 }
 
-func validateDuration(in interface{}, path cty.Path) diag.Diagnostics {
-	_, err := duration.ParseDuration(in.(string))
-	return diagFromErrf(err, "Failed to parse duration")
-}
-
-func prettyPrintDuration(dur int) string {
-	numWeeks := int(dur / int(duration.Week.Seconds()))
-
-	durWithoutWeeks := int(dur - numWeeks*int(duration.Week.Seconds()))
-	numDays := int(durWithoutWeeks / int(duration.Day.Seconds()))
+// shiftStartsCustomizeDiff checks at plan time that every shift and fallback
+// window gives its start one way, skipping any whose start isn't known yet
+func shiftStartsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	for _, field := range []string{advancedScheduleFieldShift, advancedScheduleFieldFallbackWindow} {
+		for i, shiftRaw := range d.Get(field).([]interface{}) {
+			shift, ok := shiftRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
 
-	durWithoutDays := int(durWithoutWeeks - numDays*int(duration.Day.Seconds()))
-	numHours := int(durWithoutDays / int(duration.Hour.Seconds()))
+			known := true
+			for _, key := range []string{scheduleFieldStartDayOfWeek, scheduleFieldStartTime, advancedScheduleFieldStartOffsetSeconds} {
+				known = known && d.NewValueKnown(fmt.Sprintf("%s.%d.%s", field, i, key))
+			}
+			if !known {
+				continue
+			}
 
-	durWithoutHours := int(durWithoutDays - numHours*int(duration.Hour.Seconds()))
-	numMinutes := int(durWithoutHours / int(duration.Minute.Seconds()))
+			_, err := shiftStartSeconds(shift, weekStartFor(m))
+			if err != nil {
+				return errors.Wrapf(err, "Invalid %s %d", field, i+1)
+			}
+		}
+	}
+	return nil
+}
 
-	numSeconds := int(durWithoutHours - numMinutes*int(duration.Minute.Seconds()))
+// shiftStartSeconds is when the shift starts in seconds from the start of the
+// week, from either its start day and time or its start offset
+func shiftStartSeconds(shift map[string]interface{}, weekStart time.Weekday) (int, error) {
+	startDayOfWeek, _ := shift[scheduleFieldStartDayOfWeek].(string)
+	startTime, _ := shift[scheduleFieldStartTime].(string)
+	startOffset, _ := shift[advancedScheduleFieldStartOffsetSeconds].(int)
 
-	ret := ""
-	if numWeeks > 0 {
-		ret = fmt.Sprintf("%d", numWeeks) + "w"
+	if startDayOfWeek == "" && startTime == "" {
+		return startOffset, nil
 	}
-
-	if numDays > 0 {
-		ret = fmt.Sprintf("%s%d", ret, numDays) + "d"
+	if startOffset != 0 {
+		return -1, fmt.Errorf("%s is mutually exclusive with %s and %s", advancedScheduleFieldStartOffsetSeconds, scheduleFieldStartDayOfWeek, scheduleFieldStartTime)
 	}
-
-	if numHours > 0 {
-		ret = fmt.Sprintf("%s%d", ret, numHours) + "h"
+	if startDayOfWeek == "" || startTime == "" {
+		return -1, fmt.Errorf("%s and %s must be set together", scheduleFieldStartDayOfWeek, scheduleFieldStartTime)
 	}
 
-	if numMinutes > 0 {
-		ret = fmt.Sprintf("%s%d", ret, numMinutes) + "m"
+	startSeconds, err := weekdayStartTimeToSeconds(startDayOfWeek, startTime, weekStart)
+	return startSeconds, errors.Wrapf(err, "Parsing start weekday and time")
+}
+
+// shiftsUseStartOffsets is whether every shift gives its start as an offset
+// rather than a start day and time
+func shiftsUseStartOffsets(shifts []interface{}) bool {
+	for _, shiftRaw := range shifts {
+		shift, ok := shiftRaw.(map[string]interface{})
+		if !ok || shift[scheduleFieldStartDayOfWeek] != "" || shift[scheduleFieldStartTime] != "" {
+			return false
+		}
 	}
+	return len(shifts) > 0
+}
 
-	if numSeconds > 0 {
-		ret = fmt.Sprintf("%s%d", ret, numSeconds) + "s"
+func shiftBlock(startDayOfWeek, startTime, duration string) map[string]interface{} {
+	return map[string]interface{}{
+		scheduleFieldStartDayOfWeek:   startDayOfWeek,
+		scheduleFieldStartTime:        startTime,
+		advancedScheduleFieldDuration: duration,
 	}
+}
 
-	return ret
+// eventsMatchShiftPattern checks if the events are exactly what the shift pattern expands to
+func eventsMatchShiftPattern(events []oncall.ScheduleEvent, shiftPattern string, weekStart time.Weekday) bool {
+	patternEvents, err := shiftsToEvents(shiftPatterns[shiftPattern], weekStart)
+	if err != nil {
+		return false
+	}
+	return sameEvents(events, patternEvents)
 }
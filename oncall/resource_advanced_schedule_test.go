@@ -2,48 +2,79 @@ package oncall
 
 import (
 	"testing"
+	"time"
 )
 
-func Test_prettyPrintDuration(t *testing.T) {
-	minuteSeconds := 60
-	hourSeconds := minuteSeconds * 60
-	daySeconds := hourSeconds * 24
-	weekSeconds := daySeconds * 7
+func Test_shiftPatternsCoverTheWeek(t *testing.T) {
+	weekSeconds := 7 * 24 * 60 * 60
+	covered := make([]int, weekSeconds/60)
+	for _, name := range shiftPatternNames {
+		events, err := shiftsToEvents(shiftPatterns[name], time.Sunday)
+		if err != nil {
+			t.Fatalf("shiftsToEvents(%s) error = %v", name, err)
+		}
+		if !eventsMatchShiftPattern(events, name, time.Sunday) {
+			t.Errorf("eventsMatchShiftPattern(%s) = false for its own events", name)
+		}
+		for _, e := range events {
+			for s := e.Start; s < e.Start+e.Duration; s += 60 {
+				covered[(s%weekSeconds)/60]++
+			}
+		}
+	}
+	for minute, count := range covered {
+		if count != 1 {
+			t.Fatalf("Minute %d of the week is covered by %d shift patterns, want 1", minute, count)
+		}
+	}
+}
+
+func Test_shiftStartSeconds(t *testing.T) {
 	tests := []struct {
-		name string
-		dur  int
-		want string
+		name    string
+		shift   map[string]interface{}
+		want    int
+		wantErr bool
 	}{
 		{
-			name: "Test 1 minute",
-			dur:  60,
-			want: "1m",
+			name:  "Day and time",
+			shift: map[string]interface{}{scheduleFieldStartDayOfWeek: "Monday", scheduleFieldStartTime: "09:00", advancedScheduleFieldStartOffsetSeconds: 0},
+			want:  118800,
+		},
+		{
+			name:  "Offset",
+			shift: map[string]interface{}{scheduleFieldStartDayOfWeek: "", scheduleFieldStartTime: "", advancedScheduleFieldStartOffsetSeconds: 118800},
+			want:  118800,
 		},
 		{
-			name: "Test 1 day",
-			dur:  1 * daySeconds,
-			want: "1d",
+			name:  "Offset at the start of the week",
+			shift: map[string]interface{}{scheduleFieldStartDayOfWeek: "", scheduleFieldStartTime: "", advancedScheduleFieldStartOffsetSeconds: 0},
+			want:  0,
 		},
 		{
-			name: "Test 1 week",
-			dur:  1 * weekSeconds,
-			want: "1w",
+			name:  "Shift pattern block without an offset",
+			shift: shiftBlock("Sunday", "01:00", "1h"),
+			want:  3600,
 		},
 		{
-			name: "Test 1 day 1 hour 1 minute",
-			dur:  daySeconds + hourSeconds + minuteSeconds,
-			want: "1d1h1m",
+			name:    "Both",
+			shift:   map[string]interface{}{scheduleFieldStartDayOfWeek: "Monday", scheduleFieldStartTime: "09:00", advancedScheduleFieldStartOffsetSeconds: 60},
+			wantErr: true,
 		},
 		{
-			name: "Test 1 week 1 day 1 hour 1 minute",
-			dur:  weekSeconds + daySeconds + hourSeconds + minuteSeconds,
-			want: "1w1d1h1m",
+			name:    "Day without time",
+			shift:   map[string]interface{}{scheduleFieldStartDayOfWeek: "Monday", scheduleFieldStartTime: "", advancedScheduleFieldStartOffsetSeconds: 0},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := prettyPrintDuration(tt.dur); got != tt.want {
-				t.Errorf("prettyPrintDuration() = %v, want %v", got, tt.want)
+			got, err := shiftStartSeconds(tt.shift, time.Sunday)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("shiftStartSeconds() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("shiftStartSeconds() = %v, want %v", got, tt.want)
 			}
 		})
 	}
@@ -3,7 +3,7 @@ package oncall
 import (
 	"context"
 	"fmt"
-	"math"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,7 +11,9 @@ import (
 	"github.com/bushelpowered/oncall-client-go/oncall"
 	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 	"github.com/pkg/errors"
 	"maze.io/x/duration"
 )
@@ -20,10 +22,34 @@ const (
 	// Used by basic and advanced schedule
 	scheduleFieldRole                 = "role"
 	scheduleFieldRosterID             = "roster_id"
+	scheduleFieldTeam                 = "team"
+	scheduleFieldRoster               = "roster"
 	scheduleFieldAutoPopulateDays     = "auto_populate_days"
 	scheduleFieldStartDayOfWeek       = "start_day_of_week"
 	scheduleFieldStartTime            = "start_time"
 	scheduleFieldSchedulingAlgorithim = "scheduling_algorithim"
+	scheduleFieldNextRotationAt       = "next_rotation_at"
+	scheduleFieldScheduledUntil       = "scheduled_until"
+	scheduleFieldLastEpochScheduled   = "last_epoch_scheduled"
+	scheduleFieldPopulationStatus     = "population_status"
+	scheduleFieldDryRunPopulate       = "dry_run_populate"
+	scheduleFieldScheduleID           = "schedule_id"
+	scheduleFieldAllowEmptyRoster     = "allow_empty_roster"
+	scheduleFieldDeferPopulate        = "defer_populate"
+	scheduleFieldPopulatePending      = "populate_pending"
+	scheduleFieldTrimFutureEvents     = "trim_future_events"
+	scheduleFieldAdvancedMode         = "advanced_mode"
+	scheduleFieldCalendarURL          = "calendar_url"
+	scheduleFieldICalURL              = "ical_url"
+
+	scheduleFieldRespectHolidayCalendar = "respect_holiday_calendar"
+
+	scheduleFieldNormalizedDefinitionJSON = "normalized_definition_json"
+	scheduleFieldChangeSummary            = "change_summary"
+
+	populationStatusEmpty     = "empty"
+	populationStatusBehind    = "behind"
+	populationStatusPopulated = "populated"
 
 	basicScheduleRotationWeekly   = "weekly"
 	basicScheduleRotationBiWeekly = "bi-weekly"
@@ -32,14 +58,40 @@ const (
 	schedulingAlgorithmRoundRobin = "round-robin"
 
 	// Used only by basic schedule
-	basicScheduleFieldRotateFrequency = "rotate_frequency"
+	basicScheduleFieldRotateFrequency  = "rotate_frequency"
+	basicScheduleFieldRotateEveryWeeks = "rotate_every_weeks"
+	basicScheduleFieldHandoff          = "handoff"
+	basicScheduleFieldForceOverwrite   = "force_overwrite"
+	basicScheduleFieldOffsetWeeks      = "offset_weeks"
+	handoffFieldDayOfWeek              = "day_of_week"
+	handoffFieldTime                   = "time"
+	handoffFieldBusinessDayAdjustment  = "business_day_adjustment"
+
+	businessDayAdjustmentNone     = "none"
+	businessDayAdjustmentPrevious = "previous"
+	businessDayAdjustmentNext     = "next"
 )
 
+var businessDayAdjustments = []string{
+	businessDayAdjustmentNone,
+	businessDayAdjustmentPrevious,
+	businessDayAdjustmentNext,
+}
+
+// maxRotateEveryWeeks is the longest rotation a basic schedule can have, a year
+const maxRotateEveryWeeks = 52
+
 var basicScheduleRotations = []string{
 	basicScheduleRotationWeekly,
 	basicScheduleRotationBiWeekly,
 }
 
+var populationStatuses = []string{
+	populationStatusEmpty,
+	populationStatusBehind,
+	populationStatusPopulated,
+}
+
 var schedulingAlgorithms = []string{
 	schedulingAlgorithmDefault,
 	schedulingAlgorithmRoundRobin,
@@ -73,6 +125,23 @@ func resourceBasicSchedule() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceBasicScheduleImport,
 		},
+		CustomizeDiff: customdiff.All(
+			scheduleRosterCustomizeDiff,
+			teamPrefixCustomizeDiff(scheduleFieldTeam),
+			deferredPopulateCustomizeDiff(scheduleFieldRosterID),
+			resourceBasicScheduleCustomizeDiff,
+			offsetWeeksCustomizeDiff,
+			scheduleAdvancedModeCustomizeDiff(false),
+			scheduleSelfEscalationCustomizeDiff,
+			scheduleOverlapCustomizeDiff,
+			scheduleReferencesCustomizeDiff(scheduleFieldRosterID),
+			scheduleDefinitionCustomizeDiff(basicScheduleFromResource),
+			revisionCustomizeDiff(),
+			autoPopulateDaysCustomizeDiff,
+			populateSummaryCustomizeDiff(),
+			customdiff.ComputedIf(scheduleFieldCalendarURL, scheduleURLsChanged),
+			customdiff.ComputedIf(scheduleFieldICalURL, scheduleURLsChanged),
+		),
 
 		Schema: map[string]*schema.Schema{
 			scheduleFieldRole: {
@@ -83,30 +152,88 @@ func resourceBasicSchedule() *schema.Resource {
 				Description:      fmt.Sprintf("Name of the role, one of %v", roleNames),
 			},
 			scheduleFieldRosterID: {
-				Type:        schema.TypeString,
-				ForceNew:    false,
-				Required:    true,
-				Description: "Roster ID (in team/roster format) to map this schedule to",
+				Type:         schema.TypeString,
+				ForceNew:     false,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{scheduleFieldRosterID, scheduleFieldTeam},
+				Description:  fmt.Sprintf("Roster ID (in team/roster format) to map this schedule to, or set %s and %s instead", scheduleFieldTeam, scheduleFieldRoster),
+			},
+			scheduleFieldTeam: {
+				Type:          schema.TypeString,
+				ForceNew:      false,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{scheduleFieldRosterID},
+				RequiredWith:  []string{scheduleFieldTeam, scheduleFieldRoster},
+				Description:   fmt.Sprintf("Name of the team of the roster to map this schedule to, an alternative to %s", scheduleFieldRosterID),
+			},
+			scheduleFieldRoster: {
+				Type:          schema.TypeString,
+				ForceNew:      false,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{scheduleFieldRosterID},
+				RequiredWith:  []string{scheduleFieldTeam, scheduleFieldRoster},
+				Description:   fmt.Sprintf("Name of the roster to map this schedule to, an alternative to %s", scheduleFieldRosterID),
 			},
 			scheduleFieldAutoPopulateDays: {
-				Type:        schema.TypeInt,
-				Optional:    true,
-				Default:     21,
-				Description: "How many days in advance to plan the schedule",
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          21,
+				DiffSuppressFunc: suppressRoundedAutoPopulateDays,
+				ValidateDiagFunc: validateAutoPopulateDays,
+				Description:      "How many days in advance to plan the schedule. Oncall rounds this up to a whole number of weeks. " + autoPopulateDaysDescription(defaultMaxAutoPopulateDays),
 			},
 			scheduleFieldStartDayOfWeek: {
 				Type:             schema.TypeString,
 				ForceNew:         false,
-				Required:         true,
+				Optional:         true,
+				Computed:         true,
+				ExactlyOneOf:     []string{scheduleFieldStartDayOfWeek, basicScheduleFieldHandoff},
+				RequiredWith:     []string{scheduleFieldStartTime},
 				ValidateDiagFunc: validateStringSliceContains(daysOfWeek),
-				Description:      fmt.Sprintf("Day of week to start the schedule one, one of: %v", daysOfWeek),
+				Description:      fmt.Sprintf("Day of week to start the schedule one, one of: %v. Computed when using handoff", daysOfWeek),
 			},
 			scheduleFieldStartTime: {
 				Type:             schema.TypeString,
 				ForceNew:         false,
 				ValidateDiagFunc: validate24HourTime,
-				Required:         true,
-				Description:      "Start time of schedule in 24 hour time format, e.g. 13:15 for 1:15pm",
+				StateFunc:        timeOfDayStateFunc,
+				Optional:         true,
+				Computed:         true,
+				RequiredWith:     []string{scheduleFieldStartDayOfWeek},
+				Description:      "Start time of schedule in 24 hour time format, e.g. 13:15 for 1:15pm, or 13:15:30 to the second. Computed when using handoff",
+			},
+			basicScheduleFieldHandoff: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				MaxItems:    1,
+				Description: "When the rotation hands off, as an alternative to start_day_of_week and start_time that can keep handoffs off the weekend",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						handoffFieldDayOfWeek: {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validateStringSliceContains(daysOfWeek),
+							Description:      fmt.Sprintf("Day of week to hand off on, one of: %v", daysOfWeek),
+						},
+						handoffFieldTime: {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validate24HourTime,
+							StateFunc:        timeOfDayStateFunc,
+							Description:      "Time to hand off at in 24 hour time format, e.g. 17:00 or 17:00:30",
+						},
+						handoffFieldBusinessDayAdjustment: {
+							Type:             schema.TypeString,
+							Optional:         true,
+							Default:          businessDayAdjustmentPrevious,
+							ValidateDiagFunc: validateStringSliceContains(businessDayAdjustments),
+							Description:      fmt.Sprintf("Where to move the handoff if day_of_week is on the weekend, one of: %v. With previous, a Sunday handoff happens on the last business day of the week (Friday)", businessDayAdjustments),
+						},
+					},
+				},
 			},
 			basicScheduleFieldRotateFrequency: {
 				Type:             schema.TypeString,
@@ -116,6 +243,14 @@ func resourceBasicSchedule() *schema.Resource {
 				ValidateDiagFunc: validateStringSliceContains(basicScheduleRotations),
 				Description:      fmt.Sprintf("Rotation frequency, one of: %v", basicScheduleRotations),
 			},
+			basicScheduleFieldRotateEveryWeeks: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          0,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IntBetween(0, maxRotateEveryWeeks)),
+				Description:      fmt.Sprintf("Rotate every this many weeks, e.g. 3 for a tri-weekly rotation, instead of rotate_frequency. At most %d, 0 uses rotate_frequency", maxRotateEveryWeeks),
+			},
+			basicScheduleFieldOffsetWeeks: offsetWeeksSchema(),
 			scheduleFieldSchedulingAlgorithim: {
 				Type:             schema.TypeString,
 				Optional:         true,
@@ -123,13 +258,112 @@ func resourceBasicSchedule() *schema.Resource {
 				ValidateDiagFunc: validateStringSliceContains(schedulingAlgorithms),
 				Description:      fmt.Sprintf("Scheduling algorithim to use, one of: %v", schedulingAlgorithms),
 			},
+			scheduleFieldNextRotationAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the schedule next hands off to the next person (RFC 3339), from the populated calendar. Empty if nothing upcoming has been populated",
+			},
+			scheduleFieldAllowEmptyRoster: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow the schedule to be created or updated while its roster has nobody in rotation, which oncall populates as an empty calendar",
+			},
+			scheduleFieldTrimFutureEvents: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When auto_populate_days is lowered, delete the events this schedule already populated that start past the new horizon instead of leaving them on the calendar with a warning",
+			},
+			scheduleFieldDryRunPopulate: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Instead of populating the calendar when the schedule is updated, preview who would be scheduled and report it as a warning",
+			},
+			scheduleFieldExcludeMembers: excludeMembersSchema(),
+			scheduleFieldDescription:    scheduleDescriptionSchema(),
+			basicScheduleFieldForceOverwrite: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When the schedule has been changed in oncall into one a basic schedule can't represent, e.g. a second event added in the UI, plan overwriting it with this configuration instead of failing to read it",
+			},
+			scheduleFieldDeferPopulate: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When the roster has nobody in rotation, e.g. because it is created in the same apply, warn and hold off populating the calendar instead of failing. The next plan after the roster gets members updates the schedule to populate it",
+			},
+			scheduleFieldPopulatePending: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether populating the calendar was deferred as the roster had nobody in rotation",
+			},
+			scheduleFieldAdvancedMode: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether the schedule is in advanced mode in oncall. Always false once applied, a schedule imported from advanced mode is converted to basic mode by the next apply",
+			},
+			scheduleFieldRespectHolidayCalendar: {
+				Type:        schema.TypeString,
+				Optional:    true,
+				Description: "Experimental. Name of an oncall_holiday_calendar of the team, handoffs that fall on one of its holidays are moved to the next day when the provider populates the calendar",
+			},
+			scheduleFieldPopulatedThrough: populatedThroughSchema(),
+			scheduleFieldAssignments:      assignmentsSchema(),
+			scheduleFieldScheduledUntil: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "How far into the future the calendar is populated for this schedule (RFC 3339). Empty if nothing upcoming has been populated",
+			},
+			scheduleFieldCalendarURL: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the calendar of the schedule's team in the oncall UI",
+			},
+			scheduleFieldICalURL: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the iCal feed of the team's on call events for the schedule's role",
+			},
+			fieldRevision: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the schedule as read from oncall, which changes whenever it is changed, including outside of terraform e.g. in the oncall UI. For replace_triggered_by and postconditions to react to such changes",
+			},
+			scheduleFieldScheduleID: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Numeric ID of the schedule in oncall, used to update and delete it even if its role is renamed",
+			},
+			scheduleFieldLastEpochScheduled: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Unix time up to which oncall's scheduler has scheduled this schedule",
+			},
+			scheduleFieldChangeSummary: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Planned when the schedule's normalized definition changes, describing what changed for people reviewing the plan, e.g. handoff moved from Mon 09:00 to Tue 10:00. Only meaningful in plans that change the schedule",
+			},
+			scheduleFieldNormalizedDefinitionJSON: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "JSON of the schedule's role, roster, scheduling settings and events sorted by start, in the same shape for basic and advanced schedules, for policy as code to check",
+			},
+			scheduleFieldPopulationStatus: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: fmt.Sprintf("Whether the calendar is populated as far ahead as auto_populate_days asks, one of: %v. Behind means it covers over a week less than asked for", populationStatuses),
+			},
 		},
 	}
 }
 
 func resourceBasicScheduleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	diags := diag.Diagnostics{}
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	rosterID := d.Get(scheduleFieldRosterID).(string)
 	teamName, rosterName, err := parseRosterID(rosterID)
@@ -139,34 +373,49 @@ func resourceBasicScheduleCreate(ctx context.Context, d *schema.ResourceData, m
 	scheduleName := d.Get(scheduleFieldRole).(string)
 
 	traceLog("Going to create roster schedule: %s/%s/%s", teamName, rosterName, scheduleName)
-	sched, err := basicScheduleFromResource(d)
+	sched, err := basicScheduleFromResource(d, weekStartFor(m))
 	if err != nil {
 		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
 	}
 
+	deferred, diags := checkRosterNotEmpty(c, d, scheduleFieldRosterID, teamName, rosterName)
+	if diags.HasError() {
+		return diags
+	}
+
 	resourceID := getScheduleID(teamName, rosterName, scheduleName)
 	err = c.AddRosterSchedule(teamName, rosterName, sched)
 	if err != nil {
-		if strings.Contains(err.Error(), "(422)") {
-			return diagFromErrf(err, "Roster schedule already exists, please import using id '%s", resourceID)
-		}
-		return diagFromErrf(err, "Creating oncall roster")
+		return createErrorDiags(err, "Creating oncall roster schedule", resourceID, scheduleFieldRole, scheduleFieldRosterID, scheduleFieldAutoPopulateDays, scheduleFieldSchedulingAlgorithim)
 	}
 
 	d.SetId(resourceID)
+	d.Set(scheduleFieldPopulatePending, deferred)
+	diags = append(diags, updateScheduleDescription(ctx, d, m, teamName, rosterName)...)
+
+	// Left to oncall, the rotation would start on whichever week it populates
+	// the new schedule, so populate it on the configured parity right away
+	if _, ok := d.GetOkExists(basicScheduleFieldOffsetWeeks); ok && !deferred {
+		resourceBasicScheduleRead(ctx, d, m)
+		diags = append(diags, populateOrPreview(ctx, c, d, teamName, rosterName, scheduleName, basicSchedulePopulateStart(c, d, teamName, weekStartFor(m)))...)
+		if diags.HasError() {
+			return diags
+		}
+	}
 	resourceBasicScheduleRead(ctx, d, m)
 	return diags
 }
 
 func resourceBasicScheduleImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
-	teamName, rosterName, scheduleName, err := parseScheduleID(d.Id())
+	teamName, rosterName, scheduleName, err := canonicalScheduleImportID(ctx, d.Id(), m)
 	if err != nil {
-		return nil, errors.Wrap(err, "Parsing roster ID, this is an internal error")
+		return nil, errors.Wrap(err, "Parsing import ID")
 	}
+	d.SetId(getScheduleID(teamName, rosterName, scheduleName))
 
 	rosterID := getRosterID(teamName, rosterName)
 
-	traceLog("Going to import roster schedule %q as team: %s, roster: %s, role: ", d.Id(), teamName, rosterName, scheduleName)
+	traceLog("Going to import roster schedule %q as team: %s, roster: %s, role: %s", d.Id(), teamName, rosterName, scheduleName)
 	d.Set(scheduleFieldRole, scheduleName)
 	d.Set(scheduleFieldRosterID, rosterID)
 
@@ -174,11 +423,14 @@ func resourceBasicScheduleImport(ctx context.Context, d *schema.ResourceData, m
 	if len(readErr) > 0 {
 		err = errors.New(readErr[0].Summary)
 	}
+	if err == nil && d.Id() == "" {
+		err = fmt.Errorf("Roster schedule %s does not exist", getScheduleID(teamName, rosterName, scheduleName))
+	}
 	return []*schema.ResourceData{d}, errors.Wrap(err, "Reading resource for import")
 }
 
 func resourceBasicScheduleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
@@ -188,61 +440,267 @@ func resourceBasicScheduleRead(ctx context.Context, d *schema.ResourceData, m in
 		return diagFromErrf(err, "Parsing roster ID, this is an internal error")
 	}
 
-	schedule, err := c.GetRosterSchedule(teamName, rosterName, scheduleName)
+	schedule, found, err := getRosterScheduleByID(c, teamName, rosterName, d.Get(scheduleFieldScheduleID).(int), scheduleName)
 	if err != nil {
 		return diagFromErrf(err, "Getting roster schedule %s/%s/%s", teamName, rosterName, scheduleName)
 	}
+	if !found {
+		warnLog("Roster schedule %s/%s/%s no longer exists, removing it from state", teamName, rosterName, scheduleName)
+		d.SetId("")
+		return diags
+	}
+
+	err = checkBasicSchedule(schedule.Schedule)
+	if err != nil && schedule.AdvancedMode != 0 {
+		// An advanced schedule with a single event of whole weeks, e.g. one
+		// imported to convert it from oncall_advanced_schedule, is converted
+		// back to basic mode on the next apply
+		basic := schedule.Schedule
+		basic.AdvancedMode = 0
+		if checkBasicSchedule(basic) == nil {
+			err = nil
+		}
+	}
+	notBasic := err
+	if notBasic != nil {
+		if !d.Get(basicScheduleFieldForceOverwrite).(bool) {
+			return notBasicScheduleDiags(d.Id(), schedule.Schedule, notBasic, weekStartFor(m), false)
+		}
+		diags = append(diags, notBasicScheduleDiags(d.Id(), schedule.Schedule, notBasic, weekStartFor(m), true)...)
+	}
 
+	// The role may have been renamed since the schedule was created, the
+	// numeric ID is what identifies it so keep the resource ID in step with it
+	d.SetId(getScheduleID(teamName, rosterName, schedule.Role))
+	d.Set(scheduleFieldScheduleID, schedule.ID)
+	d.Set(fieldRevision, scheduleRevision(schedule.Schedule))
+	d.Set(scheduleFieldCalendarURL, teamCalendarURL(c.Config.Endpoint, teamName))
+	d.Set(scheduleFieldICalURL, teamICalURL(c.Config.Endpoint, teamName, schedule.Role))
 	d.Set(scheduleFieldRole, schedule.Role)
+	diags = append(diags, checkReadRole(m, d.Id(), schedule.Role)...)
 	d.Set(scheduleFieldRosterID, getRosterID(teamName, rosterName))
+	d.Set(scheduleFieldTeam, teamName)
+	d.Set(scheduleFieldRoster, rosterName)
 	d.Set(scheduleFieldAutoPopulateDays, schedule.AutoPopulateThreshold)
 	d.Set(scheduleFieldSchedulingAlgorithim, schedule.Scheduler.Name)
-
-	if len(schedule.Events) != 1 {
-		return diag.Errorf("The schedule you are reading is not a basic schedule as it does not have exactly one event")
+	d.Set(scheduleFieldAdvancedMode, schedule.AdvancedMode != 0)
+	readScheduleCalendar(c, d, teamName, schedule)
+	readScheduleDescription(c, d, teamName, rosterName, schedule.Role)
+
+	if notBasic != nil {
+		// The rest of the attributes can't describe the schedule, so keep
+		// them as configured and read the definition from oncall instead,
+		// which plans an update overwriting it with the basic shape
+		def, err := normalizedScheduleDefinition(schedule.Schedule, weekStartFor(m))
+		if err != nil {
+			return append(diags, diagFromErrf(err, "Reading definition of roster schedule %s/%s/%s", teamName, rosterName, scheduleName)...)
+		}
+		d.Set(scheduleFieldNormalizedDefinitionJSON, def)
+		return diags
 	}
 
-	d.Set(basicScheduleFieldRotateFrequency, basicScheduleRotationWeekly)
-	if schedule.Events[0].Duration == int(duration.Fortnight.Seconds()) {
-		d.Set(basicScheduleFieldRotateFrequency, basicScheduleRotationBiWeekly)
+	// Rotations of one or two weeks are read back as rotate_frequency, unless
+	// rotate_every_weeks is what the configuration uses for them
+	weeks := schedule.Events[0].Duration / weekSeconds
+	if weeks > 2 || d.Get(basicScheduleFieldRotateEveryWeeks).(int) != 0 {
+		d.Set(basicScheduleFieldRotateEveryWeeks, weeks)
+		if d.Get(basicScheduleFieldRotateFrequency).(string) == "" {
+			d.Set(basicScheduleFieldRotateFrequency, basicScheduleRotationWeekly)
+		}
+	} else {
+		d.Set(basicScheduleFieldRotateFrequency, basicScheduleRotationWeekly)
+		if weeks == 2 {
+			d.Set(basicScheduleFieldRotateFrequency, basicScheduleRotationBiWeekly)
+		}
 	}
 
-	dayOfWeekIndex, startHour, startMin := secondsToDayHourMinute(schedule.Events[0].Start)
+	dayOfWeekIndex, startHour, startMin, startSec := secondsToDayTime(schedule.Events[0].Start, weekStartFor(m))
 	d.Set(scheduleFieldStartDayOfWeek, daysOfWeek[dayOfWeekIndex])
-	d.Set(scheduleFieldStartTime, fmt.Sprintf("%02d:%02d", startHour, startMin))
+	d.Set(scheduleFieldStartTime, formatTimeOfDay(startHour, startMin, startSec))
 
+	if handoff, ok := handoffFromResource(d); ok {
+		day, startTime, err := resolveHandoff(handoff)
+		if err != nil || day != daysOfWeek[dayOfWeekIndex] || startTime != d.Get(scheduleFieldStartTime).(string) {
+			diags = append(diags, normalizationDiags(m, "Schedule %s/%s/%s no longer matches its handoff", teamName, rosterName, scheduleName)...)
+			d.Set(basicScheduleFieldHandoff, []interface{}{})
+		}
+	}
+
+	diags = append(diags, readScheduleDefinition(d, basicScheduleFromResource, m)...)
 	return diags
 }
 
+func scheduleURLsChanged(ctx context.Context, d *schema.ResourceDiff, m interface{}) bool {
+	return d.HasChange(scheduleFieldRosterID) || d.HasChange(scheduleFieldRole)
+}
+
+// notBasicScheduleDiags explains that the schedule was changed in oncall into
+// one a basic schedule can't represent, listing its events and the ways out
+func notBasicScheduleDiags(id string, schedule oncall.Schedule, reason error, weekStart time.Weekday, overwrite bool) diag.Diagnostics {
+	events := append([]oncall.ScheduleEvent{}, schedule.Events...)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start < events[j].Start })
+	layout := strings.Builder{}
+	for _, e := range events {
+		dayOfWeekIndex, startHour, startMin, startSec := secondsToDayTime(e.Start, weekStart)
+		layout.WriteString(fmt.Sprintf("  %s %s for %s\n", daysOfWeek[dayOfWeekIndex], formatTimeOfDay(startHour, startMin, startSec), prettyPrintDuration(e.Duration)))
+	}
+	if len(events) == 0 {
+		layout.WriteString("  no events\n")
+	}
+
+	if overwrite {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       fmt.Sprintf("Roster schedule %s is no longer a basic schedule, the next apply overwrites it", id),
+				Detail:        fmt.Sprintf("%s. It has the events:\n%s\n%s is set, so applying puts the schedule back to the single event this resource configures.", reason, layout.String(), basicScheduleFieldForceOverwrite),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: basicScheduleFieldForceOverwrite}},
+			},
+		}
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Roster schedule %s is no longer a basic schedule", id),
+			Detail: fmt.Sprintf("%s, e.g. after it was edited in the oncall UI. It has the events:\n%s\n"+
+				"To keep these events, remove this resource from state and import the schedule as an oncall_advanced_schedule with ID %s. "+
+				"To put the schedule back to the single event this resource configures, set %s = true and apply.", reason, layout.String(), id, basicScheduleFieldForceOverwrite),
+		},
+	}
+}
+
+// checkBasicSchedule makes sure a schedule can be represented by a basic
+// schedule, it may have been changed to an advanced one in the oncall UI
+func checkBasicSchedule(schedule oncall.Schedule) error {
+	if schedule.AdvancedMode != 0 {
+		return errors.New("The schedule has been converted to advanced mode, manage it with oncall_advanced_schedule instead")
+	}
+	if len(schedule.Events) != 1 {
+		return fmt.Errorf("The schedule is not a basic schedule as it has %d events instead of exactly one", len(schedule.Events))
+	}
+	eventDuration := schedule.Events[0].Duration
+	if eventDuration <= 0 || eventDuration%weekSeconds != 0 || eventDuration/weekSeconds > maxRotateEveryWeeks {
+		return fmt.Errorf("The schedule is not a basic schedule as its event lasts %s instead of a whole number of weeks", prettyPrintDuration(eventDuration))
+	}
+	return nil
+}
+
+func resourceBasicScheduleCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	handoffRaw := d.Get(basicScheduleFieldHandoff).([]interface{})
+	if len(handoffRaw) == 0 || handoffRaw[0] == nil || !d.HasChange(basicScheduleFieldHandoff) {
+		return nil
+	}
+
+	day, startTime, err := resolveHandoff(handoffRaw[0].(map[string]interface{}))
+	if err != nil {
+		return errors.Wrap(err, "Invalid handoff")
+	}
+	err = d.SetNew(scheduleFieldStartDayOfWeek, day)
+	if err != nil {
+		return err
+	}
+	return d.SetNew(scheduleFieldStartTime, startTime)
+}
+
+func handoffFromResource(d *schema.ResourceData) (map[string]interface{}, bool) {
+	handoffRaw := d.Get(basicScheduleFieldHandoff).([]interface{})
+	if len(handoffRaw) == 0 || handoffRaw[0] == nil {
+		return nil, false
+	}
+	return handoffRaw[0].(map[string]interface{}), true
+}
+
+// resolveHandoff turns a handoff block into the start day and time of the
+// schedule, moving it off the weekend according to its business day adjustment
+func resolveHandoff(handoff map[string]interface{}) (day, startTime string, err error) {
+	day = handoff[handoffFieldDayOfWeek].(string)
+	dayIndex := -1
+	for i, d := range daysOfWeek {
+		if strings.EqualFold(d, day) {
+			dayIndex = i
+			break
+		}
+	}
+	if dayIndex == -1 {
+		return "", "", fmt.Errorf("%q is not a valid day name", day)
+	}
+
+	hour, min, sec, err := parseTimeOfDay(handoff[handoffFieldTime].(string))
+	if err != nil {
+		return "", "", err
+	}
+	startTime = formatTimeOfDay(hour, min, sec)
+
+	// daysOfWeek starts on Sunday, so Saturday is 6 and Sunday is 0
+	switch handoff[handoffFieldBusinessDayAdjustment].(string) {
+	case businessDayAdjustmentPrevious:
+		if dayIndex == 6 || dayIndex == 0 {
+			dayIndex = 5
+		}
+	case businessDayAdjustmentNext:
+		if dayIndex == 6 || dayIndex == 0 {
+			dayIndex = 1
+		}
+	}
+	return daysOfWeek[dayIndex], startTime, nil
+}
+
 func resourceBasicScheduleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	traceLog("Going to update schedule %q", d.Id())
 	teamName, rosterName, schedulename, err := parseScheduleID(d.Id())
 	if err != nil {
 		return diagFromErrf(err, "Parsing roster schedule ID, this is an internal error")
 	}
+	teamName, rosterName, err = followMigratedSchedule(c, d, teamName, rosterName, schedulename)
+	if err != nil {
+		return diagFromErrf(err, "Finding schedule %s on its roster's new team", d.Id())
+	}
 
 	traceLog("Going to update roster schedule %s/%s/%s", teamName, rosterName, schedulename)
-	sched, err := basicScheduleFromResource(d)
+	sched, err := basicScheduleFromResource(d, weekStartFor(m))
 	if err != nil {
 		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
 	}
 
-	err = c.UpdateRosterSchedule(teamName, rosterName, sched.Role, sched)
+	deferred, diags := checkRosterNotEmpty(c, d, scheduleFieldRosterID, teamName, rosterName)
+	if diags.HasError() {
+		return diags
+	}
+
+	err = updateRosterSchedule(c, d.Get(scheduleFieldScheduleID).(int), teamName, rosterName, schedulename, sched)
 	if err != nil {
 		return diagFromErrf(err, "Updating oncall roster schedule")
 	}
-	err = c.PopulateRosterSchedule(teamName, rosterName, sched.Role, time.Now())
-	if err != nil {
-		return diagFromErrf(err, "Populating oncall roster schedule")
+	d.Set(scheduleFieldPopulatePending, deferred)
+	if !deferred {
+		populateStart := time.Now()
+		diags = append(diags, populateOrPreview(ctx, c, d, teamName, rosterName, sched.Role, basicSchedulePopulateStart(c, d, teamName, weekStartFor(m)))...)
+		if diags.HasError() {
+			return diags
+		}
+		if !d.Get(scheduleFieldDryRunPopulate).(bool) {
+			diags = append(diags, recordPopulateSummary(c, d, teamName, sched.Role, populateStart)...)
+		}
+	}
+
+	diags = append(diags, updateScheduleDescription(ctx, d, m, teamName, rosterName)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	trim := d.Get(scheduleFieldTrimFutureEvents).(bool) && !d.Get(scheduleFieldDryRunPopulate).(bool)
+	diags = append(diags, shrinkPopulateHorizon(c, d, teamName, sched.Role, d.Get(scheduleFieldScheduleID).(int), trim)...)
+	if diags.HasError() {
+		return diags
 	}
 
-	return resourceBasicScheduleRead(ctx, d, m)
+	return append(diags, resourceBasicScheduleRead(ctx, d, m)...)
 }
 
 func resourceBasicScheduleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	traceLog("Going to update roster %q", d.Id())
 	teamName, rosterName, scheduleName, err := parseScheduleID(d.Id())
@@ -250,8 +708,14 @@ func resourceBasicScheduleDelete(ctx context.Context, d *schema.ResourceData, m
 		return diagFromErrf(err, "Parsing roster schedule ID, this is an internal error")
 	}
 
+	// Removed first, so a failure leaves the schedule in state to try again
+	diags := removeScheduleDescription(ctx, d, m, teamName, rosterName, scheduleName)
+	if diags.HasError() {
+		return diags
+	}
+
 	traceLog("Going to delete roster schedule %s/%s/%s", teamName, rosterName, scheduleName)
-	err = c.RemoveRosterSchedule(teamName, rosterName, scheduleName)
+	err = removeRosterSchedule(ctx, c, d.Get(scheduleFieldScheduleID).(int), teamName, rosterName, scheduleName)
 	if err != nil {
 		return diagFromErrf(err, "Removing roster %s/%s/%s", teamName, rosterName, scheduleName)
 	}
@@ -260,17 +724,17 @@ func resourceBasicScheduleDelete(ctx context.Context, d *schema.ResourceData, m
 	// it is added here for explicitness.
 	d.SetId("")
 
-	return diag.Diagnostics{}
+	return diags
 }
 
 func getScheduleID(team, roster, role string) string {
-	return fmt.Sprintf("%s/%s/%s", team, roster, role)
+	return fmt.Sprintf("%s/%s/%s", escapeIDPart(team), escapeIDPart(roster), escapeIDPart(role))
 }
 
 func parseScheduleID(basicScheduleID string) (team, roster, role string, err error) {
 	tr := strings.Split(basicScheduleID, "/")
 	if len(tr) == 3 {
-		team, roster, role = tr[0], tr[1], tr[2]
+		team, roster, role = unescapeIDPart(tr[0]), unescapeIDPart(tr[1]), unescapeIDPart(tr[2])
 	} else {
 		err = errors.New("Unparseable roster schedule id (should be team/roster/role)")
 	}
@@ -281,44 +745,105 @@ func parseScheduleID(basicScheduleID string) (team, roster, role string, err err
 	return
 }
 
-func validate24HourTime(in interface{}, path cty.Path) diag.Diagnostics {
-	_, _, err := parseHourMinStr(in.(string))
-	if err != nil {
-		return diagFromErrf(err, "Invalid HH:MM entry")
+// parseScheduleImportID parses the ID a schedule is imported with, which is
+// either its team/roster/role ID or the same as attributes, e.g.
+// team=platform,roster=sre,role=primary, which is easier to build in import
+// blocks. Resource identity would do this properly, but needs a newer plugin SDK.
+func parseScheduleImportID(importID string) (team, roster, role string, err error) {
+	if !strings.Contains(importID, "=") {
+		return parseScheduleID(importID)
 	}
 
-	return nil
+	for _, pair := range strings.Split(importID, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			return "", "", "", fmt.Errorf("Unparseable roster schedule import id attribute %q (should be name=value)", pair)
+		}
+		switch kv[0] {
+		case scheduleFieldTeam:
+			team = kv[1]
+		case scheduleFieldRoster:
+			roster = kv[1]
+		case scheduleFieldRole:
+			role = kv[1]
+		default:
+			return "", "", "", fmt.Errorf("Unknown roster schedule import id attribute %q, should be one of %s, %s, %s", kv[0], scheduleFieldTeam, scheduleFieldRoster, scheduleFieldRole)
+		}
+	}
+	return parseScheduleID(getScheduleID(team, roster, role))
 }
 
-func parseHourMinStr(hourMin string) (hours, minutes int, err error) {
-	splitTime := strings.Split(hourMin, ":")
-	if len(splitTime) != 2 {
-		err = fmt.Errorf("Provided time must be in 24 hour format: HH:MM")
-		return
+// suppressRoundedAutoPopulateDays ignores the difference between the configured
+// auto_populate_days and the value oncall stores, which is rounded up to whole weeks
+func suppressRoundedAutoPopulateDays(k, old, new string, d *schema.ResourceData) bool {
+	oldDays, err := strconv.Atoi(old)
+	if err != nil {
+		return false
 	}
-
-	hourString := strings.TrimLeft(splitTime[0], "0")
-	if hourString == "" {
-		hourString = "0"
+	newDays, err := strconv.Atoi(new)
+	if err != nil {
+		return false
 	}
+	return oldDays == roundUpToWeek(newDays)
+}
 
-	minString := strings.TrimLeft(splitTime[1], "0")
-	if minString == "" {
-		minString = "0"
+func roundUpToWeek(days int) int {
+	if days%7 == 0 {
+		return days
 	}
+	return days + 7 - days%7
+}
 
-	hours, err = strconv.Atoi(hourString)
+func validate24HourTime(in interface{}, path cty.Path) diag.Diagnostics {
+	_, _, _, err := parseTimeOfDay(in.(string))
 	if err != nil {
-		err = errors.Wrap(err, "The part of your time before the colon is not a number")
-		return
+		return diagFromErrf(err, "Invalid HH:MM or HH:MM:SS entry")
 	}
 
-	minutes, err = strconv.Atoi(minString)
+	return nil
+}
+
+// timeOfDayStateFunc stores times of day in their canonical form, so 9:30 and
+// 09:30:00 are both kept as 09:30 and don't show up as changes
+func timeOfDayStateFunc(val interface{}) string {
+	hours, minutes, seconds, err := parseTimeOfDay(val.(string))
 	if err != nil {
-		err = errors.Wrap(err, "The part of your time after the colon is not a number")
+		return val.(string)
+	}
+	return formatTimeOfDay(hours, minutes, seconds)
+}
+
+// formatTimeOfDay formats a time as HH:MM, or HH:MM:SS when it isn't on the minute
+func formatTimeOfDay(hours, minutes, seconds int) string {
+	if seconds == 0 {
+		return fmt.Sprintf("%02d:%02d", hours, minutes)
+	}
+	return fmt.Sprintf("%02d:%02d:%02d", hours, minutes, seconds)
+}
+
+// parseTimeOfDay reads a 24 hour time in HH:MM or HH:MM:SS format
+func parseTimeOfDay(timeOfDay string) (hours, minutes, seconds int, err error) {
+	splitTime := strings.Split(timeOfDay, ":")
+	if len(splitTime) != 2 && len(splitTime) != 3 {
+		err = fmt.Errorf("Provided time must be in 24 hour format: HH:MM or HH:MM:SS")
 		return
 	}
 
+	parts := [3]int{}
+	names := [3]string{"hours", "minutes", "seconds"}
+	for i, part := range splitTime {
+		partString := strings.TrimLeft(part, "0")
+		if partString == "" {
+			partString = "0"
+		}
+		parts[i], err = strconv.Atoi(partString)
+		if err != nil {
+			err = errors.Wrapf(err, "The %s part of your time is not a number", names[i])
+			return
+		}
+	}
+	hours, minutes, seconds = parts[0], parts[1], parts[2]
+
 	if hours < 0 || hours >= 24 {
 		err = fmt.Errorf("Your provided hours must be 0 - 23")
 		return
@@ -329,16 +854,22 @@ func parseHourMinStr(hourMin string) (hours, minutes int, err error) {
 		return
 	}
 
+	if seconds < 0 || seconds >= 60 {
+		err = fmt.Errorf("Your provided seconds must be 0 - 59")
+		return
+	}
+
 	return
 }
 
-func basicScheduleFromResource(d *schema.ResourceData) (oncall.Schedule, error) {
+func basicScheduleFromResource(d resourceGetter, weekStart time.Weekday) (oncall.Schedule, error) {
 	role := d.Get(scheduleFieldRole).(string)
 	rosterID := d.Get(scheduleFieldRosterID).(string)
 	autoPopulateDays := d.Get(scheduleFieldAutoPopulateDays).(int)
 	startDayOfWeek := d.Get(scheduleFieldStartDayOfWeek).(string)
 	startTime := d.Get(scheduleFieldStartTime).(string)
 	rotateFrequency := d.Get(basicScheduleFieldRotateFrequency).(string)
+	rotateEveryWeeks := d.Get(basicScheduleFieldRotateEveryWeeks).(int)
 	schedulingAlgorithim := d.Get(scheduleFieldSchedulingAlgorithim).(string)
 
 	sched := oncall.Schedule{
@@ -361,8 +892,11 @@ func basicScheduleFromResource(d *schema.ResourceData) (oncall.Schedule, error)
 	if rotateFrequency == basicScheduleRotationBiWeekly {
 		dur = duration.Fortnight
 	}
+	if rotateEveryWeeks > 0 {
+		dur = duration.Duration(rotateEveryWeeks) * duration.Week
+	}
 
-	startSeconds, err := weekdayStartTimeToSeconds(startDayOfWeek, startTime)
+	startSeconds, err := weekdayStartTimeToSeconds(startDayOfWeek, startTime, weekStart)
 	if err != nil {
 		return sched, errors.Wrapf(err, "Parsing start weekday and time")
 	}
@@ -376,19 +910,24 @@ func basicScheduleFromResource(d *schema.ResourceData) (oncall.Schedule, error)
 	return sched, nil
 }
 
-func secondsToDayHourMinute(seconds int) (days, hours, minutes int) {
-	days = int(math.Floor(float64(seconds / int(duration.Day.Seconds()))))
+// secondsToDayTime splits seconds from the start of a week starting on
+// weekStart into the day, as an index of daysOfWeek, hour, minute and second
+func secondsToDayTime(seconds int, weekStart time.Weekday) (days, hours, minutes, secs int) {
+	days = (seconds/daySeconds + int(weekStart)) % len(daysOfWeek)
 
-	timeInDay := seconds % int(duration.Day.Seconds())
-	hours = int(math.Floor(float64(timeInDay / int(duration.Hour.Seconds()))))
-	minutes = int(math.Floor(float64(timeInDay % int(duration.Hour.Seconds()) / int(duration.Minute.Seconds()))))
+	timeInDay := seconds % daySeconds
+	hours = timeInDay / hourSeconds
+	minutes = timeInDay % hourSeconds / minuteSeconds
+	secs = timeInDay % minuteSeconds
 	return
 }
 
-func weekdayStartTimeToSeconds(weekday, startTime string) (seconds int, err error) {
-	hour, min, err := parseHourMinStr(startTime)
+// weekdayStartTimeToSeconds is how many seconds a day and HH:MM[:SS] time are
+// from the start of a week starting on weekStart
+func weekdayStartTimeToSeconds(weekday, startTime string, weekStart time.Weekday) (seconds int, err error) {
+	hour, min, sec, err := parseTimeOfDay(startTime)
 	if err != nil {
-		return -1, errors.Wrapf(err, "Failed to parse HH:MM input of %q", startTime)
+		return -1, errors.Wrapf(err, "Failed to parse HH:MM[:SS] input of %q", startTime)
 	}
 
 	numDays := -1
@@ -401,8 +940,10 @@ func weekdayStartTimeToSeconds(weekday, startTime string) (seconds int, err erro
 	if numDays == -1 {
 		return -1, fmt.Errorf("You did not specify a valid day name")
 	}
+	numDays = (numDays - int(weekStart) + len(daysOfWeek)) % len(daysOfWeek)
 
 	return (numDays*int(duration.Day.Seconds()) +
 		hour*int(duration.Hour.Seconds()) +
-		min*int(duration.Minute.Seconds())), nil
+		min*int(duration.Minute.Seconds()) +
+		sec), nil
 }
@@ -1,18 +1,25 @@
 package oncall
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"maze.io/x/duration"
 )
 
-func Test_secondsToDayHourMinute(t *testing.T) {
+func Test_secondsToDayTime(t *testing.T) {
 	tests := []struct {
 		name        string
 		inSeconds   int
+		weekStart   time.Weekday
 		wantDays    int
 		wantHours   int
 		wantMinutes int
+		wantSeconds int
 	}{
 		{
 			name:        "Start of week",
@@ -42,27 +49,71 @@ func Test_secondsToDayHourMinute(t *testing.T) {
 			wantHours:   12,
 			wantMinutes: 31,
 		},
+		{
+			name:        "Last second of the week",
+			inSeconds:   7*int(duration.Day.Seconds()) - 1,
+			wantDays:    6,
+			wantHours:   23,
+			wantMinutes: 59,
+			wantSeconds: 59,
+		},
+		{
+			name:        "Start of an ISO week",
+			inSeconds:   9 * int(duration.Hour.Seconds()),
+			weekStart:   time.Monday,
+			wantDays:    1,
+			wantHours:   9,
+			wantMinutes: 0,
+		},
+		{
+			name:        "Last second of an ISO week",
+			inSeconds:   7*int(duration.Day.Seconds()) - 1,
+			weekStart:   time.Monday,
+			wantDays:    0,
+			wantHours:   23,
+			wantMinutes: 59,
+			wantSeconds: 59,
+		},
+		{
+			name:        "Seconds past the minute",
+			inSeconds:   2*int(duration.Hour.Seconds()) + 59,
+			wantDays:    0,
+			wantHours:   2,
+			wantMinutes: 0,
+			wantSeconds: 59,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotDays, gotHours, gotMinutes := secondsToDayHourMinute(tt.inSeconds)
+			gotDays, gotHours, gotMinutes, gotSeconds := secondsToDayTime(tt.inSeconds, tt.weekStart)
 			if gotDays != tt.wantDays {
-				t.Errorf("secondsToDayHourMinute() gotDays = %v, want %v", gotDays, tt.wantDays)
+				t.Errorf("secondsToDayTime() gotDays = %v, want %v", gotDays, tt.wantDays)
 			}
 			if gotHours != tt.wantHours {
-				t.Errorf("secondsToDayHourMinute() gotHours = %v, want %v", gotHours, tt.wantHours)
+				t.Errorf("secondsToDayTime() gotHours = %v, want %v", gotHours, tt.wantHours)
 			}
 			if gotMinutes != tt.wantMinutes {
-				t.Errorf("secondsToDayHourMinute() gotMinutes = %v, want %v", gotMinutes, tt.wantMinutes)
+				t.Errorf("secondsToDayTime() gotMinutes = %v, want %v", gotMinutes, tt.wantMinutes)
+			}
+			if gotSeconds != tt.wantSeconds {
+				t.Errorf("secondsToDayTime() gotSeconds = %v, want %v", gotSeconds, tt.wantSeconds)
 			}
 		})
 	}
 }
 
+func Benchmark_secondsToDayTime(b *testing.B) {
+	seconds := 3*daySeconds + 12*hourSeconds + 31*minuteSeconds
+	for i := 0; i < b.N; i++ {
+		secondsToDayTime(seconds, time.Sunday)
+	}
+}
+
 func Test_weekdayStartTimeToSeconds(t *testing.T) {
 	type args struct {
 		weekday   string
 		startTime string
+		weekStart time.Weekday
 	}
 	tests := []struct {
 		name        string
@@ -97,6 +148,44 @@ func Test_weekdayStartTimeToSeconds(t *testing.T) {
 			wantSeconds: 1*int(duration.Day.Seconds()) + 23*int(duration.Hour.Seconds()) + 58*int(duration.Minute.Seconds()),
 			wantErr:     false,
 		},
+		{
+			name: "Monday at the start of an ISO week",
+			args: args{
+				weekday:   "Monday",
+				startTime: "09:00",
+				weekStart: time.Monday,
+			},
+			wantSeconds: 9 * int(duration.Hour.Seconds()),
+			wantErr:     false,
+		},
+		{
+			name: "Sunday at the end of an ISO week",
+			args: args{
+				weekday:   "Sunday",
+				startTime: "23:59",
+				weekStart: time.Monday,
+			},
+			wantSeconds: 6*int(duration.Day.Seconds()) + 23*int(duration.Hour.Seconds()) + 59*int(duration.Minute.Seconds()),
+			wantErr:     false,
+		},
+		{
+			name: "Quarter past with seconds",
+			args: args{
+				weekday:   "Sunday",
+				startTime: "06:15:30",
+			},
+			wantSeconds: 6*int(duration.Hour.Seconds()) + 15*int(duration.Minute.Seconds()) + 30,
+			wantErr:     false,
+		},
+		{
+			name: "Test bad seconds",
+			args: args{
+				weekday:   "Sunday",
+				startTime: "06:15:60",
+			},
+			wantSeconds: -1,
+			wantErr:     true,
+		},
 		{
 			name: "Test bad time",
 			args: args{
@@ -127,7 +216,7 @@ func Test_weekdayStartTimeToSeconds(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotSeconds, err := weekdayStartTimeToSeconds(tt.args.weekday, tt.args.startTime)
+			gotSeconds, err := weekdayStartTimeToSeconds(tt.args.weekday, tt.args.startTime, tt.args.weekStart)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("startTimeWeekdayToSeconds() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -138,3 +227,315 @@ func Test_weekdayStartTimeToSeconds(t *testing.T) {
 		})
 	}
 }
+
+func Test_suppressRoundedAutoPopulateDays(t *testing.T) {
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want bool
+	}{
+		{
+			name: "Already a whole week",
+			old:  "21",
+			new:  "21",
+			want: true,
+		},
+		{
+			name: "Rounded up by the API",
+			old:  "14",
+			new:  "10",
+			want: true,
+		},
+		{
+			name: "Actually changed",
+			old:  "21",
+			new:  "10",
+			want: false,
+		},
+		{
+			name: "Not in state yet",
+			old:  "",
+			new:  "10",
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := suppressRoundedAutoPopulateDays("", tt.old, tt.new, nil); got != tt.want {
+				t.Errorf("suppressRoundedAutoPopulateDays() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resolveHandoff(t *testing.T) {
+	tests := []struct {
+		name          string
+		day           string
+		time          string
+		adjustment    string
+		wantDay       string
+		wantStartTime string
+		wantErr       bool
+	}{
+		{
+			name:          "Weekday is left alone",
+			day:           "Wednesday",
+			time:          "9:30",
+			adjustment:    businessDayAdjustmentPrevious,
+			wantDay:       "Wednesday",
+			wantStartTime: "09:30",
+		},
+		{
+			name:          "Sunday moves to the last business day",
+			day:           "Sunday",
+			time:          "17:00",
+			adjustment:    businessDayAdjustmentPrevious,
+			wantDay:       "Friday",
+			wantStartTime: "17:00",
+		},
+		{
+			name:          "Saturday moves to the next business day",
+			day:           "saturday",
+			time:          "09:00",
+			adjustment:    businessDayAdjustmentNext,
+			wantDay:       "Monday",
+			wantStartTime: "09:00",
+		},
+		{
+			name:          "No adjustment keeps the weekend",
+			day:           "Saturday",
+			time:          "09:00",
+			adjustment:    businessDayAdjustmentNone,
+			wantDay:       "Saturday",
+			wantStartTime: "09:00",
+		},
+		{
+			name:       "Bad time",
+			day:        "Monday",
+			time:       "25:00",
+			adjustment: businessDayAdjustmentNone,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDay, gotStartTime, err := resolveHandoff(map[string]interface{}{
+				handoffFieldDayOfWeek:             tt.day,
+				handoffFieldTime:                  tt.time,
+				handoffFieldBusinessDayAdjustment: tt.adjustment,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveHandoff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotDay != tt.wantDay || gotStartTime != tt.wantStartTime {
+				t.Errorf("resolveHandoff() = %s %s, want %s %s", gotDay, gotStartTime, tt.wantDay, tt.wantStartTime)
+			}
+		})
+	}
+}
+
+func Test_checkBasicSchedule(t *testing.T) {
+	week := int(duration.Week.Seconds())
+	tests := []struct {
+		name     string
+		schedule oncall.Schedule
+		wantErr  bool
+	}{
+		{
+			name:     "Weekly",
+			schedule: oncall.Schedule{Events: []oncall.ScheduleEvent{{Start: 0, Duration: week}}},
+		},
+		{
+			name:     "Bi-weekly",
+			schedule: oncall.Schedule{Events: []oncall.ScheduleEvent{{Start: 0, Duration: 2 * week}}},
+		},
+		{
+			name:     "Tri-weekly",
+			schedule: oncall.Schedule{Events: []oncall.ScheduleEvent{{Start: 0, Duration: 3 * week}}},
+		},
+		{
+			name:     "Ten days",
+			schedule: oncall.Schedule{Events: []oncall.ScheduleEvent{{Start: 0, Duration: week + 3*week/7}}},
+			wantErr:  true,
+		},
+		{
+			name:     "Converted to advanced mode",
+			schedule: oncall.Schedule{AdvancedMode: 1, Events: []oncall.ScheduleEvent{{Start: 0, Duration: week}}},
+			wantErr:  true,
+		},
+		{
+			name:     "Several events",
+			schedule: oncall.Schedule{Events: []oncall.ScheduleEvent{{Start: 0, Duration: week / 2}, {Start: week / 2, Duration: week / 2}}},
+			wantErr:  true,
+		},
+		{
+			name:     "Event is not a week long",
+			schedule: oncall.Schedule{Events: []oncall.ScheduleEvent{{Start: 0, Duration: week / 7}}},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkBasicSchedule(tt.schedule)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("checkBasicSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_notBasicScheduleDiags(t *testing.T) {
+	day := int(duration.Day.Seconds())
+	schedule := oncall.Schedule{
+		AdvancedMode: 1,
+		Events: []oncall.ScheduleEvent{
+			{Start: 3*day + 9*3600, Duration: 4 * day},
+			{Start: day + 9*3600, Duration: 2 * day},
+		},
+	}
+	reason := checkBasicSchedule(schedule)
+	wantLayout := "  Monday 09:00 for 2d\n  Wednesday 09:00 for 4d\n"
+
+	tests := []struct {
+		name         string
+		overwrite    bool
+		wantSeverity diag.Severity
+		wantDetail   []string
+	}{
+		{
+			name:         "Not overwriting",
+			wantSeverity: diag.Error,
+			wantDetail:   []string{wantLayout, "oncall_advanced_schedule with ID team/roster/primary", basicScheduleFieldForceOverwrite + " = true"},
+		},
+		{
+			name:         "Overwriting",
+			overwrite:    true,
+			wantSeverity: diag.Warning,
+			wantDetail:   []string{wantLayout, "applying puts the schedule back"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := notBasicScheduleDiags("team/roster/primary", schedule, reason, time.Sunday, tt.overwrite)
+			if len(diags) != 1 || diags[0].Severity != tt.wantSeverity {
+				t.Fatalf("notBasicScheduleDiags() = %v, want one with severity %v", diags, tt.wantSeverity)
+			}
+			for _, want := range tt.wantDetail {
+				if !strings.Contains(diags[0].Detail, want) {
+					t.Errorf("notBasicScheduleDiags() detail = %q, want it to contain %q", diags[0].Detail, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_basicScheduleFromResourceRotation(t *testing.T) {
+	week := int(duration.Week.Seconds())
+	tests := []struct {
+		name             string
+		rotateFrequency  string
+		rotateEveryWeeks int
+		wantDuration     int
+	}{
+		{
+			name:            "Weekly",
+			rotateFrequency: basicScheduleRotationWeekly,
+			wantDuration:    week,
+		},
+		{
+			name:            "Bi-weekly",
+			rotateFrequency: basicScheduleRotationBiWeekly,
+			wantDuration:    2 * week,
+		},
+		{
+			name:             "Every three weeks",
+			rotateEveryWeeks: 3,
+			wantDuration:     3 * week,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := map[string]interface{}{
+				scheduleFieldRole:                  "primary",
+				scheduleFieldRosterID:              "team/roster",
+				scheduleFieldStartDayOfWeek:        "Monday",
+				scheduleFieldStartTime:             "09:00",
+				basicScheduleFieldRotateEveryWeeks: tt.rotateEveryWeeks,
+			}
+			if tt.rotateFrequency != "" {
+				config[basicScheduleFieldRotateFrequency] = tt.rotateFrequency
+			}
+			d := schema.TestResourceDataRaw(t, resourceBasicSchedule().Schema, config)
+			sched, err := basicScheduleFromResource(d, time.Sunday)
+			if err != nil {
+				t.Fatalf("basicScheduleFromResource() error = %v", err)
+			}
+			if got := sched.Events[0].Duration; got != tt.wantDuration {
+				t.Errorf("basicScheduleFromResource() duration = %s, want %s", prettyPrintDuration(got), prettyPrintDuration(tt.wantDuration))
+			}
+		})
+	}
+}
+
+func Test_parseScheduleImportID(t *testing.T) {
+	tests := []struct {
+		name     string
+		importID string
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "Schedule ID",
+			importID: "platform/sre/primary",
+			want:     "platform/sre/primary",
+		},
+		{
+			name:     "Attributes in any order",
+			importID: "role=primary, team=platform, roster=sre",
+			want:     "platform/sre/primary",
+		},
+		{
+			name:     "Missing attribute",
+			importID: "team=platform,role=primary",
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown attribute",
+			importID: "team=platform,roster=sre,role=primary,schedule=1",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team, roster, role, err := parseScheduleImportID(tt.importID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseScheduleImportID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && getScheduleID(team, roster, role) != tt.want {
+				t.Errorf("parseScheduleImportID() = %s, want %s", getScheduleID(team, roster, role), tt.want)
+			}
+		})
+	}
+}
+
+func Test_timeOfDayStateFunc(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "09:30", want: "09:30"},
+		{in: "9:30", want: "09:30"},
+		{in: "09:30:00", want: "09:30"},
+		{in: "9:15:05", want: "09:15:05"},
+		{in: "not a time", want: "not a time"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := timeOfDayStateFunc(tt.in); got != tt.want {
+				t.Errorf("timeOfDayStateFunc() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	holidayCalendarFieldTeam    = "team"
+	holidayCalendarFieldName    = "name"
+	holidayCalendarFieldRegions = "regions"
+	holidayCalendarFieldHoliday = "holiday"
+
+	holidayFieldDate = "date"
+	holidayFieldName = "name"
+)
+
+func resourceHolidayCalendar() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceHolidayCalendarCreate,
+		ReadContext:   resourceHolidayCalendarRead,
+		UpdateContext: resourceHolidayCalendarUpdate,
+		DeleteContext: resourceHolidayCalendarDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: teamPrefixCustomizeDiff(holidayCalendarFieldTeam),
+
+		Schema: map[string]*schema.Schema{
+			holidayCalendarFieldTeam: {
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Name of the team whose schedules can use the calendar",
+			},
+			holidayCalendarFieldName: {
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Name of the calendar, e.g. us-2025, for schedules to refer to it by",
+			},
+			holidayCalendarFieldRegions: {
+				Type:        schema.TypeList,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Regions the holidays are observed in, e.g. US",
+			},
+			holidayCalendarFieldHoliday: {
+				Type:        schema.TypeList,
+				Required:    true,
+				Description: "Days that handoffs should not happen on",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						holidayFieldDate: {
+							Type:             schema.TypeString,
+							Required:         true,
+							ValidateDiagFunc: validateHolidayDate,
+							Description:      "Date of the holiday in YYYY-MM-DD format, in the team's scheduling timezone",
+						},
+						holidayFieldName: {
+							Type:        schema.TypeString,
+							Optional:    true,
+							Description: "Name of the holiday, e.g. Independence Day",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func holidayCalendarFromResource(d *schema.ResourceData) holidayCalendar {
+	cal := holidayCalendar{
+		Name:     d.Get(holidayCalendarFieldName).(string),
+		Regions:  []string{},
+		Holidays: []holiday{},
+	}
+	for _, r := range d.Get(holidayCalendarFieldRegions).([]interface{}) {
+		cal.Regions = append(cal.Regions, r.(string))
+	}
+	for _, h := range d.Get(holidayCalendarFieldHoliday).([]interface{}) {
+		h := h.(map[string]interface{})
+		cal.Holidays = append(cal.Holidays, holiday{
+			Date: h[holidayFieldDate].(string),
+			Name: h[holidayFieldName].(string),
+		})
+	}
+	return cal
+}
+
+func resourceHolidayCalendarCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName := d.Get(holidayCalendarFieldTeam).(string)
+	cal := holidayCalendarFromResource(d)
+
+	traceLog("Going to create holiday calendar %s of team %s with %d holidays", cal.Name, teamName, len(cal.Holidays))
+	found, err := addTeamHolidayCalendar(c, teamName, cal)
+	if err != nil {
+		return diagFromErrf(err, "Creating holiday calendar")
+	}
+	if found {
+		return diag.Errorf("Team %s already has holiday calendar %s, import it with ID %s", teamName, cal.Name, getHolidayCalendarID(teamName, cal.Name))
+	}
+
+	d.SetId(getHolidayCalendarID(teamName, cal.Name))
+	return resourceHolidayCalendarRead(ctx, d, m)
+}
+
+func resourceHolidayCalendarRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName, name, err := parseHolidayCalendarID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing holiday calendar ID")
+	}
+
+	cal, found, err := getTeamHolidayCalendar(c, teamName, name)
+	if err != nil {
+		return diagFromErrf(err, "Getting holiday calendars of team %s", teamName)
+	}
+	if !found {
+		warnLog("Holiday calendar %s of team %s no longer exists, removing it from state", name, teamName)
+		d.SetId("")
+		return diags
+	}
+
+	holidays := make([]map[string]interface{}, 0, len(cal.Holidays))
+	for _, h := range cal.Holidays {
+		holidays = append(holidays, map[string]interface{}{
+			holidayFieldDate: h.Date,
+			holidayFieldName: h.Name,
+		})
+	}
+
+	d.Set(holidayCalendarFieldTeam, teamName)
+	d.Set(holidayCalendarFieldName, cal.Name)
+	d.Set(holidayCalendarFieldRegions, cal.Regions)
+	d.Set(holidayCalendarFieldHoliday, holidays)
+
+	return diags
+}
+
+func resourceHolidayCalendarUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName, name, err := parseHolidayCalendarID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing holiday calendar ID, this is an internal error")
+	}
+	cal := holidayCalendarFromResource(d)
+
+	traceLog("Going to update holiday calendar %s of team %s with %d holidays", name, teamName, len(cal.Holidays))
+	err = setTeamHolidayCalendar(c, teamName, name, cal)
+	if err != nil {
+		return diagFromErrf(err, "Updating holiday calendar")
+	}
+	return resourceHolidayCalendarRead(ctx, d, m)
+}
+
+func resourceHolidayCalendarDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName, name, err := parseHolidayCalendarID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing holiday calendar ID, this is an internal error")
+	}
+
+	traceLog("Going to delete holiday calendar %s of team %s", name, teamName)
+	err = setTeamHolidayCalendar(c, teamName, name, holidayCalendar{})
+	// Deleting the team first deletes its holiday calendars along with it
+	if err != nil && !isNotFound(err) {
+		return diagFromErrf(err, "Deleting holiday calendar %s", d.Id())
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func validateHolidayDate(in interface{}, path cty.Path) diag.Diagnostics {
+	date := in.(string)
+	if _, err := time.Parse(holidayDateFormat, date); err != nil {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("%q is not a valid holiday date", date),
+				Detail:        "Holiday dates are in YYYY-MM-DD format, e.g. 2025-12-25",
+				AttributePath: path,
+			},
+		}
+	}
+	return nil
+}
+
+func getHolidayCalendarID(team, name string) string {
+	return fmt.Sprintf("%s/%s", escapeIDPart(team), escapeIDPart(name))
+}
+
+func parseHolidayCalendarID(id string) (team, name string, err error) {
+	tn := strings.Split(id, "/")
+	if len(tn) == 2 {
+		team, name = unescapeIDPart(tn[0]), unescapeIDPart(tn[1])
+	} else {
+		err = errors.New("Unparseable holiday calendar id (should be team/name)")
+	}
+
+	if err == nil && (team == "" || name == "") {
+		err = errors.New("Holiday calendar ID did not specify both team and name")
+	}
+	return
+}
@@ -0,0 +1,225 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	icalSubscriptionFieldUser             = "user"
+	icalSubscriptionFieldTeam             = "team"
+	icalSubscriptionFieldRotationTriggers = "rotation_triggers"
+	icalSubscriptionFieldKey              = "key"
+	icalSubscriptionFieldFeedURL          = "feed_url"
+
+	icalKeyTypeUser = "user"
+	icalKeyTypeTeam = "team"
+)
+
+// resourceICalSubscription manages the secret key of a user's or team's iCal
+// feed, which is what calendar apps subscribe to without signing in to oncall
+func resourceICalSubscription() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceICalSubscriptionCreate,
+		ReadContext:   resourceICalSubscriptionRead,
+		UpdateContext: resourceICalSubscriptionUpdate,
+		DeleteContext: resourceICalSubscriptionDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: customdiff.All(
+			teamPrefixCustomizeDiff(icalSubscriptionFieldTeam),
+			customdiff.ComputedIf(icalSubscriptionFieldKey, icalKeyRotated),
+			customdiff.ComputedIf(icalSubscriptionFieldFeedURL, icalKeyRotated),
+		),
+
+		Schema: map[string]*schema.Schema{
+			icalSubscriptionFieldUser: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				ForceNew:         true,
+				ExactlyOneOf:     []string{icalSubscriptionFieldUser, icalSubscriptionFieldTeam},
+				ValidateDiagFunc: validateUsername,
+				StateFunc:        usernameStateFunc,
+				Description:      "Username whose on call shifts the feed lists. Oncall only lets users manage their own feed, so this has to be the provider's user",
+			},
+			icalSubscriptionFieldTeam: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ExactlyOneOf: []string{icalSubscriptionFieldUser, icalSubscriptionFieldTeam},
+				Description:  "Name of the team whose on call shifts the feed lists",
+			},
+			icalSubscriptionFieldRotationTriggers: {
+				Type:        schema.TypeMap,
+				Optional:    true,
+				Elem:        &schema.Schema{Type: schema.TypeString},
+				Description: "Arbitrary values that rotate the key when they change, e.g. a date to rotate it every quarter. Rotating revokes the old feed URL",
+			},
+			icalSubscriptionFieldKey: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Secret key of the feed",
+			},
+			icalSubscriptionFieldFeedURL: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "URL of the feed to subscribe to, e.g. from Google Calendar. Anyone with it can read the feed",
+			},
+		},
+	}
+}
+
+func icalKeyRotated(ctx context.Context, d *schema.ResourceDiff, m interface{}) bool {
+	return d.HasChange(icalSubscriptionFieldRotationTriggers)
+}
+
+func getICalSubscriptionID(keyType, name string) string {
+	return keyType + "/" + name
+}
+
+func parseICalSubscriptionID(id string) (keyType, name string, err error) {
+	parts := strings.SplitN(id, "/", 2)
+	if len(parts) != 2 || parts[1] == "" || (parts[0] != icalKeyTypeUser && parts[0] != icalKeyTypeTeam) {
+		return "", "", fmt.Errorf("Invalid iCal subscription ID %q, expected user/<username> or team/<team>", id)
+	}
+	return parts[0], parts[1], nil
+}
+
+func icalKeyPath(keyType, name string) string {
+	return fmt.Sprintf("/api/v0/ical_key/%s/%s", keyType, url.PathEscape(name))
+}
+
+// parseICalKey reads a key from oncall, which sends it as plain text
+func parseICalKey(body []byte) string {
+	key := strings.TrimSpace(string(body))
+	var quoted string
+	if json.Unmarshal([]byte(key), &quoted) == nil {
+		return quoted
+	}
+	return key
+}
+
+// getICalKey is the feed key, and false if there is none
+func getICalKey(c *oncall.Client, keyType, name string) (string, bool, error) {
+	body, err := c.Get(icalKeyPath(keyType, name), nil)
+	if isNotFound(err) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Wrapf(err, "Getting iCal key of %s %s", keyType, name)
+	}
+	return parseICalKey(body), true, nil
+}
+
+// rotateICalKey creates a new feed key, replacing the one there is
+func rotateICalKey(c *oncall.Client, keyType, name string) error {
+	traceLog("Going to create a new iCal key for %s %s", keyType, name)
+	_, err := c.Post(icalKeyPath(keyType, name), nil, nil)
+	return errors.Wrapf(err, "Creating iCal key of %s %s", keyType, name)
+}
+
+func icalSubscriptionFromResource(d *schema.ResourceData) (keyType, name string) {
+	if user := d.Get(icalSubscriptionFieldUser).(string); user != "" {
+		return icalKeyTypeUser, normalizeUsername(user)
+	}
+	return icalKeyTypeTeam, d.Get(icalSubscriptionFieldTeam).(string)
+}
+
+func resourceICalSubscriptionCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	keyType, name := icalSubscriptionFromResource(d)
+	_, exists, err := getICalKey(c, keyType, name)
+	if err != nil {
+		return diagFromErrf(err, "Checking for an existing iCal key")
+	}
+	// Creating a key replaces the existing one, which would break the
+	// calendars already subscribed to it
+	if exists {
+		return diag.Errorf("The %s %s already has an iCal feed, import it with ID %s", keyType, name, getICalSubscriptionID(keyType, name))
+	}
+
+	err = rotateICalKey(c, keyType, name)
+	if err != nil {
+		return diagFromErrf(err, "Creating iCal subscription")
+	}
+
+	d.SetId(getICalSubscriptionID(keyType, name))
+	return resourceICalSubscriptionRead(ctx, d, m)
+}
+
+func resourceICalSubscriptionRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	keyType, name, err := parseICalSubscriptionID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing iCal subscription ID")
+	}
+
+	key, found, err := getICalKey(c, keyType, name)
+	if err != nil {
+		return diagFromErrf(err, "Reading iCal subscription %s", d.Id())
+	}
+	if !found {
+		warnLog("The iCal key of %s %s was revoked, removing it from state", keyType, name)
+		d.SetId("")
+		return diags
+	}
+
+	if keyType == icalKeyTypeUser {
+		d.Set(icalSubscriptionFieldUser, name)
+	} else {
+		d.Set(icalSubscriptionFieldTeam, name)
+	}
+	d.Set(icalSubscriptionFieldKey, key)
+	d.Set(icalSubscriptionFieldFeedURL, fmt.Sprintf("%s/api/v0/ical/%s", c.Config.Endpoint, url.PathEscape(key)))
+	return diags
+}
+
+func resourceICalSubscriptionUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	keyType, name, err := parseICalSubscriptionID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing iCal subscription ID, this is an internal error")
+	}
+	if d.HasChange(icalSubscriptionFieldRotationTriggers) {
+		err = rotateICalKey(c, keyType, name)
+		if err != nil {
+			return diagFromErrf(err, "Rotating iCal subscription")
+		}
+	}
+	return resourceICalSubscriptionRead(ctx, d, m)
+}
+
+func resourceICalSubscriptionDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	keyType, name, err := parseICalSubscriptionID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing iCal subscription ID, this is an internal error")
+	}
+
+	traceLog("Going to revoke the iCal key of %s %s", keyType, name)
+	_, err = c.Delete(icalKeyPath(keyType, name), nil, nil)
+	if err != nil && !isNotFound(err) {
+		return diagFromErrf(err, "Revoking iCal subscription")
+	}
+	d.SetId("")
+	return nil
+}
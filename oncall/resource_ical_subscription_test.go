@@ -0,0 +1,103 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_parseICalKey(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{name: "Plain text", body: "6b2c7a43-6e1f-4d5e-9a59-3c1d8f7e2b10\n", want: "6b2c7a43-6e1f-4d5e-9a59-3c1d8f7e2b10"},
+		{name: "JSON string", body: `"6b2c7a43-6e1f-4d5e-9a59-3c1d8f7e2b10"`, want: "6b2c7a43-6e1f-4d5e-9a59-3c1d8f7e2b10"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseICalKey([]byte(tt.body)); got != tt.want {
+				t.Errorf("parseICalKey() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resourceICalSubscription(t *testing.T) {
+	keys := map[string]string{}
+	requests := []string{}
+	generated := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, "/api/v0/ical_key/")
+		if r.Method != "GET" {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+		}
+		switch r.Method {
+		case "GET":
+			key, ok := keys[path]
+			if !ok {
+				w.WriteHeader(404)
+				return
+			}
+			w.Write([]byte(key))
+		case "POST":
+			generated++
+			keys[path] = strings.Repeat(string(rune('a'+generated)), 8)
+			w.Write([]byte(keys[path]))
+		case "DELETE":
+			delete(keys, path)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c}
+	ctx := context.Background()
+
+	keys["user/alice"] = "existing"
+	existing := schema.TestResourceDataRaw(t, resourceICalSubscription().Schema, map[string]interface{}{
+		icalSubscriptionFieldUser: "alice",
+	})
+	if diags := resourceICalSubscriptionCreate(ctx, existing, meta); !diags.HasError() {
+		t.Errorf("resourceICalSubscriptionCreate() of an existing feed = %v, want an error", diags)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceICalSubscription().Schema, map[string]interface{}{
+		icalSubscriptionFieldTeam: "payments",
+	})
+	if diags := resourceICalSubscriptionCreate(ctx, d, meta); diags.HasError() {
+		t.Fatalf("resourceICalSubscriptionCreate() = %v", diags)
+	}
+	if d.Id() != "team/payments" || d.Get(icalSubscriptionFieldKey) != "bbbbbbbb" {
+		t.Errorf("Created %s with key %s, want team/payments with key bbbbbbbb", d.Id(), d.Get(icalSubscriptionFieldKey))
+	}
+	if want := server.URL + "/api/v0/ical/bbbbbbbb"; d.Get(icalSubscriptionFieldFeedURL) != want {
+		t.Errorf("feed_url = %s, want %s", d.Get(icalSubscriptionFieldFeedURL), want)
+	}
+
+	if diags := resourceICalSubscriptionDelete(ctx, d, meta); diags.HasError() {
+		t.Fatalf("resourceICalSubscriptionDelete() = %v", diags)
+	}
+	wantRequests := []string{
+		"POST /api/v0/ical_key/team/payments",
+		"DELETE /api/v0/ical_key/team/payments",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", requests, wantRequests)
+	}
+
+	d.SetId("team/payments")
+	if diags := resourceICalSubscriptionRead(ctx, d, meta); diags.HasError() || d.Id() != "" {
+		t.Errorf("resourceICalSubscriptionRead() of a revoked feed = %v, ID %q, want it removed from state", diags, d.Id())
+	}
+}
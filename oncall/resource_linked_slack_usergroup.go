@@ -0,0 +1,178 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	linkedSlackUsergroupFieldTeam      = "team"
+	linkedSlackUsergroupFieldRole      = "role"
+	linkedSlackUsergroupFieldUsergroup = "usergroup"
+)
+
+func resourceLinkedSlackUsergroup() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceLinkedSlackUsergroupCreate,
+		ReadContext:   resourceLinkedSlackUsergroupRead,
+		UpdateContext: resourceLinkedSlackUsergroupUpdate,
+		DeleteContext: resourceLinkedSlackUsergroupDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: schema.ImportStatePassthroughContext,
+		},
+		CustomizeDiff: teamPrefixCustomizeDiff(linkedSlackUsergroupFieldTeam),
+
+		Schema: map[string]*schema.Schema{
+			linkedSlackUsergroupFieldTeam: {
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Name of the team whose on call the usergroup mirrors",
+			},
+			linkedSlackUsergroupFieldRole: {
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Required:         true,
+				ValidateDiagFunc: validateStringSliceContains(roleNames),
+				Description:      fmt.Sprintf("Role whose on call the usergroup mirrors, one of %v", roleNames),
+			},
+			linkedSlackUsergroupFieldUsergroup: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateSlackUsergroupHandle,
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return normalizeSlackUsergroup(old) == normalizeSlackUsergroup(new)
+				},
+				Description: "Handle of the slack usergroup, e.g. @platform-oncall",
+			},
+		},
+	}
+}
+
+func resourceLinkedSlackUsergroupCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName := d.Get(linkedSlackUsergroupFieldTeam).(string)
+	role := d.Get(linkedSlackUsergroupFieldRole).(string)
+	usergroup := d.Get(linkedSlackUsergroupFieldUsergroup).(string)
+
+	traceLog("Going to link %s of team %s to slack usergroup %s", role, teamName, usergroup)
+	existing, err := addTeamSlackUsergroup(c, teamName, role, usergroup)
+	if err != nil {
+		return diagFromErrf(err, "Linking slack usergroup")
+	}
+	if existing != "" {
+		return diag.Errorf("The %s role of team %s is already linked to slack usergroup %s, import it with ID %s", role, teamName, existing, getLinkedSlackUsergroupID(teamName, role))
+	}
+
+	d.SetId(getLinkedSlackUsergroupID(teamName, role))
+	return resourceLinkedSlackUsergroupRead(ctx, d, m)
+}
+
+func resourceLinkedSlackUsergroupRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName, role, err := parseLinkedSlackUsergroupID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing linked slack usergroup ID")
+	}
+
+	usergroups, err := getTeamSlackUsergroups(c, teamName)
+	if err != nil {
+		return diagFromErrf(err, "Getting slack usergroups of team %s", teamName)
+	}
+
+	usergroup, ok := usergroups[role]
+	if !ok {
+		warnLog("The %s role of team %s is no longer linked to a slack usergroup, removing it from state", role, teamName)
+		d.SetId("")
+		return diags
+	}
+
+	d.Set(linkedSlackUsergroupFieldTeam, teamName)
+	d.Set(linkedSlackUsergroupFieldRole, role)
+	d.Set(linkedSlackUsergroupFieldUsergroup, usergroup)
+
+	return diags
+}
+
+func resourceLinkedSlackUsergroupUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName, role, err := parseLinkedSlackUsergroupID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing linked slack usergroup ID, this is an internal error")
+	}
+	usergroup := d.Get(linkedSlackUsergroupFieldUsergroup).(string)
+
+	traceLog("Going to link %s of team %s to slack usergroup %s", role, teamName, usergroup)
+	err = setTeamSlackUsergroup(c, teamName, role, usergroup)
+	if err != nil {
+		return diagFromErrf(err, "Linking slack usergroup")
+	}
+	return resourceLinkedSlackUsergroupRead(ctx, d, m)
+}
+
+func resourceLinkedSlackUsergroupDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName, role, err := parseLinkedSlackUsergroupID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing linked slack usergroup ID, this is an internal error")
+	}
+
+	traceLog("Going to unlink %s of team %s from its slack usergroup", role, teamName)
+	err = setTeamSlackUsergroup(c, teamName, role, "")
+	// Deleting the team first deletes its links along with it
+	if err != nil && !isNotFound(err) {
+		return diagFromErrf(err, "Unlinking slack usergroup %s", d.Id())
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func validateSlackUsergroupHandle(in interface{}, path cty.Path) diag.Diagnostics {
+	handle := in.(string)
+	if !slackUsergroupHandleRegexp.MatchString(handle) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("%q is not a valid slack usergroup handle", handle),
+				Detail:        "Slack usergroup handles are lowercase letters, numbers, periods, hyphens, and underscores, e.g. @platform-oncall",
+				AttributePath: path,
+			},
+		}
+	}
+	return nil
+}
+
+func getLinkedSlackUsergroupID(team, role string) string {
+	return fmt.Sprintf("%s/%s", escapeIDPart(team), escapeIDPart(role))
+}
+
+func parseLinkedSlackUsergroupID(id string) (team, role string, err error) {
+	tr := strings.Split(id, "/")
+	if len(tr) == 2 {
+		team, role = unescapeIDPart(tr[0]), unescapeIDPart(tr[1])
+	} else {
+		err = errors.New("Unparseable linked slack usergroup id (should be team/role)")
+	}
+
+	if err == nil && (team == "" || role == "") {
+		err = errors.New("Linked slack usergroup ID did not specify both team and role")
+	}
+	return
+}
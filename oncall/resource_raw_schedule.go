@@ -0,0 +1,402 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
+)
+
+const (
+	rawScheduleFieldEvent           = "event"
+	rawScheduleFieldStartSeconds    = "start_seconds"
+	rawScheduleFieldDurationSeconds = "duration_seconds"
+)
+
+func resourceRawSchedule() *schema.Resource {
+	return &schema.Resource{
+		Description: "Roster schedule given as oncall's own list of events, in seconds from the start of the week, for layouts generated by other tools. The events are sent as they are, without the day and time handling of oncall_advanced_schedule.",
+
+		CreateContext: resourceRawScheduleCreate,
+		ReadContext:   resourceRawScheduleRead,
+		UpdateContext: resourceRawScheduleUpdate,
+		DeleteContext: resourceRawScheduleDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRawScheduleImport,
+		},
+		CustomizeDiff: customdiff.All(
+			scheduleRosterCustomizeDiff,
+			teamPrefixCustomizeDiff(scheduleFieldTeam),
+			deferredPopulateCustomizeDiff(scheduleFieldRosterID),
+			scheduleReferencesCustomizeDiff(scheduleFieldRosterID),
+			scheduleDefinitionCustomizeDiff(rawScheduleFromResource),
+			revisionCustomizeDiff(),
+			autoPopulateDaysCustomizeDiff,
+			customdiff.ComputedIf(scheduleFieldCalendarURL, scheduleURLsChanged),
+			customdiff.ComputedIf(scheduleFieldICalURL, scheduleURLsChanged),
+		),
+
+		Schema: map[string]*schema.Schema{
+			scheduleFieldRole: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateStringSliceContains(roleNames),
+				Description:      fmt.Sprintf("Name of the role, one of %v", roleNames),
+			},
+			scheduleFieldRosterID: {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{scheduleFieldRosterID, scheduleFieldTeam},
+				Description:  fmt.Sprintf("Roster ID (in team/roster format) to map this schedule to, or set %s and %s instead", scheduleFieldTeam, scheduleFieldRoster),
+			},
+			scheduleFieldTeam: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{scheduleFieldRosterID},
+				RequiredWith:  []string{scheduleFieldTeam, scheduleFieldRoster},
+				Description:   fmt.Sprintf("Name of the team of the roster to map this schedule to, an alternative to %s", scheduleFieldRosterID),
+			},
+			scheduleFieldRoster: {
+				Type:          schema.TypeString,
+				Optional:      true,
+				Computed:      true,
+				ConflictsWith: []string{scheduleFieldRosterID},
+				RequiredWith:  []string{scheduleFieldTeam, scheduleFieldRoster},
+				Description:   fmt.Sprintf("Name of the roster to map this schedule to, an alternative to %s", scheduleFieldRosterID),
+			},
+			scheduleFieldAutoPopulateDays: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          21,
+				DiffSuppressFunc: suppressRoundedAutoPopulateDays,
+				ValidateDiagFunc: validateAutoPopulateDays,
+				Description:      "How many days in advance to plan the schedule. Oncall rounds this up to a whole number of weeks. " + autoPopulateDaysDescription(defaultMaxAutoPopulateDays),
+			},
+			scheduleFieldSchedulingAlgorithim: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          "default",
+				ValidateDiagFunc: validateStringSliceContains(schedulingAlgorithms),
+				Description:      fmt.Sprintf("Scheduling algorithim to use, one of: %v", schedulingAlgorithms),
+			},
+			rawScheduleFieldEvent: {
+				Type:        schema.TypeList,
+				Required:    true,
+				Description: "Events of the schedule, sent to oncall in this order and kept in it when read back",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						rawScheduleFieldStartSeconds: {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntBetween(0, weekSeconds-1),
+							Description:  "When the event starts in seconds from the start of oncall's week",
+						},
+						rawScheduleFieldDurationSeconds: {
+							Type:         schema.TypeInt,
+							Required:     true,
+							ValidateFunc: validation.IntAtLeast(1),
+							Description:  "How long the event lasts in seconds",
+						},
+					},
+				},
+			},
+			scheduleFieldAllowEmptyRoster: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Allow the schedule to be created or updated while its roster has nobody in rotation, which oncall populates as an empty calendar",
+			},
+			scheduleFieldDeferPopulate: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When the roster has nobody in rotation, e.g. because it is created in the same apply, warn and hold off populating the calendar instead of failing. The next plan after the roster gets members updates the schedule to populate it",
+			},
+			scheduleFieldPopulatePending: {
+				Type:        schema.TypeBool,
+				Computed:    true,
+				Description: "Whether populating the calendar was deferred as the roster had nobody in rotation",
+			},
+			scheduleFieldNextRotationAt: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "When the schedule next hands off to the next person (RFC 3339), from the populated calendar. Empty if nothing upcoming has been populated",
+			},
+			scheduleFieldScheduledUntil: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "How far into the future the calendar is populated for this schedule (RFC 3339). Empty if nothing upcoming has been populated",
+			},
+			scheduleFieldLastEpochScheduled: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Unix time up to which oncall's scheduler has scheduled this schedule",
+			},
+			scheduleFieldPopulationStatus: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: fmt.Sprintf("Whether the calendar is populated as far ahead as auto_populate_days asks, one of: %v. Behind means it covers over a week less than asked for", populationStatuses),
+			},
+			scheduleFieldCalendarURL: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the calendar of the schedule's team in the oncall UI",
+			},
+			scheduleFieldICalURL: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the iCal feed of the team's on call events for the schedule's role",
+			},
+			fieldRevision: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the schedule as read from oncall, which changes whenever it is changed, including outside of terraform e.g. in the oncall UI. For replace_triggered_by and postconditions to react to such changes",
+			},
+			scheduleFieldScheduleID: {
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Numeric ID of the schedule in oncall, used to update and delete it even if its role is renamed",
+			},
+			scheduleFieldChangeSummary: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Planned when the schedule's normalized definition changes, describing what changed for people reviewing the plan, e.g. handoff moved from Mon 09:00 to Tue 10:00. Only meaningful in plans that change the schedule",
+			},
+			scheduleFieldNormalizedDefinitionJSON: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "JSON of the schedule's role, roster, scheduling settings and events sorted by start, in the same shape as for basic and advanced schedules, for policy as code to check",
+			},
+		},
+	}
+}
+
+func resourceRawScheduleCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	rosterID := d.Get(scheduleFieldRosterID).(string)
+	teamName, rosterName, err := parseRosterID(rosterID)
+	if err != nil {
+		return diagFromErrf(err, "Parsing roster ID, this is an internal error")
+	}
+	scheduleName := d.Get(scheduleFieldRole).(string)
+
+	traceLog("Going to create raw roster schedule: %s/%s/%s", teamName, rosterName, scheduleName)
+	sched, err := rawScheduleFromResource(d, weekStartFor(m))
+	if err != nil {
+		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
+	}
+
+	deferred, diags := checkRosterNotEmpty(c, d, scheduleFieldRosterID, teamName, rosterName)
+	if diags.HasError() {
+		return diags
+	}
+
+	resourceID := getScheduleID(teamName, rosterName, scheduleName)
+	err = c.AddRosterSchedule(teamName, rosterName, sched)
+	if err != nil {
+		return createErrorDiags(err, "Creating oncall roster schedule", resourceID, scheduleFieldRole, scheduleFieldRosterID, scheduleFieldAutoPopulateDays, scheduleFieldSchedulingAlgorithim)
+	}
+
+	d.SetId(resourceID)
+	d.Set(scheduleFieldPopulatePending, deferred)
+	return append(diags, resourceRawScheduleRead(ctx, d, m)...)
+}
+
+func resourceRawScheduleImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	teamName, rosterName, scheduleName, err := canonicalScheduleImportID(ctx, d.Id(), m)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parsing import ID")
+	}
+	d.SetId(getScheduleID(teamName, rosterName, scheduleName))
+	d.Set(scheduleFieldRole, scheduleName)
+	d.Set(scheduleFieldRosterID, getRosterID(teamName, rosterName))
+
+	readErr := resourceRawScheduleRead(ctx, d, m)
+	if len(readErr) > 0 {
+		err = errors.New(readErr[0].Summary)
+	}
+	if err == nil && d.Id() == "" {
+		err = fmt.Errorf("Roster schedule %s does not exist", getScheduleID(teamName, rosterName, scheduleName))
+	}
+	return []*schema.ResourceData{d}, errors.Wrap(err, "Reading resource for import")
+}
+
+func resourceRawScheduleRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName, rosterName, scheduleName, err := parseScheduleID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing roster ID, this is an internal error")
+	}
+
+	schedule, found, err := getRosterScheduleByID(c, teamName, rosterName, d.Get(scheduleFieldScheduleID).(int), scheduleName)
+	if err != nil {
+		return diagFromErrf(err, "Getting roster schedule %s/%s/%s", teamName, rosterName, scheduleName)
+	}
+	if !found {
+		warnLog("Roster schedule %s/%s/%s no longer exists, removing it from state", teamName, rosterName, scheduleName)
+		d.SetId("")
+		return diags
+	}
+
+	d.SetId(getScheduleID(teamName, rosterName, schedule.Role))
+	d.Set(scheduleFieldScheduleID, schedule.ID)
+	d.Set(fieldRevision, scheduleRevision(schedule.Schedule))
+	d.Set(scheduleFieldCalendarURL, teamCalendarURL(c.Config.Endpoint, teamName))
+	d.Set(scheduleFieldICalURL, teamICalURL(c.Config.Endpoint, teamName, schedule.Role))
+	d.Set(scheduleFieldRole, schedule.Role)
+	diags = append(diags, checkReadRole(m, d.Id(), schedule.Role)...)
+	d.Set(scheduleFieldRosterID, getRosterID(teamName, rosterName))
+	d.Set(scheduleFieldTeam, teamName)
+	d.Set(scheduleFieldRoster, rosterName)
+	d.Set(scheduleFieldAutoPopulateDays, schedule.AutoPopulateThreshold)
+	d.Set(scheduleFieldSchedulingAlgorithim, schedule.Scheduler.Name)
+	readScheduleCalendar(c, d, teamName, schedule)
+
+	d.Set(rawScheduleFieldEvent, rawEventsInOrder(schedule.Events, rawEventsFromResource(d)))
+
+	diags = append(diags, readScheduleDefinition(d, rawScheduleFromResource, m)...)
+	return diags
+}
+
+func resourceRawScheduleUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	traceLog("Going to update raw schedule %q", d.Id())
+	teamName, rosterName, scheduleName, err := parseScheduleID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing roster schedule ID, this is an internal error")
+	}
+	teamName, rosterName, err = followMigratedSchedule(c, d, teamName, rosterName, scheduleName)
+	if err != nil {
+		return diagFromErrf(err, "Finding schedule %s on its roster's new team", d.Id())
+	}
+
+	sched, err := rawScheduleFromResource(d, weekStartFor(m))
+	if err != nil {
+		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
+	}
+
+	deferred, diags := checkRosterNotEmpty(c, d, scheduleFieldRosterID, teamName, rosterName)
+	if diags.HasError() {
+		return diags
+	}
+
+	err = updateRosterSchedule(c, d.Get(scheduleFieldScheduleID).(int), teamName, rosterName, scheduleName, sched)
+	if err != nil {
+		return diagFromErrf(err, "Updating oncall roster schedule")
+	}
+	d.Set(scheduleFieldPopulatePending, deferred)
+	if !deferred {
+		err = populateRosterSchedule(ctx, c, teamName, rosterName, sched.Role, nil, time.Now())
+		if err != nil {
+			return append(diags, diagFromErrf(err, "Populating roster schedule %s/%s/%s", teamName, rosterName, sched.Role)...)
+		}
+	}
+
+	return append(diags, resourceRawScheduleRead(ctx, d, m)...)
+}
+
+func resourceRawScheduleDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName, rosterName, scheduleName, err := parseScheduleID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing roster schedule ID, this is an internal error")
+	}
+
+	traceLog("Going to delete raw roster schedule %s/%s/%s", teamName, rosterName, scheduleName)
+	err = removeRosterSchedule(ctx, c, d.Get(scheduleFieldScheduleID).(int), teamName, rosterName, scheduleName)
+	if err != nil {
+		return diagFromErrf(err, "Removing roster %s/%s/%s", teamName, rosterName, scheduleName)
+	}
+
+	d.SetId("")
+	return diag.Diagnostics{}
+}
+
+// rawScheduleFromResource builds the schedule with the events exactly as
+// configured. The week start doesn't matter, as the events are already in
+// seconds from it.
+func rawScheduleFromResource(d resourceGetter, weekStart time.Weekday) (oncall.Schedule, error) {
+	rosterID := d.Get(scheduleFieldRosterID).(string)
+	sched := oncall.Schedule{
+		AdvancedMode:          1,
+		Role:                  d.Get(scheduleFieldRole).(string),
+		AutoPopulateThreshold: d.Get(scheduleFieldAutoPopulateDays).(int),
+		Scheduler: oncall.ScheduleScheduler{
+			Name: d.Get(scheduleFieldSchedulingAlgorithim).(string),
+		},
+		Events: rawEventsFromResource(d),
+	}
+
+	team, roster, err := parseRosterID(rosterID)
+	if err != nil {
+		return sched, errors.Wrapf(err, "Invalid roster ID %q", rosterID)
+	}
+	sched.Team = team
+	sched.Roster = roster
+	return sched, nil
+}
+
+func rawEventsFromResource(d resourceGetter) []oncall.ScheduleEvent {
+	raw := d.Get(rawScheduleFieldEvent).([]interface{})
+	events := make([]oncall.ScheduleEvent, 0, len(raw))
+	for _, r := range raw {
+		if r == nil {
+			continue
+		}
+		event := r.(map[string]interface{})
+		events = append(events, oncall.ScheduleEvent{
+			Start:    event[rawScheduleFieldStartSeconds].(int),
+			Duration: event[rawScheduleFieldDurationSeconds].(int),
+		})
+	}
+	return events
+}
+
+// rawEventsInOrder is the events read from oncall for state, in the order
+// they were configured in if they are the same events, as oncall doesn't keep
+// the order they were sent in
+func rawEventsInOrder(read, configured []oncall.ScheduleEvent) []map[string]interface{} {
+	events := read
+	if reflect.DeepEqual(sortedRawEvents(read), sortedRawEvents(configured)) {
+		events = configured
+	}
+
+	out := make([]map[string]interface{}, 0, len(events))
+	for _, e := range events {
+		out = append(out, map[string]interface{}{
+			rawScheduleFieldStartSeconds:    e.Start,
+			rawScheduleFieldDurationSeconds: e.Duration,
+		})
+	}
+	return out
+}
+
+// sortedRawEvents sorts by start then duration, so events starting at the
+// same time compare equal whichever order they are in
+func sortedRawEvents(events []oncall.ScheduleEvent) []oncall.ScheduleEvent {
+	sorted := append([]oncall.ScheduleEvent{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Start != sorted[j].Start {
+			return sorted[i].Start < sorted[j].Start
+		}
+		return sorted[i].Duration < sorted[j].Duration
+	})
+	return sorted
+}
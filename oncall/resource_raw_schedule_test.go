@@ -0,0 +1,74 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_rawScheduleFromResource(t *testing.T) {
+	d := schema.TestResourceDataRaw(t, resourceRawSchedule().Schema, map[string]interface{}{
+		"role":      "primary",
+		"roster_id": "team/roster",
+		"event": []interface{}{
+			map[string]interface{}{"start_seconds": 86400, "duration_seconds": 3600},
+			map[string]interface{}{"start_seconds": 0, "duration_seconds": 7200},
+		},
+	})
+	got, err := rawScheduleFromResource(d, time.Monday)
+	if err != nil {
+		t.Fatalf("rawScheduleFromResource() error = %v", err)
+	}
+	want := oncall.Schedule{
+		AdvancedMode:          1,
+		Team:                  "team",
+		Roster:                "roster",
+		Role:                  "primary",
+		AutoPopulateThreshold: 21,
+		Scheduler:             oncall.ScheduleScheduler{Name: "default"},
+		Events:                []oncall.ScheduleEvent{{Start: 86400, Duration: 3600}, {Start: 0, Duration: 7200}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("rawScheduleFromResource() = %+v, want %+v", got, want)
+	}
+}
+
+func Test_rawEventsInOrder(t *testing.T) {
+	configured := []oncall.ScheduleEvent{{Start: 86400, Duration: 3600}, {Start: 0, Duration: 3600}, {Start: 0, Duration: 1800}}
+	tests := []struct {
+		name string
+		read []oncall.ScheduleEvent
+		want []oncall.ScheduleEvent
+	}{
+		{
+			name: "Same events in oncall's order",
+			read: []oncall.ScheduleEvent{{Start: 0, Duration: 1800}, {Start: 0, Duration: 3600}, {Start: 86400, Duration: 3600}},
+			want: configured,
+		},
+		{
+			name: "Changed outside of terraform",
+			read: []oncall.ScheduleEvent{{Start: 0, Duration: 3600}, {Start: 90000, Duration: 3600}},
+			want: []oncall.ScheduleEvent{{Start: 0, Duration: 3600}, {Start: 90000, Duration: 3600}},
+		},
+		{
+			name: "Event removed",
+			read: []oncall.ScheduleEvent{{Start: 0, Duration: 3600}, {Start: 86400, Duration: 3600}},
+			want: []oncall.ScheduleEvent{{Start: 0, Duration: 3600}, {Start: 86400, Duration: 3600}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rawEventsInOrder(tt.read, configured)
+			want := make([]map[string]interface{}, 0, len(tt.want))
+			for _, e := range tt.want {
+				want = append(want, map[string]interface{}{"start_seconds": e.Start, "duration_seconds": e.Duration})
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("rawEventsInOrder() = %v, want %v", got, want)
+			}
+		})
+	}
+}
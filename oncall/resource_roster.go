@@ -3,18 +3,35 @@ package oncall
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pkg/errors"
 )
 
 const (
-	rosterFieldName    = "name"
-	rosterFieldTeam    = "team"
-	rosterFieldMembers = "members"
+	rosterFieldName       = "name"
+	rosterFieldNamePrefix = "name_prefix"
+	rosterFieldTeam       = "team"
+	rosterFieldMembers    = "members"
+	rosterFieldMember     = "member"
+	rosterFieldRosterID   = "roster_id"
+	rosterFieldOncallID   = "oncall_id"
+
+	rosterFieldProtectActiveOncall = "protect_active_oncall"
+	rosterFieldAutoAddTeamMembers  = "auto_add_team_members"
+	rosterFieldMigrateOnTeamChange = "migrate_on_team_change"
+
+	rosterFieldMemberCount     = "member_count"
+	rosterFieldInRotationCount = "in_rotation_count"
 )
 
 func resourceRoster() *schema.Resource {
@@ -26,28 +43,97 @@ func resourceRoster() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceRosterImport,
 		},
+		CustomizeDiff: customdiff.All(
+			teamPrefixCustomizeDiff(rosterFieldTeam),
+			rosterReferencesCustomizeDiff,
+			rosterIDCustomizeDiff,
+			rosterTeamChangeCustomizeDiff,
+			rosterMemberCustomizeDiff,
+			rosterCountsCustomizeDiff,
+			revisionCustomizeDiff(),
+		),
 
 		Schema: map[string]*schema.Schema{
 			rosterFieldName: &schema.Schema{
-				Type:        schema.TypeString,
-				ForceNew:    true,
-				Optional:    true,
-				Computed:    true,
-				Description: "Name of the roster, if blank will default to team name",
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateName,
+				Description:      fmt.Sprintf("Name of the roster, if blank will default to team name, or be generated from %s. At most %d characters and no slashes", rosterFieldNamePrefix, maxNameLength),
+			},
+			rosterFieldNamePrefix: &schema.Schema{
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Optional:         true,
+				Computed:         true,
+				ConflictsWith:    []string{rosterFieldName},
+				ValidateDiagFunc: validateNamePrefix,
+				Description:      fmt.Sprintf("Generate a unique name for the roster starting with this prefix, so rosters created with count or for_each, or a replacement created before the roster it replaces with create_before_destroy, don't collide. At most %d characters. Conflicts with %s", maxNameLength-resource.UniqueIDSuffixLength, rosterFieldName),
 			},
 			rosterFieldTeam: &schema.Schema{
 				Type:        schema.TypeString,
-				ForceNew:    true,
 				Required:    true,
-				Description: "Name of team this roster should be assigned to",
+				Description: fmt.Sprintf("Name of team this roster should be assigned to. Changing it replaces the roster, unless %s is set", rosterFieldMigrateOnTeamChange),
 			},
 			rosterFieldMembers: &schema.Schema{
-				Type:        schema.TypeSet,
-				Description: "List of usernames which should be added to the roster",
-				Required:    true,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
+				Type:         schema.TypeSet,
+				Description:  fmt.Sprintf("List of usernames which should be added to the roster, all in rotation. Use %s blocks instead to take members out of rotation or order them", rosterFieldMember),
+				Optional:     true,
+				Computed:     true,
+				ExactlyOneOf: []string{rosterFieldMembers, rosterFieldMember},
+				Elem:         usernameElem(),
+				Set:          hashUsername,
+			},
+			rosterFieldMember: &schema.Schema{
+				Type:         schema.TypeSet,
+				Description:  fmt.Sprintf("Members of the roster, with whether they are in rotation and their order. Conflicts with %s", rosterFieldMembers),
+				Optional:     true,
+				ExactlyOneOf: []string{rosterFieldMembers, rosterFieldMember},
+				Elem:         rosterMemberResource(),
+			},
+			rosterFieldProtectActiveOncall: &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Fail to remove members, or delete the roster, while someone being removed is on call for the team, instead of leaving their shift uncovered",
+			},
+			rosterFieldAutoAddTeamMembers: &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "Add members who aren't members of the team to it before putting them on the roster, for oncall deployments that only let team members onto rosters. They stay on the team when they are taken off the roster",
+			},
+			rosterFieldMigrateOnTeamChange: &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: fmt.Sprintf("When %s changes, create the roster on the new team, copy its members and schedules there and populate them, and only then delete the old roster, instead of replacing it, which leaves the role uncovered until the schedules are recreated. Schedules referring to the roster's roster_id are updated to the copies", rosterFieldTeam),
+			},
+			fieldRevision: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the roster and its members as read from oncall, which changes whenever it is changed, including outside of terraform e.g. in the oncall UI. For replace_triggered_by and postconditions to react to such changes",
+			},
+			rosterFieldRosterID: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "ID of the roster in team/roster format, for the roster_id of schedules. Prefer it over id, which may change format",
+			},
+			rosterFieldOncallID: &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Numeric ID of the roster in oncall, which the provider finds the roster by so it is still managed after being renamed, e.g. in the oncall UI",
+			},
+			rosterFieldMemberCount: &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of members of the roster, in rotation or not. Planned from the configured members, for postconditions to check",
+			},
+			rosterFieldInRotationCount: &schema.Schema{
+				Type:        schema.TypeInt,
+				Computed:    true,
+				Description: "Number of members of the roster in rotation, who schedules on the roster put on call. Planned from the configured members, for postconditions to check, e.g. that a primary roster has at least 4 people in rotation",
 			},
 		},
 	}
@@ -55,7 +141,7 @@ func resourceRoster() *schema.Resource {
 
 func resourceRosterCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
 	diags := diag.Diagnostics{}
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	teamName := d.Get(rosterFieldTeam).(string)
 	rosterName := d.Get(rosterFieldName).(string)
@@ -70,6 +156,9 @@ func resourceRosterCreate(ctx context.Context, d *schema.ResourceData, m interfa
 		return diags
 	}
 
+	if prefix := d.Get(rosterFieldNamePrefix).(string); rosterName == "" && prefix != "" {
+		rosterName = resource.PrefixedUniqueId(prefix)
+	}
 	if rosterName == "" {
 		rosterName = teamName
 	}
@@ -77,33 +166,84 @@ func resourceRosterCreate(ctx context.Context, d *schema.ResourceData, m interfa
 	traceLog("Going to create roster: %s/%s", teamName, rosterName)
 	roster, err := c.CreateRoster(teamName, rosterName)
 	if err != nil {
-		if strings.Contains(err.Error(), "(422)") {
-			return diagFromErrf(err, "Roster already exists, please import using id '%s'", getRosterID(teamName, rosterName))
-		}
-		return diagFromErrf(err, "Creating oncall roster")
+		return createErrorDiags(err, "Creating oncall roster", getRosterID(teamName, rosterName), rosterFieldName, rosterFieldTeam)
 	}
 
-	traceLog("Setting roster resource id to %q", roster.ID)
+	traceLog("Setting roster resource id to %q", getRosterID(teamName, rosterName))
 	d.SetId(getRosterID(teamName, rosterName))
+	d.Set(rosterFieldOncallID, roster.ID)
 
-	traceLog("Getting roster %s/%s requested members", teamName, rosterName)
-	members := getResourceStringSet(d, rosterFieldMembers)
-
-	traceLog("Going to set roster %s/%s members to %v", teamName, rosterName, members)
-	err = c.SetRosterUsers(teamName, rosterName, members)
+	if d.Get(rosterFieldAutoAddTeamMembers).(bool) {
+		err = addMissingTeamMembers(c, teamName, rosterRequestedMembers(d))
+		if err != nil {
+			return diagFromErrf(err, "Adding roster members to team %s", teamName)
+		}
+	}
+	err = setRosterMembersFromResource(c, d, teamName, rosterName)
 	if err != nil {
-		return diagFromErrf(err, "Setting roster members")
+		return rosterMembersErrorDiags(c, teamName, rosterRequestedMembers(d), err)
 	}
 
 	resourceRosterRead(ctx, d, m)
 	return diags
 }
 
+// setRosterMembersFromResource sets the roster's members from whichever of
+// members or the member blocks is configured
+func setRosterMembersFromResource(c *oncall.Client, d *schema.ResourceData, teamName, rosterName string) error {
+	if blocks := d.Get(rosterFieldMember).(*schema.Set); blocks.Len() > 0 {
+		members, err := rosterMembersFromSet(blocks)
+		if err != nil {
+			return err
+		}
+		return setRosterMembers(c, teamName, rosterName, members)
+	}
+
+	traceLog("Getting roster %s/%s requested members", teamName, rosterName)
+	members := getResourceStringSet(d, rosterFieldMembers)
+
+	traceLog("Going to set roster %s/%s members to %v", teamName, rosterName, members)
+	return c.SetRosterUsers(teamName, rosterName, members)
+}
+
+// rosterRequestedMembers is the usernames the configuration wants on the roster
+func rosterRequestedMembers(d *schema.ResourceData) []string {
+	blocks := d.Get(rosterFieldMember).(*schema.Set)
+	if blocks.Len() == 0 {
+		return getResourceStringSet(d, rosterFieldMembers)
+	}
+	members := make([]string, 0, blocks.Len())
+	for _, raw := range blocks.List() {
+		members = append(members, normalizeUsername(raw.(map[string]interface{})[rosterMemberFieldName].(string)))
+	}
+	return members
+}
+
 func resourceRosterImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
-	teamName, rosterName, err := parseRosterID(d.Id())
+	c := m.(*providerMeta).clientFor(ctx)
+	teamName, rosterName, oncallID, err := parseRosterImportID(d.Id())
 	if err != nil {
-		return nil, errors.Wrap(err, "Parsing roster ID, this is an internal error")
+		return nil, errors.Wrap(err, "Parsing roster import ID")
+	}
+	if oncallID != 0 {
+		teamName, err = canonicalTeamName(c, teamName)
+		if err != nil {
+			return nil, errors.Wrap(err, "Resolving roster import ID")
+		}
+		var found bool
+		rosterName, found, err = findRosterName(c, teamName, oncallID)
+		if err != nil {
+			return nil, errors.Wrap(err, "Resolving roster import ID")
+		}
+		if !found {
+			return nil, fmt.Errorf("Team %s has no roster with ID %d", teamName, oncallID)
+		}
 	}
+	teamName, rosterName, err = canonicalRosterID(c, teamName, rosterName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Resolving roster import ID")
+	}
+	d.SetId(getRosterID(teamName, rosterName))
 
 	traceLog("Going to import roster %q as team: %s, roster: %s", d.Id(), teamName, rosterName)
 	d.Set(rosterFieldTeam, teamName)
@@ -113,11 +253,14 @@ func resourceRosterImport(ctx context.Context, d *schema.ResourceData, m interfa
 	if len(readErr) > 0 {
 		err = errors.New(readErr[0].Summary)
 	}
+	if err == nil && d.Id() == "" {
+		err = fmt.Errorf("Roster %s/%s does not exist", teamName, rosterName)
+	}
 	return []*schema.ResourceData{d}, errors.Wrap(err, "Reading resource for import")
 }
 
 func resourceRosterRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
@@ -127,12 +270,39 @@ func resourceRosterRead(ctx context.Context, d *schema.ResourceData, m interface
 		return diagFromErrf(err, "Parsing roster ID, this is an internal error")
 	}
 
+	// The numeric ID stays the same when the roster is renamed, e.g. in the
+	// oncall UI, so follow the rename rather than losing track of the roster
+	if oncallID := d.Get(rosterFieldOncallID).(int); oncallID != 0 {
+		name, found, err := findRosterName(c, teamName, oncallID)
+		if isNotFound(err) {
+			warnLog("Team %s of roster %s no longer exists, removing it from state", teamName, rosterName)
+			d.SetId("")
+			return diags
+		}
+		if err != nil {
+			return diagFromErrf(err, "Getting rosters of team %s", teamName)
+		}
+		if found && name != rosterName {
+			infoLog("Roster %d was renamed from %s to %s", oncallID, getRosterID(teamName, rosterName), getRosterID(teamName, name))
+			rosterName = name
+			d.SetId(getRosterID(teamName, rosterName))
+		}
+	}
+
 	roster, err := c.GetRoster(teamName, rosterName)
+	if isNotFound(err) {
+		warnLog("Roster %s/%s no longer exists, removing it from state", teamName, rosterName)
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
 		return diagFromErrf(err, "Getting roster %s/%s", teamName, rosterName)
 	}
 
 	d.Set(rosterFieldName, roster.Name)
+	d.Set(rosterFieldNamePrefix, namePrefixFromName(roster.Name))
+	d.Set(rosterFieldOncallID, roster.ID)
+	d.Set(rosterFieldRosterID, getRosterID(teamName, roster.Name))
 
 	members := make([]string, 0, len(roster.Users))
 	for _, m := range roster.Users {
@@ -140,11 +310,108 @@ func resourceRosterRead(ctx context.Context, d *schema.ResourceData, m interface
 	}
 	setResourceStringSet(d, rosterFieldMembers, members)
 
+	rosterMembers, err := getRosterMembers(c, teamName, roster.Name)
+	if err != nil {
+		return diagFromErrf(err, "Getting roster %s/%s", teamName, rosterName)
+	}
+	d.Set(fieldRevision, objectRevision(struct {
+		Name    string         `json:"name"`
+		Members []rosterMember `json:"members"`
+	}{roster.Name, rosterMembers}))
+
+	inRotation := 0
+	for _, member := range rosterMembers {
+		if member.InRotation {
+			inRotation++
+		}
+	}
+	d.Set(rosterFieldMemberCount, len(rosterMembers))
+	d.Set(rosterFieldInRotationCount, inRotation)
+
+	// Member blocks are only kept in state when they are used, so rosters
+	// configured with members don't plan to add them
+	if d.Get(rosterFieldMember).(*schema.Set).Len() > 0 {
+		readRosterMembers(d, rosterMembers)
+	}
+
 	return diags
 }
 
+// rosterIDCustomizeDiff plans roster_id as soon as the team and roster name
+// are known, so schedules referring to a new roster can be planned in full
+func rosterIDCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" || !d.NewValueKnown(rosterFieldTeam) || !d.NewValueKnown(rosterFieldName) {
+		return nil
+	}
+
+	teamName := d.Get(rosterFieldTeam).(string)
+	rosterName := d.Get(rosterFieldName).(string)
+	if rosterName == "" {
+		rosterName = teamName
+	}
+	return d.SetNew(rosterFieldRosterID, getRosterID(teamName, rosterName))
+}
+
+// rosterMemberCustomizeDiff checks the member blocks' orders at plan time, and
+// plans members from them so references to members see the new usernames
+func rosterMemberCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	blocks := d.Get(rosterFieldMember).(*schema.Set)
+	if blocks.Len() == 0 || !d.NewValueKnown(rosterFieldMember) {
+		return nil
+	}
+
+	members, err := rosterMembersFromSet(blocks)
+	if err != nil {
+		return err
+	}
+	if !d.HasChange(rosterFieldMember) {
+		return nil
+	}
+	names := make([]interface{}, 0, len(members))
+	for _, member := range members {
+		names = append(names, member.Name)
+	}
+	return d.SetNew(rosterFieldMembers, schema.NewSet(hashUsername, names))
+}
+
+// rosterCountsCustomizeDiff plans the member counts from the configured
+// members, so they can be checked before the roster is changed
+func rosterCountsCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() != "" && !d.HasChange(rosterFieldMembers) && !d.HasChange(rosterFieldMember) {
+		return nil
+	}
+	if !d.NewValueKnown(rosterFieldMembers) || !d.NewValueKnown(rosterFieldMember) {
+		if err := d.SetNewComputed(rosterFieldMemberCount); err != nil {
+			return err
+		}
+		return d.SetNewComputed(rosterFieldInRotationCount)
+	}
+
+	count, inRotation := d.Get(rosterFieldMembers).(*schema.Set).Len(), 0
+	if blocks := d.Get(rosterFieldMember).(*schema.Set); blocks.Len() > 0 {
+		members, err := rosterMembersFromSet(blocks)
+		if err != nil {
+			return err
+		}
+		count = len(members)
+		for _, member := range members {
+			if member.InRotation {
+				inRotation++
+			}
+		}
+	} else {
+		// Members listed in members are all put in rotation
+		inRotation = count
+	}
+
+	if err := d.SetNew(rosterFieldMemberCount, count); err != nil {
+		return err
+	}
+	return d.SetNew(rosterFieldInRotationCount, inRotation)
+}
+
 func resourceRosterUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	traceLog("Going to update roster %q", d.Id())
 	teamName, rosterName, err := parseRosterID(d.Id())
@@ -152,29 +419,70 @@ func resourceRosterUpdate(ctx context.Context, d *schema.ResourceData, m interfa
 		return diagFromErrf(err, "Parsing roster ID, this is an internal error")
 	}
 
-	traceLog("Getting roster %s/%s requested members", teamName, rosterName)
-	members := getResourceStringSet(d, rosterFieldMembers)
+	if d.HasChange(rosterFieldTeam) {
+		diags := migrateRosterTeam(ctx, c, d, teamName, rosterName)
+		if diags.HasError() {
+			return diags
+		}
+		return append(diags, resourceRosterRead(ctx, d, m)...)
+	}
 
-	traceLog("Going to set roster %s/%s members to %v", teamName, rosterName, members)
-	err = c.SetRosterUsers(teamName, rosterName, members)
+	if d.Get(rosterFieldProtectActiveOncall).(bool) {
+		// Compare with the roster itself rather than the old members, which
+		// are computed and may not be in state when switching to member blocks
+		current, err := c.GetRosterUsers(teamName, rosterName)
+		if err != nil {
+			return diagFromErrf(err, "Getting roster %s/%s members", teamName, rosterName)
+		}
+		members := rosterRequestedMembers(d)
+		removed := []string{}
+		for _, member := range current {
+			if !stringSliceContains(members, member) {
+				removed = append(removed, member)
+			}
+		}
+		diags := checkNotOnCall(c, teamName, rosterName, removed, time.Now())
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	if d.Get(rosterFieldAutoAddTeamMembers).(bool) {
+		err = addMissingTeamMembers(c, teamName, rosterRequestedMembers(d))
+		if err != nil {
+			return diagFromErrf(err, "Adding roster members to team %s", teamName)
+		}
+	}
+	err = setRosterMembersFromResource(c, d, teamName, rosterName)
 	if err != nil {
-		return diagFromErrf(err, "Setting roster members")
+		return rosterMembersErrorDiags(c, teamName, rosterRequestedMembers(d), err)
 	}
 
 	return resourceRosterRead(ctx, d, m)
 }
 
 func resourceRosterDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	teamName, rosterName, err := parseRosterID(d.Id())
 	if err != nil {
 		return diagFromErrf(err, "Parsing roster ID, this is an internal error")
 	}
 
-	err = c.DeleteRoster(teamName, rosterName)
+	if d.Get(rosterFieldProtectActiveOncall).(bool) {
+		diags := checkNotOnCall(c, teamName, rosterName, rosterRequestedMembers(d), time.Now())
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	// Deleting the team first deletes its rosters along with it, and the
+	// roster's schedules may be being deleted at the same time
+	what := "roster " + getRosterID(teamName, rosterName)
+	children := []string{"schedule"}
+	err = deleteAfterChildren(ctx, what, children, func() error { return c.DeleteRoster(teamName, rosterName) })
 	if err != nil {
-		return diagFromErrf(err, "Deleting roster")
+		return deleteOrderDiags(err, "Deleting roster", what, children, scheduleFieldRosterID)
 	}
 
 	// d.SetId("") is automatically called assuming delete returns no errors, but
@@ -184,8 +492,90 @@ func resourceRosterDelete(ctx context.Context, d *schema.ResourceData, m interfa
 	return diag.Diagnostics{}
 }
 
+// checkNotOnCall stops members being taken off a roster while they are on call
+// for the team, which would leave the rest of their shift uncovered
+func checkNotOnCall(c *oncall.Client, teamName, rosterName string, removing []string, now time.Time) diag.Diagnostics {
+	if len(removing) == 0 {
+		return nil
+	}
+
+	events, err := getCurrentEvents(c, teamName, now)
+	if err != nil {
+		return diagFromErrf(err, "Checking who is on call for team %s", teamName)
+	}
+
+	var diags diag.Diagnostics
+	for _, e := range events {
+		if !stringSliceContains(removing, e.User) {
+			continue
+		}
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("%s is on call as %s for team %s until %s, not removing them from roster %s", e.User, e.Role, teamName, time.Unix(e.End, 0).UTC().Format(time.RFC3339), rosterName),
+			Detail: fmt.Sprintf("Their shift runs from %s to %s. Apply again once it is over or covered by someone else, or set %s = false to remove them anyway.",
+				time.Unix(e.Start, 0).UTC().Format(time.RFC3339), time.Unix(e.End, 0).UTC().Format(time.RFC3339), rosterFieldProtectActiveOncall),
+			AttributePath: cty.Path{cty.GetAttrStep{Name: rosterFieldMembers}},
+		})
+	}
+	return diags
+}
+
+// parseRosterImportID parses the ID a roster is imported with, either its
+// team/roster ID or attributes with the team and its name or numeric ID, e.g.
+// team=platform,oncall_id=42
+func parseRosterImportID(importID string) (team, roster string, oncallID int, err error) {
+	if !strings.Contains(importID, "=") {
+		team, roster, err = parseRosterID(importID)
+		return
+	}
+
+	for _, pair := range strings.Split(importID, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), "=", 2)
+		if len(kv) != 2 {
+			return "", "", 0, fmt.Errorf("Unparseable roster import id attribute %q (should be name=value)", pair)
+		}
+		switch kv[0] {
+		case rosterFieldTeam:
+			team = kv[1]
+		case rosterFieldName:
+			roster = kv[1]
+		case rosterFieldOncallID:
+			oncallID, err = strconv.Atoi(strings.TrimSpace(kv[1]))
+			if err != nil || oncallID <= 0 {
+				return "", "", 0, fmt.Errorf("Roster import id attribute %s must be a positive number, got %q", rosterFieldOncallID, kv[1])
+			}
+		default:
+			return "", "", 0, fmt.Errorf("Unknown roster import id attribute %q, should be one of %s, %s, %s", kv[0], rosterFieldTeam, rosterFieldName, rosterFieldOncallID)
+		}
+	}
+	switch {
+	case strings.TrimSpace(team) == "":
+		err = fmt.Errorf("Roster import id did not specify %s", rosterFieldTeam)
+	case (roster == "") == (oncallID == 0):
+		err = fmt.Errorf("Roster import id must specify one of %s or %s", rosterFieldName, rosterFieldOncallID)
+	}
+	return
+}
+
+// findRosterName looks up the name of the team's roster with the numeric ID
+func findRosterName(c *oncall.Client, team string, oncallID int) (string, bool, error) {
+	rosters := map[string]struct {
+		ID int `json:"id"`
+	}{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters", url.PathEscape(team)), &rosters)
+	if err != nil {
+		return "", false, errors.Wrapf(err, "Fetching rosters of team %s", team)
+	}
+	for name, r := range rosters {
+		if r.ID == oncallID {
+			return name, true, nil
+		}
+	}
+	return "", false, nil
+}
+
 func getRosterID(team, roster string) string {
-	return fmt.Sprintf("%s/%s", team, roster)
+	return fmt.Sprintf("%s/%s", escapeIDPart(team), escapeIDPart(roster))
 }
 
 func parseRosterID(rosterID string) (team, roster string, err error) {
@@ -195,8 +585,8 @@ func parseRosterID(rosterID string) (team, roster string, err error) {
 		team = tr[0]
 		err = errors.New("Only team name found in roster id")
 	} else if len(tr) == 2 {
-		team = tr[0]
-		roster = tr[1]
+		team = unescapeIDPart(tr[0])
+		roster = unescapeIDPart(tr[1])
 	} else {
 		errorLog("Giving roster id %q did not match expected team/roster format", rosterID)
 		err = errors.New("Unparseable roster id")
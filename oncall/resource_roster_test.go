@@ -0,0 +1,226 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_rosterIDCustomizeDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema:        resourceRoster().Schema,
+		CustomizeDiff: rosterIDCustomizeDiff,
+	}
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   string
+	}{
+		{
+			name:   "Named roster",
+			config: map[string]interface{}{"team": "platform", "name": "sre", "members": []interface{}{"a"}},
+			want:   "platform/sre",
+		},
+		{
+			// An unset name can't be told apart from one that isn't known yet
+			name:   "Roster named after the team",
+			config: map[string]interface{}{"team": "platform", "members": []interface{}{"a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(tt.config), nil)
+			if err != nil {
+				t.Fatalf("rosterIDCustomizeDiff() error = %v", err)
+			}
+			got := diff.Attributes[rosterFieldRosterID]
+			if got == nil || got.NewComputed != (tt.want == "") || got.New != tt.want {
+				t.Errorf("rosterIDCustomizeDiff() planned roster_id = %+v, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_rosterCountsCustomizeDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema:        resourceRoster().Schema,
+		CustomizeDiff: rosterCountsCustomizeDiff,
+	}
+	state := &terraform.InstanceState{ID: "platform/sre", Attributes: map[string]string{
+		"team": "platform", "name": "sre", "members.#": "2", "members.1": "a", "members.2": "b",
+		"protect_active_oncall": "false", "member_count": "2", "in_rotation_count": "2",
+	}}
+
+	tests := []struct {
+		name           string
+		state          *terraform.InstanceState
+		config         map[string]interface{}
+		wantCount      string
+		wantInRotation string
+	}{
+		{
+			name:           "Members",
+			config:         map[string]interface{}{"team": "platform", "name": "sre", "members": []interface{}{"a", "b", "c"}},
+			wantCount:      "3",
+			wantInRotation: "3",
+		},
+		{
+			name: "Member blocks",
+			config: map[string]interface{}{"team": "platform", "name": "sre", "member": []interface{}{
+				map[string]interface{}{"name": "a"},
+				map[string]interface{}{"name": "b", "in_rotation": false},
+			}},
+			wantCount:      "2",
+			wantInRotation: "1",
+		},
+		{
+			name:   "Unchanged",
+			state:  state,
+			config: map[string]interface{}{"team": "platform", "name": "sre", "members": []interface{}{"a", "b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := r.Diff(context.Background(), tt.state, terraform.NewResourceConfigRaw(tt.config), nil)
+			if err != nil {
+				t.Fatalf("rosterCountsCustomizeDiff() error = %v", err)
+			}
+			for field, want := range map[string]string{rosterFieldMemberCount: tt.wantCount, rosterFieldInRotationCount: tt.wantInRotation} {
+				var got *terraform.ResourceAttrDiff
+				if diff != nil {
+					got = diff.Attributes[field]
+				}
+				if (got == nil) != (want == "") || (got != nil && got.New != want) {
+					t.Errorf("rosterCountsCustomizeDiff() planned %s = %+v, want %q", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_checkNotOnCall(t *testing.T) {
+	// 2025-01-01T12:00:00Z
+	now := time.Unix(1735732800, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/events" || r.URL.Query().Get("team__eq") != "team" ||
+			r.URL.Query().Get("start__le") != "1735732800" || r.URL.Query().Get("end__gt") != "1735732800" {
+			w.WriteHeader(404)
+			return
+		}
+		w.Write([]byte(`[{"id":1,"user":"alice","role":"primary","start":1735689600,"end":1736294400}]`))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		team     string
+		removing []string
+		wantErr  bool
+	}{
+		{
+			name:     "Removing someone on call",
+			team:     "team",
+			removing: []string{"bob", "alice"},
+			wantErr:  true,
+		},
+		{
+			name:     "Removing someone off call",
+			team:     "team",
+			removing: []string{"bob"},
+		},
+		{
+			name: "Removing nobody doesn't check",
+			team: "missing",
+		},
+		{
+			name:     "Checking fails",
+			team:     "missing",
+			removing: []string{"alice"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := checkNotOnCall(c, tt.team, "roster", tt.removing, now)
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("checkNotOnCall() = %v, wantErr %v", diags, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_parseRosterImportID(t *testing.T) {
+	tests := []struct {
+		name         string
+		importID     string
+		wantTeam     string
+		wantRoster   string
+		wantOncallID int
+		wantErr      bool
+	}{
+		{name: "ID", importID: "platform/sre", wantTeam: "platform", wantRoster: "sre"},
+		{name: "Numeric ID", importID: "team=platform,oncall_id=42", wantTeam: "platform", wantOncallID: 42},
+		{name: "Name", importID: "team=platform, name=sre", wantTeam: "platform", wantRoster: "sre"},
+		{name: "No team", importID: "oncall_id=42", wantErr: true},
+		{name: "Name and numeric ID", importID: "team=platform,name=sre,oncall_id=42", wantErr: true},
+		{name: "Bad numeric ID", importID: "team=platform,oncall_id=sre", wantErr: true},
+		{name: "Unknown attribute", importID: "team=platform,roster=sre", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team, roster, oncallID, err := parseRosterImportID(tt.importID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseRosterImportID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if team != tt.wantTeam || roster != tt.wantRoster || oncallID != tt.wantOncallID {
+				t.Errorf("parseRosterImportID() = %q, %q, %d, want %q, %q, %d", team, roster, oncallID, tt.wantTeam, tt.wantRoster, tt.wantOncallID)
+			}
+		})
+	}
+}
+
+func Test_resourceRosterReadRenamed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/platform/rosters":
+			w.Write([]byte(`{"sre-renamed": {"id": 42, "users": [], "schedules": []}}`))
+		case "/api/v0/teams/platform/rosters/sre-renamed":
+			w.Write([]byte(`{"id": 42, "users": [{"name": "alice", "in_rotation": true}], "schedules": []}`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceRoster().Schema, map[string]interface{}{"team": "platform", "name": "sre", "members": []interface{}{"alice"}})
+	d.SetId("platform/sre")
+	d.Set(rosterFieldOncallID, 42)
+
+	diags := resourceRosterRead(context.Background(), d, &providerMeta{client: c})
+	if diags.HasError() {
+		t.Fatalf("resourceRosterRead() error = %v", diags)
+	}
+	if d.Id() != "platform/sre-renamed" || d.Get(rosterFieldName).(string) != "sre-renamed" {
+		t.Errorf("resourceRosterRead() read roster %s named %s, want it to follow the rename", d.Id(), d.Get(rosterFieldName))
+	}
+}
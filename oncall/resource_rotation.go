@@ -0,0 +1,397 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	"maze.io/x/duration"
+)
+
+const (
+	rotationFieldTeam        = "team"
+	rotationFieldRole        = "role"
+	rotationFieldRosterName  = "roster_name"
+	rotationFieldMembers     = "members"
+	rotationFieldHandoffDay  = "handoff_day"
+	rotationFieldHandoffTime = "handoff_time"
+	rotationFieldLength      = "length"
+)
+
+// rotationSchedule is a round robin schedule, the oncall client doesn't know
+// about the scheduler's data which holds the order to rotate through members in
+type rotationSchedule struct {
+	ID                    int                    `json:"id,omitempty"`
+	Team                  string                 `json:"team"`
+	Roster                string                 `json:"roster"`
+	Role                  string                 `json:"role"`
+	AdvancedMode          int                    `json:"advanced_mode"`
+	AutoPopulateThreshold int                    `json:"auto_populate_threshold"`
+	Events                []oncall.ScheduleEvent `json:"events"`
+	Scheduler             rotationScheduler      `json:"scheduler"`
+}
+
+type rotationScheduler struct {
+	Name string   `json:"name"`
+	Data []string `json:"data"`
+}
+
+func resourceRotation() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceRotationCreate,
+		ReadContext:   resourceRotationRead,
+		UpdateContext: resourceRotationUpdate,
+		DeleteContext: resourceRotationDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceRotationImport,
+		},
+		CustomizeDiff: customdiff.All(
+			teamPrefixCustomizeDiff(rotationFieldTeam),
+			revisionCustomizeDiff(),
+			autoPopulateDaysCustomizeDiff,
+		),
+
+		Schema: map[string]*schema.Schema{
+			rotationFieldTeam: {
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Name of the team the rotation belongs to",
+			},
+			rotationFieldRole: {
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Required:         true,
+				ValidateDiagFunc: validateStringSliceContains(roleNames),
+				Description:      fmt.Sprintf("Role the rotation fills, one of %v", roleNames),
+			},
+			rotationFieldRosterName: {
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateName,
+				Description:      fmt.Sprintf("Name of the roster the rotation manages, defaults to the role. At most %d characters and no slashes", maxNameLength),
+			},
+			rotationFieldMembers: {
+				Type:        schema.TypeList,
+				Required:    true,
+				MinItems:    1,
+				Description: "Usernames to rotate through, in order",
+				Elem:        usernameElem(),
+			},
+			rotationFieldHandoffDay: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateStringSliceContains(daysOfWeek),
+				Description:      fmt.Sprintf("Day of week the rotation hands off on, one of: %v", daysOfWeek),
+			},
+			rotationFieldHandoffTime: {
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validate24HourTime,
+				StateFunc:        timeOfDayStateFunc,
+				Description:      "Time the rotation hands off at in 24 hour time format, e.g. 09:00 or 09:00:30",
+			},
+			rotationFieldLength: {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          basicScheduleRotationWeekly,
+				ValidateDiagFunc: validateStringSliceContains(basicScheduleRotations),
+				Description:      fmt.Sprintf("How long each member is on call for, one of: %v", basicScheduleRotations),
+			},
+			scheduleFieldAutoPopulateDays: {
+				Type:             schema.TypeInt,
+				Optional:         true,
+				Default:          21,
+				DiffSuppressFunc: suppressRoundedAutoPopulateDays,
+				ValidateDiagFunc: validateAutoPopulateDays,
+				Description:      "How many days in advance to plan the rotation. Oncall rounds this up to a whole number of weeks. " + autoPopulateDaysDescription(defaultMaxAutoPopulateDays),
+			},
+			fieldRevision: {
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the rotation schedule and its members as read from oncall, which changes whenever it is changed, including outside of terraform e.g. in the oncall UI. For replace_triggered_by and postconditions to react to such changes",
+			},
+			scheduleFieldTrimFutureEvents: {
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     false,
+				Description: "When auto_populate_days is lowered, delete the events this rotation already populated that start past the new horizon instead of leaving them on the calendar with a warning",
+			},
+		},
+	}
+}
+
+func resourceRotationCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName := d.Get(rotationFieldTeam).(string)
+	role := d.Get(rotationFieldRole).(string)
+	rosterName := d.Get(rotationFieldRosterName).(string)
+	if rosterName == "" {
+		rosterName = role
+	}
+	resourceID := getScheduleID(teamName, rosterName, role)
+
+	sched, err := rotationScheduleFromResource(d, teamName, rosterName, weekStartFor(m))
+	if err != nil {
+		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
+	}
+
+	traceLog("Going to create rotation roster: %s/%s", teamName, rosterName)
+	_, err = c.CreateRoster(teamName, rosterName)
+	if err != nil {
+		return createErrorDiags(err, "Creating rotation roster", resourceID, rotationFieldRosterName, rotationFieldTeam)
+	}
+
+	// Don't leave half a rotation behind if the rest of it can't be created
+	rollback := func(diags diag.Diagnostics) diag.Diagnostics {
+		warnLog("Removing roster %s/%s as the rotation could not be created", teamName, rosterName)
+		err := c.DeleteRoster(teamName, rosterName)
+		if err != nil {
+			diags = append(diags, diagFromErrf(err, "Removing roster %s/%s after the rotation failed to be created", teamName, rosterName)...)
+		}
+		return diags
+	}
+
+	traceLog("Going to set rotation roster %s/%s members to %v", teamName, rosterName, sched.Scheduler.Data)
+	err = c.SetRosterUsers(teamName, rosterName, sched.Scheduler.Data)
+	if err != nil {
+		return rollback(diagFromErrf(err, "Setting rotation members"))
+	}
+
+	traceLog("Going to create rotation schedule: %s", resourceID)
+	_, err = c.Post(fmt.Sprintf("/api/v0/teams/%s/rosters/%s/schedules", url.PathEscape(teamName), url.PathEscape(rosterName)), sched, nil)
+	if err != nil {
+		return rollback(createErrorDiags(err, "Creating rotation schedule", resourceID, rotationFieldRole, scheduleFieldAutoPopulateDays))
+	}
+
+	d.SetId(resourceID)
+
+	err = populateRosterSchedule(ctx, c, teamName, rosterName, role, nil, time.Now())
+	if err != nil {
+		return diagFromErrf(err, "Populating rotation schedule")
+	}
+	return resourceRotationRead(ctx, d, m)
+}
+
+func resourceRotationImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	teamName, rosterName, role, err := canonicalScheduleImportID(ctx, d.Id(), m)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parsing import ID")
+	}
+	d.SetId(getScheduleID(teamName, rosterName, role))
+	return []*schema.ResourceData{d}, nil
+}
+
+func resourceRotationRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName, rosterName, role, err := parseScheduleID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing rotation ID, this is an internal error")
+	}
+
+	sched, found, err := getRotationSchedule(c, teamName, rosterName, role)
+	if err != nil {
+		return diagFromErrf(err, "Getting rotation schedule %s", d.Id())
+	}
+	if !found {
+		warnLog("Rotation %s no longer exists, removing it from state", d.Id())
+		d.SetId("")
+		return diags
+	}
+
+	members, err := c.GetRosterUsers(teamName, rosterName)
+	if err != nil {
+		return diagFromErrf(err, "Getting rotation members of %s/%s", teamName, rosterName)
+	}
+
+	d.Set(rotationFieldTeam, teamName)
+	d.Set(rotationFieldRosterName, rosterName)
+	d.Set(rotationFieldRole, sched.Role)
+	diags = append(diags, checkReadRole(m, d.Id(), sched.Role)...)
+	d.Set(rotationFieldMembers, orderedMembers(members, sched.Scheduler.Data))
+	d.Set(scheduleFieldAutoPopulateDays, sched.AutoPopulateThreshold)
+
+	sortedMembers := append([]string{}, members...)
+	sort.Strings(sortedMembers)
+	sched.Events = sortedScheduleEvents(sched.Events)
+	d.Set(fieldRevision, objectRevision(struct {
+		rotationSchedule
+		Members []string `json:"members"`
+	}{sched, sortedMembers}))
+
+	if len(sched.Events) == 1 {
+		dayOfWeekIndex, startHour, startMin, startSec := secondsToDayTime(sched.Events[0].Start, weekStartFor(m))
+		d.Set(rotationFieldHandoffDay, daysOfWeek[dayOfWeekIndex])
+		d.Set(rotationFieldHandoffTime, formatTimeOfDay(startHour, startMin, startSec))
+
+		d.Set(rotationFieldLength, basicScheduleRotationWeekly)
+		if sched.Events[0].Duration == int(duration.Fortnight.Seconds()) {
+			d.Set(rotationFieldLength, basicScheduleRotationBiWeekly)
+		}
+	} else {
+		diags = append(diags, normalizationDiags(m, "Rotation %s has %d events instead of one, it has been changed outside of terraform", d.Id(), len(sched.Events))...)
+		d.Set(rotationFieldHandoffDay, "")
+		d.Set(rotationFieldHandoffTime, "")
+	}
+
+	return diags
+}
+
+func resourceRotationUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	traceLog("Going to update rotation %q", d.Id())
+	teamName, rosterName, role, err := parseScheduleID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing rotation ID, this is an internal error")
+	}
+
+	current, found, err := getRotationSchedule(c, teamName, rosterName, role)
+	if err != nil {
+		return diagFromErrf(err, "Getting rotation schedule %s", d.Id())
+	}
+	if !found {
+		return diag.Errorf("Rotation schedule %s no longer exists", d.Id())
+	}
+
+	sched, err := rotationScheduleFromResource(d, teamName, rosterName, weekStartFor(m))
+	if err != nil {
+		return diagFromErrf(err, "Failed to parse resource into oncall schedule")
+	}
+
+	if d.HasChange(rotationFieldMembers) {
+		traceLog("Going to set rotation roster %s/%s members to %v", teamName, rosterName, sched.Scheduler.Data)
+		err = c.SetRosterUsers(teamName, rosterName, sched.Scheduler.Data)
+		if err != nil {
+			return diagFromErrf(err, "Setting rotation members")
+		}
+	}
+
+	traceLog("Going to update rotation schedule %d", current.ID)
+	_, err = c.Put(fmt.Sprintf("/api/v0/schedules/%d", current.ID), sched, nil)
+	if err != nil {
+		return diagFromErrf(err, "Updating rotation schedule")
+	}
+
+	err = populateRosterSchedule(ctx, c, teamName, rosterName, role, nil, time.Now())
+	if err != nil {
+		return diagFromErrf(err, "Populating rotation schedule")
+	}
+
+	diags := shrinkPopulateHorizon(c, d, teamName, role, current.ID, d.Get(scheduleFieldTrimFutureEvents).(bool))
+	if diags.HasError() {
+		return diags
+	}
+	return append(diags, resourceRotationRead(ctx, d, m)...)
+}
+
+func resourceRotationDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName, rosterName, role, err := parseScheduleID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing rotation ID, this is an internal error")
+	}
+
+	traceLog("Going to delete rotation schedule %s/%s/%s", teamName, rosterName, role)
+	err = removeRosterSchedule(ctx, c, 0, teamName, rosterName, role)
+	if err != nil {
+		return diagFromErrf(err, "Removing rotation schedule %s", d.Id())
+	}
+
+	traceLog("Going to delete rotation roster %s/%s", teamName, rosterName)
+	err = c.DeleteRoster(teamName, rosterName)
+	if err != nil && !isNotFound(err) {
+		return diagFromErrf(err, "Removing rotation roster %s/%s", teamName, rosterName)
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func rotationScheduleFromResource(d *schema.ResourceData, team, roster string, weekStart time.Weekday) (rotationSchedule, error) {
+	members := []string{}
+	for _, member := range d.Get(rotationFieldMembers).([]interface{}) {
+		members = append(members, member.(string))
+	}
+
+	sched := rotationSchedule{
+		Team:                  team,
+		Roster:                roster,
+		Role:                  d.Get(rotationFieldRole).(string),
+		AutoPopulateThreshold: d.Get(scheduleFieldAutoPopulateDays).(int),
+		Scheduler: rotationScheduler{
+			Name: schedulingAlgorithmRoundRobin,
+			Data: members,
+		},
+	}
+
+	startSeconds, err := weekdayStartTimeToSeconds(d.Get(rotationFieldHandoffDay).(string), d.Get(rotationFieldHandoffTime).(string), weekStart)
+	if err != nil {
+		return sched, errors.Wrap(err, "Parsing handoff day and time")
+	}
+	dur := duration.Week
+	if d.Get(rotationFieldLength).(string) == basicScheduleRotationBiWeekly {
+		dur = duration.Fortnight
+	}
+	sched.Events = []oncall.ScheduleEvent{{Start: startSeconds, Duration: int(dur.Seconds())}}
+	return sched, nil
+}
+
+// getRotationSchedule finds the schedule for a role on a roster, including its
+// scheduler's data. found is false if the roster or schedule are missing.
+func getRotationSchedule(c *oncall.Client, team, roster, role string) (schedule rotationSchedule, found bool, err error) {
+	schedules := []rotationSchedule{}
+	_, err = c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters/%s/schedules", url.PathEscape(team), url.PathEscape(roster)), &schedules)
+	if err != nil {
+		if strings.Contains(err.Error(), "(404)") {
+			return rotationSchedule{}, false, nil
+		}
+		return rotationSchedule{}, false, errors.Wrapf(err, "Fetching schedules for roster %s/%s", team, roster)
+	}
+
+	for _, s := range schedules {
+		if strings.EqualFold(s.Role, role) {
+			return s, true, nil
+		}
+	}
+	return rotationSchedule{}, false, nil
+}
+
+// orderedMembers puts the roster members in the order the scheduler rotates
+// through them, members the scheduler doesn't know about go at the end
+func orderedMembers(members, order []string) []string {
+	ordered := []string{}
+	for _, m := range order {
+		if stringSliceContains(members, m) && !stringSliceContains(ordered, m) {
+			ordered = append(ordered, m)
+		}
+	}
+
+	rest := []string{}
+	for _, m := range members {
+		if !stringSliceContains(ordered, m) {
+			rest = append(rest, m)
+		}
+	}
+	sort.Strings(rest)
+	return append(ordered, rest...)
+}
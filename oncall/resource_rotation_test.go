@@ -0,0 +1,47 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_orderedMembers(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []string
+		order   []string
+		want    []string
+	}{
+		{
+			name:    "Follows the scheduler's order",
+			members: []string{"alice", "bob", "carol"},
+			order:   []string{"carol", "alice", "bob"},
+			want:    []string{"carol", "alice", "bob"},
+		},
+		{
+			name:    "Members the scheduler doesn't know go last",
+			members: []string{"dave", "alice", "bob", "carol"},
+			order:   []string{"bob", "alice"},
+			want:    []string{"bob", "alice", "carol", "dave"},
+		},
+		{
+			name:    "Users no longer on the roster are dropped",
+			members: []string{"alice"},
+			order:   []string{"bob", "alice", "alice"},
+			want:    []string{"alice"},
+		},
+		{
+			name:    "No scheduler data",
+			members: []string{"bob", "alice"},
+			order:   nil,
+			want:    []string{"alice", "bob"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderedMembers(tt.members, tt.order); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("orderedMembers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+	"github.com/pkg/errors"
+)
+
+const (
+	scheduleSwapFieldTeam  = "team"
+	scheduleSwapFieldRole  = "role"
+	scheduleSwapFieldUserA = "user_a"
+	scheduleSwapFieldUserB = "user_b"
+	scheduleSwapFieldStart = "start"
+	scheduleSwapFieldEnd   = "end"
+	scheduleSwapFieldSwap  = "swap"
+
+	swappedEventsFieldEventA = "event_a"
+	swappedEventsFieldEventB = "event_b"
+)
+
+// resourceScheduleSwap is a one off trade of shifts between two users. There
+// is nothing in oncall to read it back from but the events it swapped, so it
+// can't be imported, and deleting it swaps the events back.
+func resourceScheduleSwap() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceScheduleSwapCreate,
+		ReadContext:   resourceScheduleSwapRead,
+		DeleteContext: resourceScheduleSwapDelete,
+		CustomizeDiff: teamPrefixCustomizeDiff(scheduleSwapFieldTeam),
+
+		Schema: map[string]*schema.Schema{
+			scheduleSwapFieldTeam: &schema.Schema{
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Name of the team whose calendar the events are on",
+			},
+			scheduleSwapFieldRole: &schema.Schema{
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Role of the events to swap, e.g. primary",
+			},
+			scheduleSwapFieldUserA: &schema.Schema{
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Required:         true,
+				ValidateDiagFunc: validateUsername,
+				StateFunc:        usernameStateFunc,
+				Description:      "Username of one side of the swap",
+			},
+			scheduleSwapFieldUserB: &schema.Schema{
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Required:         true,
+				ValidateDiagFunc: validateUsername,
+				StateFunc:        usernameStateFunc,
+				Description:      "Username of the other side of the swap",
+			},
+			scheduleSwapFieldStart: &schema.Schema{
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+				Description:      "Start of the window (RFC 3339), events starting in the window are swapped",
+			},
+			scheduleSwapFieldEnd: &schema.Schema{
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Required:         true,
+				ValidateDiagFunc: validation.ToDiagFunc(validation.IsRFC3339Time),
+				Description:      "End of the window (RFC 3339)",
+			},
+			scheduleSwapFieldSwap: &schema.Schema{
+				Type:        schema.TypeList,
+				Computed:    true,
+				Description: "Pairs of events whose users were exchanged, in order of start. Each of user_a's events in the window is paired with the user_b event at the same place in order",
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						swappedEventsFieldEventA: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "ID of the event that was user_a's and is now user_b's",
+						},
+						swappedEventsFieldEventB: &schema.Schema{
+							Type:        schema.TypeInt,
+							Computed:    true,
+							Description: "ID of the event that was user_b's and is now user_a's",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// swapPair is two events whose users get exchanged
+type swapPair struct {
+	EventA int
+	EventB int
+}
+
+// pairSwapEvents pairs the events of user a starting in the window with those
+// of user b, in order of start. Both must have the same number of events.
+func pairSwapEvents(events []scheduleEvent, userA, userB string, start, end time.Time) ([]swapPair, error) {
+	sorted := make([]scheduleEvent, len(events))
+	copy(sorted, events)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	eventsA, eventsB := []scheduleEvent{}, []scheduleEvent{}
+	for _, e := range sorted {
+		if e.Start < start.Unix() || e.Start >= end.Unix() {
+			continue
+		}
+		switch e.User {
+		case userA:
+			eventsA = append(eventsA, e)
+		case userB:
+			eventsB = append(eventsB, e)
+		}
+	}
+
+	if len(eventsA) == 0 && len(eventsB) == 0 {
+		return nil, errors.Errorf("Neither %s nor %s has an event starting in the window, there is nothing to swap", userA, userB)
+	}
+	if len(eventsA) != len(eventsB) {
+		return nil, errors.Errorf("%s has %d events starting in the window and %s has %d, events are swapped one for one so change the window until they have as many", userA, len(eventsA), userB, len(eventsB))
+	}
+
+	pairs := make([]swapPair, 0, len(eventsA))
+	for i := range eventsA {
+		pairs = append(pairs, swapPair{EventA: eventsA[i].ID, EventB: eventsB[i].ID})
+	}
+	return pairs, nil
+}
+
+func setSwapPairs(d *schema.ResourceData, pairs []swapPair) {
+	swaps := make([]map[string]interface{}, 0, len(pairs))
+	for _, p := range pairs {
+		swaps = append(swaps, map[string]interface{}{
+			swappedEventsFieldEventA: p.EventA,
+			swappedEventsFieldEventB: p.EventB,
+		})
+	}
+	d.Set(scheduleSwapFieldSwap, swaps)
+}
+
+func getSwapPairs(d *schema.ResourceData) []swapPair {
+	pairs := []swapPair{}
+	for _, s := range d.Get(scheduleSwapFieldSwap).([]interface{}) {
+		s := s.(map[string]interface{})
+		pairs = append(pairs, swapPair{EventA: s[swappedEventsFieldEventA].(int), EventB: s[swappedEventsFieldEventB].(int)})
+	}
+	return pairs
+}
+
+func resourceScheduleSwapCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName := d.Get(scheduleSwapFieldTeam).(string)
+	role := d.Get(scheduleSwapFieldRole).(string)
+	userA := d.Get(scheduleSwapFieldUserA).(string)
+	userB := d.Get(scheduleSwapFieldUserB).(string)
+	start, err := time.Parse(time.RFC3339, d.Get(scheduleSwapFieldStart).(string))
+	if err != nil {
+		return diagFromErrf(err, "Parsing %s", scheduleSwapFieldStart)
+	}
+	end, err := time.Parse(time.RFC3339, d.Get(scheduleSwapFieldEnd).(string))
+	if err != nil {
+		return diagFromErrf(err, "Parsing %s", scheduleSwapFieldEnd)
+	}
+	if !end.After(start) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("%s must be after %s", scheduleSwapFieldEnd, scheduleSwapFieldStart),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: scheduleSwapFieldEnd}},
+			},
+		}
+	}
+
+	events, err := getEventsBetween(c, teamName, role, start, end)
+	if err != nil {
+		return diagFromErrf(err, "Getting events to swap")
+	}
+	pairs, err := pairSwapEvents(events, userA, userB, start, end)
+	if err != nil {
+		return diagFromErrf(err, "Swapping %s events of team %s", role, teamName)
+	}
+
+	// The swaps done so far are kept in state if one fails, so destroying the
+	// tainted resource swaps them back
+	d.SetId(getScheduleSwapID(teamName, role, userA, userB, start))
+	done := []swapPair{}
+	for _, p := range pairs {
+		traceLog("Going to swap event %d of %s with event %d of %s", p.EventA, userA, p.EventB, userB)
+		err = swapEvents(c, p.EventA, p.EventB)
+		if err != nil {
+			setSwapPairs(d, done)
+			return diagFromErrf(err, "Swapping %s events of team %s", role, teamName)
+		}
+		done = append(done, p)
+	}
+	setSwapPairs(d, done)
+
+	return resourceScheduleSwapRead(ctx, d, m)
+}
+
+func resourceScheduleSwapRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	userA := d.Get(scheduleSwapFieldUserA).(string)
+	userB := d.Get(scheduleSwapFieldUserB).(string)
+	for _, p := range getSwapPairs(d) {
+		swapped, err := eventsStillSwapped(c, p, userA, userB)
+		if err != nil {
+			return diagFromErrf(err, "Reading swapped events")
+		}
+		if !swapped {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Events %d and %d are no longer swapped between %s and %s", p.EventA, p.EventB, userA, userB),
+				Detail:   "They were deleted or reassigned outside of terraform, so destroying the swap will leave them as they are.",
+			})
+		}
+	}
+
+	return diags
+}
+
+func resourceScheduleSwapDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	userA := d.Get(scheduleSwapFieldUserA).(string)
+	userB := d.Get(scheduleSwapFieldUserB).(string)
+	for _, p := range getSwapPairs(d) {
+		swapped, err := eventsStillSwapped(c, p, userA, userB)
+		if err != nil {
+			return diagFromErrf(err, "Reading swapped events")
+		}
+		if !swapped {
+			warnLog("Not swapping events %d and %d back, they are no longer swapped between %s and %s", p.EventA, p.EventB, userA, userB)
+			continue
+		}
+		traceLog("Going to swap events %d and %d back", p.EventA, p.EventB)
+		err = swapEvents(c, p.EventA, p.EventB)
+		if err != nil {
+			return diagFromErrf(err, "Reverting swap %s", d.Id())
+		}
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+// eventsStillSwapped is whether both events of the pair still exist, with
+// user a's event now user b's and the other way around
+func eventsStillSwapped(c *oncall.Client, p swapPair, userA, userB string) (bool, error) {
+	eventA, foundA, err := getEvent(c, p.EventA)
+	if err != nil {
+		return false, err
+	}
+	eventB, foundB, err := getEvent(c, p.EventB)
+	if err != nil {
+		return false, err
+	}
+	return foundA && foundB && eventA.User == userB && eventB.User == userA, nil
+}
+
+func getScheduleSwapID(team, role, userA, userB string, start time.Time) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%d", escapeIDPart(team), escapeIDPart(role), escapeIDPart(userA), escapeIDPart(userB), start.Unix())
+}
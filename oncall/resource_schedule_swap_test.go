@@ -0,0 +1,113 @@
+package oncall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_pairSwapEvents(t *testing.T) {
+	day := int64(24 * 60 * 60)
+	// 2025-01-06T00:00:00Z, a Monday
+	monday := int64(1736121600)
+	events := []scheduleEvent{
+		{ID: 4, User: "bob", Start: monday + 7*day, End: monday + 14*day},
+		{ID: 3, User: "alice", Start: monday, End: monday + 7*day},
+		{ID: 5, User: "carol", Start: monday + 14*day, End: monday + 21*day},
+		{ID: 6, User: "alice", Start: monday + 21*day, End: monday + 28*day},
+		{ID: 2, User: "bob", Start: monday - 7*day, End: monday},
+	}
+
+	tests := []struct {
+		name    string
+		start   int64
+		end     int64
+		want    []swapPair
+		wantErr string
+	}{
+		{
+			name:  "One week each",
+			start: monday,
+			end:   monday + 14*day,
+			want:  []swapPair{{EventA: 3, EventB: 4}},
+		},
+		{
+			name:  "Two weeks each, paired in order",
+			start: monday - 7*day,
+			end:   monday + 28*day,
+			want:  []swapPair{{EventA: 3, EventB: 2}, {EventA: 6, EventB: 4}},
+		},
+		{
+			name:    "Uneven",
+			start:   monday,
+			end:     monday + 28*day,
+			wantErr: "alice has 2 events starting in the window and bob has 1",
+		},
+		{
+			name:    "Nothing to swap",
+			start:   monday + 14*day,
+			end:     monday + 21*day,
+			wantErr: "Neither alice nor bob",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pairSwapEvents(events, "alice", "bob", time.Unix(tt.start, 0), time.Unix(tt.end, 0))
+			if (err != nil) != (tt.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("pairSwapEvents() error = %v, wantErr %q", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pairSwapEvents() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_eventsStillSwapped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/events/1":
+			w.Write([]byte(`{"id": 1, "user": "bob"}`))
+		case "/api/v0/events/2":
+			w.Write([]byte(`{"id": 2, "user": "alice"}`))
+		case "/api/v0/events/3":
+			w.Write([]byte(`{"id": 3, "user": "carol"}`))
+		case "/api/v0/events/500":
+			w.WriteHeader(500)
+		default:
+			w.WriteHeader(404)
+			w.Write([]byte(`{"title": "Not Found", "description": "Event not found"}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		pair    swapPair
+		want    bool
+		wantErr bool
+	}{
+		{name: "Still swapped", pair: swapPair{EventA: 1, EventB: 2}, want: true},
+		{name: "Reassigned", pair: swapPair{EventA: 3, EventB: 2}},
+		{name: "Deleted", pair: swapPair{EventA: 1, EventB: 404}},
+		{name: "Error", pair: swapPair{EventA: 1, EventB: 500}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := eventsStillSwapped(c, tt.pair, "alice", "bob")
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("eventsStillSwapped() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
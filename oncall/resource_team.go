@@ -2,10 +2,13 @@ package oncall
 
 import (
 	"context"
-	"strings"
+	"fmt"
+	"sort"
+	"time"
 
 	"github.com/bushelpowered/oncall-client-go/oncall"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/pkg/errors"
 )
@@ -15,8 +18,18 @@ const (
 	teamFieldSchedulingTimezone = "scheduling_timezone"
 	teamFieldEmail              = "email"
 	teamFieldSlackChannel       = "slack_channel"
+	teamFieldSlackNotifications = "slack_channel_notifications"
 	teamFieldIrisPlan           = "iris_plan"
+	teamFieldIrisEnabled        = "iris_enabled"
+	teamFieldNotifyManagerAfter = "notify_manager_after"
 	teamFieldAdmins             = "admins"
+	teamFieldDescription        = "description"
+	teamFieldRoster             = "roster"
+	teamFieldCalendarURL        = "calendar_url"
+	teamFieldICalURL            = "ical_url"
+	teamFieldArchived           = "archived"
+
+	teamFieldExternalManagementNote = "external_management_note"
 )
 
 func resourceTeam() *schema.Resource {
@@ -28,11 +41,21 @@ func resourceTeam() *schema.Resource {
 		Importer: &schema.ResourceImporter{
 			StateContext: resourceTeamImport,
 		},
+		CustomizeDiff: customdiff.All(
+			teamPrefixCustomizeDiff(teamFieldName),
+			resourceTeamCustomizeDiff,
+			teamReferencesCustomizeDiff,
+			irisPlanCustomizeDiff(teamFieldIrisPlan),
+			customdiff.ComputedIf(teamFieldCalendarURL, teamURLsChanged),
+			customdiff.ComputedIf(teamFieldICalURL, teamURLsChanged),
+			revisionCustomizeDiff(),
+		),
 		Schema: map[string]*schema.Schema{
 			teamFieldName: &schema.Schema{
-				Type:        schema.TypeString,
-				Required:    true,
-				Description: "Name of the team, acts as the ID as well",
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateName,
+				Description:      fmt.Sprintf("Name of the team, acts as the ID as well. At most %d characters and no slashes", maxNameLength),
 			},
 			teamFieldSchedulingTimezone: &schema.Schema{
 				Type:        schema.TypeString,
@@ -50,18 +73,74 @@ func resourceTeam() *schema.Resource {
 				Description: "Slack channel that this team should all be members of",
 				Optional:    true,
 			},
+			teamFieldSlackNotifications: &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Slack channel oncall posts the team's notifications to, e.g. shift reminders, instead of slack_channel. Only sent to and read back from oncall versions that support it",
+				Optional:    true,
+			},
 			teamFieldIrisPlan: &schema.Schema{
 				Type:        schema.TypeString,
-				Description: "Default iris plan for this team. Allows paging from oncall",
+				Description: "Default iris plan for this team. Allows paging from oncall. Checked to exist in Iris at plan time when the provider has an iris_endpoint",
+				Optional:    true,
+			},
+			teamFieldIrisEnabled: &schema.Schema{
+				Type:        schema.TypeBool,
+				Description: "Whether the team can be paged through iris, set to false to turn paging off e.g. during a maintenance window. Left as oncall has it if not set, and only read back from oncall versions that support it",
+				Optional:    true,
+				Computed:    true,
+			},
+			teamFieldNotifyManagerAfter: &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				Computed:         true,
+				ValidateDiagFunc: validateNotifyManagerAfter,
+				StateFunc:        notifyManagerAfterStateFunc,
+				Description:      "How long a page to the team goes unacknowledged before whoever is on call for the manager role is notified, in duration shorthand e.g. 30m, or 0s to never notify them. Only on oncall instances with manager escalation, left as oncall has it if not set",
+			},
+			teamFieldDescription: &schema.Schema{
+				Type:        schema.TypeString,
+				Description: "Description of the team, e.g. what is expected of whoever is on call",
+				Optional:    true,
+			},
+			teamFieldExternalManagementNote: &schema.Schema{
+				Type:             schema.TypeString,
+				Optional:         true,
+				ValidateDiagFunc: validateManagementNote,
+				Description:      "Note put at the top of the team's description to warn people off editing the team in the oncall UI, e.g. where to change it instead. Reading the team warns if the note was changed or removed, as a sign the team was edited outside of terraform",
+			},
+			teamFieldArchived: &schema.Schema{
+				Type:        schema.TypeBool,
 				Optional:    true,
+				Default:     false,
+				Description: "Archive the team instead of deleting it when it is destroyed, keeping its calendar history for audits. The team is renamed to <name>-archived-<unix time> and its rosters and admins are removed",
+			},
+			teamFieldCalendarURL: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the team's calendar in the oncall UI",
+			},
+			teamFieldICalURL: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "URL of the iCal feed of the team's on call events",
+			},
+			fieldRevision: &schema.Schema{
+				Type:        schema.TypeString,
+				Computed:    true,
+				Description: "Hash of the team as read from oncall, which changes whenever it is changed, including outside of terraform e.g. in the oncall UI. The lines other resources keep at the end of its description, e.g. schedule descriptions, are left out. For replace_triggered_by and postconditions to react to such changes",
 			},
 			teamFieldAdmins: &schema.Schema{
 				Type:        schema.TypeSet,
 				Description: "Authoritative list of usernames of who should admin the team",
 				Required:    true,
-				Elem: &schema.Schema{
-					Type: schema.TypeString,
-				},
+				Elem:        usernameElem(),
+				Set:         hashUsername,
+			},
+			teamFieldRoster: &schema.Schema{
+				Type:        schema.TypeSet,
+				Description: "Rosters to manage as part of the team, for small teams that don't need oncall_roster. Only the rosters listed here are managed, a roster must not be both a block here and an oncall_roster resource",
+				Optional:    true,
+				Elem:        teamRosterResource(),
 			},
 		},
 	}
@@ -69,17 +148,36 @@ func resourceTeam() *schema.Resource {
 
 func resourceTeamImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
 	traceLog("Going to import team %s", d.Id())
-	var err error
+	teamName, include, err := parseTeamImportID(d.Id())
+	if err != nil {
+		return nil, errors.Wrap(err, "Parsing team import ID")
+	}
+	teamName, err = canonicalTeamName(m.(*providerMeta).clientFor(ctx), teamName)
+	if err != nil {
+		return nil, errors.Wrap(err, "Resolving team import ID")
+	}
+	d.SetId(teamName)
 
 	readErr := resourceTeamRead(ctx, d, m)
 	if len(readErr) > 0 {
-		err = errors.New(readErr[0].Summary)
+		return nil, errors.Wrap(errors.New(readErr[0].Summary), "Reading team for import")
+	}
+	if d.Id() == "" {
+		return nil, fmt.Errorf("Team %s does not exist", teamName)
+	}
+	if len(include) == 0 {
+		return []*schema.ResourceData{d}, nil
+	}
+
+	imported, err := importTeamGraph(ctx, teamName, include, m)
+	if err != nil {
+		return nil, errors.Wrap(err, "Importing the rest of the team")
 	}
-	return []*schema.ResourceData{d}, errors.Wrap(err, "Reading team for import")
+	return append([]*schema.ResourceData{d}, imported...), nil
 }
 
 func resourceTeamCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	// Warning or errors can be collected in a slice type
 	teamConfig, diags := resourceTeamAsTeamConfig(d)
@@ -90,21 +188,28 @@ func resourceTeamCreate(ctx context.Context, d *schema.ResourceData, m interface
 	traceLog("Going to create team: %+v", teamConfig)
 	t, err := c.CreateTeam(teamConfig)
 	if err != nil {
-		if strings.Contains(err.Error(), "(422)") {
-			return diagFromErrf(err, "Team already exists, please import using id %q", teamConfig.Name)
-		}
-		return diagFromErrf(err, "Creating oncall team")
+		return createErrorDiags(err, "Creating oncall team", teamConfig.Name, teamFieldName, teamFieldSchedulingTimezone, teamFieldEmail, teamFieldSlackChannel, teamFieldIrisPlan)
 	}
 
 	traceLog("Setting team resource id to %q", t.Name)
 	d.SetId(t.Name)
 
-	admins := getResourceStringSet(d, teamFieldAdmins)
+	err = updateTeamExtras(c, t.Name, resourceTeamAsTeamExtras(d, false))
+	if err != nil {
+		return diagFromErrf(err, "Setting team settings")
+	}
+
+	admins := teamAdminsToSet(d, m.(*providerMeta))
 	err = c.SetTeamAdmins(t.Name, admins)
 	if err != nil {
 		return diagFromErrf(err, "Setting team admins to %v", admins)
 	}
 
+	err = updateTeamRosters(c, d, t.Name)
+	if err != nil {
+		return diagFromErrf(err, "Creating team rosters")
+	}
+
 	resourceTeamRead(ctx, d, m)
 	return diags
 }
@@ -138,35 +243,131 @@ func resourceTeamAsTeamConfig(d *schema.ResourceData) (oncall.TeamConfig, diag.D
 	return teamConfig, diags
 }
 
+func teamURLsChanged(ctx context.Context, d *schema.ResourceDiff, m interface{}) bool {
+	return d.HasChange(teamFieldName)
+}
+
+// resourceTeamAsTeamExtras gets the settings the oncall client doesn't handle,
+// on update only the ones which changed are included
+func resourceTeamAsTeamExtras(d *schema.ResourceData, onlyChanged bool) teamExtras {
+	extras := teamExtras{}
+
+	description := joinManagementNote(d.Get(teamFieldDescription).(string), d.Get(teamFieldExternalManagementNote).(string))
+	if (!onlyChanged && description != "") || (onlyChanged && d.HasChanges(teamFieldDescription, teamFieldExternalManagementNote)) {
+		extras.Description = &description
+	}
+
+	// GetOkExists tells an iris_enabled of false apart from one that isn't set
+	irisEnabled, irisEnabledSet := d.GetOkExists(teamFieldIrisEnabled)
+	if (!onlyChanged && irisEnabledSet) || (onlyChanged && d.HasChange(teamFieldIrisEnabled)) {
+		enabled := irisEnabled.(bool)
+		extras.IrisEnabled = &enabled
+	}
+
+	notificationsChannel := d.Get(teamFieldSlackNotifications).(string)
+	if (!onlyChanged && notificationsChannel != "") || (onlyChanged && d.HasChange(teamFieldSlackNotifications)) {
+		extras.SlackChannelNotifications = &notificationsChannel
+	}
+
+	// Validation has already parsed the duration
+	notifyAfter, notifyAfterSet := d.GetOk(teamFieldNotifyManagerAfter)
+	if (!onlyChanged && notifyAfterSet) || (onlyChanged && d.HasChange(teamFieldNotifyManagerAfter)) {
+		seconds, _ := parseDurationSeconds(notifyAfter.(string))
+		extras.NotifyManagerAfter = &seconds
+	}
+
+	return extras
+}
+
 func resourceTeamRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	// Warning or errors can be collected in a slice type
 	var diags diag.Diagnostics
 
 	teamName := d.Id()
-	team, err := c.GetTeam(teamName)
+	team, err := getTeam(c, teamName)
+	if isNotFound(err) {
+		warnLog("Team %s no longer exists, removing it from state", teamName)
+		d.SetId("")
+		return diags
+	}
 	if err != nil {
-		return diag.FromErr(errors.Wrapf(err, "Fetching team %s", teamName))
+		return diagFromErrf(err, "Fetching team %s", teamName)
 	}
 
 	d.Set(teamFieldName, team.Name)
 	d.Set(teamFieldEmail, team.Email)
 	d.Set(teamFieldSlackChannel, team.SlackChannel)
 	d.Set(teamFieldIrisPlan, team.IrisPlan)
+	if team.IrisEnabled != nil {
+		d.Set(teamFieldIrisEnabled, bool(*team.IrisEnabled))
+	}
+	if team.SlackChannelNotifications.present {
+		d.Set(teamFieldSlackNotifications, team.SlackChannelNotifications.value)
+	} else if d.Get(teamFieldSlackNotifications).(string) != "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Oncall doesn't support %s, it is ignored for team %s", teamFieldSlackNotifications, team.Name),
+			Detail:   fmt.Sprintf("This oncall version only has %s, notifications go there.", teamFieldSlackChannel),
+		})
+	}
+	if team.NotifyManagerAfter != nil {
+		d.Set(teamFieldNotifyManagerAfter, formatNotifyManagerAfter(*team.NotifyManagerAfter))
+	} else if d.Get(teamFieldNotifyManagerAfter).(string) != "" {
+		diags = append(diags, diag.Diagnostic{
+			Severity: diag.Warning,
+			Summary:  fmt.Sprintf("Oncall doesn't support %s, it is ignored for team %s", teamFieldNotifyManagerAfter, team.Name),
+			Detail:   "This oncall instance doesn't escalate to the manager role, remove the setting or escalate through the team's iris plan instead.",
+		})
+	}
 	d.Set(teamFieldSchedulingTimezone, team.SchedulingTimezone)
-
+	description, note, found := splitManagementNote(parseTeamDescription(team.Description).text)
+	diags = append(diags, managementNoteDrift(team.Name, d.Get(teamFieldExternalManagementNote).(string), note, found)...)
+	d.Set(teamFieldDescription, description)
+	d.Set(teamFieldExternalManagementNote, note)
+	d.Set(teamFieldCalendarURL, teamCalendarURL(c.Config.Endpoint, team.Name))
+	d.Set(teamFieldICalURL, teamICalURL(c.Config.Endpoint, team.Name, ""))
+
+	// The provider's own user is left out if it was only added to keep access
+	self := m.(*providerMeta).selfTeamAdmin()
+	keepSelf := stringSliceContains(getResourceStringSet(d, teamFieldAdmins), self)
 	admins := make([]string, 0, len(team.Admins))
 	for _, a := range team.Admins {
+		if a.Name == self && !keepSelf {
+			continue
+		}
 		admins = append(admins, a.Name)
 	}
 	setResourceStringSet(d, teamFieldAdmins, admins)
 
+	allAdmins := make([]string, 0, len(team.Admins))
+	for _, a := range team.Admins {
+		allAdmins = append(allAdmins, a.Name)
+	}
+	sort.Strings(allAdmins)
+	// The annotations other resources keep in the description are left out, so
+	// changing e.g. a schedule's description doesn't change the team's revision
+	d.Set(fieldRevision, objectRevision(struct {
+		oncall.TeamConfig
+		SlackChannelNotifications string   `json:"slack_channel_notifications"`
+		Description               string   `json:"description"`
+		ManagementNote            string   `json:"management_note"`
+		IrisEnabled               *apiBool `json:"iris_enabled"`
+		NotifyManagerAfter        *int     `json:"notify_manager_after"`
+		Admins                    []string `json:"admins"`
+	}{team.TeamConfig, team.SlackChannelNotifications.value, description, note, team.IrisEnabled, team.NotifyManagerAfter, allAdmins}))
+
+	err = readTeamRosters(c, d, team.Name)
+	if err != nil {
+		return diagFromErrf(err, "Reading team %s rosters", teamName)
+	}
+
 	return diags
 }
 
 func resourceTeamUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
+	c := m.(*providerMeta).clientFor(ctx)
 
 	// Warning or errors can be collected in a slice type
 	teamConfig, diags := resourceTeamAsTeamConfig(d)
@@ -177,26 +378,58 @@ func resourceTeamUpdate(ctx context.Context, d *schema.ResourceData, m interface
 	traceLog("Going to update team %q: %+v", d.Id(), teamConfig)
 	t, err := c.UpdateTeam(d.Id(), teamConfig)
 	if err != nil {
-		return diag.FromErr(errors.Wrap(err, "Updating oncall team"))
+		return diagFromErrf(err, "Updating oncall team")
 	}
 
 	traceLog("Setting team resource id to %q", t.Name)
 	d.SetId(t.Name)
 
-	admins := getResourceStringSet(d, teamFieldAdmins)
+	err = updateTeamExtras(c, t.Name, resourceTeamAsTeamExtras(d, true))
+	if err != nil {
+		return diagFromErrf(err, "Updating team settings")
+	}
+
+	admins := teamAdminsToSet(d, m.(*providerMeta))
 	err = c.SetTeamAdmins(t.Name, admins)
 	if err != nil {
 		return diagFromErrf(err, "Setting team admins to %v", admins)
 	}
 
-	return resourceTeamRead(ctx, d, m)
+	if d.HasChange(teamFieldRoster) {
+		err = updateTeamRosters(c, d, t.Name)
+		if err != nil {
+			return diagFromErrf(err, "Updating team rosters")
+		}
+	}
+
+	// Not checked on create, as the team's schedules are created after it
+	if d.HasChange(teamFieldNotifyManagerAfter) {
+		diags = append(diags, managerEscalationDiags(c, d, t.Name)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	return append(diags, resourceTeamRead(ctx, d, m)...)
 }
 
 func resourceTeamDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
-	c := m.(*oncall.Client)
-	err := c.DeleteTeam(d.Id())
-	if err != nil {
-		return diag.FromErr(err)
+	c := m.(*providerMeta).clientFor(ctx)
+
+	if d.Get(teamFieldArchived).(bool) {
+		archivedName, err := archiveTeam(c, d.Id(), time.Now())
+		if err != nil {
+			return diagFromErrf(err, "Archiving oncall team")
+		}
+		infoLog("Archived team %s as %s", d.Id(), archivedName)
+	} else {
+		// The team's rosters and schedules may be being deleted at the same time
+		what := "team " + d.Id()
+		children := []string{"roster", "schedule"}
+		err := deleteAfterChildren(ctx, what, children, func() error { return c.DeleteTeam(d.Id()) })
+		if err != nil {
+			return deleteOrderDiags(err, "Deleting oncall team", what, children, rosterFieldTeam)
+		}
 	}
 
 	// d.SetId("") is automatically called assuming delete returns no errors, but
@@ -205,3 +438,13 @@ func resourceTeamDelete(ctx context.Context, d *schema.ResourceData, m interface
 
 	return diag.Diagnostics{}
 }
+
+// teamAdminsToSet is the authoritative list of admins to give the team, which
+// includes the provider's own user if it should stay an admin
+func teamAdminsToSet(d *schema.ResourceData, meta *providerMeta) []string {
+	admins := getResourceStringSet(d, teamFieldAdmins)
+	if self := meta.selfTeamAdmin(); self != "" && !stringSliceContains(admins, self) {
+		admins = append(admins, self)
+	}
+	return admins
+}
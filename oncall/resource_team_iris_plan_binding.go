@@ -0,0 +1,149 @@
+package oncall
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	irisPlanBindingFieldTeam = "team"
+	irisPlanBindingFieldPlan = "plan"
+)
+
+// resourceTeamIrisPlanBinding owns the iris plan of a team, so it can be
+// managed apart from the team, e.g. by whoever owns the plans in Iris. The
+// team's own iris_plan has to be left to it with ignore_changes.
+func resourceTeamIrisPlanBinding() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceTeamIrisPlanBindingCreate,
+		ReadContext:   resourceTeamIrisPlanBindingRead,
+		UpdateContext: resourceTeamIrisPlanBindingUpdate,
+		DeleteContext: resourceTeamIrisPlanBindingDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTeamIrisPlanBindingImport,
+		},
+		CustomizeDiff: customdiff.All(
+			teamPrefixCustomizeDiff(irisPlanBindingFieldTeam),
+			irisPlanCustomizeDiff(irisPlanBindingFieldPlan),
+		),
+
+		Schema: map[string]*schema.Schema{
+			irisPlanBindingFieldTeam: {
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Name of the team to page through the plan",
+			},
+			irisPlanBindingFieldPlan: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the iris plan the team is paged through. Checked to exist in Iris at plan time when the provider has an iris_endpoint",
+			},
+		},
+	}
+}
+
+// setTeamIrisPlan sets only the team's iris plan, leaving its other settings
+func setTeamIrisPlan(c *oncall.Client, teamName, plan string) error {
+	traceLog("Going to set the iris plan of team %s to %q", teamName, plan)
+	_, err := c.Put("/api/v0/teams/"+url.PathEscape(teamName), map[string]string{"iris_plan": plan}, nil)
+	return errors.Wrapf(err, "Setting iris plan of team %s", teamName)
+}
+
+func resourceTeamIrisPlanBindingCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName := d.Get(irisPlanBindingFieldTeam).(string)
+	plan := d.Get(irisPlanBindingFieldPlan).(string)
+
+	// The plan may not have been known at plan time
+	err := checkIrisPlan(ctx, m, plan)
+	if err != nil {
+		return diagFromErrf(err, "Checking iris plan")
+	}
+
+	t, err := getTeam(c, teamName)
+	if err != nil {
+		return diagFromErrf(err, "Getting team %s", teamName)
+	}
+	if t.IrisPlan != "" && t.IrisPlan != plan {
+		return diag.Errorf("Team %s is already paged through iris plan %s, import it with ID %s", teamName, t.IrisPlan, teamName)
+	}
+
+	err = setTeamIrisPlan(c, teamName, plan)
+	if err != nil {
+		return diagFromErrf(err, "Binding iris plan")
+	}
+
+	d.SetId(teamName)
+	return resourceTeamIrisPlanBindingRead(ctx, d, m)
+}
+
+func resourceTeamIrisPlanBindingRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName := d.Id()
+	t, err := getTeam(c, teamName)
+	if isNotFound(err) {
+		warnLog("Team %s no longer exists, removing its iris plan binding from state", teamName)
+		d.SetId("")
+		return diags
+	}
+	if err != nil {
+		return diagFromErrf(err, "Getting team %s", teamName)
+	}
+	if t.IrisPlan == "" {
+		warnLog("Team %s is no longer paged through an iris plan, removing its binding from state", teamName)
+		d.SetId("")
+		return diags
+	}
+
+	d.Set(irisPlanBindingFieldTeam, t.Name)
+	d.Set(irisPlanBindingFieldPlan, t.IrisPlan)
+	return diags
+}
+
+func resourceTeamIrisPlanBindingUpdate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	plan := d.Get(irisPlanBindingFieldPlan).(string)
+	err := checkIrisPlan(ctx, m, plan)
+	if err != nil {
+		return diagFromErrf(err, "Checking iris plan")
+	}
+
+	err = setTeamIrisPlan(c, d.Id(), plan)
+	if err != nil {
+		return diagFromErrf(err, "Binding iris plan")
+	}
+	return resourceTeamIrisPlanBindingRead(ctx, d, m)
+}
+
+func resourceTeamIrisPlanBindingDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	err := setTeamIrisPlan(c, d.Id(), "")
+	if err != nil && !isNotFound(err) {
+		return diagFromErrf(err, "Unbinding iris plan")
+	}
+	d.SetId("")
+	return nil
+}
+
+func resourceTeamIrisPlanBindingImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	teamName, err := canonicalTeamName(m.(*providerMeta).clientFor(ctx), d.Id())
+	if err != nil {
+		return nil, errors.Wrap(err, "Resolving iris plan binding import ID")
+	}
+	d.SetId(teamName)
+	return []*schema.ResourceData{d}, nil
+}
@@ -0,0 +1,90 @@
+package oncall
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_resourceTeamIrisPlanBindingCreate(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			switch r.URL.Path {
+			case "/api/v0/teams/unpaged":
+				w.Write([]byte(`{"name": "unpaged", "iris_plan": ""}`))
+			case "/api/v0/teams/paged":
+				w.Write([]byte(`{"name": "paged", "iris_plan": "page-paged"}`))
+			default:
+				w.WriteHeader(404)
+			}
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`null`))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		team         string
+		plan         string
+		wantRequests []string
+		wantErr      bool
+	}{
+		{
+			name:         "Team without a plan",
+			team:         "unpaged",
+			plan:         "page-unpaged",
+			wantRequests: []string{`PUT /api/v0/teams/unpaged {"iris_plan":"page-unpaged"}`},
+		},
+		{
+			name:         "Team already bound to the plan",
+			team:         "paged",
+			plan:         "page-paged",
+			wantRequests: []string{`PUT /api/v0/teams/paged {"iris_plan":"page-paged"}`},
+		},
+		{
+			name:         "Team bound to another plan",
+			team:         "paged",
+			plan:         "page-other",
+			wantRequests: []string{},
+			wantErr:      true,
+		},
+		{
+			name:         "Missing team",
+			team:         "missing",
+			plan:         "page-missing",
+			wantRequests: []string{},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = []string{}
+			d := schema.TestResourceDataRaw(t, resourceTeamIrisPlanBinding().Schema, map[string]interface{}{
+				irisPlanBindingFieldTeam: tt.team,
+				irisPlanBindingFieldPlan: tt.plan,
+			})
+			diags := resourceTeamIrisPlanBindingCreate(context.Background(), d, &providerMeta{client: c})
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("resourceTeamIrisPlanBindingCreate() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("resourceTeamIrisPlanBindingCreate() requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
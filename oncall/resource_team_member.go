@@ -0,0 +1,155 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	teamMemberFieldTeam     = "team"
+	teamMemberFieldUsername = "username"
+)
+
+func resourceTeamMember() *schema.Resource {
+	return &schema.Resource{
+		Description:   "A user who is a member of a team without being in any of its rosters, so the team's page lists them. Each user is a resource of their own rather than the team's members being set as a whole, as oncall makes every roster member a member of the team too, so a set of the team's members would have to repeat every roster's members.",
+		CreateContext: resourceTeamMemberCreate,
+		ReadContext:   resourceTeamMemberRead,
+		DeleteContext: resourceTeamMemberDelete,
+		Importer: &schema.ResourceImporter{
+			StateContext: resourceTeamMemberImport,
+		},
+		CustomizeDiff: teamPrefixCustomizeDiff(teamMemberFieldTeam),
+
+		Schema: map[string]*schema.Schema{
+			teamMemberFieldTeam: &schema.Schema{
+				Type:        schema.TypeString,
+				ForceNew:    true,
+				Required:    true,
+				Description: "Name of the team the user should be a member of",
+			},
+			teamMemberFieldUsername: &schema.Schema{
+				Type:             schema.TypeString,
+				ForceNew:         true,
+				Required:         true,
+				ValidateDiagFunc: validateUsername,
+				StateFunc:        usernameStateFunc,
+				Description:      "Username of the team member",
+			},
+		},
+	}
+}
+
+func resourceTeamMemberCreate(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName := d.Get(teamMemberFieldTeam).(string)
+	username := d.Get(teamMemberFieldUsername).(string)
+
+	traceLog("Going to add %s as a member of team %s", username, teamName)
+	err := c.AddTeamUser(teamName, username)
+	if err != nil {
+		return createErrorDiags(err, "Adding oncall team member", getTeamMemberID(teamName, username), teamMemberFieldTeam, teamMemberFieldUsername)
+	}
+
+	d.SetId(getTeamMemberID(teamName, username))
+	return resourceTeamMemberRead(ctx, d, m)
+}
+
+func resourceTeamMemberImport(ctx context.Context, d *schema.ResourceData, m interface{}) ([]*schema.ResourceData, error) {
+	teamName, username, err := parseTeamMemberID(d.Id())
+	if err != nil {
+		return nil, errors.Wrap(err, "Parsing team member ID")
+	}
+	teamName, username, err = canonicalTeamMemberID(m.(*providerMeta).clientFor(ctx), teamName, username)
+	if err != nil {
+		return nil, errors.Wrap(err, "Resolving team member import ID")
+	}
+	d.SetId(getTeamMemberID(teamName, username))
+
+	traceLog("Going to import team member %q as team: %s, username: %s", d.Id(), teamName, username)
+	d.Set(teamMemberFieldTeam, teamName)
+	d.Set(teamMemberFieldUsername, username)
+
+	readErr := resourceTeamMemberRead(ctx, d, m)
+	if len(readErr) > 0 {
+		err = errors.New(readErr[0].Summary)
+	}
+	if err == nil && d.Id() == "" {
+		err = fmt.Errorf("%s is not a member of team %s", username, teamName)
+	}
+	return []*schema.ResourceData{d}, errors.Wrap(err, "Reading resource for import")
+}
+
+func resourceTeamMemberRead(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	// Warning or errors can be collected in a slice type
+	var diags diag.Diagnostics
+
+	teamName, username, err := parseTeamMemberID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing team member ID, this is an internal error")
+	}
+
+	members, err := c.GetTeamUsers(teamName)
+	if err != nil {
+		return diagFromErrf(err, "Getting members of team %s", teamName)
+	}
+
+	if !stringSliceContains(members, username) {
+		warnLog("%s is no longer a member of team %s, removing it from state", username, teamName)
+		d.SetId("")
+		return diags
+	}
+
+	d.Set(teamMemberFieldTeam, teamName)
+	d.Set(teamMemberFieldUsername, username)
+
+	return diags
+}
+
+func resourceTeamMemberDelete(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+	c := m.(*providerMeta).clientFor(ctx)
+
+	teamName, username, err := parseTeamMemberID(d.Id())
+	if err != nil {
+		return diagFromErrf(err, "Parsing team member ID, this is an internal error")
+	}
+
+	traceLog("Going to remove %s as a member of team %s", username, teamName)
+	err = c.RemoveTeamUser(teamName, username)
+	// Deleting the team first deletes its members along with it
+	if err != nil && !isNotFound(err) {
+		return diagFromErrf(err, "Removing team member %s/%s", teamName, username)
+	}
+
+	// d.SetId("") is automatically called assuming delete returns no errors, but
+	// it is added here for explicitness.
+	d.SetId("")
+
+	return diag.Diagnostics{}
+}
+
+func getTeamMemberID(team, username string) string {
+	return fmt.Sprintf("%s/%s", team, username)
+}
+
+func parseTeamMemberID(teamMemberID string) (team, username string, err error) {
+	tu := strings.Split(teamMemberID, "/")
+	if len(tu) == 2 {
+		team, username = tu[0], tu[1]
+	} else {
+		err = errors.New("Unparseable team member id (should be team/username)")
+	}
+
+	if err == nil && (team == "" || username == "") {
+		err = errors.New("Team member ID did not specify both team and username")
+	}
+	return
+}
@@ -0,0 +1,159 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_parseTeamMemberID(t *testing.T) {
+	tests := []struct {
+		name         string
+		id           string
+		wantTeam     string
+		wantUsername string
+		wantErr      bool
+	}{
+		{name: "ID", id: "platform/alice", wantTeam: "platform", wantUsername: "alice"},
+		{name: "No username", id: "platform/", wantErr: true},
+		{name: "No team", id: "/alice", wantErr: true},
+		{name: "No separator", id: "platform", wantErr: true},
+		{name: "Too many parts", id: "platform/sre/alice", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			team, username, err := parseTeamMemberID(tt.id)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTeamMemberID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if team != tt.wantTeam || username != tt.wantUsername {
+				t.Errorf("parseTeamMemberID() = %q, %q, want %q, %q", team, username, tt.wantTeam, tt.wantUsername)
+			}
+			if got := getTeamMemberID(team, username); got != tt.id {
+				t.Errorf("getTeamMemberID() = %q, want %q", got, tt.id)
+			}
+		})
+	}
+}
+
+// teamMembersServer is a fake oncall server for the members of team platform,
+// recording the requests that change them
+func teamMembersServer(t *testing.T, members *[]string, teamExists bool) (*oncall.Client, *[]string, func()) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !teamExists {
+			w.WriteHeader(404)
+			return
+		}
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v0/teams/platform/users":
+			json.NewEncoder(w).Encode(*members)
+		case r.Method == "POST" && r.URL.Path == "/api/v0/teams/platform/users":
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			*members = append(*members, "alice")
+			w.WriteHeader(201)
+		case r.Method == "DELETE" && r.URL.Path == "/api/v0/teams/platform/users/alice":
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			*members = []string{}
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	return c, &requests, server.Close
+}
+
+func Test_resourceTeamMemberRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		members []string
+		wantID  string
+	}{
+		{name: "Member", members: []string{"bob", "alice"}, wantID: "platform/alice"},
+		{name: "No longer a member", members: []string{"bob"}, wantID: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _, close := teamMembersServer(t, &tt.members, true)
+			defer close()
+
+			d := schema.TestResourceDataRaw(t, resourceTeamMember().Schema, map[string]interface{}{})
+			d.SetId("platform/alice")
+			diags := resourceTeamMemberRead(context.Background(), d, &providerMeta{client: c})
+			if diags.HasError() {
+				t.Fatalf("resourceTeamMemberRead() error = %v", diags)
+			}
+			if d.Id() != tt.wantID {
+				t.Errorf("resourceTeamMemberRead() ID = %q, want %q", d.Id(), tt.wantID)
+			}
+			if tt.wantID != "" && (d.Get(teamMemberFieldTeam) != "platform" || d.Get(teamMemberFieldUsername) != "alice") {
+				t.Errorf("resourceTeamMemberRead() read team %v and username %v", d.Get(teamMemberFieldTeam), d.Get(teamMemberFieldUsername))
+			}
+		})
+	}
+}
+
+func Test_resourceTeamMemberCreate(t *testing.T) {
+	members := []string{"bob"}
+	c, requests, close := teamMembersServer(t, &members, true)
+	defer close()
+
+	d := schema.TestResourceDataRaw(t, resourceTeamMember().Schema, map[string]interface{}{
+		teamMemberFieldTeam:     "platform",
+		teamMemberFieldUsername: "alice",
+	})
+	diags := resourceTeamMemberCreate(context.Background(), d, &providerMeta{client: c})
+	if diags.HasError() {
+		t.Fatalf("resourceTeamMemberCreate() error = %v", diags)
+	}
+	if d.Id() != "platform/alice" {
+		t.Errorf("resourceTeamMemberCreate() ID = %q, want %q", d.Id(), "platform/alice")
+	}
+	if want := []string{"POST /api/v0/teams/platform/users"}; !reflect.DeepEqual(*requests, want) {
+		t.Errorf("resourceTeamMemberCreate() made requests %v, want %v", *requests, want)
+	}
+}
+
+func Test_resourceTeamMemberDelete(t *testing.T) {
+	tests := []struct {
+		name         string
+		teamExists   bool
+		wantRequests []string
+	}{
+		{name: "Member", teamExists: true, wantRequests: []string{"DELETE /api/v0/teams/platform/users/alice"}},
+		{name: "Team deleted first", teamExists: false, wantRequests: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			members := []string{"alice"}
+			c, requests, close := teamMembersServer(t, &members, tt.teamExists)
+			defer close()
+
+			d := schema.TestResourceDataRaw(t, resourceTeamMember().Schema, map[string]interface{}{})
+			d.SetId("platform/alice")
+			diags := resourceTeamMemberDelete(context.Background(), d, &providerMeta{client: c})
+			if diags.HasError() {
+				t.Fatalf("resourceTeamMemberDelete() error = %v", diags)
+			}
+			if d.Id() != "" {
+				t.Errorf("resourceTeamMemberDelete() left ID %q", d.Id())
+			}
+			if !reflect.DeepEqual(*requests, tt.wantRequests) {
+				t.Errorf("resourceTeamMemberDelete() made requests %v, want %v", *requests, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_teamAdminsToSet(t *testing.T) {
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: "http://oncall", Username: "terraform", Password: "secret", AuthMethod: oncall.AuthMethodUser}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name               string
+		admins             []interface{}
+		addSelfAsTeamAdmin bool
+		want               []string
+	}{
+		{
+			name:   "Only the configured admins",
+			admins: []interface{}{"alice"},
+			want:   []string{"alice"},
+		},
+		{
+			name:               "Provider's user added",
+			admins:             []interface{}{"alice"},
+			addSelfAsTeamAdmin: true,
+			want:               []string{"alice", "terraform"},
+		},
+		{
+			name:               "Provider's user already an admin",
+			admins:             []interface{}{"terraform"},
+			addSelfAsTeamAdmin: true,
+			want:               []string{"terraform"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceTeam().Schema, map[string]interface{}{
+				teamFieldName:   "team",
+				teamFieldAdmins: tt.admins,
+			})
+			got := teamAdminsToSet(d, &providerMeta{client: c, addSelfAsTeamAdmin: tt.addSelfAsTeamAdmin})
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("teamAdminsToSet() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_resourceTeamReadRevision(t *testing.T) {
+	description := "Keeps the lights on"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/platform":
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": "platform", "description": description, "admins": []interface{}{}})
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	revision := func() string {
+		d := resourceTeam().TestResourceData()
+		d.SetId("platform")
+		if diags := resourceTeamRead(context.Background(), d, &providerMeta{client: c}); diags.HasError() {
+			t.Fatalf("resourceTeamRead() error = %v", diags)
+		}
+		return d.Get(fieldRevision).(string)
+	}
+
+	before := revision()
+	description = "Keeps the lights on\n\nschedule-description: platform/primary EU covers nights"
+	if got := revision(); got != before {
+		t.Errorf("resourceTeamRead() revision = %s after a schedule description was added, want %s", got, before)
+	}
+	description = "Keeps the lights on and the heating"
+	if got := revision(); got == before {
+		t.Errorf("resourceTeamRead() revision = %s after the description changed, want it to change", got)
+	}
+}
@@ -0,0 +1,53 @@
+package oncall
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// fieldRevision is shared by the resources that report a revision, a hash of
+// what was read from oncall that changes whenever the object is changed there,
+// including in the UI, for replace_triggered_by and postconditions to react to
+const fieldRevision = "revision"
+
+// objectRevision hashes the JSON of an object normalized by the caller, so
+// only real changes to it change the revision
+func objectRevision(v interface{}) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		warnLog("Could not hash revision of %T: %s", v, err)
+		return ""
+	}
+	sum := sha256.Sum256(out)
+	return hex.EncodeToString(sum[:])
+}
+
+// scheduleRevision is the revision of a schedule, leaving out how far it has
+// been populated as that moves on without the schedule itself changing
+func scheduleRevision(sched oncall.Schedule) string {
+	sched.Events = sortedScheduleEvents(sched.Events)
+	return objectRevision(sched)
+}
+
+// sortedScheduleEvents is a copy of the events sorted by start, as oncall
+// doesn't return them in any particular order
+func sortedScheduleEvents(events []oncall.ScheduleEvent) []oncall.ScheduleEvent {
+	sorted := append([]oncall.ScheduleEvent{}, events...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	return sorted
+}
+
+// revisionCustomizeDiff plans the revision as unknown when the resource is
+// going to be changed, as applying the change changes the object in oncall
+func revisionCustomizeDiff() schema.CustomizeDiffFunc {
+	return customdiff.ComputedIf(fieldRevision, func(ctx context.Context, d *schema.ResourceDiff, m interface{}) bool {
+		return d.Id() != "" && len(d.GetChangedKeysPrefix("")) > 0
+	})
+}
@@ -0,0 +1,84 @@
+package oncall
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/customdiff"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_scheduleRevision(t *testing.T) {
+	sched := oncall.Schedule{
+		ID:                    1,
+		Team:                  "t",
+		Roster:                "r",
+		Role:                  "primary",
+		AutoPopulateThreshold: 21,
+		Events:                []oncall.ScheduleEvent{{Start: 0, Duration: 3600}, {Start: 86400, Duration: 3600}},
+	}
+	reordered := sched
+	reordered.Events = []oncall.ScheduleEvent{sched.Events[1], sched.Events[0]}
+	moved := sched
+	moved.Events = []oncall.ScheduleEvent{{Start: 0, Duration: 3600}, {Start: 90000, Duration: 3600}}
+
+	got := scheduleRevision(sched)
+	if got == "" {
+		t.Fatalf("scheduleRevision() is empty")
+	}
+	if r := scheduleRevision(reordered); r != got {
+		t.Errorf("scheduleRevision() = %s for reordered events, want %s", r, got)
+	}
+	if r := scheduleRevision(moved); r == got {
+		t.Errorf("scheduleRevision() = %s for a moved event, want it to change", r)
+	}
+	if sched.Events[0].Start != 0 || reordered.Events[0].Start != 86400 {
+		t.Errorf("scheduleRevision() sorted the events it was given")
+	}
+}
+
+func Test_revisionCustomizeDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema:        resourceRotation().Schema,
+		CustomizeDiff: customdiff.All(revisionCustomizeDiff()),
+	}
+	state := &terraform.InstanceState{ID: "t/r/primary", Attributes: map[string]string{
+		"team": "t", "role": "primary", "roster_name": "r", "members.#": "1", "members.0": "alice",
+		"handoff_day": "Monday", "handoff_time": "09:00", "length": "weekly", "auto_populate_days": "21",
+		"trim_future_events": "false", "revision": "abc",
+	}}
+	config := func(members ...interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"team": "t", "role": "primary", "roster_name": "r", "members": members,
+			"handoff_day": "Monday", "handoff_time": "09:00",
+		}
+	}
+
+	tests := []struct {
+		name        string
+		state       *terraform.InstanceState
+		config      map[string]interface{}
+		wantUnknown bool
+	}{
+		{name: "New", config: config("alice"), wantUnknown: true},
+		{name: "Unchanged", state: state, config: config("alice")},
+		{name: "Changed", state: state, config: config("alice", "bob"), wantUnknown: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := r.Diff(context.Background(), tt.state, terraform.NewResourceConfigRaw(tt.config), nil)
+			if err != nil {
+				t.Fatalf("revisionCustomizeDiff() error = %v", err)
+			}
+			var attr *terraform.ResourceAttrDiff
+			if diff != nil {
+				attr = diff.Attributes[fieldRevision]
+			}
+			if got := attr != nil && attr.NewComputed; got != tt.wantUnknown {
+				t.Errorf("revisionCustomizeDiff() planned revision %v, wantUnknown %v", attr, tt.wantUnknown)
+			}
+		})
+	}
+}
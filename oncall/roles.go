@@ -0,0 +1,27 @@
+package oncall
+
+import (
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// oncallRole is a role as returned by /api/v0/roles
+type oncallRole struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	DisplayOrder int    `json:"display_order"`
+}
+
+// getRoles returns every role configured on the oncall instance in display order
+func getRoles(c *oncall.Client) ([]oncallRole, error) {
+	roles := []oncallRole{}
+	_, err := c.Get("/api/v0/roles", &roles)
+	if err != nil {
+		return nil, errors.Wrap(err, "Fetching roles")
+	}
+
+	sort.SliceStable(roles, func(i, j int) bool { return roles[i].DisplayOrder < roles[j].DisplayOrder })
+	return roles, nil
+}
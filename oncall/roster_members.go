@@ -0,0 +1,225 @@
+package oncall
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	rosterMemberFieldName       = "name"
+	rosterMemberFieldInRotation = "in_rotation"
+	rosterMemberFieldOrder      = "order"
+)
+
+// rosterMember is a roster user as returned by the oncall API. The oncall
+// client's RosterUser can't be used, it has two fields for in_rotation so
+// encoding/json ignores both.
+type rosterMember struct {
+	Name           string  `json:"name"`
+	InRotation     apiBool `json:"in_rotation"`
+	RosterPriority int     `json:"roster_priority"`
+}
+
+// rosterMemberConfig is how a member block wants a user on the roster
+type rosterMemberConfig struct {
+	Name       string
+	InRotation bool
+	// Order is the member's position in the rotation, from 1, or 0 for
+	// members whose position doesn't matter
+	Order int
+}
+
+// rosterMemberResource is the schema of the member blocks of oncall_roster
+func rosterMemberResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			rosterMemberFieldName: &schema.Schema{
+				Type:             schema.TypeString,
+				Required:         true,
+				ValidateDiagFunc: validateUsername,
+				StateFunc:        usernameStateFunc,
+				Description:      "Username of the member",
+			},
+			rosterMemberFieldInRotation: &schema.Schema{
+				Type:        schema.TypeBool,
+				Optional:    true,
+				Default:     true,
+				Description: "Whether schedules on the roster put the member on call, false keeps them on the roster without scheduling them, e.g. while on leave",
+			},
+			rosterMemberFieldOrder: &schema.Schema{
+				Type:        schema.TypeInt,
+				Optional:    true,
+				Default:     0,
+				Description: "Position of the member in the roster's rotation order, from 1. Members with an order come first and must be numbered 1, 2, 3 and so on, the rest follow in the order oncall has them",
+			},
+		},
+	}
+}
+
+// rosterMembersFromSet reads the member blocks, checking each user is only
+// listed once and the orders that are set are numbered from 1 without gaps
+func rosterMembersFromSet(members *schema.Set) ([]rosterMemberConfig, error) {
+	ret := []rosterMemberConfig{}
+	seen := map[string]bool{}
+	orders := []int{}
+	for _, raw := range members.List() {
+		m := raw.(map[string]interface{})
+		member := rosterMemberConfig{
+			Name:       normalizeUsername(m[rosterMemberFieldName].(string)),
+			InRotation: m[rosterMemberFieldInRotation].(bool),
+			Order:      m[rosterMemberFieldOrder].(int),
+		}
+		if seen[member.Name] {
+			return nil, errors.Errorf("Member %s is listed more than once", member.Name)
+		}
+		seen[member.Name] = true
+		if member.Order != 0 {
+			orders = append(orders, member.Order)
+		}
+		ret = append(ret, member)
+	}
+
+	sort.Ints(orders)
+	for i, order := range orders {
+		if order != i+1 {
+			return nil, errors.Errorf("Member orders must be numbered 1 to %d without gaps or repeats, got %v", len(orders), orders)
+		}
+	}
+	return ret, nil
+}
+
+// getRosterMembers lists the members of a roster in their rotation order
+func getRosterMembers(c *oncall.Client, team, roster string) ([]rosterMember, error) {
+	r := struct {
+		Users []rosterMember `json:"users"`
+	}{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters/%s", url.PathEscape(team), url.PathEscape(roster)), &r)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Fetching members of roster %s/%s", team, roster)
+	}
+	sort.SliceStable(r.Users, func(i, j int) bool { return r.Users[i].RosterPriority < r.Users[j].RosterPriority })
+	return r.Users, nil
+}
+
+// rosterMemberOrder is the rotation order of the roster once the members are
+// set, the ordered members first and then the rest as they were. Nil if none
+// of the members have an order.
+func rosterMemberOrder(current []rosterMember, members []rosterMemberConfig) []string {
+	ordered := []rosterMemberConfig{}
+	wanted := map[string]bool{}
+	for _, m := range members {
+		wanted[m.Name] = true
+		if m.Order != 0 {
+			ordered = append(ordered, m)
+		}
+	}
+	if len(ordered) == 0 {
+		return nil
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Order < ordered[j].Order })
+
+	order := []string{}
+	placed := map[string]bool{}
+	for _, m := range ordered {
+		order = append(order, m.Name)
+		placed[m.Name] = true
+	}
+	// Members that are staying keep their place, new members go last
+	rest := []string{}
+	for _, m := range current {
+		if wanted[m.Name] && !placed[m.Name] {
+			rest = append(rest, m.Name)
+			placed[m.Name] = true
+		}
+	}
+	for _, m := range members {
+		if !placed[m.Name] {
+			rest = append(rest, m.Name)
+		}
+	}
+	return append(order, rest...)
+}
+
+// setRosterMembers makes the roster's members, whether they are in rotation,
+// and their order match the member blocks
+func setRosterMembers(c *oncall.Client, team, roster string, members []rosterMemberConfig) error {
+	current, err := getRosterMembers(c, team, roster)
+	if err != nil {
+		return err
+	}
+	existing := map[string]rosterMember{}
+	for _, m := range current {
+		existing[m.Name] = m
+	}
+
+	path := fmt.Sprintf("/api/v0/teams/%s/rosters/%s", url.PathEscape(team), url.PathEscape(roster))
+	wanted := map[string]bool{}
+	for _, m := range members {
+		wanted[m.Name] = true
+		body := map[string]interface{}{"in_rotation": m.InRotation}
+		e, ok := existing[m.Name]
+		switch {
+		case !ok:
+			traceLog("Going to add %s to roster %s/%s, in rotation: %v", m.Name, team, roster, m.InRotation)
+			body["name"] = m.Name
+			_, err = c.Post(path+"/users", body, nil)
+		case bool(e.InRotation) != m.InRotation:
+			traceLog("Going to set %s in rotation on roster %s/%s to %v", m.Name, team, roster, m.InRotation)
+			_, err = c.Put(path+"/users/"+url.PathEscape(m.Name), body, nil)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Setting member %s of roster %s/%s", m.Name, team, roster)
+		}
+	}
+	for _, m := range current {
+		if wanted[m.Name] {
+			continue
+		}
+		traceLog("Going to remove %s from roster %s/%s", m.Name, team, roster)
+		_, err = c.Delete(path+"/users/"+url.PathEscape(m.Name), nil, nil)
+		if err != nil {
+			return errors.Wrapf(err, "Removing member %s of roster %s/%s", m.Name, team, roster)
+		}
+	}
+
+	if order := rosterMemberOrder(current, members); order != nil {
+		traceLog("Going to set the order of roster %s/%s to %v", team, roster, order)
+		_, err = c.Put(path, map[string]interface{}{"roster_order": order}, nil)
+		if err != nil {
+			return errors.Wrapf(err, "Ordering roster %s/%s", team, roster)
+		}
+	}
+	return nil
+}
+
+// readRosterMembers sets the member blocks from the roster. Orders are only
+// read back for the members the configuration orders, so the others don't
+// churn as oncall moves them around.
+func readRosterMembers(d *schema.ResourceData, members []rosterMember) {
+	ordered := map[string]bool{}
+	for _, raw := range d.Get(rosterFieldMember).(*schema.Set).List() {
+		m := raw.(map[string]interface{})
+		if m[rosterMemberFieldOrder].(int) != 0 {
+			ordered[m[rosterMemberFieldName].(string)] = true
+		}
+	}
+
+	blocks := make([]interface{}, 0, len(members))
+	for i, m := range members {
+		order := 0
+		if ordered[m.Name] {
+			order = i + 1
+		}
+		blocks = append(blocks, map[string]interface{}{
+			rosterMemberFieldName:       m.Name,
+			rosterMemberFieldInRotation: bool(m.InRotation),
+			rosterMemberFieldOrder:      order,
+		})
+	}
+	d.Set(rosterFieldMember, blocks)
+}
@@ -0,0 +1,196 @@
+package oncall
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_rosterMembersFromSet(t *testing.T) {
+	member := func(name string, inRotation bool, order int) interface{} {
+		return map[string]interface{}{
+			rosterMemberFieldName:       name,
+			rosterMemberFieldInRotation: inRotation,
+			rosterMemberFieldOrder:      order,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		members []interface{}
+		want    []rosterMemberConfig
+		wantErr bool
+	}{
+		{
+			name:    "Unordered",
+			members: []interface{}{member(" alice", true, 0)},
+			want:    []rosterMemberConfig{{Name: "alice", InRotation: true}},
+		},
+		{
+			name:    "Ordered from 1",
+			members: []interface{}{member("alice", false, 2), member("bob", true, 1), member("carol", true, 0)},
+			want: []rosterMemberConfig{
+				{Name: "alice", InRotation: false, Order: 2},
+				{Name: "bob", InRotation: true, Order: 1},
+				{Name: "carol", InRotation: true},
+			},
+		},
+		{
+			name:    "Gap in order",
+			members: []interface{}{member("alice", true, 1), member("bob", true, 3)},
+			wantErr: true,
+		},
+		{
+			name:    "Repeated order",
+			members: []interface{}{member("alice", true, 1), member("bob", true, 1)},
+			wantErr: true,
+		},
+		{
+			name:    "Repeated member",
+			members: []interface{}{member("alice", true, 0), member("alice ", false, 0)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := schema.NewSet(schema.HashResource(rosterMemberResource()), tt.members)
+			got, err := rosterMembersFromSet(set)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("rosterMembersFromSet() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("rosterMembersFromSet() = %v, want %v", got, tt.want)
+			}
+			for _, w := range tt.want {
+				found := false
+				for _, g := range got {
+					found = found || g == w
+				}
+				if !found {
+					t.Errorf("rosterMembersFromSet() = %v, missing %v", got, w)
+				}
+			}
+		})
+	}
+}
+
+func Test_rosterMemberOrder(t *testing.T) {
+	current := []rosterMember{{Name: "alice"}, {Name: "bob"}, {Name: "carol"}}
+
+	tests := []struct {
+		name    string
+		members []rosterMemberConfig
+		want    []string
+	}{
+		{
+			name:    "Nothing ordered",
+			members: []rosterMemberConfig{{Name: "alice"}, {Name: "bob"}},
+			want:    nil,
+		},
+		{
+			name:    "Ordered members first, the rest keep their places",
+			members: []rosterMemberConfig{{Name: "alice"}, {Name: "bob"}, {Name: "carol", Order: 1}},
+			want:    []string{"carol", "alice", "bob"},
+		},
+		{
+			name:    "New unordered members go last",
+			members: []rosterMemberConfig{{Name: "dave"}, {Name: "bob", Order: 2}, {Name: "carol"}, {Name: "erin", Order: 1}},
+			want:    []string{"erin", "bob", "carol", "dave"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rosterMemberOrder(current, tt.members); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rosterMemberOrder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_setRosterMembers(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			if r.URL.Path != "/api/v0/teams/team/rosters/roster" {
+				w.WriteHeader(404)
+				return
+			}
+			w.Write([]byte(`{"id":1,"name":"roster","users":[
+				{"name":"bob","in_rotation":1,"roster_priority":1},
+				{"name":"alice","in_rotation":1,"roster_priority":0},
+				{"name":"carol","in_rotation":0,"roster_priority":2}
+			]}`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		w.Write([]byte(`null`))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		roster       string
+		members      []rosterMemberConfig
+		wantRequests []string
+		wantErr      bool
+	}{
+		{
+			name:   "Already set",
+			roster: "roster",
+			members: []rosterMemberConfig{
+				{Name: "alice", InRotation: true},
+				{Name: "bob", InRotation: true},
+				{Name: "carol", InRotation: false},
+			},
+			wantRequests: []string{},
+		},
+		{
+			name:   "Add, remove, take out of rotation and order",
+			roster: "roster",
+			members: []rosterMemberConfig{
+				{Name: "alice", InRotation: false},
+				{Name: "bob", InRotation: true, Order: 1},
+				{Name: "dave", InRotation: true},
+			},
+			wantRequests: []string{
+				`PUT /api/v0/teams/team/rosters/roster/users/alice {"in_rotation":false}`,
+				`POST /api/v0/teams/team/rosters/roster/users {"in_rotation":true,"name":"dave"}`,
+				`DELETE /api/v0/teams/team/rosters/roster/users/carol null`,
+				`PUT /api/v0/teams/team/rosters/roster {"roster_order":["bob","alice","dave"]}`,
+			},
+		},
+		{
+			name:         "Missing roster",
+			roster:       "missing",
+			members:      []rosterMemberConfig{{Name: "alice", InRotation: true}},
+			wantRequests: []string{},
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests = []string{}
+			err := setRosterMembers(c, "team", tt.roster, tt.members)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("setRosterMembers() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("setRosterMembers() requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,154 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// rosterTeamChangeCustomizeDiff replaces the roster when its team changes,
+// unless migrate_on_team_change moves it in place, in which case its
+// roster_id is planned on the new team so schedules follow it
+func rosterTeamChangeCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.Id() == "" || !d.HasChange(rosterFieldTeam) {
+		return nil
+	}
+	if !d.Get(rosterFieldMigrateOnTeamChange).(bool) {
+		return d.ForceNew(rosterFieldTeam)
+	}
+
+	if err := d.SetNewComputed(rosterFieldOncallID); err != nil {
+		return err
+	}
+	if !d.NewValueKnown(rosterFieldTeam) {
+		return d.SetNewComputed(rosterFieldRosterID)
+	}
+	_, rosterName, err := parseRosterID(d.Id())
+	if err != nil {
+		return err
+	}
+	return d.SetNew(rosterFieldRosterID, getRosterID(d.Get(rosterFieldTeam).(string), rosterName))
+}
+
+// migrateRosterTeam moves the roster to its new team: it creates the roster
+// there, copies the members and schedules over and populates the copies, and
+// only then deletes the old roster, so the role is covered throughout. The
+// previous state is kept until it is done, and a roster left on the new team
+// by a migration that failed part way is picked up by the next one.
+func migrateRosterTeam(ctx context.Context, c *oncall.Client, d *schema.ResourceData, oldTeam, rosterName string) diag.Diagnostics {
+	newTeam := d.Get(rosterFieldTeam).(string)
+	oldID, newID := getRosterID(oldTeam, rosterName), getRosterID(newTeam, rosterName)
+	d.Partial(true)
+
+	if d.Get(rosterFieldProtectActiveOncall).(bool) {
+		current, err := c.GetRosterUsers(oldTeam, rosterName)
+		if err != nil {
+			return diagFromErrf(err, "Getting roster %s members", oldID)
+		}
+		diags := checkNotOnCall(c, oldTeam, rosterName, current, time.Now())
+		if diags.HasError() {
+			return diags
+		}
+	}
+
+	schedules, err := getRosterSchedules(c, oldTeam, rosterName)
+	if err != nil {
+		return diagFromErrf(err, "Getting schedules of roster %s to migrate", oldID)
+	}
+
+	infoLog("Going to migrate roster %s to %s", oldID, newID)
+	roster, err := c.CreateRoster(newTeam, rosterName)
+	if err != nil {
+		if e, ok := parseAPIError(err); !ok || !e.alreadyExists() {
+			return createErrorDiags(err, "Creating roster on its new team", newID, rosterFieldTeam)
+		}
+		infoLog("Roster %s already exists, continuing an earlier migration into it", newID)
+		roster, err = c.GetRoster(newTeam, rosterName)
+		if err != nil {
+			return diagFromErrf(err, "Getting roster %s", newID)
+		}
+	}
+
+	if d.Get(rosterFieldAutoAddTeamMembers).(bool) {
+		err = addMissingTeamMembers(c, newTeam, rosterRequestedMembers(d))
+		if err != nil {
+			return diagFromErrf(err, "Adding roster members to team %s", newTeam)
+		}
+	}
+	err = setRosterMembersFromResource(c, d, newTeam, rosterName)
+	if err != nil {
+		return rosterMembersErrorDiags(c, newTeam, rosterRequestedMembers(d), err)
+	}
+
+	var diags diag.Diagnostics
+	copied, err := getRosterSchedules(c, newTeam, rosterName)
+	if err != nil {
+		return diagFromErrf(err, "Getting schedules of roster %s", newID)
+	}
+	for _, s := range schedules {
+		if _, found := findRosterSchedule(copied, 0, s.Role); found {
+			debugLog("Roster %s already has a %s schedule, not copying it again", newID, s.Role)
+			continue
+		}
+		sched := s.Schedule
+		sched.ID = 0
+		sched.Team = newTeam
+		sched.Roster = rosterName
+		err = c.AddRosterSchedule(newTeam, rosterName, sched)
+		if err != nil {
+			return diagFromErrf(err, "Copying %s schedule to roster %s", s.Role, newID)
+		}
+		err = populateRosterSchedule(ctx, c, newTeam, rosterName, s.Role, nil, time.Now())
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Could not populate the %s schedule copied to roster %s", s.Role, newID),
+				Detail:   fmt.Sprintf("Oncall populates it on its own within a day, until then team %s has no %s events from it.\n\n%s", newTeam, s.Role, err),
+			})
+		}
+	}
+
+	err = c.DeleteRoster(oldTeam, rosterName)
+	if err != nil && !isNotFound(err) {
+		return append(diags, diagFromErrf(err, "Deleting roster %s after migrating it to %s", oldID, newID)...)
+	}
+	infoLog("Migrated roster %s to %s with %d schedules", oldID, newID, len(schedules))
+
+	d.SetId(newID)
+	d.Set(rosterFieldOncallID, roster.ID)
+	d.Partial(false)
+	return diags
+}
+
+// followMigratedSchedule finds a schedule whose roster_id moved to another
+// team on the copy its roster's migration made there, as the migration
+// deleted the schedule along with the old roster. The schedule is left as it
+// is if it is still on its old roster, or has no copy.
+func followMigratedSchedule(c *oncall.Client, d *schema.ResourceData, teamName, rosterName, role string) (string, string, error) {
+	if !d.HasChange(scheduleFieldRosterID) {
+		return teamName, rosterName, nil
+	}
+	newTeam, newRoster, err := parseRosterID(d.Get(scheduleFieldRosterID).(string))
+	if err != nil || strings.EqualFold(newTeam, teamName) {
+		return teamName, rosterName, nil
+	}
+
+	_, found, err := getRosterScheduleByID(c, teamName, rosterName, d.Get(scheduleFieldScheduleID).(int), role)
+	if err != nil || found {
+		return teamName, rosterName, err
+	}
+	moved, found, err := getRosterSchedule(c, newTeam, newRoster, role)
+	if err != nil || !found {
+		return teamName, rosterName, err
+	}
+
+	infoLog("Schedule %s moved to roster %s along with its roster", d.Id(), getRosterID(newTeam, newRoster))
+	d.SetId(getScheduleID(newTeam, newRoster, role))
+	d.Set(scheduleFieldScheduleID, moved.ID)
+	return newTeam, newRoster, nil
+}
@@ -0,0 +1,219 @@
+package oncall
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_rosterTeamChangeCustomizeDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema:        resourceRoster().Schema,
+		CustomizeDiff: rosterTeamChangeCustomizeDiff,
+	}
+	state := &terraform.InstanceState{ID: "platform/sre", Attributes: map[string]string{
+		"team": "platform", "name": "sre", "members.#": "1", "members.1": "a", "roster_id": "platform/sre",
+		"oncall_id": "42", "protect_active_oncall": "false", "auto_add_team_members": "false", "migrate_on_team_change": "false",
+	}}
+
+	tests := []struct {
+		name            string
+		config          map[string]interface{}
+		wantRequiresNew bool
+		wantRosterID    string
+	}{
+		{
+			name:            "Replaced",
+			config:          map[string]interface{}{"team": "payments", "name": "sre", "members": []interface{}{"a"}},
+			wantRequiresNew: true,
+		},
+		{
+			name:         "Migrated",
+			config:       map[string]interface{}{"team": "payments", "name": "sre", "members": []interface{}{"a"}, "migrate_on_team_change": true},
+			wantRosterID: "payments/sre",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(tt.config), nil)
+			if err != nil {
+				t.Fatalf("rosterTeamChangeCustomizeDiff() error = %v", err)
+			}
+			if diff.RequiresNew() != tt.wantRequiresNew {
+				t.Errorf("rosterTeamChangeCustomizeDiff() requires new = %v, want %v", diff.RequiresNew(), tt.wantRequiresNew)
+			}
+			if tt.wantRosterID != "" {
+				if got := diff.Attributes[rosterFieldRosterID]; got == nil || got.New != tt.wantRosterID {
+					t.Errorf("rosterTeamChangeCustomizeDiff() planned roster_id = %+v, want %q", got, tt.wantRosterID)
+				}
+			}
+		})
+	}
+}
+
+func Test_migrateRosterTeam(t *testing.T) {
+	tests := []struct {
+		name        string
+		resumed     bool
+		wantChanges []string
+	}{
+		{
+			name: "Migrated",
+			wantChanges: []string{
+				"POST /api/v0/teams/payments/rosters",
+				"POST /api/v0/teams/payments/rosters/sre/users",
+				"POST /api/v0/teams/payments/rosters/sre/schedules",
+				"POST /api/v0/schedules/21/populate",
+				"DELETE /api/v0/teams/platform/rosters/sre",
+			},
+		},
+		{
+			name:    "Resumed after failing part way",
+			resumed: true,
+			wantChanges: []string{
+				"POST /api/v0/teams/payments/rosters",
+				"DELETE /api/v0/teams/platform/rosters/sre",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			changes := []string{}
+			copied := tt.resumed
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if mutatingMethod(r.Method) {
+					changes = append(changes, r.Method+" "+r.URL.Path)
+				}
+				switch r.Method + " " + r.URL.Path {
+				case "GET /api/v0/teams/platform/rosters/sre/schedules":
+					w.Write([]byte(`[{"id": 11, "role": "primary", "advanced_mode": 0, "auto_populate_threshold": 21, "scheduler": {"name": "default"}, "events": [{"start": 0, "duration": 604800}]}]`))
+				case "POST /api/v0/teams/payments/rosters":
+					if tt.resumed {
+						w.WriteHeader(422)
+						w.Write([]byte(`{"title": "IntegrityError", "description": "roster \"sre\" already exists"}`))
+						return
+					}
+					w.WriteHeader(201)
+				case "GET /api/v0/teams/payments/rosters/sre":
+					w.Write([]byte(`{"id": 7, "users": [], "schedules": []}`))
+				case "GET /api/v0/teams/payments/rosters/sre/users":
+					if tt.resumed {
+						w.Write([]byte(`["alice"]`))
+						return
+					}
+					w.Write([]byte(`[]`))
+				case "GET /api/v0/teams/payments/rosters/sre/schedules":
+					if copied {
+						w.Write([]byte(`[{"id": 21, "role": "primary", "scheduler": {"name": "default"}, "events": [{"start": 0, "duration": 604800}]}]`))
+						return
+					}
+					w.Write([]byte(`[]`))
+				case "POST /api/v0/teams/payments/rosters/sre/schedules":
+					body, _ := ioutil.ReadAll(r.Body)
+					if !strings.Contains(string(body), `"team":"payments"`) || strings.Contains(string(body), `"id":11`) {
+						t.Errorf("Copied schedule %s, want it on team payments without the old ID", body)
+					}
+					copied = true
+					w.WriteHeader(201)
+				case "GET /api/v0/events":
+					w.Write([]byte(`[]`))
+				case "POST /api/v0/teams/payments/rosters/sre/users", "POST /api/v0/schedules/21/populate", "DELETE /api/v0/teams/platform/rosters/sre":
+				default:
+					w.WriteHeader(404)
+				}
+			}))
+			defer server.Close()
+
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+			d := schema.TestResourceDataRaw(t, resourceRoster().Schema, map[string]interface{}{
+				"team": "payments", "name": "sre", "members": []interface{}{"alice"}, "migrate_on_team_change": true,
+			})
+			d.SetId("platform/sre")
+
+			diags := migrateRosterTeam(context.Background(), c, d, "platform", "sre")
+			if diags.HasError() {
+				t.Fatalf("migrateRosterTeam() = %v", diags)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("migrateRosterTeam() made changes %q, want %q", changes, tt.wantChanges)
+			}
+			if d.Id() != "payments/sre" || d.Get(rosterFieldOncallID).(int) != 7 {
+				t.Errorf("migrateRosterTeam() left roster %s with oncall_id %d, want payments/sre with 7", d.Id(), d.Get(rosterFieldOncallID))
+			}
+		})
+	}
+}
+
+func Test_followMigratedSchedule(t *testing.T) {
+	tests := []struct {
+		name           string
+		oldRoster      string
+		wantID         string
+		wantScheduleID int
+	}{
+		{
+			name:           "Moved with its roster",
+			oldRoster:      `[]`,
+			wantID:         "payments/sre/primary",
+			wantScheduleID: 21,
+		},
+		{
+			name:           "Still on its old roster",
+			oldRoster:      `[{"id": 11, "role": "primary"}]`,
+			wantID:         "platform/sre/primary",
+			wantScheduleID: 11,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v0/teams/platform/rosters/sre/schedules":
+					w.Write([]byte(tt.oldRoster))
+				case "/api/v0/teams/payments/rosters/sre/schedules":
+					w.Write([]byte(`[{"id": 21, "role": "primary"}]`))
+				default:
+					w.WriteHeader(404)
+				}
+			}))
+			defer server.Close()
+
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+			d := schema.TestResourceDataRaw(t, resourceBasicSchedule().Schema, map[string]interface{}{
+				scheduleFieldRosterID: "payments/sre",
+				scheduleFieldRole:     "primary",
+			})
+			d.SetId("platform/sre/primary")
+			d.Set(scheduleFieldScheduleID, 11)
+
+			team, roster, err := followMigratedSchedule(c, d, "platform", "sre", "primary")
+			if err != nil {
+				t.Fatalf("followMigratedSchedule() error = %v", err)
+			}
+			if got := getScheduleID(team, roster, "primary"); got != tt.wantID || d.Id() != tt.wantID {
+				t.Errorf("followMigratedSchedule() = %s with ID %s, want %s", got, d.Id(), tt.wantID)
+			}
+			if got := d.Get(scheduleFieldScheduleID).(int); got != tt.wantScheduleID {
+				t.Errorf("followMigratedSchedule() schedule_id = %d, want %d", got, tt.wantScheduleID)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package oncall
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+// uniqueNameSuffixPattern matches the suffix resource.PrefixedUniqueId adds,
+// a timestamp and a counter
+var uniqueNameSuffixPattern = regexp.MustCompile(fmt.Sprintf(`^[0-9a-f]{%d}$`, resource.UniqueIDSuffixLength))
+
+// validateNamePrefix checks that a name made from the prefix is a name oncall
+// accepts, leaving room for the unique suffix
+func validateNamePrefix(val interface{}, path cty.Path) diag.Diagnostics {
+	prefix := val.(string)
+	if max := maxNameLength - resource.UniqueIDSuffixLength; len(prefix) > max {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("Name prefix %q is %d characters long, at most %d leaves room for the %d character unique suffix", prefix, len(prefix), max, resource.UniqueIDSuffixLength),
+				AttributePath: path,
+			},
+		}
+	}
+	return validateName(prefix+strings.Repeat("0", resource.UniqueIDSuffixLength), path)
+}
+
+// namePrefixFromName is the prefix a name was generated from, empty if it
+// wasn't generated, so rosters created with name_prefix keep it when imported
+func namePrefixFromName(name string) string {
+	if len(name) < resource.UniqueIDSuffixLength {
+		return ""
+	}
+	split := len(name) - resource.UniqueIDSuffixLength
+	if !uniqueNameSuffixPattern.MatchString(name[split:]) {
+		return ""
+	}
+	return name[:split]
+}
@@ -0,0 +1,50 @@
+package oncall
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/resource"
+)
+
+func Test_validateNamePrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "Plain", value: "sre-"},
+		{name: "Longest", value: strings.Repeat("a", maxNameLength-resource.UniqueIDSuffixLength)},
+		{name: "No room for suffix", value: strings.Repeat("a", maxNameLength-resource.UniqueIDSuffixLength+1), wantErr: true},
+		{name: "Slash", value: "platform/sre-", wantErr: true},
+		{name: "Leading space", value: " sre-", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diags := validateNamePrefix(tt.value, cty.GetAttrPath("name_prefix")); diags.HasError() != tt.wantErr {
+				t.Errorf("validateNamePrefix() = %v, wantErr %v", diags, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_namePrefixFromName(t *testing.T) {
+	tests := []struct {
+		name       string
+		rosterName string
+		want       string
+	}{
+		{name: "Generated", rosterName: resource.PrefixedUniqueId("sre-"), want: "sre-"},
+		{name: "Generated without prefix", rosterName: resource.PrefixedUniqueId(""), want: ""},
+		{name: "Plain", rosterName: "sre", want: ""},
+		{name: "Not a suffix", rosterName: "sre-" + strings.Repeat("z", resource.UniqueIDSuffixLength), want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := namePrefixFromName(tt.rosterName); got != tt.want {
+				t.Errorf("namePrefixFromName(%q) = %q, want %q", tt.rosterName, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// rosterSchedule is a schedule as returned by the oncall API, including the
+// fields that the oncall client does not know about
+type rosterSchedule struct {
+	oncall.Schedule
+	LastEpochScheduled int64 `json:"last_epoch_scheduled"`
+}
+
+// getRosterSchedules lists every schedule on a roster
+func getRosterSchedules(c *oncall.Client, team, roster string) ([]rosterSchedule, error) {
+	schedules := []rosterSchedule{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters/%s/schedules", url.PathEscape(team), url.PathEscape(roster)), &schedules)
+	return schedules, errors.Wrapf(err, "Fetching schedules for roster %s/%s", team, roster)
+}
+
+// getRosterSchedule finds the schedule for a role on a roster. It is not an
+// error for the roster or schedule to be missing, found is false instead.
+func getRosterSchedule(c *oncall.Client, team, roster, role string) (schedule rosterSchedule, found bool, err error) {
+	return getRosterScheduleByID(c, team, roster, 0, role)
+}
+
+// getRosterScheduleByID finds a schedule on a roster by its numeric ID, only
+// falling back to the role when the ID isn't known yet, e.g. on import
+func getRosterScheduleByID(c *oncall.Client, team, roster string, id int, role string) (schedule rosterSchedule, found bool, err error) {
+	schedules, err := getRosterSchedules(c, team, roster)
+	if err != nil {
+		if strings.Contains(err.Error(), "(404)") {
+			return rosterSchedule{}, false, nil
+		}
+		return rosterSchedule{}, false, err
+	}
+
+	schedule, found = findRosterSchedule(schedules, id, role)
+	return schedule, found, nil
+}
+
+func findRosterSchedule(schedules []rosterSchedule, id int, role string) (rosterSchedule, bool) {
+	for _, s := range schedules {
+		if id != 0 && s.ID == id {
+			return s, true
+		}
+		if id == 0 && strings.EqualFold(s.Role, role) {
+			return s, true
+		}
+	}
+	return rosterSchedule{}, false
+}
+
+// updateRosterSchedule updates the schedule by its numeric ID if it is known,
+// as the role in the resource ID may have been renamed
+func updateRosterSchedule(c *oncall.Client, id int, team, roster, role string, schedule oncall.Schedule) error {
+	if id == 0 {
+		existing, found, err := getRosterSchedule(c, team, roster, role)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return fmt.Errorf("Did not find roster schedule %s/%s/%s to update", team, roster, role)
+		}
+		id = existing.ID
+	}
+	_, err := c.Put(fmt.Sprintf("/api/v0/schedules/%d", id), schedule, nil)
+	return errors.Wrapf(err, "Updating schedule %d on roster %s/%s", id, team, roster)
+}
+
+const (
+	scheduleDeleteAttempts   = 3
+	scheduleDeleteRetryDelay = time.Second
+)
+
+// removeRosterSchedule removes the schedule by its numeric ID if it is known,
+// so that a schedule which has taken over the role is left alone. On destroy
+// the roster or team may be deleted first, which deletes its schedules along
+// with it, so a schedule that is already gone counts as removed. A delete
+// racing one of those cascades can fail on the server, so those are retried.
+func removeRosterSchedule(ctx context.Context, c *oncall.Client, id int, team, roster, role string) error {
+	if id == 0 {
+		schedule, found, err := getRosterSchedule(c, team, roster, role)
+		if err != nil {
+			return err
+		}
+		if !found {
+			debugLog("Roster schedule %s/%s/%s was already deleted", team, roster, role)
+			return nil
+		}
+		id = schedule.ID
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.RemoveRosterScheduleByID(id)
+		if err == nil || isNotFound(err) {
+			if err != nil {
+				debugLog("Roster schedule %s/%s/%s was already deleted: %s", team, roster, role, err)
+			}
+			return nil
+		}
+
+		apiErr, ok := parseAPIError(err)
+		if !ok || apiErr.status < 500 || attempt >= scheduleDeleteAttempts {
+			return err
+		}
+		warnLog("Deleting roster schedule %s/%s/%s failed, retrying: %s", team, roster, role, err)
+		select {
+		case <-ctx.Done():
+			return errors.Wrapf(ctx.Err(), "Deleting roster schedule, gave up after %d attempts", attempt)
+		case <-time.After(scheduleDeleteRetryDelay/2 + time.Duration(rand.Int63n(int64(scheduleDeleteRetryDelay)))):
+		}
+		recordRetry(ctx)
+	}
+}
+
+// getRosterInRotationUsers lists the roster members which are currently in rotation
+func getRosterInRotationUsers(c *oncall.Client, team, roster string) ([]string, error) {
+	users := []string{}
+	_, err := c.Get(fmt.Sprintf("/api/v0/teams/%s/rosters/%s/users?in_rotation=1", url.PathEscape(team), url.PathEscape(roster)), &users)
+	return users, errors.Wrapf(err, "Fetching in rotation users for roster %s/%s", team, roster)
+}
+
+// checkRosterNotEmpty stops a schedule being put on a roster with nobody in
+// rotation, as oncall silently populates an empty calendar for it. With
+// defer_populate set it warns instead and returns true, so the populate can
+// be put off until the roster has members.
+func checkRosterNotEmpty(c *oncall.Client, d *schema.ResourceData, field, team, roster string) (deferred bool, diags diag.Diagnostics) {
+	if d.Get(scheduleFieldAllowEmptyRoster).(bool) {
+		return false, nil
+	}
+
+	users, err := getRosterInRotationUsers(c, team, roster)
+	if err != nil {
+		return false, diagFromErrf(err, "Checking members of roster %s", getRosterID(team, roster))
+	}
+	if len(users) > 0 {
+		return false, nil
+	}
+
+	if d.Get(scheduleFieldDeferPopulate).(bool) {
+		return true, diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       fmt.Sprintf("Roster %s has nobody in rotation, not populating the schedule yet", getRosterID(team, roster)),
+				Detail:        fmt.Sprintf("%s is set, so the schedule will be populated by the first plan and apply after the roster has members.", scheduleFieldDeferPopulate),
+				AttributePath: cty.Path{cty.GetAttrStep{Name: field}},
+			},
+		}
+	}
+	return false, diag.Diagnostics{
+		diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("Roster %s has nobody in rotation", getRosterID(team, roster)),
+			Detail:        fmt.Sprintf("Oncall would populate an empty calendar for this schedule. Add members to the roster, set %s = true to populate it once they are added, or set %s = true if it is filled in later.", scheduleFieldDeferPopulate, scheduleFieldAllowEmptyRoster),
+			AttributePath: cty.Path{cty.GetAttrStep{Name: field}},
+		},
+	}
+}
+
+// scheduleAdvancedModeCustomizeDiff plans converting a schedule to the mode
+// of the resource managing it, e.g. after it was imported into the other kind
+// of schedule resource. Updates send the mode along with the events, so the
+// schedule keeps its ID and populated calendar instead of being replaced.
+func scheduleAdvancedModeCustomizeDiff(advanced bool) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if d.Id() != "" && d.Get(scheduleFieldAdvancedMode).(bool) == advanced {
+			return nil
+		}
+		return d.SetNew(scheduleFieldAdvancedMode, advanced)
+	}
+}
+
+// deferredPopulateCustomizeDiff plans an update of schedules whose populate
+// was deferred once all of their rosters have someone in rotation, so the
+// update can populate them
+func deferredPopulateCustomizeDiff(rosterFields ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		if d.Id() == "" || !d.Get(scheduleFieldPopulatePending).(bool) {
+			return nil
+		}
+
+		for _, field := range rosterFields {
+			rosterID := d.Get(field).(string)
+			if rosterID == "" {
+				continue
+			}
+			if !d.NewValueKnown(field) {
+				return nil
+			}
+			teamName, rosterName, err := parseRosterID(rosterID)
+			if err != nil {
+				return nil
+			}
+			users, err := getRosterInRotationUsers(m.(*providerMeta).clientFor(ctx), teamName, rosterName)
+			if err != nil {
+				debugLog("Not checking if deferred populate of %s can go ahead: %s", d.Id(), err)
+				return nil
+			}
+			if len(users) == 0 {
+				return nil
+			}
+		}
+
+		traceLog("Rosters of %s have members now, planning its deferred populate", d.Id())
+		return d.SetNew(scheduleFieldPopulatePending, false)
+	}
+}
+
+// scheduleRosterCustomizeDiff keeps roster_id and the separate team and roster
+// attributes in step, so a schedule can be configured with either form and the
+// rest of the resource only has to look at roster_id
+func scheduleRosterCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	if d.HasChange(scheduleFieldTeam) || d.HasChange(scheduleFieldRoster) {
+		if !d.NewValueKnown(scheduleFieldTeam) || !d.NewValueKnown(scheduleFieldRoster) {
+			return d.SetNewComputed(scheduleFieldRosterID)
+		}
+		rosterID := getRosterID(d.Get(scheduleFieldTeam).(string), d.Get(scheduleFieldRoster).(string))
+		if rosterID == d.Get(scheduleFieldRosterID).(string) {
+			return nil
+		}
+		return d.SetNew(scheduleFieldRosterID, rosterID)
+	}
+
+	if !d.NewValueKnown(scheduleFieldRosterID) {
+		if err := d.SetNewComputed(scheduleFieldTeam); err != nil {
+			return err
+		}
+		return d.SetNewComputed(scheduleFieldRoster)
+	}
+	rosterID := d.Get(scheduleFieldRosterID).(string)
+	teamName, rosterName, err := parseRosterID(rosterID)
+	if err != nil {
+		return errors.Wrapf(err, "Parsing %s", scheduleFieldRosterID)
+	}
+	if teamName != d.Get(scheduleFieldTeam).(string) {
+		if err := d.SetNew(scheduleFieldTeam, teamName); err != nil {
+			return err
+		}
+	}
+	if rosterName != d.Get(scheduleFieldRoster).(string) {
+		if err := d.SetNew(scheduleFieldRoster, rosterName); err != nil {
+			return err
+		}
+		// Setting roster clears the planned value of every attribute it is a
+		// prefix of, which includes roster_id, so put it back
+		return d.SetNew(scheduleFieldRosterID, rosterID)
+	}
+	return nil
+}
@@ -0,0 +1,423 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_getRosterSchedule(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/team/rosters/roster/schedules":
+			w.Write([]byte(`[{"id": 7, "role": "primary", "advanced_mode": 0}]`))
+		case "/api/v0/teams/team/rosters/broken/schedules":
+			w.WriteHeader(500)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		roster    string
+		role      string
+		wantID    int
+		wantFound bool
+		wantErr   bool
+	}{
+		{
+			name:      "Found",
+			roster:    "roster",
+			role:      "Primary",
+			wantID:    7,
+			wantFound: true,
+		},
+		{
+			name:   "Missing schedule",
+			roster: "roster",
+			role:   "secondary",
+		},
+		{
+			name:   "Missing roster",
+			roster: "gone",
+			role:   "primary",
+		},
+		{
+			name:    "Server error",
+			roster:  "broken",
+			role:    "primary",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotFound, err := getRosterSchedule(c, "team", tt.roster, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRosterSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotFound != tt.wantFound || got.ID != tt.wantID {
+				t.Errorf("getRosterSchedule() = %d, %v, want %d, %v", got.ID, gotFound, tt.wantID, tt.wantFound)
+			}
+		})
+	}
+}
+
+func Test_updateRosterSchedule(t *testing.T) {
+	puts := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v0/teams/team/rosters/roster/schedules":
+			w.Write([]byte(`[{"id": 7, "role": "primary", "advanced_mode": 0}]`))
+		case "PUT /api/v0/schedules/7", "PUT /api/v0/schedules/9":
+			puts = append(puts, r.URL.Path)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		id       int
+		role     string
+		wantPuts []string
+		wantErr  bool
+	}{
+		{name: "By role", role: "primary", wantPuts: []string{"/api/v0/schedules/7"}},
+		{name: "By ID", id: 9, role: "renamed", wantPuts: []string{"/api/v0/schedules/9"}},
+		{name: "Missing role", role: "secondary", wantPuts: []string{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			puts = []string{}
+			err := updateRosterSchedule(c, tt.id, "team", "roster", tt.role, oncall.Schedule{Role: tt.role})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("updateRosterSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(puts, tt.wantPuts) {
+				t.Errorf("updateRosterSchedule() puts = %v, want %v", puts, tt.wantPuts)
+			}
+		})
+	}
+}
+
+func Test_removeRosterSchedule(t *testing.T) {
+	deletes := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deletes[r.URL.Path]++
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v0/teams/team/rosters/roster/schedules":
+			w.Write([]byte(`[{"id": 7, "role": "primary", "advanced_mode": 0}]`))
+		case "DELETE /api/v0/schedules/7":
+		case "DELETE /api/v0/schedules/8":
+			// Deadlocked with a cascading delete the first time
+			if deletes[r.URL.Path] == 1 {
+				w.WriteHeader(500)
+			}
+		case "DELETE /api/v0/schedules/9":
+			w.WriteHeader(403)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		id          int
+		roster      string
+		role        string
+		wantErr     bool
+		wantDeletes int
+	}{
+		{name: "By role", roster: "roster", role: "primary", wantDeletes: 1},
+		{name: "By ID", id: 7, roster: "roster", role: "primary", wantDeletes: 1},
+		{name: "Schedule already gone", id: 6, roster: "roster", role: "primary", wantDeletes: 1},
+		{name: "Roster already gone", roster: "gone", role: "primary"},
+		{name: "Retried server error", id: 8, roster: "roster", role: "secondary", wantDeletes: 2},
+		{name: "Not retried client error", id: 9, roster: "roster", role: "secondary", wantErr: true, wantDeletes: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deletes = map[string]int{}
+			err := removeRosterSchedule(context.Background(), c, tt.id, "team", tt.roster, tt.role)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("removeRosterSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			total := 0
+			for _, n := range deletes {
+				total += n
+			}
+			if total != tt.wantDeletes {
+				t.Errorf("removeRosterSchedule() made %d deletes, want %d", total, tt.wantDeletes)
+			}
+		})
+	}
+}
+
+func Test_findRosterSchedule(t *testing.T) {
+	schedules := []rosterSchedule{
+		{Schedule: oncall.Schedule{ID: 3, Role: "primary"}},
+		{Schedule: oncall.Schedule{ID: 7, Role: "secondary"}},
+	}
+	tests := []struct {
+		name      string
+		id        int
+		role      string
+		wantID    int
+		wantFound bool
+	}{
+		{
+			name:      "By role without an ID",
+			role:      "Secondary",
+			wantID:    7,
+			wantFound: true,
+		},
+		{
+			name:      "ID wins over a renamed role",
+			id:        7,
+			role:      "primary",
+			wantID:    7,
+			wantFound: true,
+		},
+		{
+			name: "Deleted schedule isn't replaced by one with the same role",
+			id:   9,
+			role: "primary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := findRosterSchedule(schedules, tt.id, tt.role)
+			if found != tt.wantFound {
+				t.Fatalf("findRosterSchedule() found = %v, want %v", found, tt.wantFound)
+			}
+			if got.ID != tt.wantID {
+				t.Errorf("findRosterSchedule() ID = %v, want %v", got.ID, tt.wantID)
+			}
+		})
+	}
+}
+
+func Test_checkRosterNotEmpty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/team/rosters/staffed/users":
+			w.Write([]byte(`["alice"]`))
+		case "/api/v0/teams/team/rosters/empty/users":
+			w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		roster        string
+		allowEmpty    bool
+		deferPopulate bool
+		wantDeferred  bool
+		wantErr       bool
+	}{
+		{
+			name:   "Roster with members",
+			roster: "staffed",
+		},
+		{
+			name:    "Empty roster",
+			roster:  "empty",
+			wantErr: true,
+		},
+		{
+			name:       "Empty roster allowed",
+			roster:     "empty",
+			allowEmpty: true,
+		},
+		{
+			name:          "Empty roster deferred",
+			roster:        "empty",
+			deferPopulate: true,
+			wantDeferred:  true,
+		},
+		{
+			name:          "Roster with members not deferred",
+			roster:        "staffed",
+			deferPopulate: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceBasicSchedule().Schema, map[string]interface{}{
+				scheduleFieldAllowEmptyRoster: tt.allowEmpty,
+				scheduleFieldDeferPopulate:    tt.deferPopulate,
+			})
+			deferred, diags := checkRosterNotEmpty(c, d, scheduleFieldRosterID, "team", tt.roster)
+			if diags.HasError() != tt.wantErr {
+				t.Errorf("checkRosterNotEmpty() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if deferred != tt.wantDeferred {
+				t.Errorf("checkRosterNotEmpty() deferred = %v, want %v", deferred, tt.wantDeferred)
+			}
+			if deferred && len(diags) == 0 {
+				t.Errorf("checkRosterNotEmpty() deferred without a warning")
+			}
+		})
+	}
+}
+
+func Test_scheduleRosterCustomizeDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema:        resourceBasicSchedule().Schema,
+		CustomizeDiff: scheduleRosterCustomizeDiff,
+	}
+
+	tests := []struct {
+		name         string
+		state        *terraform.InstanceState
+		config       map[string]interface{}
+		wantRosterID string
+		wantTeam     string
+		wantRoster   string
+		wantErr      bool
+	}{
+		{
+			name:         "New with roster_id",
+			config:       map[string]interface{}{"role": "primary", "roster_id": "t/r"},
+			wantRosterID: "t/r",
+			wantTeam:     "t",
+			wantRoster:   "r",
+		},
+		{
+			name:         "New with team and roster",
+			config:       map[string]interface{}{"role": "primary", "team": "t", "roster": "r"},
+			wantRosterID: "t/r",
+			wantTeam:     "t",
+			wantRoster:   "r",
+		},
+		{
+			name: "Moved with team and roster",
+			state: &terraform.InstanceState{ID: "t/r/primary", Attributes: map[string]string{
+				"role": "primary", "roster_id": "t/r", "team": "t", "roster": "r",
+			}},
+			config:       map[string]interface{}{"role": "primary", "team": "t", "roster": "other"},
+			wantRosterID: "t/other",
+			wantTeam:     "t",
+			wantRoster:   "other",
+		},
+		{
+			name: "Moved with roster_id",
+			state: &terraform.InstanceState{ID: "t/r/primary", Attributes: map[string]string{
+				"role": "primary", "roster_id": "t/r", "team": "t", "roster": "r",
+			}},
+			config:       map[string]interface{}{"role": "primary", "roster_id": "u/r"},
+			wantRosterID: "u/r",
+			wantTeam:     "u",
+			wantRoster:   "r",
+		},
+		{
+			name:    "Bad roster_id",
+			config:  map[string]interface{}{"role": "primary", "roster_id": "t"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diff, err := r.Diff(context.Background(), tt.state, terraform.NewResourceConfigRaw(tt.config), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("scheduleRosterCustomizeDiff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for field, want := range map[string]string{
+				scheduleFieldRosterID: tt.wantRosterID,
+				scheduleFieldTeam:     tt.wantTeam,
+				scheduleFieldRoster:   tt.wantRoster,
+			} {
+				got := ""
+				if tt.state != nil {
+					got = tt.state.Attributes[field]
+				}
+				if attr := diff.Attributes[field]; attr != nil {
+					got = attr.New
+				}
+				if got != want {
+					t.Errorf("scheduleRosterCustomizeDiff() planned %s = %q, want %q", field, got, want)
+				}
+			}
+		})
+	}
+}
+
+func Test_scheduleAdvancedModeCustomizeDiff(t *testing.T) {
+	state := func(advancedMode string) *terraform.InstanceState {
+		return &terraform.InstanceState{ID: "t/r/primary", Attributes: map[string]string{
+			"role": "primary", "roster_id": "t/r", "team": "t", "roster": "r", "advanced_mode": advancedMode,
+		}}
+	}
+
+	tests := []struct {
+		name         string
+		advanced     bool
+		state        *terraform.InstanceState
+		wantChange   bool
+		wantAdvanced string
+	}{
+		{name: "New advanced schedule", advanced: true, wantChange: true, wantAdvanced: "true"},
+		{name: "New basic schedule", wantChange: true, wantAdvanced: "false"},
+		{name: "Advanced schedule in advanced mode", advanced: true, state: state("true")},
+		{name: "Basic schedule in basic mode", state: state("false")},
+		{name: "Imported into advanced schedule", advanced: true, state: state("false"), wantChange: true, wantAdvanced: "true"},
+		{name: "Imported into basic schedule", state: state("true"), wantChange: true, wantAdvanced: "false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &schema.Resource{
+				Schema:        resourceBasicSchedule().Schema,
+				CustomizeDiff: scheduleAdvancedModeCustomizeDiff(tt.advanced),
+			}
+			diff, err := r.Diff(context.Background(), tt.state, terraform.NewResourceConfigRaw(map[string]interface{}{"role": "primary", "roster_id": "t/r"}), nil)
+			if err != nil {
+				t.Fatalf("scheduleAdvancedModeCustomizeDiff() error = %v", err)
+			}
+			attr := diff.Attributes[scheduleFieldAdvancedMode]
+			if (attr != nil) != tt.wantChange {
+				t.Fatalf("scheduleAdvancedModeCustomizeDiff() planned %v, wantChange %v", attr, tt.wantChange)
+			}
+			if attr != nil && attr.New != tt.wantAdvanced {
+				t.Errorf("scheduleAdvancedModeCustomizeDiff() planned advanced_mode = %q, want %q", attr.New, tt.wantAdvanced)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package oncall
+
+import (
+	"fmt"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/pkg/errors"
+)
+
+// missingTeamMembers are the usernames that aren't members of the team. Some
+// oncall deployments only let team members onto the team's rosters.
+func missingTeamMembers(c *oncall.Client, team string, usernames []string) ([]string, error) {
+	members, err := c.GetTeamUsers(team)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Getting members of team %s", team)
+	}
+	missing := []string{}
+	for _, username := range usernames {
+		if !stringSliceContains(members, username) {
+			missing = append(missing, username)
+		}
+	}
+	return missing, nil
+}
+
+// addMissingTeamMembers adds the usernames that aren't members of the team to
+// it, so they can be put on its rosters
+func addMissingTeamMembers(c *oncall.Client, team string, usernames []string) error {
+	missing, err := missingTeamMembers(c, team, usernames)
+	if err != nil {
+		return err
+	}
+	for _, username := range missing {
+		infoLog("Adding %s to team %s to put them on its roster", username, team)
+		err = c.AddTeamUser(team, username)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rosterMembersErrorDiags explains a failure to set the roster's members when
+// some of them aren't members of the team, which oncall reports obscurely
+func rosterMembersErrorDiags(c *oncall.Client, team string, usernames []string, err error) diag.Diagnostics {
+	missing, checkErr := missingTeamMembers(c, team, usernames)
+	if checkErr != nil || len(missing) == 0 {
+		return diagFromErrf(err, "Setting roster members")
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  fmt.Sprintf("Setting roster members failed, %v are not members of team %s", missing, team),
+			Detail:   fmt.Sprintf("This oncall may only let team members onto the team's rosters. Add them to the team with oncall_team_member or the team's roster, or set %s = true to add them when the roster is changed.\n\n%s", rosterFieldAutoAddTeamMembers, err),
+		},
+	}
+}
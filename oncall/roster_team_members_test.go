@@ -0,0 +1,78 @@
+package oncall
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_rosterTeamMembers(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v0/teams/team/users" {
+			w.WriteHeader(404)
+			return
+		}
+		if r.Method == "GET" {
+			w.Write([]byte(`["alice", "bob"]`))
+			return
+		}
+		user := struct {
+			Name string `json:"name"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&user)
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+user.Name)
+		w.Write([]byte(`null`))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		members      []string
+		wantRequests []string
+		wantSummary  string
+	}{
+		{
+			name:         "All team members",
+			members:      []string{"alice", "bob"},
+			wantRequests: []string{},
+			wantSummary:  "Setting roster members",
+		},
+		{
+			name:    "Not on the team",
+			members: []string{"alice", "carol", "dave"},
+			wantRequests: []string{
+				`POST /api/v0/teams/team/users carol`,
+				`POST /api/v0/teams/team/users dave`,
+			},
+			wantSummary: "[carol dave] are not members of team team",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := rosterMembersErrorDiags(c, "team", tt.members, errors.New("HTTP Request failed (422) (Invalid user)"))
+			if !diags.HasError() || !strings.Contains(diags[0].Summary, tt.wantSummary) {
+				t.Errorf("rosterMembersErrorDiags() = %v, want an error containing %q", diags, tt.wantSummary)
+			}
+
+			requests = []string{}
+			if err := addMissingTeamMembers(c, "team", tt.members); err != nil {
+				t.Fatalf("addMissingTeamMembers() error = %v", err)
+			}
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("addMissingTeamMembers() requests = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// resourceGetter is satisfied by both schema.ResourceData and
+// schema.ResourceDiff, so schedules can be built from state or from a plan
+type resourceGetter interface {
+	Get(key string) interface{}
+}
+
+// scheduleFromResourceFunc builds the oncall schedule a resource describes
+type scheduleFromResourceFunc func(d resourceGetter, weekStart time.Weekday) (oncall.Schedule, error)
+
+// scheduleDefinition is the canonical form of a schedule for policy checks to
+// assert against, independent of how it was written in the configuration
+type scheduleDefinition struct {
+	Role                string                    `json:"role"`
+	RosterID            string                    `json:"roster_id"`
+	AdvancedMode        bool                      `json:"advanced_mode"`
+	SchedulingAlgorithm string                    `json:"scheduling_algorithm"`
+	AutoPopulateDays    int                       `json:"auto_populate_days"`
+	Events              []scheduleDefinitionEvent `json:"events"`
+}
+
+type scheduleDefinitionEvent struct {
+	StartDayOfWeek  string  `json:"start_day_of_week"`
+	StartTime       string  `json:"start_time"`
+	StartSeconds    int     `json:"start_seconds"`
+	DurationSeconds int     `json:"duration_seconds"`
+	DurationHours   float64 `json:"duration_hours"`
+}
+
+// normalizedScheduleDefinition renders the schedule as JSON with its events
+// sorted by when they start in the week
+func normalizedScheduleDefinition(sched oncall.Schedule, weekStart time.Weekday) (string, error) {
+	def := scheduleDefinition{
+		Role:                sched.Role,
+		RosterID:            getRosterID(sched.Team, sched.Roster),
+		AdvancedMode:        sched.AdvancedMode != 0,
+		SchedulingAlgorithm: sched.Scheduler.Name,
+		AutoPopulateDays:    sched.AutoPopulateThreshold,
+		Events:              []scheduleDefinitionEvent{},
+	}
+
+	for _, e := range sched.Events {
+		dayOfWeekIndex, startHour, startMin, startSec := secondsToDayTime(e.Start, weekStart)
+		def.Events = append(def.Events, scheduleDefinitionEvent{
+			StartDayOfWeek:  daysOfWeek[dayOfWeekIndex],
+			StartTime:       formatTimeOfDay(startHour, startMin, startSec),
+			StartSeconds:    e.Start,
+			DurationSeconds: e.Duration,
+			DurationHours:   float64(e.Duration) / 3600,
+		})
+	}
+	sort.SliceStable(def.Events, func(i, j int) bool { return def.Events[i].StartSeconds < def.Events[j].StartSeconds })
+
+	out, err := json.Marshal(def)
+	return string(out), err
+}
+
+// readScheduleDefinition sets the normalized definition from what was read
+// into state, so it matches what scheduleDefinitionCustomizeDiff plans
+func readScheduleDefinition(d *schema.ResourceData, fromResource scheduleFromResourceFunc, m interface{}) diag.Diagnostics {
+	sched, err := fromResource(d, weekStartFor(m))
+	if err != nil {
+		return normalizationDiags(m, "Could not build normalized definition of schedule %s: %s", d.Id(), err)
+	}
+	def, err := normalizedScheduleDefinition(sched, weekStartFor(m))
+	if err != nil {
+		return normalizationDiags(m, "Could not build normalized definition of schedule %s: %s", d.Id(), err)
+	}
+	d.Set(scheduleFieldNormalizedDefinitionJSON, def)
+	return nil
+}
+
+// scheduleDefinitionCustomizeDiff plans the normalized definition so policies
+// can check it before apply. It is left unknown when the schedule depends on
+// values that aren't known until apply, such as a roster being created.
+func scheduleDefinitionCustomizeDiff(fromResource scheduleFromResourceFunc) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		sched, err := fromResource(d, weekStartFor(m))
+		if err != nil {
+			traceLog("Could not plan normalized definition of schedule %s: %s", d.Id(), err)
+			if err := d.SetNewComputed(scheduleFieldChangeSummary); err != nil {
+				return err
+			}
+			return d.SetNewComputed(scheduleFieldNormalizedDefinitionJSON)
+		}
+		def, err := normalizedScheduleDefinition(sched, weekStartFor(m))
+		if err != nil {
+			return err
+		}
+		oldDef := d.Get(scheduleFieldNormalizedDefinitionJSON).(string)
+		if def == oldDef {
+			return nil
+		}
+		// The summary is left alone when nothing changed, so the last one
+		// doesn't turn into a diff of its own on the next plan
+		err = d.SetNew(scheduleFieldChangeSummary, scheduleChangeSummary(oldDef, def))
+		if err != nil {
+			return err
+		}
+		return d.SetNew(scheduleFieldNormalizedDefinitionJSON, def)
+	}
+}
+
+// scheduleChangeSummary describes how a schedule changes between two of its
+// normalized definitions, e.g. "handoff moved from Mon 09:00 to Tue 10:00"
+func scheduleChangeSummary(oldJSON, newJSON string) string {
+	newDef := scheduleDefinition{}
+	if err := json.Unmarshal([]byte(newJSON), &newDef); err != nil {
+		return ""
+	}
+	oldDef := scheduleDefinition{}
+	if oldJSON == "" || json.Unmarshal([]byte(oldJSON), &oldDef) != nil {
+		return fmt.Sprintf("new %s schedule on %s with %s", newDef.Role, newDef.RosterID, describeEvents(newDef.Events))
+	}
+
+	changes := []string{}
+	if oldDef.Role != newDef.Role {
+		changes = append(changes, fmt.Sprintf("role changed from %s to %s", oldDef.Role, newDef.Role))
+	}
+	if oldDef.RosterID != newDef.RosterID {
+		changes = append(changes, fmt.Sprintf("roster changed from %s to %s", oldDef.RosterID, newDef.RosterID))
+	}
+
+	if len(oldDef.Events) == 1 && len(newDef.Events) == 1 {
+		oldEvent, newEvent := oldDef.Events[0], newDef.Events[0]
+		if oldEvent.StartSeconds != newEvent.StartSeconds {
+			changes = append(changes, fmt.Sprintf("handoff moved from %s to %s", eventStart(oldEvent), eventStart(newEvent)))
+		}
+		if oldEvent.DurationSeconds != newEvent.DurationSeconds {
+			changes = append(changes, fmt.Sprintf("rotation length changed from %s to %s", eventLength(oldEvent), eventLength(newEvent)))
+		}
+	} else if !reflect.DeepEqual(oldDef.Events, newDef.Events) {
+		changes = append(changes, fmt.Sprintf("shifts changed from %s to %s", describeEvents(oldDef.Events), describeEvents(newDef.Events)))
+	}
+
+	if oldDef.SchedulingAlgorithm != newDef.SchedulingAlgorithm {
+		changes = append(changes, fmt.Sprintf("scheduling algorithm changed from %s to %s", oldDef.SchedulingAlgorithm, newDef.SchedulingAlgorithm))
+	}
+	if oldDef.AutoPopulateDays != newDef.AutoPopulateDays {
+		changes = append(changes, fmt.Sprintf("auto populate days changed from %d to %d", oldDef.AutoPopulateDays, newDef.AutoPopulateDays))
+	}
+	if oldDef.AdvancedMode != newDef.AdvancedMode {
+		changes = append(changes, "advanced mode turned "+map[bool]string{true: "on", false: "off"}[newDef.AdvancedMode])
+	}
+	return strings.Join(changes, "; ")
+}
+
+// eventStart is when in the week an event starts, e.g. Mon 09:00
+func eventStart(e scheduleDefinitionEvent) string {
+	day := e.StartDayOfWeek
+	if len(day) > 3 {
+		day = day[:3]
+	}
+	return day + " " + e.StartTime
+}
+
+// eventLength is how long an event lasts in hours, e.g. 168h
+func eventLength(e scheduleDefinitionEvent) string {
+	return strconv.FormatFloat(e.DurationHours, 'f', -1, 64) + "h"
+}
+
+// describeEvents lists when each event starts and how long it lasts
+func describeEvents(events []scheduleDefinitionEvent) string {
+	if len(events) == 0 {
+		return "no shifts"
+	}
+	described := make([]string, 0, len(events))
+	for _, e := range events {
+		described = append(described, eventStart(e)+" for "+eventLength(e))
+	}
+	return "[" + strings.Join(described, ", ") + "]"
+}
@@ -0,0 +1,126 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"maze.io/x/duration"
+)
+
+func Test_normalizedScheduleDefinition(t *testing.T) {
+	day := int(duration.Day.Seconds())
+	hour := int(duration.Hour.Seconds())
+	tests := []struct {
+		name  string
+		sched oncall.Schedule
+		want  string
+	}{
+		{
+			name: "Basic weekly schedule",
+			sched: oncall.Schedule{
+				Team:                  "team",
+				Roster:                "roster",
+				Role:                  "primary",
+				AutoPopulateThreshold: 21,
+				Scheduler:             oncall.ScheduleScheduler{Name: "default"},
+				Events:                []oncall.ScheduleEvent{{Start: day + 9*hour, Duration: weekSeconds}},
+			},
+			want: `{"role":"primary","roster_id":"team/roster","advanced_mode":false,"scheduling_algorithm":"default","auto_populate_days":21,` +
+				`"events":[{"start_day_of_week":"Monday","start_time":"09:00","start_seconds":118800,"duration_seconds":604800,"duration_hours":168}]}`,
+		},
+		{
+			name: "Advanced schedule events are sorted",
+			sched: oncall.Schedule{
+				Team:         "team",
+				Roster:       "roster",
+				Role:         "secondary",
+				AdvancedMode: 1,
+				Scheduler:    oncall.ScheduleScheduler{Name: "round-robin"},
+				Events:       []oncall.ScheduleEvent{{Start: 2 * day, Duration: 12 * hour}, {Start: day + 30*60, Duration: 90 * 60}},
+			},
+			want: `{"role":"secondary","roster_id":"team/roster","advanced_mode":true,"scheduling_algorithm":"round-robin","auto_populate_days":0,` +
+				`"events":[{"start_day_of_week":"Monday","start_time":"00:30","start_seconds":88200,"duration_seconds":5400,"duration_hours":1.5},` +
+				`{"start_day_of_week":"Tuesday","start_time":"00:00","start_seconds":172800,"duration_seconds":43200,"duration_hours":12}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizedScheduleDefinition(tt.sched, time.Sunday)
+			if err != nil {
+				t.Fatalf("normalizedScheduleDefinition() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("normalizedScheduleDefinition() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_scheduleChangeSummary(t *testing.T) {
+	day := int(duration.Day.Seconds())
+	hour := int(duration.Hour.Seconds())
+	definition := func(f func(s *oncall.Schedule)) string {
+		sched := oncall.Schedule{
+			Team:                  "team",
+			Roster:                "roster",
+			Role:                  "primary",
+			AutoPopulateThreshold: 21,
+			Scheduler:             oncall.ScheduleScheduler{Name: "default"},
+			Events:                []oncall.ScheduleEvent{{Start: day + 9*hour, Duration: weekSeconds}},
+		}
+		f(&sched)
+		def, _ := normalizedScheduleDefinition(sched, time.Sunday)
+		return def
+	}
+	unchanged := definition(func(s *oncall.Schedule) {})
+
+	tests := []struct {
+		name string
+		old  string
+		new  string
+		want string
+	}{
+		{
+			name: "New schedule",
+			new:  unchanged,
+			want: "new primary schedule on team/roster with [Mon 09:00 for 168h]",
+		},
+		{
+			name: "Handoff moved",
+			old:  unchanged,
+			new:  definition(func(s *oncall.Schedule) { s.Events[0].Start = 2*day + 10*hour }),
+			want: "handoff moved from Mon 09:00 to Tue 10:00",
+		},
+		{
+			name: "Bi-weekly on another roster",
+			old:  unchanged,
+			new: definition(func(s *oncall.Schedule) {
+				s.Roster = "other"
+				s.Events[0].Duration = 2 * weekSeconds
+			}),
+			want: "roster changed from team/roster to team/other; rotation length changed from 168h to 336h",
+		},
+		{
+			name: "Shifts changed",
+			old:  unchanged,
+			new: definition(func(s *oncall.Schedule) {
+				s.AdvancedMode = 1
+				s.Events = []oncall.ScheduleEvent{{Start: day + 9*hour, Duration: 8 * hour}, {Start: 2*day + 9*hour, Duration: 8 * hour}}
+			}),
+			want: "shifts changed from [Mon 09:00 for 168h] to [Mon 09:00 for 8h, Tue 09:00 for 8h]; advanced mode turned on",
+		},
+		{
+			name: "Unchanged",
+			old:  unchanged,
+			new:  unchanged,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduleChangeSummary(tt.old, tt.new); got != tt.want {
+				t.Errorf("scheduleChangeSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
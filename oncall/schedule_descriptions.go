@@ -0,0 +1,133 @@
+package oncall
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// Oncall schedules have no description either, so like slack usergroups they
+// are kept as annotation lines at the end of the team's description, which
+// the oncall UI shows on the team's page, e.g.
+// "schedule-description: primary-roster/primary Split so EU covers nights"
+const scheduleDescriptionAnnotationPrefix = "schedule-description:"
+
+const scheduleFieldDescription = "description"
+
+// scheduleDescriptionSchema is the schema of the description of schedules
+func scheduleDescriptionSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		Optional:         true,
+		ValidateDiagFunc: validateScheduleDescription,
+		Description:      "Notes on the schedule, e.g. why its shifts are laid out the way they are. Oncall has no description for schedules, so it is kept as a line at the end of the team's description, which the oncall UI shows on the team's page",
+	}
+}
+
+func validateScheduleDescription(val interface{}, path cty.Path) diag.Diagnostics {
+	if strings.ContainsAny(val.(string), "\r\n") {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "Schedule descriptions must be a single line",
+				AttributePath: path,
+			},
+		}
+	}
+	return nil
+}
+
+// scheduleDescriptionKey identifies a schedule among the team's annotations
+func scheduleDescriptionKey(roster, role string) string {
+	return roster + "/" + role
+}
+
+func init() {
+	registerTeamAnnotation(teamAnnotationKind{
+		prefix: scheduleDescriptionAnnotationPrefix,
+		parse: func(rest string) (string, string, bool) {
+			fields := strings.SplitN(strings.TrimSpace(rest), " ", 2)
+			if len(fields) != 2 || !strings.Contains(fields[0], "/") {
+				return "", "", false
+			}
+			return fields[0], strings.TrimSpace(fields[1]), true
+		},
+		format: func(key, text string) string {
+			return key + " " + text
+		},
+	})
+}
+
+// readScheduleDescription reads the schedule's description from the team's,
+// only warning when it can't be as the rest of the schedule was read
+func readScheduleDescription(c *oncall.Client, d *schema.ResourceData, teamName, roster, role string) {
+	descriptions, err := getTeamAnnotations(c, teamName, scheduleDescriptionAnnotationPrefix)
+	if err != nil {
+		warnLog("Could not get the description of %s: %s", getScheduleID(teamName, roster, role), err)
+		return
+	}
+	d.Set(scheduleFieldDescription, descriptions[scheduleDescriptionKey(roster, role)])
+}
+
+// setScheduleDescription stores the schedule's description in the team's
+// description, under its role after removing the one under its old role. An
+// empty text removes it.
+func setScheduleDescription(c *oncall.Client, teamName, roster, oldRole, role, text string) error {
+	key := scheduleDescriptionKey(roster, role)
+	traceLog("Going to update the description of schedule %s of team %s", key, teamName)
+	return updateTeamAnnotations(c, teamName, scheduleDescriptionAnnotationPrefix, func(descriptions map[string]string) error {
+		delete(descriptions, scheduleDescriptionKey(roster, oldRole))
+		if text == "" {
+			delete(descriptions, key)
+		} else {
+			descriptions[key] = text
+		}
+		return nil
+	})
+}
+
+// updateScheduleDescription stores the description of a schedule resource
+// when it or the schedule's role was changed, or the schedule moved along
+// with its roster to another team
+func updateScheduleDescription(ctx context.Context, d *schema.ResourceData, m interface{}, teamName, roster string) diag.Diagnostics {
+	if !d.HasChange(scheduleFieldDescription) && !d.HasChange(scheduleFieldRole) && !d.HasChange(scheduleFieldRosterID) {
+		return nil
+	}
+	oldRole, role := d.GetChange(scheduleFieldRole)
+	if oldRole.(string) == "" {
+		oldRole = role
+	}
+
+	c := m.(*providerMeta).clientFor(ctx)
+
+	oldRosterID, _ := d.GetChange(scheduleFieldRosterID)
+	oldDescription, _ := d.GetChange(scheduleFieldDescription)
+	oldTeam, oldRoster, err := parseRosterID(oldRosterID.(string))
+	if err == nil && oldDescription.(string) != "" && getRosterID(oldTeam, oldRoster) != getRosterID(teamName, roster) {
+		err = setScheduleDescription(c, oldTeam, oldRoster, oldRole.(string), oldRole.(string), "")
+		if err != nil && !isNotFound(err) {
+			return diagFromErrf(err, "Removing description of schedule %s", getScheduleID(oldTeam, oldRoster, oldRole.(string)))
+		}
+	}
+
+	err = setScheduleDescription(c, teamName, roster, oldRole.(string), role.(string), strings.TrimSpace(d.Get(scheduleFieldDescription).(string)))
+	return diagFromErrf(err, "Setting description of schedule %s", getScheduleID(teamName, roster, role.(string)))
+}
+
+// removeScheduleDescription removes the description of a deleted schedule,
+// there is nothing to remove when the team was deleted too
+func removeScheduleDescription(ctx context.Context, d *schema.ResourceData, m interface{}, teamName, roster, role string) diag.Diagnostics {
+	if d.Get(scheduleFieldDescription).(string) == "" {
+		return nil
+	}
+
+	err := setScheduleDescription(m.(*providerMeta).clientFor(ctx), teamName, roster, role, role, "")
+	if isNotFound(err) {
+		return nil
+	}
+	return diagFromErrf(err, "Removing description of schedule %s", getScheduleID(teamName, roster, role))
+}
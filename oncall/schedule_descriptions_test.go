@@ -0,0 +1,120 @@
+package oncall
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_parseTeamDescriptionDescriptions(t *testing.T) {
+	tests := []struct {
+		name             string
+		description      string
+		wantText         string
+		wantDescriptions map[string]string
+	}{
+		{
+			name:             "No annotations",
+			description:      "Keeps the lights on",
+			wantText:         "Keeps the lights on",
+			wantDescriptions: map[string]string{},
+		},
+		{
+			name:        "Annotations after the description",
+			description: "Keeps the lights on\n\nschedule-description: platform/primary EU covers nights\nschedule-description: platform/secondary Weekends only",
+			wantText:    "Keeps the lights on",
+			wantDescriptions: map[string]string{
+				"platform/primary":   "EU covers nights",
+				"platform/secondary": "Weekends only",
+			},
+		},
+		{
+			name:             "Prose mentioning the prefix is left alone",
+			description:      "schedule-description: lines are managed by terraform",
+			wantText:         "schedule-description: lines are managed by terraform",
+			wantDescriptions: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseTeamDescription(tt.description)
+			if parsed.text != tt.wantText {
+				t.Errorf("parseTeamDescription() text = %q, want %q", parsed.text, tt.wantText)
+			}
+			if got := parsed.annotations[scheduleDescriptionAnnotationPrefix]; !reflect.DeepEqual(got, tt.wantDescriptions) {
+				t.Errorf("parseTeamDescription() descriptions = %v, want %v", got, tt.wantDescriptions)
+			}
+			if got := parsed.String(); got != tt.description {
+				t.Errorf("String() = %q, want %q", got, tt.description)
+			}
+		})
+	}
+}
+
+func Test_setScheduleDescription(t *testing.T) {
+	tests := []struct {
+		name            string
+		description     string
+		oldRole         string
+		role            string
+		text            string
+		wantDescription string
+	}{
+		{
+			name:            "Added after the team's text",
+			description:     "Keeps the lights on",
+			oldRole:         "primary",
+			role:            "primary",
+			text:            "EU covers nights",
+			wantDescription: "Keeps the lights on\n\nschedule-description: platform/primary EU covers nights",
+		},
+		{
+			name:            "Moved with the role",
+			description:     "Keeps the lights on\n\nschedule-description: platform/primary EU covers nights",
+			oldRole:         "primary",
+			role:            "secondary",
+			text:            "EU covers nights",
+			wantDescription: "Keeps the lights on\n\nschedule-description: platform/secondary EU covers nights",
+		},
+		{
+			name:            "Removed",
+			description:     "Keeps the lights on\n\nschedule-description: platform/primary EU covers nights",
+			oldRole:         "primary",
+			role:            "primary",
+			text:            "",
+			wantDescription: "Keeps the lights on",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			description := tt.description
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.Method {
+				case "GET":
+					json.NewEncoder(w).Encode(map[string]interface{}{"name": "team", "description": description})
+				case "PUT":
+					body := map[string]string{}
+					json.NewDecoder(r.Body).Decode(&body)
+					description = body["description"]
+				}
+			}))
+			defer server.Close()
+
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+			err = setScheduleDescription(c, "team", "platform", tt.oldRole, tt.role, tt.text)
+			if err != nil {
+				t.Fatalf("setScheduleDescription() error = %v", err)
+			}
+			if description != tt.wantDescription {
+				t.Errorf("setScheduleDescription() description = %q, want %q", description, tt.wantDescription)
+			}
+		})
+	}
+}
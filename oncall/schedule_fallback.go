@@ -0,0 +1,254 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// weekInterval is a [start, end) range of seconds within a single week
+type weekInterval struct {
+	start int
+	end   int
+}
+
+// scheduleFallback is a schedule split across two rosters: the fallback roster
+// covers the fallback windows and the primary roster covers everything else
+type scheduleFallback struct {
+	team           string
+	roster         string
+	primaryEvents  []oncall.ScheduleEvent
+	fallbackEvents []oncall.ScheduleEvent
+}
+
+// fallbackFromResource splits the schedule's events between its roster and the
+// fallback roster. It returns nil if no fallback roster is configured.
+func fallbackFromResource(d *schema.ResourceData, events []oncall.ScheduleEvent, weekStart time.Weekday) (*scheduleFallback, error) {
+	fallbackRosterID := d.Get(advancedScheduleFieldFallbackRosterID).(string)
+	if fallbackRosterID == "" {
+		return nil, nil
+	}
+
+	team, roster, err := parseRosterID(fallbackRosterID)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid fallback roster ID %q", fallbackRosterID)
+	}
+
+	windows := []map[string]interface{}{}
+	for _, windowRaw := range d.Get(advancedScheduleFieldFallbackWindow).([]interface{}) {
+		windows = append(windows, windowRaw.(map[string]interface{}))
+	}
+	windowEvents, err := shiftsToEvents(windows, weekStart)
+	if err != nil {
+		return nil, errors.Wrap(err, "Parsing fallback windows")
+	}
+
+	primary, fallback, err := splitEventsByWindows(events, windowEvents)
+	if err != nil {
+		return nil, err
+	}
+	if len(primary) == 0 {
+		return nil, errors.New("The fallback windows cover every shift, leaving nothing for roster_id")
+	}
+	if len(fallback) == 0 {
+		return nil, errors.New("The fallback windows do not overlap any shifts")
+	}
+	return &scheduleFallback{
+		team:           team,
+		roster:         roster,
+		primaryEvents:  primary,
+		fallbackEvents: fallback,
+	}, nil
+}
+
+// schedule is the schedule to put on the fallback roster for the given primary schedule
+func (f *scheduleFallback) schedule(primary oncall.Schedule) oncall.Schedule {
+	sched := primary
+	sched.Team = f.team
+	sched.Roster = f.roster
+	sched.Events = f.fallbackEvents
+	return sched
+}
+
+// readScheduleFallback checks that the schedule and its fallback schedule are
+// still split the way the resource expects, and clears the fallback roster from
+// state if its schedule has gone missing
+func readScheduleFallback(c *oncall.Client, d *schema.ResourceData, schedule oncall.Schedule, m interface{}) (bool, diag.Diagnostics) {
+	fallbackRosterID := d.Get(advancedScheduleFieldFallbackRosterID).(string)
+	if fallbackRosterID == "" {
+		return false, nil
+	}
+
+	expected, err := advancedScheduleFromResource(d, weekStartFor(m))
+	if err != nil {
+		return false, normalizationDiags(m, "Could not parse schedule %s to compare with its fallback: %s", d.Id(), err)
+	}
+	fallback, err := fallbackFromResource(d, expected.Events, weekStartFor(m))
+	if err != nil {
+		return false, normalizationDiags(m, "Could not split schedule %s with its fallback: %s", d.Id(), err)
+	}
+
+	fallbackSchedule, found, err := getRosterSchedule(c, fallback.team, fallback.roster, schedule.Role)
+	if err != nil {
+		return false, diagFromErrf(err, "Getting fallback schedule for %s from %s", d.Id(), fallbackRosterID)
+	}
+	if !found {
+		warnLog("Fallback schedule for %s no longer exists on %s", d.Id(), fallbackRosterID)
+		d.Set(advancedScheduleFieldFallbackRosterID, "")
+		return false, nil
+	}
+
+	if !sameEvents(schedule.Events, fallback.primaryEvents) || !sameEvents(fallbackSchedule.Events, fallback.fallbackEvents) {
+		warnLog("Schedule %s and its fallback on %s no longer match the configured shifts", d.Id(), fallbackRosterID)
+		return false, nil
+	}
+	return true, nil
+}
+
+// updateScheduleFallback moves, updates, or creates the fallback schedule to match the resource
+func updateScheduleFallback(ctx context.Context, c *oncall.Client, d *schema.ResourceData, primary oncall.Schedule, fallback *scheduleFallback) error {
+	oldRaw, newRaw := d.GetChange(advancedScheduleFieldFallbackRosterID)
+	oldRosterID, newRosterID := oldRaw.(string), newRaw.(string)
+
+	if oldRosterID != "" && oldRosterID != newRosterID {
+		err := removeScheduleFallback(ctx, c, oldRosterID, primary.Role)
+		if err != nil {
+			return err
+		}
+	}
+	if fallback == nil {
+		return nil
+	}
+
+	if oldRosterID == newRosterID {
+		existing, found, err := getRosterSchedule(c, fallback.team, fallback.roster, primary.Role)
+		if err != nil {
+			return err
+		}
+		if found {
+			traceLog("Going to update fallback roster schedule: %s/%s/%s", fallback.team, fallback.roster, primary.Role)
+			return updateRosterSchedule(c, existing.ID, fallback.team, fallback.roster, primary.Role, fallback.schedule(primary))
+		}
+	}
+
+	traceLog("Going to create fallback roster schedule: %s/%s/%s", fallback.team, fallback.roster, primary.Role)
+	return c.AddRosterSchedule(fallback.team, fallback.roster, fallback.schedule(primary))
+}
+
+// removeScheduleFallback removes the fallback schedule for the role, if there is one
+func removeScheduleFallback(ctx context.Context, c *oncall.Client, fallbackRosterID, role string) error {
+	if fallbackRosterID == "" {
+		return nil
+	}
+
+	team, roster, err := parseRosterID(fallbackRosterID)
+	if err != nil {
+		return errors.Wrapf(err, "Invalid fallback roster ID %q", fallbackRosterID)
+	}
+
+	traceLog("Going to delete fallback roster schedule %s/%s/%s", team, roster, role)
+	return removeRosterSchedule(ctx, c, 0, team, roster, role)
+}
+
+// splitEventsByWindows cuts the fallback windows out of every event, returning
+// the events left outside of the windows and the pieces that fell inside of them
+func splitEventsByWindows(events, windows []oncall.ScheduleEvent) (outside, inside []oncall.ScheduleEvent, err error) {
+	for _, e := range append(append([]oncall.ScheduleEvent{}, events...), windows...) {
+		if e.Start < 0 || e.Start >= weekSeconds || e.Duration > weekSeconds {
+			return nil, nil, fmt.Errorf("Fallback rosters only support shifts and windows that fit in a single week")
+		}
+	}
+
+	windowIntervals := []weekInterval{}
+	for _, w := range windows {
+		windowIntervals = append(windowIntervals, eventToIntervals(w)...)
+	}
+
+	outside = []oncall.ScheduleEvent{}
+	inside = []oncall.ScheduleEvent{}
+	for _, e := range events {
+		eventIntervals := eventToIntervals(e)
+		outside = append(outside, intervalsToEvents(subtractIntervals(eventIntervals, windowIntervals))...)
+		inside = append(inside, intervalsToEvents(intersectIntervals(eventIntervals, windowIntervals))...)
+	}
+	return outside, inside, nil
+}
+
+// eventToIntervals splits an event that wraps past the end of the week in two
+func eventToIntervals(e oncall.ScheduleEvent) []weekInterval {
+	end := e.Start + e.Duration
+	if end <= weekSeconds {
+		return []weekInterval{{e.Start, end}}
+	}
+	return []weekInterval{{e.Start, weekSeconds}, {0, end - weekSeconds}}
+}
+
+// intervalsToEvents turns the intervals cut from a single event back into
+// events, rejoining the pieces on either side of the end of the week
+func intervalsToEvents(intervals []weekInterval) []oncall.ScheduleEvent {
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].start < intervals[j].start })
+
+	if len(intervals) > 1 && intervals[0].start == 0 && intervals[len(intervals)-1].end == weekSeconds {
+		last := intervals[len(intervals)-1]
+		intervals = append([]weekInterval{{last.start, weekSeconds + intervals[0].end}}, intervals[1:len(intervals)-1]...)
+	}
+
+	events := make([]oncall.ScheduleEvent, 0, len(intervals))
+	for _, i := range intervals {
+		events = append(events, oncall.ScheduleEvent{
+			Start:    i.start,
+			Duration: i.end - i.start,
+		})
+	}
+	return events
+}
+
+func subtractIntervals(from, remove []weekInterval) []weekInterval {
+	remaining := from
+	for _, r := range remove {
+		next := []weekInterval{}
+		for _, i := range remaining {
+			if r.end <= i.start || r.start >= i.end {
+				next = append(next, i)
+				continue
+			}
+			if r.start > i.start {
+				next = append(next, weekInterval{i.start, r.start})
+			}
+			if r.end < i.end {
+				next = append(next, weekInterval{r.end, i.end})
+			}
+		}
+		remaining = next
+	}
+	return remaining
+}
+
+func intersectIntervals(a, b []weekInterval) []weekInterval {
+	// Subtracting what is left over after removing b gives the overlap without
+	// double counting any windows which overlap each other
+	return subtractIntervals(a, subtractIntervals(a, b))
+}
+
+// sameEvents compares two sets of events regardless of order
+func sameEvents(a, b []oncall.ScheduleEvent) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	sortedA := append([]oncall.ScheduleEvent{}, a...)
+	sortedB := append([]oncall.ScheduleEvent{}, b...)
+	sort.Slice(sortedA, func(i, j int) bool { return sortedA[i].Start < sortedA[j].Start })
+	sort.Slice(sortedB, func(i, j int) bool { return sortedB[i].Start < sortedB[j].Start })
+	for i := range sortedA {
+		if sortedA[i] != sortedB[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package oncall
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"maze.io/x/duration"
+)
+
+func Test_splitEventsByWindows(t *testing.T) {
+	day := int(duration.Day.Seconds())
+	hour := int(duration.Hour.Seconds())
+	tests := []struct {
+		name        string
+		events      []oncall.ScheduleEvent
+		windows     []oncall.ScheduleEvent
+		wantOutside []oncall.ScheduleEvent
+		wantInside  []oncall.ScheduleEvent
+		wantErr     bool
+	}{
+		{
+			name:        "Window outside of the shift",
+			events:      []oncall.ScheduleEvent{{Start: day, Duration: 8 * hour}},
+			windows:     []oncall.ScheduleEvent{{Start: 2 * day, Duration: 8 * hour}},
+			wantOutside: []oncall.ScheduleEvent{{Start: day, Duration: 8 * hour}},
+			wantInside:  []oncall.ScheduleEvent{},
+		},
+		{
+			name:        "Window in the middle of a 24/7 week",
+			events:      []oncall.ScheduleEvent{{Start: day, Duration: weekSeconds}},
+			windows:     []oncall.ScheduleEvent{{Start: 3 * day, Duration: day}},
+			wantOutside: []oncall.ScheduleEvent{{Start: day, Duration: 2 * day}, {Start: 4 * day, Duration: 4 * day}},
+			wantInside:  []oncall.ScheduleEvent{{Start: 3 * day, Duration: day}},
+		},
+		{
+			name:        "Window over the end of the week",
+			events:      []oncall.ScheduleEvent{{Start: 5 * day, Duration: 3 * day}},
+			windows:     []oncall.ScheduleEvent{{Start: 6 * day, Duration: day}},
+			wantOutside: []oncall.ScheduleEvent{{Start: 5 * day, Duration: day}, {Start: 0, Duration: day}},
+			wantInside:  []oncall.ScheduleEvent{{Start: 6 * day, Duration: day}},
+		},
+		{
+			name:        "Overlapping windows",
+			events:      []oncall.ScheduleEvent{{Start: day, Duration: day}},
+			windows:     []oncall.ScheduleEvent{{Start: day, Duration: 12 * hour}, {Start: day + 6*hour, Duration: 12 * hour}},
+			wantOutside: []oncall.ScheduleEvent{{Start: day + 18*hour, Duration: 6 * hour}},
+			wantInside:  []oncall.ScheduleEvent{{Start: day, Duration: 18 * hour}},
+		},
+		{
+			name:    "Shift longer than a week",
+			events:  []oncall.ScheduleEvent{{Start: 0, Duration: 2 * weekSeconds}},
+			windows: []oncall.ScheduleEvent{{Start: day, Duration: day}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotOutside, gotInside, err := splitEventsByWindows(tt.events, tt.windows)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitEventsByWindows() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !sameEvents(gotOutside, tt.wantOutside) {
+				t.Errorf("splitEventsByWindows() outside = %v, want %v", gotOutside, tt.wantOutside)
+			}
+			if !reflect.DeepEqual(gotInside, tt.wantInside) {
+				t.Errorf("splitEventsByWindows() inside = %v, want %v", gotInside, tt.wantInside)
+			}
+		})
+	}
+}
+
+func Test_readScheduleFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v0/teams/team/rosters/backup/schedules":
+			w.Write([]byte(`[{"id": 8, "role": "primary", "advanced_mode": 1, "events": [{"start": 518400, "duration": 86400}]}]`))
+		case "/api/v0/teams/team/rosters/empty/schedules":
+			w.Write([]byte(`[]`))
+		case "/api/v0/teams/team/rosters/broken/schedules":
+			w.WriteHeader(500)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	primary := oncall.Schedule{Role: "primary", Events: []oncall.ScheduleEvent{{Start: 0, Duration: 518400}}}
+
+	tests := []struct {
+		name             string
+		fallbackRosterID string
+		wantMatches      bool
+		wantFallback     string
+		wantErr          bool
+	}{
+		{name: "Matches", fallbackRosterID: "team/backup", wantMatches: true, wantFallback: "team/backup"},
+		{name: "Fallback schedule deleted", fallbackRosterID: "team/empty"},
+		{name: "Fallback roster deleted", fallbackRosterID: "team/gone"},
+		{name: "Server error keeps the fallback", fallbackRosterID: "team/broken", wantFallback: "team/broken", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, resourceAdvancedSchedule().Schema, map[string]interface{}{
+				"roster_id": "team/roster", "role": "primary", "fallback_roster_id": tt.fallbackRosterID,
+				"shift": []interface{}{
+					map[string]interface{}{"start_day_of_week": "sunday", "start_time": "00:00", "duration": "168h"},
+				},
+				"fallback_window": []interface{}{
+					map[string]interface{}{"start_day_of_week": "saturday", "start_time": "00:00", "duration": "24h"},
+				},
+			})
+			d.SetId("team/roster/primary")
+
+			matches, diags := readScheduleFallback(c, d, primary, nil)
+			if diags.HasError() != tt.wantErr {
+				t.Fatalf("readScheduleFallback() = %v, wantErr %v", diags, tt.wantErr)
+			}
+			if matches != tt.wantMatches {
+				t.Errorf("readScheduleFallback() matches = %v, want %v", matches, tt.wantMatches)
+			}
+			if got := d.Get(advancedScheduleFieldFallbackRosterID).(string); got != tt.wantFallback {
+				t.Errorf("readScheduleFallback() left %s = %q, want %q", advancedScheduleFieldFallbackRosterID, got, tt.wantFallback)
+			}
+		})
+	}
+}
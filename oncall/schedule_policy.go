@@ -0,0 +1,189 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	selfEscalationCheckOff   = "off"
+	selfEscalationCheckWarn  = "warn"
+	selfEscalationCheckError = "error"
+)
+
+var selfEscalationChecks = []string{
+	selfEscalationCheckOff,
+	selfEscalationCheckWarn,
+	selfEscalationCheckError,
+}
+
+// escalatesTo maps each role to the role it escalates to, and back
+var escalatesTo = map[string]string{
+	"primary":   "secondary",
+	"secondary": "primary",
+}
+
+// scheduleRegistry remembers which roster/role pairs have been planned by this
+// provider so checks can take schedules elsewhere in the configuration into account
+type scheduleRegistry struct {
+	mu        sync.Mutex
+	schedules map[string]map[string]bool
+}
+
+func newScheduleRegistry() *scheduleRegistry {
+	return &scheduleRegistry{
+		schedules: map[string]map[string]bool{},
+	}
+}
+
+func (r *scheduleRegistry) add(rosterID, role string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.schedules[rosterID] == nil {
+		r.schedules[rosterID] = map[string]bool{}
+	}
+	r.schedules[rosterID][role] = true
+}
+
+func (r *scheduleRegistry) has(rosterID, role string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.schedules[rosterID][role]
+}
+
+// rostersWithRole lists the other rosters of the team which have been planned
+// with a schedule for the role
+func (r *scheduleRegistry) rostersWithRole(rosterID, role string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	team := strings.SplitN(rosterID, "/", 2)[0]
+	rosters := []string{}
+	for otherRosterID, roles := range r.schedules {
+		if otherRosterID != rosterID && strings.HasPrefix(otherRosterID, team+"/") && roles[role] {
+			rosters = append(rosters, otherRosterID)
+		}
+	}
+	sort.Strings(rosters)
+	return rosters
+}
+
+// scheduleOverlapCustomizeDiff warns when the same role of a team is scheduled
+// from more than one roster, as oncall puts everyone from every roster on call
+// for it. Like the self escalation check, it sees the schedules planned before
+// it and the ones which already exist. Terraform can't show warnings from a plan
+// so it is logged.
+func scheduleOverlapCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	meta := m.(*providerMeta)
+	if !d.NewValueKnown(scheduleFieldRosterID) || !d.NewValueKnown(scheduleFieldRole) {
+		return nil
+	}
+
+	role := d.Get(scheduleFieldRole).(string)
+	rosterID := d.Get(scheduleFieldRosterID).(string)
+	teamName, rosterName, err := parseRosterID(rosterID)
+	if err != nil {
+		return nil
+	}
+	meta.plannedSchedules.add(rosterID, role)
+
+	overlapping := meta.plannedSchedules.rostersWithRole(rosterID, role)
+
+	// Only look for existing schedules when this one is new or moving, rather
+	// than listing every roster of the team on every plan
+	rosters := []string{}
+	c := meta.clientFor(ctx)
+	if d.Id() == "" || d.HasChange(scheduleFieldRosterID) || d.HasChange(scheduleFieldRole) {
+		rosters, err = c.GetRosters(teamName)
+		if err != nil {
+			// The team is likely being created in this same plan
+			debugLog("Not checking %s for overlapping %s schedules: %s", teamName, role, err)
+			rosters = nil
+		}
+	}
+	for _, otherRoster := range rosters {
+		otherRosterID := getRosterID(teamName, otherRoster)
+		if otherRoster == rosterName || stringSliceContains(overlapping, otherRosterID) {
+			continue
+		}
+		_, found, err := getRosterSchedule(c, teamName, otherRoster, role)
+		if err != nil {
+			debugLog("Not checking %s for overlapping %s schedules: %s", otherRosterID, role, err)
+			continue
+		}
+		if found {
+			overlapping = append(overlapping, otherRosterID)
+		}
+	}
+
+	if len(overlapping) > 0 {
+		sort.Strings(overlapping)
+		warnLog("The %s role of team %s is scheduled from roster %s as well as %s, oncall will put members of all of them on call for it", role, teamName, rosterID, strings.Join(overlapping, ", "))
+	}
+	return nil
+}
+
+// scheduleSelfEscalationCustomizeDiff flags schedules where the same roster backs
+// both primary and secondary but only has one member in rotation, so whoever is
+// primary would escalate to themselves. It only catches the pair on the second
+// of the two schedules to be planned, or when the other one already exists.
+func scheduleSelfEscalationCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	meta := m.(*providerMeta)
+	if meta.selfEscalationCheck == selfEscalationCheckOff {
+		return nil
+	}
+
+	role := d.Get(scheduleFieldRole).(string)
+	otherRole, ok := escalatesTo[role]
+	if !ok || !d.NewValueKnown(scheduleFieldRosterID) {
+		return nil
+	}
+
+	rosterID := d.Get(scheduleFieldRosterID).(string)
+	teamName, rosterName, err := parseRosterID(rosterID)
+	if err != nil {
+		return nil
+	}
+	meta.plannedSchedules.add(rosterID, role)
+	c := meta.clientFor(ctx)
+
+	otherScheduled := meta.plannedSchedules.has(rosterID, otherRole)
+	if !otherScheduled {
+		schedules, err := getRosterSchedules(c, teamName, rosterName)
+		if err != nil {
+			// The roster is likely being created in this same plan
+			debugLog("Not checking %s for self escalation: %s", rosterID, err)
+			return nil
+		}
+		for _, s := range schedules {
+			if strings.EqualFold(s.Role, otherRole) {
+				otherScheduled = true
+			}
+		}
+	}
+	if !otherScheduled {
+		return nil
+	}
+
+	inRotation, err := getRosterInRotationUsers(c, teamName, rosterName)
+	if err != nil {
+		debugLog("Not checking %s for self escalation: %s", rosterID, err)
+		return nil
+	}
+	if len(inRotation) > 1 {
+		return nil
+	}
+
+	msg := fmt.Sprintf("Roster %s backs both the %s and %s schedules but has %d member(s) in rotation, so %s would escalate to themselves", rosterID, role, otherRole, len(inRotation), role)
+	if meta.selfEscalationCheck == selfEscalationCheckWarn {
+		warnLog("%s", msg)
+		return nil
+	}
+	return errors.New(msg)
+}
@@ -0,0 +1,47 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_scheduleRegistry_rostersWithRole(t *testing.T) {
+	r := newScheduleRegistry()
+	r.add("team/day", "primary")
+	r.add("team/night", "primary")
+	r.add("team/backup", "secondary")
+	r.add("team-two/day", "primary")
+
+	tests := []struct {
+		name     string
+		rosterID string
+		role     string
+		want     []string
+	}{
+		{
+			name:     "Other rosters of the team with the role",
+			rosterID: "team/day",
+			role:     "primary",
+			want:     []string{"team/night"},
+		},
+		{
+			name:     "Role only on this roster",
+			rosterID: "team/backup",
+			role:     "secondary",
+			want:     []string{},
+		},
+		{
+			name:     "Rosters of teams sharing a prefix are ignored",
+			rosterID: "team-two/night",
+			role:     "primary",
+			want:     []string{"team-two/day"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := r.rostersWithRole(tt.rosterID, tt.role); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("rostersWithRole() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package oncall
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// schemaSnapshot is the parts of the provider's schemas that existing
+// configurations and state depend on, so changes to them can be reviewed
+type schemaSnapshot struct {
+	Provider    blockSnapshot            `json:"provider"`
+	Resources   map[string]blockSnapshot `json:"resources"`
+	DataSources map[string]blockSnapshot `json:"data_sources"`
+}
+
+type blockSnapshot struct {
+	SchemaVersion int                          `json:"schema_version,omitempty"`
+	Attributes    map[string]attributeSnapshot `json:"attributes"`
+}
+
+type attributeSnapshot struct {
+	Type      string      `json:"type"`
+	Required  bool        `json:"required,omitempty"`
+	Optional  bool        `json:"optional,omitempty"`
+	Computed  bool        `json:"computed,omitempty"`
+	ForceNew  bool        `json:"force_new,omitempty"`
+	Sensitive bool        `json:"sensitive,omitempty"`
+	Default   interface{} `json:"default,omitempty"`
+	MinItems  int         `json:"min_items,omitempty"`
+	MaxItems  int         `json:"max_items,omitempty"`
+
+	// Only one of Elem, for collections of primitives, and Block, for nested
+	// blocks, is set
+	Elem  *attributeSnapshot `json:"elem,omitempty"`
+	Block *blockSnapshot     `json:"block,omitempty"`
+}
+
+func snapshotProvider(p *schema.Provider) schemaSnapshot {
+	snap := schemaSnapshot{
+		Provider:    snapshotBlock(p.Schema, 0),
+		Resources:   map[string]blockSnapshot{},
+		DataSources: map[string]blockSnapshot{},
+	}
+	for name, r := range p.ResourcesMap {
+		snap.Resources[name] = snapshotBlock(r.Schema, r.SchemaVersion)
+	}
+	for name, r := range p.DataSourcesMap {
+		snap.DataSources[name] = snapshotBlock(r.Schema, r.SchemaVersion)
+	}
+	return snap
+}
+
+func snapshotBlock(s map[string]*schema.Schema, version int) blockSnapshot {
+	block := blockSnapshot{
+		SchemaVersion: version,
+		Attributes:    map[string]attributeSnapshot{},
+	}
+	for name, attr := range s {
+		block.Attributes[name] = snapshotAttribute(attr)
+	}
+	return block
+}
+
+func snapshotAttribute(s *schema.Schema) attributeSnapshot {
+	attr := attributeSnapshot{
+		Type:      s.Type.String(),
+		Required:  s.Required,
+		Optional:  s.Optional,
+		Computed:  s.Computed,
+		ForceNew:  s.ForceNew,
+		Sensitive: s.Sensitive,
+		Default:   s.Default,
+		MinItems:  s.MinItems,
+		MaxItems:  s.MaxItems,
+	}
+	switch elem := s.Elem.(type) {
+	case *schema.Schema:
+		e := snapshotAttribute(elem)
+		attr.Elem = &e
+	case *schema.Resource:
+		b := snapshotBlock(elem.Schema, 0)
+		attr.Block = &b
+	}
+	return attr
+}
+
+// breakingSchemaChanges lists the changes from old to new that would break
+// existing configurations, or state written by an earlier version, sorted
+func breakingSchemaChanges(old, new schemaSnapshot) []string {
+	changes := breakingBlockChanges("provider", old.Provider, new.Provider)
+	for name, o := range old.Resources {
+		n, ok := new.Resources[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("resource %s: removed", name))
+			continue
+		}
+		if n.SchemaVersion > o.SchemaVersion {
+			// A state upgrader handles the change
+			continue
+		}
+		changes = append(changes, breakingBlockChanges("resource "+name, o, n)...)
+	}
+	for name, o := range old.DataSources {
+		n, ok := new.DataSources[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("data source %s: removed", name))
+			continue
+		}
+		changes = append(changes, breakingBlockChanges("data source "+name, o, n)...)
+	}
+	sort.Strings(changes)
+	return changes
+}
+
+func breakingBlockChanges(path string, old, new blockSnapshot) []string {
+	changes := []string{}
+	for name, o := range old.Attributes {
+		n, ok := new.Attributes[name]
+		if !ok {
+			changes = append(changes, fmt.Sprintf("%s.%s: removed", path, name))
+			continue
+		}
+		changes = append(changes, breakingAttributeChanges(path+"."+name, o, n)...)
+	}
+	for name, n := range new.Attributes {
+		if _, ok := old.Attributes[name]; !ok && n.Required {
+			changes = append(changes, fmt.Sprintf("%s.%s: added as required", path, name))
+		}
+	}
+	return changes
+}
+
+func breakingAttributeChanges(path string, old, new attributeSnapshot) []string {
+	if old.Type != new.Type {
+		return []string{fmt.Sprintf("%s: type changed from %s to %s", path, old.Type, new.Type)}
+	}
+
+	changes := []string{}
+	if !old.Required && new.Required {
+		changes = append(changes, fmt.Sprintf("%s: now required", path))
+	}
+	if old.Computed && !new.Computed && !new.Optional {
+		changes = append(changes, fmt.Sprintf("%s: no longer computed", path))
+	}
+	if !old.ForceNew && new.ForceNew {
+		changes = append(changes, fmt.Sprintf("%s: now forces replacement", path))
+	}
+	if !reflect.DeepEqual(old.Default, new.Default) {
+		changes = append(changes, fmt.Sprintf("%s: default changed from %v to %v", path, old.Default, new.Default))
+	}
+	if new.MinItems > old.MinItems || (new.MaxItems != 0 && (old.MaxItems == 0 || new.MaxItems < old.MaxItems)) {
+		changes = append(changes, fmt.Sprintf("%s: item limits narrowed from %d..%d to %d..%d", path, old.MinItems, old.MaxItems, new.MinItems, new.MaxItems))
+	}
+
+	switch {
+	case old.Elem != nil && new.Elem != nil:
+		changes = append(changes, breakingAttributeChanges(path+".*", *old.Elem, *new.Elem)...)
+	case old.Block != nil && new.Block != nil:
+		changes = append(changes, breakingBlockChanges(path, *old.Block, *new.Block)...)
+	case (old.Elem == nil) != (new.Elem == nil) || (old.Block == nil) != (new.Block == nil):
+		changes = append(changes, fmt.Sprintf("%s: element changed between a value and a block", path))
+	}
+	return changes
+}
@@ -0,0 +1,143 @@
+package oncall
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const schemaSnapshotFile = "testdata/schema_snapshot.json"
+
+var (
+	updateSchemaSnapshot = flag.Bool("update-schema-snapshot", false, "Rewrite "+schemaSnapshotFile+" with the current schemas")
+	acceptBreaking       = flag.Bool("accept-breaking-schema-changes", false, "Rewrite the schema snapshot even when the changes are breaking")
+)
+
+// Test_schemaSnapshot fails when the schemas no longer match the snapshot, so
+// every schema change is reviewed, and refuses breaking changes to resources
+// unless their SchemaVersion is bumped. Rewrite the snapshot with
+//
+//	go test ./oncall -run Test_schemaSnapshot -update-schema-snapshot
+func Test_schemaSnapshot(t *testing.T) {
+	encoded, err := json.MarshalIndent(snapshotProvider(Provider()), "", "  ")
+	if err != nil {
+		t.Fatalf("Encoding schema snapshot: %v", err)
+	}
+	encoded = append(encoded, '\n')
+	// Decode what is written, so defaults compare as they are read back
+	current := schemaSnapshot{}
+	if err := json.Unmarshal(encoded, &current); err != nil {
+		t.Fatalf("Decoding schema snapshot: %v", err)
+	}
+
+	previous := schemaSnapshot{}
+	raw, err := ioutil.ReadFile(schemaSnapshotFile)
+	if err == nil {
+		err = json.Unmarshal(raw, &previous)
+	}
+	if err != nil && !*updateSchemaSnapshot {
+		t.Fatalf("Reading %s: %v", schemaSnapshotFile, err)
+	}
+
+	if err == nil {
+		if breaking := breakingSchemaChanges(previous, current); len(breaking) > 0 && !*acceptBreaking {
+			t.Fatalf("Schema changes would break existing configurations or state:\n  %s\n\n"+
+				"Bump the SchemaVersion of the resource and add a StateUpgrader, or pass -accept-breaking-schema-changes along with -update-schema-snapshot for a major release",
+				strings.Join(breaking, "\n  "))
+		}
+	}
+
+	if *updateSchemaSnapshot {
+		if err := ioutil.WriteFile(schemaSnapshotFile, encoded, 0644); err != nil {
+			t.Fatalf("Writing %s: %v", schemaSnapshotFile, err)
+		}
+		return
+	}
+	if !reflect.DeepEqual(previous, current) {
+		t.Errorf("Schemas have changed since %s was written, review the changes and rewrite it with -update-schema-snapshot", schemaSnapshotFile)
+	}
+}
+
+func Test_breakingSchemaChanges(t *testing.T) {
+	resource := func(version int, attrs map[string]attributeSnapshot) schemaSnapshot {
+		return schemaSnapshot{
+			Resources: map[string]blockSnapshot{
+				"oncall_thing": {SchemaVersion: version, Attributes: attrs},
+			},
+		}
+	}
+	name := attributeSnapshot{Type: "TypeString", Required: true, ForceNew: true}
+	members := attributeSnapshot{Type: "TypeSet", Optional: true, Elem: &attributeSnapshot{Type: "TypeString"}}
+
+	tests := []struct {
+		name string
+		old  schemaSnapshot
+		new  schemaSnapshot
+		want []string
+	}{
+		{
+			name: "Unchanged",
+			old:  resource(0, map[string]attributeSnapshot{"name": name}),
+			new:  resource(0, map[string]attributeSnapshot{"name": name}),
+			want: []string{},
+		},
+		{
+			name: "Optional attribute added",
+			old:  resource(0, map[string]attributeSnapshot{"name": name}),
+			new:  resource(0, map[string]attributeSnapshot{"name": name, "members": members}),
+			want: []string{},
+		},
+		{
+			name: "Required attribute added",
+			old:  resource(0, map[string]attributeSnapshot{"members": members}),
+			new:  resource(0, map[string]attributeSnapshot{"name": name, "members": members}),
+			want: []string{"resource oncall_thing.name: added as required"},
+		},
+		{
+			name: "Attribute removed",
+			old:  resource(0, map[string]attributeSnapshot{"name": name, "members": members}),
+			new:  resource(0, map[string]attributeSnapshot{"name": name}),
+			want: []string{"resource oncall_thing.members: removed"},
+		},
+		{
+			name: "Type and default changed",
+			old:  resource(0, map[string]attributeSnapshot{"count": {Type: "TypeInt", Optional: true, Default: float64(1)}}),
+			new:  resource(0, map[string]attributeSnapshot{"count": {Type: "TypeInt", Optional: true, Default: float64(2)}, "name": {Type: "TypeList", Optional: true}}),
+			want: []string{"resource oncall_thing.count: default changed from 1 to 2"},
+		},
+		{
+			name: "Now forces replacement",
+			old:  resource(0, map[string]attributeSnapshot{"members": members}),
+			new:  resource(0, map[string]attributeSnapshot{"members": {Type: "TypeSet", Optional: true, ForceNew: true, Elem: &attributeSnapshot{Type: "TypeString"}}}),
+			want: []string{"resource oncall_thing.members: now forces replacement"},
+		},
+		{
+			name: "Element type changed",
+			old:  resource(0, map[string]attributeSnapshot{"members": members}),
+			new:  resource(0, map[string]attributeSnapshot{"members": {Type: "TypeSet", Optional: true, Elem: &attributeSnapshot{Type: "TypeInt"}}}),
+			want: []string{"resource oncall_thing.members.*: type changed from TypeString to TypeInt"},
+		},
+		{
+			name: "Breaking change with a schema version bump",
+			old:  resource(0, map[string]attributeSnapshot{"name": name, "members": members}),
+			new:  resource(1, map[string]attributeSnapshot{"name": name}),
+			want: []string{},
+		},
+		{
+			name: "Resource removed",
+			old:  resource(0, map[string]attributeSnapshot{"name": name}),
+			new:  schemaSnapshot{},
+			want: []string{"resource oncall_thing: removed"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := breakingSchemaChanges(tt.old, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("breakingSchemaChanges() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package oncall
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// authSession shares a single login to oncall between every resource of a run.
+// The oncall client logs in lazily on the first request, so resources read in
+// parallel each log in, and on a 401 every request that got one logs in again,
+// each login replacing the session the others just got. Logging in once when
+// the provider is configured, and only again when a request made with the
+// current session is turned away, keeps it to one login per expired session.
+type authSession struct {
+	mu   sync.Mutex
+	auth oncall.AuthRoundtripper
+	// generation counts logins, so a request refused with a session that has
+	// since been replaced retries with the new one instead of logging in again
+	generation int
+}
+
+// newAuthSession returns the session of a user auth client, nil for API auth
+// or no auth, which sign each request instead of logging in
+func newAuthSession(c *oncall.Client) *authSession {
+	if c.Config.AuthMethod != oncall.AuthMethodUser || c.Config.Password == "" {
+		return nil
+	}
+	auth, ok := c.Client.Transport.(oncall.AuthRoundtripper)
+	if !ok {
+		return nil
+	}
+	return &authSession{auth: auth}
+}
+
+// login logs in, replacing the current session
+func (s *authSession) login() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loginLocked()
+}
+
+func (s *authSession) loginLocked() error {
+	traceLog("Going to log into oncall, session %d", s.generation+1)
+	err := s.auth.Login()
+	if err != nil {
+		return errors.Wrap(err, "Logging into oncall")
+	}
+	s.generation++
+	return nil
+}
+
+// current is the generation of the session requests are being made with
+func (s *authSession) current() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generation
+}
+
+// refresh logs in again after a request made with the given session was
+// refused, unless another request has already done so
+func (s *authSession) refresh(refused int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.generation != refused {
+		debugLog("Oncall session %d has already replaced session %d", s.generation, refused)
+		return nil
+	}
+	debugLog("Oncall session %d expired, logging in again", refused)
+	return s.loginLocked()
+}
+
+// sessionRoundTripper sends requests through the provider's login session
+type sessionRoundTripper struct {
+	session *authSession
+	proxied http.RoundTripper
+}
+
+func (srt sessionRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return srt.session.roundTrip(srt.proxied, req)
+}
+
+// roundTrip sends the request through the session, logging in again and
+// retrying once if oncall says the session has expired
+func (s *authSession) roundTrip(proxied http.RoundTripper, req *http.Request) (*http.Response, error) {
+	// The oncall client's auth adds its cookies and token to the request
+	// itself, so a retry starts again from the headers as they were
+	header := req.Header.Clone()
+	generation := s.current()
+	resp, err := proxied.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.GetBody == nil {
+		// There's no body to send again, let the oncall client handle it
+		return resp, err
+	}
+
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if err := s.refresh(generation); err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	retry.Header = header
+	if req.GetBody != nil {
+		retry.Body, err = req.GetBody()
+		if err != nil {
+			return nil, errors.Wrap(err, "Rewinding request body to retry it after logging in")
+		}
+	}
+	return proxied.RoundTrip(retry)
+}
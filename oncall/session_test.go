@@ -0,0 +1,129 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_authSession(t *testing.T) {
+	var mu sync.Mutex
+	logins := 0
+	session := ""
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.URL.Path == "/login" {
+			logins++
+			session = fmt.Sprintf("session-%d", logins)
+			http.SetCookie(w, &http.Cookie{Name: "oncall-auth", Value: session})
+			fmt.Fprintf(w, `{"csrf_token": "token-%d"}`, logins)
+			return
+		}
+		cookie, err := r.Cookie("oncall-auth")
+		if err != nil || cookie.Value != session || r.Header.Get("X-CSRF-TOKEN") != fmt.Sprintf("token-%d", logins) {
+			w.WriteHeader(401)
+			return
+		}
+		if r.Method == http.MethodPost {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, Username: "terraform", Password: "secret", AuthMethod: oncall.AuthMethodUser}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c, session: newAuthSession(c)}
+	if meta.session == nil {
+		t.Fatalf("newAuthSession() = nil for user auth")
+	}
+	if err := meta.session.login(); err != nil {
+		t.Fatalf("login() error = %v", err)
+	}
+
+	getInParallel := func(n int) {
+		var wg sync.WaitGroup
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				teams := []string{}
+				_, err := meta.clientFor(context.Background()).Get("/api/v0/teams", &teams)
+				if err != nil {
+					t.Errorf("Get() error = %v", err)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	getInParallel(10)
+	if logins != 1 {
+		t.Errorf("logged in %d times for one session, want 1", logins)
+	}
+
+	// Oncall expires the session
+	mu.Lock()
+	session = "expired"
+	mu.Unlock()
+	getInParallel(10)
+	if logins != 2 {
+		t.Errorf("logged in %d times after the session expired, want 2", logins)
+	}
+
+	mu.Lock()
+	session = "expired"
+	mu.Unlock()
+	_, err = meta.clientFor(context.Background()).Post("/api/v0/teams", map[string]string{"name": "team"}, nil)
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	if len(bodies) != 1 || bodies[0] != `{"name":"team"}` {
+		t.Errorf("Post() sent bodies %v after logging in again, want the request's body once", bodies)
+	}
+}
+
+func Test_newAuthSession(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   oncall.Config
+		wantNone bool
+	}{
+		{
+			name:   "User auth",
+			config: oncall.Config{Endpoint: "http://oncall", Username: "terraform", Password: "secret", AuthMethod: oncall.AuthMethodUser},
+		},
+		{
+			name:     "API auth",
+			config:   oncall.Config{Endpoint: "http://oncall", Username: "terraform", Password: "key", AuthMethod: oncall.AuthMethodAPI},
+			wantNone: true,
+		},
+		{
+			name:     "No credentials",
+			config:   oncall.Config{Endpoint: "http://oncall", AuthMethod: oncall.AuthMethodUser},
+			wantNone: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := oncall.New(&http.Client{}, tt.config, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+			if got := newAuthSession(c); (got == nil) != tt.wantNone {
+				t.Errorf("newAuthSession() = %v, wantNone %v", got, tt.wantNone)
+			}
+		})
+	}
+}
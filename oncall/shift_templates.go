@@ -0,0 +1,115 @@
+package oncall
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	shiftTemplateFieldName  = "name"
+	shiftTemplateFieldShift = "shift"
+)
+
+// shiftTemplateFileShift is a shift in a shift templates file, which has the
+// same fields as a shift block
+type shiftTemplateFileShift struct {
+	StartDayOfWeek     string `yaml:"start_day_of_week"`
+	StartTime          string `yaml:"start_time"`
+	StartOffsetSeconds int    `yaml:"start_offset_seconds"`
+	Duration           string `yaml:"duration"`
+}
+
+// shiftTemplateResource is a shift_template block of the provider, a named set
+// of shifts schedules can share through the oncall_shift_template data source
+func shiftTemplateResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			shiftTemplateFieldName: {
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name to look the template up by, e.g. business_hours_eu",
+			},
+			shiftTemplateFieldShift: {
+				Type:        schema.TypeList,
+				Required:    true,
+				Description: "Shifts of the template, in the same format as the shift blocks of oncall_advanced_schedule",
+				Elem:        shiftResource(),
+			},
+		},
+	}
+}
+
+// loadShiftTemplates gathers the shift templates of the provider's
+// configuration and of its shift templates file, checking every shift can be
+// turned into a schedule event
+func loadShiftTemplates(d *schema.ResourceData) (map[string][]map[string]interface{}, error) {
+	templates := map[string][]map[string]interface{}{}
+
+	if path := d.Get(providerFieldShiftTemplatesFile).(string); path != "" {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Reading %s", providerFieldShiftTemplatesFile)
+		}
+		templates, err = parseShiftTemplatesFile(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Parsing %s %s", providerFieldShiftTemplatesFile, path)
+		}
+	}
+
+	for _, templateRaw := range d.Get(providerFieldShiftTemplate).([]interface{}) {
+		template := templateRaw.(map[string]interface{})
+		name := template[shiftTemplateFieldName].(string)
+		if _, exists := templates[name]; exists {
+			return nil, fmt.Errorf("Shift template %q is defined more than once", name)
+		}
+		shifts := []map[string]interface{}{}
+		for _, shift := range template[shiftTemplateFieldShift].([]interface{}) {
+			shifts = append(shifts, shift.(map[string]interface{}))
+		}
+		templates[name] = shifts
+	}
+
+	// Only whether the shifts parse is checked, which doesn't depend on the
+	// day weeks start on
+	for name, shifts := range templates {
+		if _, err := shiftsToEvents(shifts, time.Sunday); err != nil {
+			return nil, errors.Wrapf(err, "Invalid shift template %q", name)
+		}
+	}
+	return templates, nil
+}
+
+// parseShiftTemplatesFile reads shift templates by name from YAML, or JSON as
+// YAML is a superset of it, e.g.
+//
+//	business_hours_eu:
+//	  - start_day_of_week: Monday
+//	    start_time: "08:00"
+//	    duration: 9h
+func parseShiftTemplatesFile(data []byte) (map[string][]map[string]interface{}, error) {
+	file := map[string][]shiftTemplateFileShift{}
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string][]map[string]interface{}, len(file))
+	for name, fileShifts := range file {
+		shifts := make([]map[string]interface{}, 0, len(fileShifts))
+		for _, s := range fileShifts {
+			shifts = append(shifts, map[string]interface{}{
+				scheduleFieldStartDayOfWeek:             s.StartDayOfWeek,
+				scheduleFieldStartTime:                  s.StartTime,
+				advancedScheduleFieldStartOffsetSeconds: s.StartOffsetSeconds,
+				advancedScheduleFieldDuration:           s.Duration,
+			})
+		}
+		templates[name] = shifts
+	}
+	return templates, nil
+}
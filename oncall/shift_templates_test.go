@@ -0,0 +1,137 @@
+package oncall
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_loadShiftTemplates(t *testing.T) {
+	dir := t.TempDir()
+	yamlFile := filepath.Join(dir, "templates.yaml")
+	ioutil.WriteFile(yamlFile, []byte(`
+business_hours_eu:
+  - start_day_of_week: Monday
+    start_time: "08:00"
+    duration: 9h
+`), 0600)
+	jsonFile := filepath.Join(dir, "templates.json")
+	ioutil.WriteFile(jsonFile, []byte(`{"sunday_noon": [{"start_offset_seconds": 43200, "duration": "1d"}]}`), 0600)
+	invalidFile := filepath.Join(dir, "invalid.yaml")
+	ioutil.WriteFile(invalidFile, []byte(`
+broken:
+  - start_day_of_week: Someday
+    start_time: "08:00"
+    duration: 9h
+`), 0600)
+
+	configTemplate := map[string]interface{}{
+		shiftTemplateFieldName: "weekend",
+		shiftTemplateFieldShift: []interface{}{
+			map[string]interface{}{
+				scheduleFieldStartDayOfWeek:   "Friday",
+				scheduleFieldStartTime:        "17:00",
+				advancedScheduleFieldDuration: "2d16h",
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		file      string
+		templates []interface{}
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name:      "YAML file and configuration",
+			file:      yamlFile,
+			templates: []interface{}{configTemplate},
+			wantNames: []string{"business_hours_eu", "weekend"},
+		},
+		{
+			name:      "JSON file",
+			file:      jsonFile,
+			wantNames: []string{"sunday_noon"},
+		},
+		{
+			name:      "Template defined twice",
+			templates: []interface{}{configTemplate, configTemplate},
+			wantErr:   true,
+		},
+		{
+			name:    "Shift with an invalid day",
+			file:    invalidFile,
+			wantErr: true,
+		},
+		{
+			name:    "Missing file",
+			file:    filepath.Join(dir, "missing.yaml"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := schema.TestResourceDataRaw(t, Provider().Schema, map[string]interface{}{
+				providerFieldShiftTemplatesFile: tt.file,
+				providerFieldShiftTemplate:      tt.templates,
+			})
+			got, err := loadShiftTemplates(d)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadShiftTemplates() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			for _, name := range tt.wantNames {
+				if _, err := shiftsToEvents(got[name], time.Sunday); err != nil || len(got[name]) == 0 {
+					t.Errorf("loadShiftTemplates()[%s] = %v, %v", name, got[name], err)
+				}
+			}
+			if len(got) != len(tt.wantNames) {
+				t.Errorf("loadShiftTemplates() = %v, want %v", got, tt.wantNames)
+			}
+		})
+	}
+}
+
+func Test_dataSourceShiftTemplateRead(t *testing.T) {
+	meta := &providerMeta{
+		shiftTemplates: map[string][]map[string]interface{}{
+			"business_hours_eu": {
+				shiftBlock("Monday", "08:00", "9h"),
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, dataSourceShiftTemplate().Schema, map[string]interface{}{
+		shiftTemplateFieldName: "business_hours_eu",
+	})
+	diags := dataSourceShiftTemplateRead(context.Background(), d, meta)
+	if diags.HasError() {
+		t.Fatalf("dataSourceShiftTemplateRead() = %v", diags)
+	}
+	want := []interface{}{
+		map[string]interface{}{
+			scheduleFieldStartDayOfWeek:             "Monday",
+			scheduleFieldStartTime:                  "08:00",
+			advancedScheduleFieldStartOffsetSeconds: 0,
+			advancedScheduleFieldDuration:           "9h",
+		},
+	}
+	if got := d.Get(shiftTemplateFieldShift); !reflect.DeepEqual(got, want) {
+		t.Errorf("shift = %v, want %v", got, want)
+	}
+
+	d = schema.TestResourceDataRaw(t, dataSourceShiftTemplate().Schema, map[string]interface{}{
+		shiftTemplateFieldName: "business_hours_us",
+	})
+	if diags := dataSourceShiftTemplateRead(context.Background(), d, meta); !diags.HasError() {
+		t.Errorf("dataSourceShiftTemplateRead() of a missing template = %v, want an error", diags)
+	}
+}
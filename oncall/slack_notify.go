@@ -0,0 +1,360 @@
+package oncall
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	slackNotifyTimeout = 5 * time.Second
+
+	// slackNotifyDelay is how long a team's changes are collected for after
+	// its last change, before a summary of them is posted
+	slackNotifyDelay = 2 * time.Second
+
+	// slackNotifyMaxResources, slackNotifyMaxChanges and slackNotifyMaxValue
+	// keep a summary of a large change readable, the rest is in terraform's
+	// own output
+	slackNotifyMaxResources = 20
+	slackNotifyMaxChanges   = 10
+	slackNotifyMaxValue     = 200
+
+	// slackNotifyRunIDEnv is set by Terraform Cloud and Enterprise to the run
+	// applying the change
+	slackNotifyRunIDEnv = "TFC_RUN_ID"
+)
+
+// slackNotifier posts a summary to a Slack incoming webhook of the resource
+// changes terraform makes in oncall, so teams see when their on-call rotation
+// was changed. Changes are collected per team and posted in the background
+// once the team has had none for slackNotifyDelay, so an apply posts a message
+// per team rather than one per resource, and never waits on Slack. A nil
+// *slackNotifier posts nothing.
+type slackNotifier struct {
+	webhook    string
+	httpClient *http.Client
+	runID      string
+	delay      time.Duration
+
+	mu      sync.Mutex
+	pending map[string]*slackSummary
+}
+
+// slackSummary are the resource changes of a team waiting to be posted
+type slackSummary struct {
+	resources []string
+	timer     *time.Timer
+}
+
+// slackChange is an attribute changed by a resource operation
+type slackChange struct {
+	attribute string
+	old       string
+	new       string
+}
+
+// slackNotifiers are the notifiers of every configured provider, so their
+// pending summaries can be posted before the provider exits
+var slackNotifiers = struct {
+	sync.Mutex
+	all []*slackNotifier
+}{}
+
+func newSlackNotifier(webhook string) *slackNotifier {
+	if webhook == "" {
+		return nil
+	}
+	n := &slackNotifier{
+		webhook:    webhook,
+		httpClient: &http.Client{Timeout: slackNotifyTimeout},
+		runID:      os.Getenv(slackNotifyRunIDEnv),
+		delay:      slackNotifyDelay,
+		pending:    map[string]*slackSummary{},
+	}
+	slackNotifiers.Lock()
+	slackNotifiers.all = append(slackNotifiers.all, n)
+	slackNotifiers.Unlock()
+	return n
+}
+
+// FlushNotifications posts the change summaries still waiting on their delay,
+// it is called as the provider exits as Terraform is done with it
+func FlushNotifications() {
+	slackNotifiers.Lock()
+	defer slackNotifiers.Unlock()
+	for _, n := range slackNotifiers.all {
+		n.flush()
+	}
+}
+
+// queue adds a resource change to the team's summary, and pushes posting it
+// back until the team has had no changes for the notifier's delay
+func (n *slackNotifier) queue(team, resource string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	summary := n.pending[team]
+	if summary == nil {
+		summary = &slackSummary{timer: time.AfterFunc(n.delay, func() { n.send(team) })}
+		n.pending[team] = summary
+	} else {
+		summary.timer.Reset(n.delay)
+	}
+	summary.resources = append(summary.resources, resource)
+}
+
+// send posts the team's summary, if it wasn't posted already
+func (n *slackNotifier) send(team string) {
+	n.mu.Lock()
+	summary := n.pending[team]
+	delete(n.pending, team)
+	n.mu.Unlock()
+	if summary == nil {
+		return
+	}
+
+	err := n.post(slackMessage(n.runID, team, summary.resources))
+	if err != nil {
+		warnLog("Could not notify slack of %d changes to team %s: %s", len(summary.resources), team, err)
+	}
+}
+
+// flush posts every pending summary without waiting for their delay
+func (n *slackNotifier) flush() {
+	n.mu.Lock()
+	teams := make([]string, 0, len(n.pending))
+	for team, summary := range n.pending {
+		summary.timer.Stop()
+		teams = append(teams, team)
+	}
+	n.mu.Unlock()
+	sort.Strings(teams)
+
+	for _, team := range teams {
+		n.send(team)
+	}
+}
+
+// notifySlack adds the resource operation to its team's summary once it
+// succeeds. The changes are taken before the operation, as it replaces the
+// planned attributes with what oncall returns.
+func notifySlack(resourceType, operation string, s map[string]*schema.Schema, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		meta, ok := m.(*providerMeta)
+		if !ok || meta.slackNotifier == nil {
+			return f(ctx, d, m)
+		}
+
+		id := d.Id()
+		team := affectedTeam(d, s, resourceType)
+		changes := []slackChange{}
+		if operation != "delete" {
+			changes = slackChanges(d, s)
+		}
+
+		diags := f(ctx, d, m)
+		if diags.HasError() {
+			return diags
+		}
+		if id == "" {
+			id = d.Id()
+		}
+		if team == "" {
+			team = affectedTeam(d, s, resourceType)
+		}
+		meta.slackNotifier.queue(team, slackResource(resourceType, operation, id, changes))
+		return diags
+	}
+}
+
+// affectedTeam is the team a resource belongs to, from its team or roster_id,
+// or its name for a team itself
+func affectedTeam(d *schema.ResourceData, s map[string]*schema.Schema, resourceType string) string {
+	if resourceType == "oncall_team" {
+		return d.Get(teamFieldName).(string)
+	}
+	if _, ok := s[scheduleFieldTeam]; ok {
+		if team, ok := d.Get(scheduleFieldTeam).(string); ok && team != "" {
+			return team
+		}
+	}
+	if _, ok := s[scheduleFieldRosterID]; ok {
+		if rosterID, ok := d.Get(scheduleFieldRosterID).(string); ok {
+			if team, _, err := parseRosterID(rosterID); err == nil {
+				return team
+			}
+		}
+	}
+	return ""
+}
+
+// slackChanges are the configurable attributes the operation changes, sorted
+// by name. Sensitive values are never sent to slack.
+func slackChanges(d *schema.ResourceData, s map[string]*schema.Schema) []slackChange {
+	keys := make([]string, 0, len(s))
+	for k := range s {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	changes := []slackChange{}
+	for _, k := range keys {
+		if !s[k].Optional && !s[k].Required {
+			continue
+		}
+		if !d.HasChange(k) {
+			continue
+		}
+		old, new := d.GetChange(k)
+		change := slackChange{attribute: k, old: slackValue(old), new: slackValue(new)}
+		if change.old == change.new {
+			continue
+		}
+		if s[k].Sensitive {
+			change.old, change.new = slackMasked(change.old), slackMasked(change.new)
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// slackMasked hides a sensitive value, while still telling it being set or
+// unset apart from it changing
+func slackMasked(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "(sensitive)"
+}
+
+// slackValue formats an attribute value on a single line, e.g. shift blocks
+// as duration=12h start_day_of_week=Monday start_time=09:00; ...
+func slackValue(v interface{}) string {
+	var out string
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case *schema.Set:
+		return slackValue(v.List())
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, slackValue(item))
+		}
+		sep := ", "
+		if len(v) > 0 {
+			if _, ok := v[0].(map[string]interface{}); ok {
+				sep = "; "
+			}
+		}
+		out = strings.Join(items, sep)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fields := []string{}
+		for _, k := range keys {
+			if value := slackValue(v[k]); value != "" && value != "0" && value != "false" {
+				fields = append(fields, k+"="+value)
+			}
+		}
+		out = strings.Join(fields, " ")
+	default:
+		out = fmt.Sprint(v)
+	}
+	if len(out) > slackNotifyMaxValue {
+		out = out[:slackNotifyMaxValue] + "…"
+	}
+	return out
+}
+
+// slackMessage summarizes the resource changes to a team, e.g.
+//
+//	Terraform run run-abc123 changed team *payments*
+//	Updated oncall_advanced_schedule `payments/oncall/primary`
+//	• shift: start_day_of_week=Monday start_time=09:00 → start_day_of_week=Tuesday start_time=09:00
+func slackMessage(runID, team string, resources []string) string {
+	who := "Terraform"
+	if runID != "" {
+		who = fmt.Sprintf("Terraform run %s", runID)
+	}
+	msg := fmt.Sprintf("%s changed oncall", who)
+	if team != "" {
+		msg = fmt.Sprintf("%s changed team *%s*", who, team)
+	}
+
+	for i, resource := range resources {
+		if i == slackNotifyMaxResources {
+			msg += fmt.Sprintf("\nand %d more resources", len(resources)-i)
+			break
+		}
+		msg += "\n" + resource
+	}
+	return msg
+}
+
+// slackResource describes a change to a resource, e.g.
+//
+//	Updated oncall_advanced_schedule `payments/oncall/primary`
+//	• auto_populate_days: 21 → 28
+func slackResource(resourceType, operation, id string, changes []slackChange) string {
+	past := map[string]string{"create": "Created", "update": "Updated", "delete": "Deleted"}[operation]
+	if past == "" {
+		past = operation
+	}
+	msg := fmt.Sprintf("%s %s `%s`", past, resourceType, id)
+
+	for i, c := range changes {
+		if i == slackNotifyMaxChanges {
+			msg += fmt.Sprintf("\n• and %d more", len(changes)-i)
+			break
+		}
+		switch {
+		case c.old == "":
+			msg += fmt.Sprintf("\n• %s: %s", c.attribute, c.new)
+		case c.new == "":
+			msg += fmt.Sprintf("\n• %s: %s → (unset)", c.attribute, c.old)
+		default:
+			msg += fmt.Sprintf("\n• %s: %s → %s", c.attribute, c.old, c.new)
+		}
+	}
+	return msg
+}
+
+func (n *slackNotifier) post(text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return errors.Wrap(err, "Encoding message")
+	}
+
+	resp, err := n.httpClient.Post(n.webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The webhook URL is a secret, don't log it along with the error
+		if urlErr, ok := err.(*url.Error); ok {
+			err = urlErr.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Slack responded with %s", resp.Status)
+	}
+	return nil
+}
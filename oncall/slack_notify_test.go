@@ -0,0 +1,178 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_slackValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{name: "Unset", value: nil, want: ""},
+		{name: "String", value: "Monday", want: "Monday"},
+		{name: "Number", value: 14, want: "14"},
+		{name: "List of strings", value: []interface{}{"alice", "bob"}, want: "alice, bob"},
+		{
+			name: "Shift blocks",
+			value: []interface{}{
+				map[string]interface{}{"start_day_of_week": "Monday", "start_time": "09:00", "duration": "12h", "start_offset_seconds": 0},
+				map[string]interface{}{"start_day_of_week": "Tuesday", "start_time": "09:00", "duration": "12h", "start_offset_seconds": 0},
+			},
+			want: "duration=12h start_day_of_week=Monday start_time=09:00; duration=12h start_day_of_week=Tuesday start_time=09:00",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slackValue(tt.value); got != tt.want {
+				t.Errorf("slackValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_slackMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		runID     string
+		team      string
+		resources []string
+		want      string
+	}{
+		{
+			name:  "Changes in a run",
+			runID: "run-abc123",
+			team:  "payments",
+			resources: []string{
+				slackResource("oncall_advanced_schedule", "update", "payments/oncall/primary", []slackChange{
+					{attribute: "auto_populate_days", old: "21", new: "28"},
+					{attribute: "shift", old: "start_day_of_week=Monday", new: "start_day_of_week=Tuesday"},
+				}),
+				slackResource("oncall_roster", "create", "payments/oncall", []slackChange{{attribute: "name", new: "oncall"}}),
+			},
+			want: "Terraform run run-abc123 changed team *payments*\n" +
+				"Updated oncall_advanced_schedule `payments/oncall/primary`\n" +
+				"• auto_populate_days: 21 → 28\n" +
+				"• shift: start_day_of_week=Monday → start_day_of_week=Tuesday\n" +
+				"Created oncall_roster `payments/oncall`\n" +
+				"• name: oncall",
+		},
+		{
+			name:      "Delete without a team",
+			resources: []string{slackResource("oncall_advanced_schedule", "delete", "payments/oncall/primary", nil)},
+			want:      "Terraform changed oncall\nDeleted oncall_advanced_schedule `payments/oncall/primary`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slackMessage(tt.runID, tt.team, tt.resources)
+			if got != tt.want {
+				t.Errorf("slackMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_notifySlack(t *testing.T) {
+	var mu sync.Mutex
+	posted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Decoding webhook body: %s", err)
+		}
+		mu.Lock()
+		posted = append(posted, body["text"])
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	s := map[string]*schema.Schema{
+		scheduleFieldRosterID: {Type: schema.TypeString, Required: true},
+		scheduleFieldRole:     {Type: schema.TypeString, Required: true},
+		"token":               {Type: schema.TypeString, Optional: true, Sensitive: true},
+	}
+	create := notifySlack("oncall_basic_schedule", "create", s, func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		if d.Get(scheduleFieldRole).(string) == "broken" {
+			return diag.Errorf("Creating schedule failed")
+		}
+		d.SetId(d.Get(scheduleFieldRosterID).(string) + "/" + d.Get(scheduleFieldRole).(string))
+		return nil
+	})
+
+	n := newSlackNotifier(server.URL)
+	n.delay = time.Hour
+	meta := &providerMeta{slackNotifier: n}
+	for _, role := range []string{"primary", "broken", "secondary"} {
+		d := schema.TestResourceDataRaw(t, s, map[string]interface{}{
+			scheduleFieldRosterID: "payments/oncall",
+			scheduleFieldRole:     role,
+			"token":               "secret",
+		})
+		diags := create(context.Background(), d, meta)
+		if diags.HasError() != (role == "broken") {
+			t.Fatalf("create() of %s = %v", role, diags)
+		}
+	}
+
+	mu.Lock()
+	if len(posted) != 0 {
+		t.Errorf("posted %q before the team's changes were done", posted)
+	}
+	mu.Unlock()
+
+	n.flush()
+	want := []string{"Terraform changed team *payments*\n" +
+		"Created oncall_basic_schedule `payments/oncall/primary`\n" +
+		"• role: primary\n" +
+		"• roster_id: payments/oncall\n" +
+		"• token: (sensitive)\n" +
+		"Created oncall_basic_schedule `payments/oncall/secondary`\n" +
+		"• role: secondary\n" +
+		"• roster_id: payments/oncall\n" +
+		"• token: (sensitive)"}
+	if !reflect.DeepEqual(posted, want) {
+		t.Errorf("posted %q, want %q", posted, want)
+	}
+}
+
+func Test_slackNotifierDelay(t *testing.T) {
+	posts := make(chan string, 2)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		posts <- body["text"]
+	}))
+	defer server.Close()
+
+	n := newSlackNotifier(server.URL)
+	n.delay = 50 * time.Millisecond
+	n.queue("payments", "Created oncall_roster `payments/oncall`")
+	n.queue("payments", "Created oncall_team `payments`")
+
+	select {
+	case got := <-posts:
+		want := "Terraform changed team *payments*\nCreated oncall_roster `payments/oncall`\nCreated oncall_team `payments`"
+		if got != want {
+			t.Errorf("posted %q, want %q", got, want)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Nothing posted after the delay")
+	}
+	select {
+	case got := <-posts:
+		t.Errorf("posted %q as well, want a single summary", got)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,68 @@
+package oncall
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+// Oncall has nowhere to keep which slack usergroup mirrors a role, so the
+// mappings are kept as annotation lines at the end of the team's description,
+// e.g. "slack-usergroup: primary @platform-oncall", where a sync bot can read them
+const slackUsergroupAnnotationPrefix = "slack-usergroup:"
+
+var slackUsergroupHandleRegexp = regexp.MustCompile(`^@?[a-z0-9][a-z0-9._-]*$`)
+
+func init() {
+	registerTeamAnnotation(teamAnnotationKind{
+		prefix: slackUsergroupAnnotationPrefix,
+		parse: func(rest string) (string, string, bool) {
+			fields := strings.Fields(rest)
+			if len(fields) != 2 {
+				return "", "", false
+			}
+			return fields[0], fields[1], true
+		},
+		format: func(role, handle string) string {
+			return role + " " + handle
+		},
+	})
+}
+
+// normalizeSlackUsergroup makes sure usergroup handles are always stored with a
+// leading @, which is how they are mentioned in slack
+func normalizeSlackUsergroup(handle string) string {
+	return "@" + strings.TrimPrefix(handle, "@")
+}
+
+func getTeamSlackUsergroups(c *oncall.Client, teamName string) (map[string]string, error) {
+	return getTeamAnnotations(c, teamName, slackUsergroupAnnotationPrefix)
+}
+
+// setTeamSlackUsergroup maps the role to the usergroup handle in the team's
+// description, an empty handle removes the mapping
+func setTeamSlackUsergroup(c *oncall.Client, teamName, role, handle string) error {
+	return updateTeamAnnotations(c, teamName, slackUsergroupAnnotationPrefix, func(usergroups map[string]string) error {
+		if handle == "" {
+			delete(usergroups, role)
+		} else {
+			usergroups[role] = normalizeSlackUsergroup(handle)
+		}
+		return nil
+	})
+}
+
+// addTeamSlackUsergroup maps the role to the usergroup handle unless the role
+// is mapped already, in which case it returns the handle it is mapped to
+func addTeamSlackUsergroup(c *oncall.Client, teamName, role, handle string) (existing string, err error) {
+	err = updateTeamAnnotations(c, teamName, slackUsergroupAnnotationPrefix, func(usergroups map[string]string) error {
+		if linked, ok := usergroups[role]; ok {
+			existing = linked
+			return nil
+		}
+		usergroups[role] = normalizeSlackUsergroup(handle)
+		return nil
+	})
+	return existing, err
+}
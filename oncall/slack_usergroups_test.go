@@ -0,0 +1,54 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseTeamDescriptionUsergroups(t *testing.T) {
+	tests := []struct {
+		name           string
+		description    string
+		wantText       string
+		wantUsergroups map[string]string
+	}{
+		{
+			name:           "No annotations",
+			description:    "Keeps the lights on",
+			wantText:       "Keeps the lights on",
+			wantUsergroups: map[string]string{},
+		},
+		{
+			name:           "Annotations after the description",
+			description:    "Keeps the lights on\n\nslack-usergroup: primary @platform-oncall\nslack-usergroup: secondary @platform-backup",
+			wantText:       "Keeps the lights on",
+			wantUsergroups: map[string]string{"primary": "@platform-oncall", "secondary": "@platform-backup"},
+		},
+		{
+			name:           "Only annotations",
+			description:    "slack-usergroup: primary @platform-oncall",
+			wantText:       "",
+			wantUsergroups: map[string]string{"primary": "@platform-oncall"},
+		},
+		{
+			name:           "Prose mentioning the prefix is left alone",
+			description:    "slack-usergroup: lines are managed by terraform",
+			wantText:       "slack-usergroup: lines are managed by terraform",
+			wantUsergroups: map[string]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parsed := parseTeamDescription(tt.description)
+			if parsed.text != tt.wantText {
+				t.Errorf("parseTeamDescription() text = %q, want %q", parsed.text, tt.wantText)
+			}
+			if got := parsed.annotations[slackUsergroupAnnotationPrefix]; !reflect.DeepEqual(got, tt.wantUsergroups) {
+				t.Errorf("parseTeamDescription() usergroups = %v, want %v", got, tt.wantUsergroups)
+			}
+			if got := parsed.String(); got != tt.description {
+				t.Errorf("String() = %q, want %q", got, tt.description)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package oncall
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// strictModeFor is whether the provider fails reads that can't be normalized,
+// off when there is no provider configuration, e.g. in tests
+func strictModeFor(m interface{}) bool {
+	if meta, ok := m.(*providerMeta); ok {
+		return meta.strictMode
+	}
+	return false
+}
+
+// normalizationDiags reports that what was read from oncall couldn't be
+// normalized into the resource's attributes, leaving some of them empty. This
+// is only logged, as the next plan shows the difference, unless strict_mode
+// is set on the provider, which makes it an error.
+func normalizationDiags(m interface{}, format string, args ...interface{}) diag.Diagnostics {
+	msg := fmt.Sprintf(format, args...)
+	if !strictModeFor(m) {
+		warnLog("%s", msg)
+		return nil
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity: diag.Error,
+			Summary:  msg,
+			Detail:   fmt.Sprintf("%s is set on the provider, so this fails instead of leaving the attributes it affects empty. Fix the object in oncall or the configuration to match it.", providerFieldStrictMode),
+		},
+	}
+}
+
+// checkReadRole reports a role read from oncall that isn't one the provider
+// knows, which the resource's role validation would reject
+func checkReadRole(m interface{}, id, role string) diag.Diagnostics {
+	for _, r := range roleNames {
+		if r == role {
+			return nil
+		}
+	}
+	return normalizationDiags(m, "%s has role %q, which is not one of %v", id, role, roleNames)
+}
@@ -0,0 +1,45 @@
+package oncall
+
+import "testing"
+
+func Test_normalizationDiags(t *testing.T) {
+	tests := []struct {
+		name      string
+		meta      interface{}
+		wantError bool
+	}{
+		{name: "No provider configuration", meta: nil},
+		{name: "Not strict", meta: &providerMeta{}},
+		{name: "Strict", meta: &providerMeta{strictMode: true}, wantError: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := normalizationDiags(tt.meta, "Rotation %s has %d events instead of one", "t/r/primary", 2)
+			if diags.HasError() != tt.wantError {
+				t.Errorf("normalizationDiags() = %v, wantError %v", diags, tt.wantError)
+			}
+			if !tt.wantError && len(diags) > 0 {
+				t.Errorf("normalizationDiags() = %v, want it only logged", diags)
+			}
+		})
+	}
+}
+
+func Test_checkReadRole(t *testing.T) {
+	strict := &providerMeta{strictMode: true}
+	tests := []struct {
+		name string
+		role string
+		errs bool
+	}{
+		{name: "Known role", role: "primary"},
+		{name: "Unknown role", role: "oncall-lead", errs: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkReadRole(strict, "t/r/"+tt.role, tt.role); got.HasError() != tt.errs {
+				t.Errorf("checkReadRole() = %v, wantError %v", got, tt.errs)
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// teamAdminGrants are the teams the provider's user was made an admin of for
+// act_as_team_admin, shared by every operation of the provider. Admins are
+// per team, not per operation, so the user is only taken back out of a team's
+// admins once the last operation that needed it there is done.
+type teamAdminGrants struct {
+	// teams serializes the admin changes of each team, without one team's
+	// calls to oncall holding up the others
+	teams keyedMutex
+
+	mu sync.Mutex
+	// holders counts the running operations relying on each grant
+	holders map[string]int
+	// managed are the granted teams whose admins an operation changed itself,
+	// the user is left as whatever that made it
+	managed map[string]bool
+}
+
+// acquire makes the user an admin of the team if it isn't one already,
+// returning whether the caller now holds a grant it has to release
+func (g *teamAdminGrants) acquire(c *oncall.Client, user, team string) bool {
+	unlock := g.teams.lock(team)
+	defer unlock()
+
+	g.mu.Lock()
+	if g.holders == nil {
+		g.holders = map[string]int{}
+		g.managed = map[string]bool{}
+	}
+	if g.holders[team] > 0 {
+		g.holders[team]++
+		g.mu.Unlock()
+		return true
+	}
+	g.mu.Unlock()
+
+	admins, err := c.GetTeamAdmins(url.PathEscape(team))
+	if err != nil {
+		warnLog("Could not check whether %s is an admin of team %s, not acting as one: %s", user, team, err)
+		return false
+	}
+	if stringSliceContains(admins, user) {
+		return false
+	}
+	infoLog("Making %s an admin of team %s for %s", user, team, providerFieldActAsTeamAdmin)
+	err = c.AddTeamAdmin(url.PathEscape(team), user)
+	if err != nil {
+		warnLog("Could not make %s an admin of team %s: %s", user, team, err)
+		return false
+	}
+
+	g.mu.Lock()
+	g.holders[team] = 1
+	g.mu.Unlock()
+	return true
+}
+
+// setManaged records that an operation set the team's admins itself, so a
+// grant held on it isn't taken back
+func (g *teamAdminGrants) setManaged(team string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.holders[team] > 0 {
+		g.managed[team] = true
+	}
+}
+
+// release gives up a grant, removing the user from the team's admins if it
+// was the last one held
+func (g *teamAdminGrants) release(c *oncall.Client, user, team string) error {
+	unlock := g.teams.lock(team)
+	defer unlock()
+
+	g.mu.Lock()
+	g.holders[team]--
+	if g.holders[team] > 0 {
+		traceLog("Leaving %s as an admin of team %s, %d other operations still act as one", user, team, g.holders[team])
+		g.mu.Unlock()
+		return nil
+	}
+	delete(g.holders, team)
+	managed := g.managed[team]
+	delete(g.managed, team)
+	g.mu.Unlock()
+
+	if managed {
+		debugLog("Leaving %s as an admin of team %s as it is, an operation set the team's admins", user, team)
+		return nil
+	}
+
+	traceLog("Going to remove %s from the admins of team %s", user, team)
+	err := c.RemoveTeamAdmin(url.PathEscape(team), url.PathEscape(user))
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// teamAdminElevation makes the provider's user an admin of the teams an
+// operation changes something of, for as long as the operation takes, so
+// act_as_team_admin leaves no trace in the teams' admins afterwards
+type teamAdminElevation struct {
+	// client makes the admin changes, without going through the elevation
+	client *oncall.Client
+	user   string
+	grants *teamAdminGrants
+
+	mu sync.Mutex
+	// checked are the teams whose admins have been looked at
+	checked map[string]bool
+	// held are the teams the operation holds a grant on
+	held []string
+}
+
+type teamAdminElevationKey struct{}
+
+func teamAdminElevationFrom(ctx context.Context) *teamAdminElevation {
+	el, _ := ctx.Value(teamAdminElevationKey{}).(*teamAdminElevation)
+	return el
+}
+
+// elevateTeamAdmin runs an operation with the provider's user made an admin
+// of the teams it changes, and takes it back out of those it wasn't one of
+// already once no running operation needs it anymore
+func elevateTeamAdmin(f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		meta, ok := m.(*providerMeta)
+		if !ok || !meta.actAsTeamAdmin || meta.client == nil {
+			return f(ctx, d, m)
+		}
+
+		el := &teamAdminElevation{
+			client:  meta.clientFor(ctx),
+			user:    meta.client.Config.Username,
+			grants:  &meta.teamAdminGrants,
+			checked: map[string]bool{},
+		}
+		diags := f(context.WithValue(ctx, teamAdminElevationKey{}, el), d, m)
+		return append(diags, el.restore()...)
+	}
+}
+
+// before is called for every request of the operation, making the user an
+// admin of the request's team before it changes something of the team
+func (el *teamAdminElevation) before(req *http.Request, team string) {
+	if el == nil || team == "" || !mutatingMethod(req.Method) {
+		return
+	}
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	if isTeamAdminsPath(req, team) {
+		el.grants.setManaged(team)
+		return
+	}
+	if el.checked[team] {
+		return
+	}
+	el.checked[team] = true
+
+	if el.grants.acquire(el.client, el.user, team) {
+		el.held = append(el.held, team)
+	}
+}
+
+// teamAdminRoundTripper makes the provider's user an admin of the teams the
+// operation changes, when it runs with act_as_team_admin
+type teamAdminRoundTripper struct {
+	proxied http.RoundTripper
+}
+
+func (tart teamAdminRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	team, _ := pathTeam(req)
+	teamAdminElevationFrom(req.Context()).before(req, team)
+	return tart.proxied.RoundTrip(req)
+}
+
+// restore releases the operation's grants, removing the user from the admins
+// of the teams no other running operation needs it to be an admin of
+func (el *teamAdminElevation) restore() diag.Diagnostics {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+
+	diags := diag.Diagnostics{}
+	for _, team := range el.held {
+		err := el.grants.release(el.client, el.user, team)
+		if err != nil {
+			diags = append(diags, diag.Diagnostic{
+				Severity: diag.Warning,
+				Summary:  fmt.Sprintf("Could not remove %s from the admins of team %s after acting as a team admin", el.user, team),
+				Detail:   fmt.Sprintf("%s\n\nRemove it from the team's admins in oncall to keep them clean.", err),
+			})
+		}
+	}
+	el.held = nil
+	return diags
+}
+
+// isTeamAdminsPath is whether the request is for the admins of the team
+func isTeamAdminsPath(req *http.Request, team string) bool {
+	prefix := "api/v0/teams/" + url.PathEscape(team) + "/admins"
+	return strings.Contains(req.URL.EscapedPath(), prefix)
+}
+
+// isInstanceAdmin is whether the user is a global admin of oncall, who may
+// add themselves to the admins of any team
+func isInstanceAdmin(c *oncall.Client, username string) (bool, error) {
+	user := struct {
+		God apiBool `json:"god"`
+	}{}
+	_, err := c.Get("/api/v0/users/"+url.PathEscape(username), &user)
+	if err != nil {
+		return false, errors.Wrapf(err, "Fetching user %s", username)
+	}
+	return bool(user.God), nil
+}
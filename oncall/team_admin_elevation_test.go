@@ -0,0 +1,268 @@
+package oncall
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_elevateTeamAdmin(t *testing.T) {
+	tests := []struct {
+		name         string
+		admins       []string
+		requests     []string
+		wantRequests []string
+		wantAdmins   []string
+	}{
+		{
+			name:     "Made an admin for the operation",
+			admins:   []string{"alice"},
+			requests: []string{"PUT /api/v0/teams/acme/rosters/oncall", "POST /api/v0/teams/acme/rosters/oncall/users"},
+			wantRequests: []string{
+				"POST /api/v0/teams/acme/admins",
+				"PUT /api/v0/teams/acme/rosters/oncall",
+				"POST /api/v0/teams/acme/rosters/oncall/users",
+				"DELETE /api/v0/teams/acme/admins/terraform",
+			},
+			wantAdmins: []string{"alice"},
+		},
+		{
+			name:         "Already an admin",
+			admins:       []string{"alice", "terraform"},
+			requests:     []string{"PUT /api/v0/teams/acme/rosters/oncall"},
+			wantRequests: []string{"PUT /api/v0/teams/acme/rosters/oncall"},
+			wantAdmins:   []string{"alice", "terraform"},
+		},
+		{
+			name:     "The operation sets the team's admins",
+			admins:   []string{"alice"},
+			requests: []string{"PUT /api/v0/teams/acme", "DELETE /api/v0/teams/acme/admins/alice"},
+			wantRequests: []string{
+				"POST /api/v0/teams/acme/admins",
+				"PUT /api/v0/teams/acme",
+				"DELETE /api/v0/teams/acme/admins/alice",
+			},
+			wantAdmins: []string{"terraform"},
+		},
+		{
+			name:         "Nothing changed",
+			admins:       []string{"alice"},
+			requests:     []string{"GET /api/v0/teams/acme"},
+			wantRequests: []string{},
+			wantAdmins:   []string{"alice"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu := sync.Mutex{}
+			admins := append([]string{}, tt.admins...)
+			requests := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+				if r.Method != "GET" {
+					requests = append(requests, r.Method+" "+r.URL.Path)
+				}
+				switch {
+				case r.Method == "GET" && r.URL.Path == "/api/v0/teams/acme/admins":
+					json.NewEncoder(w).Encode(admins)
+				case r.Method == "POST" && r.URL.Path == "/api/v0/teams/acme/admins":
+					body := map[string]interface{}{}
+					json.NewDecoder(r.Body).Decode(&body)
+					admins = append(admins, body["name"].(string))
+				case r.Method == "DELETE" && strings.HasPrefix(r.URL.Path, "/api/v0/teams/acme/admins/"):
+					name := strings.TrimPrefix(r.URL.Path, "/api/v0/teams/acme/admins/")
+					kept := []string{}
+					for _, a := range admins {
+						if a != name {
+							kept = append(kept, a)
+						}
+					}
+					admins = kept
+				default:
+					w.Write([]byte(`{}`))
+				}
+			}))
+			defer server.Close()
+
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, Username: "terraform", AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+			meta := &providerMeta{client: c, actAsTeamAdmin: true}
+
+			op := elevateTeamAdmin(func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+				oc := m.(*providerMeta).clientFor(ctx)
+				for _, req := range tt.requests {
+					parts := strings.SplitN(req, " ", 2)
+					var err error
+					switch parts[0] {
+					case "GET":
+						_, err = oc.Get(parts[1], nil)
+					case "PUT":
+						_, err = oc.Put(parts[1], map[string]string{}, nil)
+					case "POST":
+						_, err = oc.Post(parts[1], map[string]string{}, nil)
+					case "DELETE":
+						_, err = oc.Delete(parts[1], nil, nil)
+					}
+					if err != nil {
+						return diag.FromErr(err)
+					}
+				}
+				return nil
+			})
+			d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{}, map[string]interface{}{})
+			if diags := op(context.Background(), d, meta); diags.HasError() {
+				t.Fatalf("operation = %v", diags)
+			}
+
+			if !reflect.DeepEqual(requests, tt.wantRequests) {
+				t.Errorf("requests = %v, want %v", requests, tt.wantRequests)
+			}
+			if !reflect.DeepEqual(admins, tt.wantAdmins) {
+				t.Errorf("admins = %v, want %v", admins, tt.wantAdmins)
+			}
+		})
+	}
+}
+
+func Test_elevateTeamAdminOverlapping(t *testing.T) {
+	mu := sync.Mutex{}
+	admins := []string{"alice"}
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v0/teams/acme/admins":
+			json.NewEncoder(w).Encode(admins)
+		case r.Method == "POST" && r.URL.Path == "/api/v0/teams/acme/admins":
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			body := map[string]interface{}{}
+			json.NewDecoder(r.Body).Decode(&body)
+			admins = append(admins, body["name"].(string))
+		case r.Method == "DELETE" && r.URL.Path == "/api/v0/teams/acme/admins/terraform":
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			admins = []string{"alice"}
+		case !stringSliceContains(admins, "terraform"):
+			requests = append(requests, r.Method+" "+r.URL.Path+" forbidden")
+			w.WriteHeader(403)
+		default:
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, Username: "terraform", AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c, actAsTeamAdmin: true}
+
+	// operation puts each path once it is its turn, reporting each one done,
+	// and finishes on the turn after its last one
+	done := make(chan struct{})
+	operation := func(turn chan struct{}, paths ...string) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+		return elevateTeamAdmin(func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+			oc := m.(*providerMeta).clientFor(ctx)
+			for _, path := range paths {
+				<-turn
+				_, err := oc.Put(path, map[string]string{}, nil)
+				done <- struct{}{}
+				if err != nil {
+					return diag.FromErr(err)
+				}
+			}
+			<-turn
+			return nil
+		})
+	}
+
+	aTurn, bTurn := make(chan struct{}), make(chan struct{})
+	a := operation(aTurn, "/api/v0/teams/acme/rosters/a")
+	b := operation(bTurn, "/api/v0/teams/acme/rosters/b", "/api/v0/teams/acme/rosters/b")
+
+	d := schema.TestResourceDataRaw(t, map[string]*schema.Schema{}, map[string]interface{}{})
+	aDone := make(chan diag.Diagnostics)
+	bDone := make(chan diag.Diagnostics)
+	go func() { aDone <- a(context.Background(), d, meta) }()
+	go func() { bDone <- b(context.Background(), d, meta) }()
+
+	// A makes the user an admin, B starts changing the team while it is one,
+	// and A finishes before B makes its last change
+	aTurn <- struct{}{}
+	<-done
+	bTurn <- struct{}{}
+	<-done
+	aTurn <- struct{}{}
+	if diags := <-aDone; diags.HasError() {
+		t.Fatalf("operation A = %v", diags)
+	}
+	bTurn <- struct{}{}
+	<-done
+	bTurn <- struct{}{}
+	if diags := <-bDone; diags.HasError() {
+		t.Fatalf("operation B = %v", diags)
+	}
+
+	wantRequests := []string{
+		"POST /api/v0/teams/acme/admins",
+		"PUT /api/v0/teams/acme/rosters/a",
+		"PUT /api/v0/teams/acme/rosters/b",
+		"PUT /api/v0/teams/acme/rosters/b",
+		"DELETE /api/v0/teams/acme/admins/terraform",
+	}
+	if !reflect.DeepEqual(requests, wantRequests) {
+		t.Errorf("requests = %v, want %v", requests, wantRequests)
+	}
+	if !reflect.DeepEqual(admins, []string{"alice"}) {
+		t.Errorf("admins = %v, want [alice]", admins)
+	}
+}
+
+func Test_teamAdminGrantsPerTeam(t *testing.T) {
+	slow := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v0/teams/slow/admins" && r.Method == "GET" {
+			<-slow
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	defer close(slow)
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, Username: "terraform", AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	grants := &teamAdminGrants{}
+
+	go grants.acquire(c, "terraform", "slow")
+
+	// A team whose admins oncall is slow to return doesn't hold up the others
+	acquired := make(chan bool)
+	go func() { acquired <- grants.acquire(c, "terraform", "fast") }()
+	select {
+	case held := <-acquired:
+		if !held {
+			t.Errorf("acquire() of team fast = false, want a grant")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("acquire() of team fast waited on team slow")
+	}
+	if err := grants.release(c, "terraform", "fast"); err != nil {
+		t.Errorf("release() error = %v", err)
+	}
+}
@@ -0,0 +1,107 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	teamImportIncludeRosters   = "rosters"
+	teamImportIncludeSchedules = "schedules"
+)
+
+var teamImportIncludes = []string{
+	teamImportIncludeRosters,
+	teamImportIncludeSchedules,
+}
+
+// parseTeamImportID splits a team import ID like team-name?include=rosters,schedules
+// into the team name and which of the team's resources to import along with it
+func parseTeamImportID(importID string) (teamName string, include map[string]bool, err error) {
+	include = map[string]bool{}
+	teamName, rawQuery := importID, ""
+	if i := strings.Index(importID, "?"); i >= 0 {
+		teamName, rawQuery = importID[:i], importID[i+1:]
+	}
+	if teamName == "" {
+		return "", nil, errors.New("Team import ID did not specify a team name")
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", nil, errors.Wrap(err, "Parsing team import options")
+	}
+	for key := range query {
+		if key != "include" {
+			return "", nil, fmt.Errorf("Unknown team import option %q, only include is supported", key)
+		}
+	}
+	for _, includes := range query["include"] {
+		for _, i := range strings.Split(includes, ",") {
+			if !stringSliceContains(teamImportIncludes, i) {
+				return "", nil, fmt.Errorf("Can not include %q when importing a team, must be one of: %v", i, teamImportIncludes)
+			}
+			include[i] = true
+		}
+	}
+	return teamName, include, nil
+}
+
+// importTeamGraph imports the team's rosters and/or schedules as their own
+// resources. Terraform puts them in state next to the team with the same name,
+// suffixed with -1, -2, etc. when there is more than one of a type.
+func importTeamGraph(ctx context.Context, teamName string, include map[string]bool, m interface{}) ([]*schema.ResourceData, error) {
+	c := m.(*providerMeta).clientFor(ctx)
+	imported := []*schema.ResourceData{}
+
+	rosters, err := c.GetRosters(teamName)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Listing rosters of team %s", teamName)
+	}
+
+	for _, rosterName := range rosters {
+		if include[teamImportIncludeRosters] {
+			traceLog("Going to import roster %s/%s along with its team", teamName, rosterName)
+			rd := resourceRoster().Data(nil)
+			rd.SetType("oncall_roster")
+			rd.SetId(getRosterID(teamName, rosterName))
+			rds, err := resourceRosterImport(ctx, rd, m)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Importing roster %s/%s", teamName, rosterName)
+			}
+			imported = append(imported, rds...)
+		}
+
+		if include[teamImportIncludeSchedules] {
+			schedules, err := getRosterSchedules(c, teamName, rosterName)
+			if err != nil {
+				return nil, err
+			}
+			for _, schedule := range schedules {
+				traceLog("Going to import roster schedule %s/%s/%s along with its team", teamName, rosterName, schedule.Role)
+				var rds []*schema.ResourceData
+				if checkBasicSchedule(schedule.Schedule) == nil {
+					rd := resourceBasicSchedule().Data(nil)
+					rd.SetType("oncall_basic_schedule")
+					rd.SetId(getScheduleID(teamName, rosterName, schedule.Role))
+					rds, err = resourceBasicScheduleImport(ctx, rd, m)
+				} else {
+					rd := resourceAdvancedSchedule().Data(nil)
+					rd.SetType("oncall_advanced_schedule")
+					rd.SetId(getScheduleID(teamName, rosterName, schedule.Role))
+					rds, err = resourceAdvancedScheduleImport(ctx, rd, m)
+				}
+				if err != nil {
+					return nil, errors.Wrapf(err, "Importing roster schedule %s/%s/%s", teamName, rosterName, schedule.Role)
+				}
+				imported = append(imported, rds...)
+			}
+		}
+	}
+	return imported, nil
+}
@@ -0,0 +1,64 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseTeamImportID(t *testing.T) {
+	tests := []struct {
+		name        string
+		importID    string
+		wantTeam    string
+		wantInclude map[string]bool
+		wantErr     bool
+	}{
+		{
+			name:        "Just the team",
+			importID:    "my-team",
+			wantTeam:    "my-team",
+			wantInclude: map[string]bool{},
+		},
+		{
+			name:        "Rosters and schedules",
+			importID:    "my-team?include=rosters,schedules",
+			wantTeam:    "my-team",
+			wantInclude: map[string]bool{"rosters": true, "schedules": true},
+		},
+		{
+			name:        "Repeated include",
+			importID:    "my-team?include=schedules&include=rosters",
+			wantTeam:    "my-team",
+			wantInclude: map[string]bool{"rosters": true, "schedules": true},
+		},
+		{
+			name:     "Unknown include",
+			importID: "my-team?include=members",
+			wantErr:  true,
+		},
+		{
+			name:     "Unknown option",
+			importID: "my-team?with=rosters",
+			wantErr:  true,
+		},
+		{
+			name:     "No team",
+			importID: "?include=rosters",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotTeam, gotInclude, err := parseTeamImportID(tt.importID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseTeamImportID() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if gotTeam != tt.wantTeam || !reflect.DeepEqual(gotInclude, tt.wantInclude) {
+				t.Errorf("parseTeamImportID() = %s, %v, want %s, %v", gotTeam, gotInclude, tt.wantTeam, tt.wantInclude)
+			}
+		})
+	}
+}
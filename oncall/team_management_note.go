@@ -0,0 +1,75 @@
+package oncall
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+)
+
+// Oncall has no flag for teams managed elsewhere, so the management note is
+// kept as the first line of the team's description, where it is the first
+// thing seen by someone about to edit the team in the UI, e.g.
+// `terraform-managed: changes here are overwritten, edit github.com/acme/oncall`
+const managementNoteAnnotationPrefix = "terraform-managed:"
+
+// splitManagementNote separates the management note from the rest of a team
+// description, found is false if the description has no note
+func splitManagementNote(description string) (text, note string, found bool) {
+	lines := strings.SplitN(description, "\n", 2)
+	if !strings.HasPrefix(lines[0], managementNoteAnnotationPrefix) {
+		return description, "", false
+	}
+	note = strings.TrimSpace(strings.TrimPrefix(lines[0], managementNoteAnnotationPrefix))
+	if len(lines) == 2 {
+		text = strings.TrimLeft(lines[1], "\n")
+	}
+	return text, note, true
+}
+
+// joinManagementNote puts the management note in front of a team description,
+// an empty note is left out
+func joinManagementNote(text, note string) string {
+	if note == "" {
+		return text
+	}
+	line := managementNoteAnnotationPrefix + " " + note
+	if text == "" {
+		return line
+	}
+	return line + "\n\n" + text
+}
+
+// managementNoteDrift warns that the team's management note isn't the one
+// terraform wrote, which means someone edited the team outside of terraform
+func managementNoteDrift(teamName, want, got string, found bool) diag.Diagnostics {
+	if want == "" || (found && got == want) {
+		return nil
+	}
+	summary := fmt.Sprintf("The management note of team %s was removed outside of terraform", teamName)
+	if found {
+		summary = fmt.Sprintf("The management note of team %s was changed outside of terraform to %q", teamName, got)
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity:      diag.Warning,
+			Summary:       summary,
+			Detail:        "Someone has edited the team in oncall despite the note, check the plan for other changes they made before applying, which puts the note back and undoes them.",
+			AttributePath: cty.GetAttrPath(teamFieldExternalManagementNote),
+		},
+	}
+}
+
+func validateManagementNote(in interface{}, path cty.Path) diag.Diagnostics {
+	if strings.ContainsAny(in.(string), "\r\n") {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       "The management note must be a single line",
+				AttributePath: path,
+			},
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package oncall
+
+import (
+	"testing"
+)
+
+func Test_managementNote(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		wantText    string
+		wantNote    string
+		wantFound   bool
+	}{
+		{
+			name:        "No note",
+			description: "The platform team\nterraform-managed: not on the first line",
+			wantText:    "The platform team\nterraform-managed: not on the first line",
+		},
+		{
+			name:        "Note only",
+			description: "terraform-managed: edit github.com/acme/oncall",
+			wantNote:    "edit github.com/acme/oncall",
+			wantFound:   true,
+		},
+		{
+			name:        "Note and description",
+			description: "terraform-managed: edit github.com/acme/oncall\n\nThe platform team\n\nslack-usergroup: primary @platform-oncall",
+			wantText:    "The platform team\n\nslack-usergroup: primary @platform-oncall",
+			wantNote:    "edit github.com/acme/oncall",
+			wantFound:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, note, found := splitManagementNote(tt.description)
+			if text != tt.wantText || note != tt.wantNote || found != tt.wantFound {
+				t.Fatalf("splitManagementNote() = %q, %q, %v, want %q, %q, %v", text, note, found, tt.wantText, tt.wantNote, tt.wantFound)
+			}
+			if joined := joinManagementNote(text, note); joined != tt.description {
+				t.Errorf("joinManagementNote() = %q, want %q", joined, tt.description)
+			}
+		})
+	}
+}
+
+func Test_managementNoteDrift(t *testing.T) {
+	tests := []struct {
+		name     string
+		want     string
+		got      string
+		found    bool
+		wantWarn bool
+	}{
+		{name: "No note configured", got: "anything", found: true},
+		{name: "Note kept", want: "edit github.com/acme/oncall", got: "edit github.com/acme/oncall", found: true},
+		{name: "Note changed", want: "edit github.com/acme/oncall", got: "fixed it by hand", found: true, wantWarn: true},
+		{name: "Note removed", want: "edit github.com/acme/oncall", wantWarn: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := managementNoteDrift("platform", tt.want, tt.got, tt.found)
+			if (len(diags) > 0) != tt.wantWarn || diags.HasError() {
+				t.Errorf("managementNoteDrift() = %v, wantWarn %v", diags, tt.wantWarn)
+			}
+		})
+	}
+}
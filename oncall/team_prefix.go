@@ -0,0 +1,97 @@
+package oncall
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+// checkTeamPrefix makes sure a team is inside the namespace set by the
+// provider's default_team_prefix, which is how teams of different tenants of
+// an oncall instance are kept apart
+func checkTeamPrefix(prefix, teamName string) error {
+	if prefix == "" || strings.HasPrefix(teamName, prefix) {
+		return nil
+	}
+	return fmt.Errorf("Team %q is outside of the %q prefix set by the provider's %s, did you mean %q?", teamName, prefix, providerFieldDefaultTeamPrefix, prefix+teamName)
+}
+
+// teamPrefixCustomizeDiff checks at plan time that the teams in the given team
+// name fields are inside the provider's default_team_prefix
+func teamPrefixCustomizeDiff(teamFields ...string) schema.CustomizeDiffFunc {
+	return func(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+		meta, ok := m.(*providerMeta)
+		if !ok || meta.teamPrefix == "" {
+			return nil
+		}
+
+		for _, field := range teamFields {
+			if !d.NewValueKnown(field) || d.Get(field).(string) == "" {
+				continue
+			}
+			if err := checkTeamPrefix(meta.teamPrefix, d.Get(field).(string)); err != nil {
+				return fmt.Errorf("Invalid %s: %s", field, err)
+			}
+		}
+		return nil
+	}
+}
+
+// teamPrefixDiags is the error diagnostic for data sources looking up a team
+// outside of the provider's default_team_prefix
+func teamPrefixDiags(m interface{}, field, teamName string) diag.Diagnostics {
+	meta, ok := m.(*providerMeta)
+	if !ok {
+		return nil
+	}
+	return diagFromErrf(checkTeamPrefix(meta.teamPrefix, teamName), "Invalid %s", field)
+}
+
+// requestTeam is the team an API request is about, from its path or from the
+// team filter of its query. Empty if the request isn't about a single team.
+func requestTeam(req *http.Request) string {
+	path := strings.TrimPrefix(req.URL.EscapedPath(), "/")
+	if i := strings.Index(path, "api/v0/teams/"); i != -1 {
+		team := strings.SplitN(path[i+len("api/v0/teams/"):], "/", 2)[0]
+		if unescaped, err := url.PathUnescape(team); err == nil {
+			return unescaped
+		}
+		return team
+	}
+	for _, param := range []string{"team", "team__eq"} {
+		if team := req.URL.Query().Get(param); team != "" {
+			return team
+		}
+	}
+	return ""
+}
+
+// checkRequestTeamPrefix refuses API requests about teams outside of the
+// provider's default_team_prefix, so a reference that slipped past plan time
+// checks (e.g. from an import) still can't touch another tenant's teams
+func checkRequestTeamPrefix(prefix string, req *http.Request) error {
+	team := requestTeam(req)
+	if prefix == "" || team == "" {
+		return nil
+	}
+	return checkTeamPrefix(prefix, team)
+}
+
+// teamPrefixRoundTripper keeps the requests to teams inside of the provider's
+// default_team_prefix
+type teamPrefixRoundTripper struct {
+	teamPrefix string
+	proxied    http.RoundTripper
+}
+
+func (tp teamPrefixRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := checkRequestTeamPrefix(tp.teamPrefix, req); err != nil {
+		return nil, err
+	}
+	return tp.proxied.RoundTrip(req)
+}
@@ -0,0 +1,98 @@
+package oncall
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_checkRequestTeamPrefix(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefix  string
+		url     string
+		wantErr bool
+	}{
+		{
+			name: "No prefix",
+			url:  "https://oncall/api/v0/teams/checkout",
+		},
+		{
+			name:   "Team inside the prefix",
+			prefix: "payments--",
+			url:    "https://oncall/api/v0/teams/payments--checkout/rosters",
+		},
+		{
+			name:    "Team outside the prefix",
+			prefix:  "payments--",
+			url:     "https://oncall/api/v0/teams/search--indexing/rosters",
+			wantErr: true,
+		},
+		{
+			name:    "Escaped team outside the prefix",
+			prefix:  "payments--",
+			url:     "https://oncall/api/v0/teams/search%2Fpayments--",
+			wantErr: true,
+		},
+		{
+			name:    "Events of a team outside the prefix",
+			prefix:  "payments--",
+			url:     "https://oncall/api/v0/events?team__eq=search--indexing&role__eq=primary",
+			wantErr: true,
+		},
+		{
+			name:   "Request about no team",
+			prefix: "payments--",
+			url:    "https://oncall/api/v0/roles",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			if err != nil {
+				t.Fatalf("http.NewRequest() error = %v", err)
+			}
+			if err := checkRequestTeamPrefix(tt.prefix, req); (err != nil) != tt.wantErr {
+				t.Errorf("checkRequestTeamPrefix() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_teamPrefixCustomizeDiff(t *testing.T) {
+	r := &schema.Resource{
+		Schema:        resourceTeamMember().Schema,
+		CustomizeDiff: teamPrefixCustomizeDiff(teamMemberFieldTeam),
+	}
+	meta := &providerMeta{teamPrefix: "payments--"}
+
+	tests := []struct {
+		name    string
+		team    string
+		wantErr bool
+	}{
+		{
+			name: "Team inside the prefix",
+			team: "payments--checkout",
+		},
+		{
+			name:    "Team outside the prefix",
+			team:    "checkout",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Diff(context.Background(), nil, terraform.NewResourceConfigRaw(map[string]interface{}{
+				teamMemberFieldTeam:     tt.team,
+				teamMemberFieldUsername: "alice",
+			}), meta)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Diff() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
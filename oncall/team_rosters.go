@@ -0,0 +1,162 @@
+package oncall
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+// teamRosterResource is the schema of the roster blocks on oncall_team, the
+// same fields as oncall_roster minus the team
+func teamRosterResource() *schema.Resource {
+	return &schema.Resource{
+		Schema: map[string]*schema.Schema{
+			rosterFieldName: &schema.Schema{
+				Type:        schema.TypeString,
+				Required:    true,
+				Description: "Name of the roster",
+			},
+			rosterFieldMembers: &schema.Schema{
+				Type:        schema.TypeSet,
+				Description: "List of usernames which should be added to the roster",
+				Required:    true,
+				Elem:        usernameElem(),
+				Set:         hashUsername,
+			},
+		},
+	}
+}
+
+// teamRostersFromSet turns the roster blocks into a map of roster name to members
+func teamRostersFromSet(rosters *schema.Set) (map[string][]string, error) {
+	ret := map[string][]string{}
+	for _, rosterRaw := range rosters.List() {
+		roster := rosterRaw.(map[string]interface{})
+		name := roster[rosterFieldName].(string)
+		if _, ok := ret[name]; ok {
+			return nil, errors.Errorf("Roster %q is defined more than once", name)
+		}
+
+		members := []string{}
+		for _, m := range roster[rosterFieldMembers].(*schema.Set).List() {
+			members = append(members, m.(string))
+		}
+		sort.Strings(members)
+		ret[name] = members
+	}
+	return ret, nil
+}
+
+// resourceTeamCustomizeDiff rejects roster blocks which share a name, as a
+// roster can only have one set of members
+func resourceTeamCustomizeDiff(ctx context.Context, d *schema.ResourceDiff, m interface{}) error {
+	_, err := teamRostersFromSet(d.Get(teamFieldRoster).(*schema.Set))
+	return err
+}
+
+// diffTeamRosters works out which rosters need to be created, have their
+// members updated, or be removed to go from the old rosters to the new ones
+func diffTeamRosters(old, new map[string][]string) (create, update, remove []string) {
+	create, update, remove = []string{}, []string{}, []string{}
+	for name, members := range new {
+		oldMembers, ok := old[name]
+		if !ok {
+			create = append(create, name)
+		} else if strings.Join(oldMembers, ",") != strings.Join(members, ",") {
+			update = append(update, name)
+		}
+	}
+	for name := range old {
+		if _, ok := new[name]; !ok {
+			remove = append(remove, name)
+		}
+	}
+	sort.Strings(create)
+	sort.Strings(update)
+	sort.Strings(remove)
+	return
+}
+
+// updateTeamRosters creates, updates and removes the team's rosters so that
+// they match the roster blocks. Rosters the resource has never managed are
+// left alone, so standalone oncall_roster resources keep working.
+func updateTeamRosters(c *oncall.Client, d *schema.ResourceData, teamName string) error {
+	oldRaw, newRaw := d.GetChange(teamFieldRoster)
+	old, err := teamRostersFromSet(oldRaw.(*schema.Set))
+	if err != nil {
+		return err
+	}
+	new, err := teamRostersFromSet(newRaw.(*schema.Set))
+	if err != nil {
+		return err
+	}
+
+	create, update, remove := diffTeamRosters(old, new)
+	for _, rosterName := range remove {
+		traceLog("Going to delete team roster %s/%s", teamName, rosterName)
+		err := c.DeleteRoster(teamName, rosterName)
+		if err != nil {
+			return errors.Wrapf(err, "Deleting roster %s/%s", teamName, rosterName)
+		}
+	}
+	for _, rosterName := range create {
+		traceLog("Going to create team roster %s/%s", teamName, rosterName)
+		_, err := c.CreateRoster(teamName, rosterName)
+		if err != nil {
+			if e, ok := parseAPIError(err); ok && e.alreadyExists() {
+				return errors.Wrapf(err, "Roster %s/%s already exists, if it is managed by an oncall_roster resource it can not also be a roster block", teamName, rosterName)
+			}
+			return errors.Wrapf(err, "Creating roster %s/%s", teamName, rosterName)
+		}
+	}
+	for _, rosterName := range append(create, update...) {
+		traceLog("Going to set team roster %s/%s members to %v", teamName, rosterName, new[rosterName])
+		err := c.SetRosterUsers(teamName, rosterName, new[rosterName])
+		if err != nil {
+			return errors.Wrapf(err, "Setting roster %s/%s members", teamName, rosterName)
+		}
+	}
+	return nil
+}
+
+// readTeamRosters refreshes the rosters the resource manages, dropping any
+// which have been deleted outside of terraform
+func readTeamRosters(c *oncall.Client, d *schema.ResourceData, teamName string) error {
+	managed, err := teamRostersFromSet(d.Get(teamFieldRoster).(*schema.Set))
+	if err != nil {
+		return err
+	}
+	if len(managed) == 0 {
+		return nil
+	}
+
+	existing, err := c.GetRosters(teamName)
+	if err != nil {
+		return err
+	}
+
+	rosters := []interface{}{}
+	for _, rosterName := range existing {
+		if _, ok := managed[rosterName]; !ok {
+			continue
+		}
+
+		members, err := c.GetRosterUsers(teamName, rosterName)
+		if err != nil {
+			return errors.Wrapf(err, "Getting roster %s/%s members", teamName, rosterName)
+		}
+		memberSet := schema.NewSet(schema.HashString, nil)
+		for _, m := range members {
+			memberSet.Add(m)
+		}
+		rosters = append(rosters, map[string]interface{}{
+			rosterFieldName:    rosterName,
+			rosterFieldMembers: memberSet,
+		})
+	}
+	return d.Set(teamFieldRoster, rosters)
+}
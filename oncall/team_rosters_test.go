@@ -0,0 +1,64 @@
+package oncall
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_diffTeamRosters(t *testing.T) {
+	tests := []struct {
+		name       string
+		old        map[string][]string
+		new        map[string][]string
+		wantCreate []string
+		wantUpdate []string
+		wantRemove []string
+	}{
+		{
+			name:       "No rosters",
+			old:        map[string][]string{},
+			new:        map[string][]string{},
+			wantCreate: []string{},
+			wantUpdate: []string{},
+			wantRemove: []string{},
+		},
+		{
+			name:       "New rosters",
+			old:        map[string][]string{},
+			new:        map[string][]string{"primary": {"alice"}, "backup": {"bob"}},
+			wantCreate: []string{"backup", "primary"},
+			wantUpdate: []string{},
+			wantRemove: []string{},
+		},
+		{
+			name:       "Members changed",
+			old:        map[string][]string{"primary": {"alice"}, "backup": {"bob"}},
+			new:        map[string][]string{"primary": {"alice", "carol"}, "backup": {"bob"}},
+			wantCreate: []string{},
+			wantUpdate: []string{"primary"},
+			wantRemove: []string{},
+		},
+		{
+			name:       "Renamed roster",
+			old:        map[string][]string{"primary": {"alice"}},
+			new:        map[string][]string{"main": {"alice"}},
+			wantCreate: []string{"main"},
+			wantUpdate: []string{},
+			wantRemove: []string{"primary"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotCreate, gotUpdate, gotRemove := diffTeamRosters(tt.old, tt.new)
+			if !reflect.DeepEqual(gotCreate, tt.wantCreate) {
+				t.Errorf("diffTeamRosters() create = %v, want %v", gotCreate, tt.wantCreate)
+			}
+			if !reflect.DeepEqual(gotUpdate, tt.wantUpdate) {
+				t.Errorf("diffTeamRosters() update = %v, want %v", gotUpdate, tt.wantUpdate)
+			}
+			if !reflect.DeepEqual(gotRemove, tt.wantRemove) {
+				t.Errorf("diffTeamRosters() remove = %v, want %v", gotRemove, tt.wantRemove)
+			}
+		})
+	}
+}
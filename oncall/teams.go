@@ -0,0 +1,297 @@
+package oncall
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/pkg/errors"
+)
+
+// team is a team as returned by the oncall API, including the fields that the
+// oncall client does not know about
+type team struct {
+	oncall.Team
+	Description string `json:"description"`
+	// IrisEnabled is nil on oncall versions without iris paging
+	IrisEnabled *apiBool `json:"iris_enabled"`
+	// NotifyManagerAfter is nil on oncall instances without manager escalation
+	NotifyManagerAfter *int `json:"notify_manager_after"`
+	// SlackChannelNotifications is only present on oncall versions that post
+	// notifications to a channel of their own
+	SlackChannelNotifications apiOptionalString `json:"slack_channel_notifications"`
+}
+
+// apiBool is a boolean oncall may return as 0 or 1, as it is read straight
+// out of a MySQL tinyint
+type apiBool bool
+
+func (b *apiBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", "1":
+		*b = true
+	case "false", "0", "null":
+		*b = false
+	default:
+		return errors.Errorf("Can't read %s as a boolean", data)
+	}
+	return nil
+}
+
+// apiOptionalString is a string field only some oncall versions return, which
+// tells a field that is missing apart from one that is null
+type apiOptionalString struct {
+	present bool
+	value   string
+}
+
+func (s *apiOptionalString) UnmarshalJSON(data []byte) error {
+	s.present = true
+	if string(data) == "null" {
+		s.value = ""
+		return nil
+	}
+	return json.Unmarshal(data, &s.value)
+}
+
+// teamExtras are the team settings the oncall client does not know about. Only
+// the fields which are set get sent, so older oncall versions aren't sent
+// fields they don't support. Oncall has no per team privacy settings (whether
+// the calendar is public or full names are shown), every signed in user can
+// see every team's calendar, so there are none to add here.
+type teamExtras struct {
+	Description        *string `json:"description,omitempty"`
+	IrisEnabled        *bool   `json:"iris_enabled,omitempty"`
+	NotifyManagerAfter *int    `json:"notify_manager_after,omitempty"`
+	// SlackChannelNotifications is only sent to oncall versions that return it
+	SlackChannelNotifications *string `json:"slack_channel_notifications,omitempty"`
+}
+
+func getTeam(c *oncall.Client, name string) (team, error) {
+	t := team{}
+	_, err := c.Get("/api/v0/teams/"+url.PathEscape(name), &t)
+	return t, errors.Wrapf(err, "Fetching team details for %s", name)
+}
+
+func updateTeamExtras(c *oncall.Client, name string, extras teamExtras) error {
+	if extras == (teamExtras{}) {
+		return nil
+	}
+
+	var t team
+	if extras.Description != nil || extras.SlackChannelNotifications != nil {
+		unlock := teamDescriptionLocks.lock(name)
+		defer unlock()
+		var err error
+		t, err = getTeam(c, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	if extras.SlackChannelNotifications != nil && !t.SlackChannelNotifications.present {
+		warnLog("Oncall doesn't support a notifications slack channel, not setting one for team %s", name)
+		extras.SlackChannelNotifications = nil
+		if extras == (teamExtras{}) {
+			return nil
+		}
+	}
+
+	if extras.Description != nil {
+		// Keep the annotations managed by other resources
+		parsed := parseTeamDescription(t.Description)
+		parsed.text = *extras.Description
+		description := parsed.String()
+		extras.Description = &description
+	}
+
+	traceLog("Going to update team %s extra settings", name)
+	_, err := c.Put("/api/v0/teams/"+url.PathEscape(name), extras, nil)
+	return errors.Wrapf(err, "Updating team %s", name)
+}
+
+// teamAnnotationKind is a kind of annotation line kept at the end of team
+// descriptions, for settings oncall has nowhere else to keep. Each line is the
+// kind's prefix followed by a key and its value, e.g.
+// "slack-usergroup: primary @platform-oncall".
+type teamAnnotationKind struct {
+	prefix string
+	// parse reads the key and value out of what follows the prefix, ok is
+	// false for prose that happens to start with the prefix
+	parse func(rest string) (key, value string, ok bool)
+	// format writes the key and value back as what follows the prefix
+	format func(key, value string) string
+}
+
+// teamAnnotationKinds are the registered kinds of annotations by prefix
+var teamAnnotationKinds = map[string]teamAnnotationKind{}
+
+// registerTeamAnnotation adds a kind of annotation kept in team descriptions,
+// every team description update keeps the annotations of every kind
+func registerTeamAnnotation(kind teamAnnotationKind) {
+	teamAnnotationKinds[kind.prefix] = kind
+}
+
+// teamDescriptionLocks serialize changes to each team's description, as the
+// annotations of different resources on a team are read, modified and
+// written back as a whole
+var teamDescriptionLocks = newKeyedMutex()
+
+// teamDescription is a team description split into the text written for
+// people and the annotations, by the prefix of their kind and their key
+type teamDescription struct {
+	text        string
+	annotations map[string]map[string]string
+}
+
+func parseTeamDescription(description string) teamDescription {
+	parsed := teamDescription{annotations: map[string]map[string]string{}}
+	for prefix := range teamAnnotationKinds {
+		parsed.annotations[prefix] = map[string]string{}
+	}
+
+	lines := []string{}
+	for _, line := range strings.Split(description, "\n") {
+		if prefix, key, value, ok := parseTeamAnnotation(line); ok {
+			parsed.annotations[prefix][key] = value
+			continue
+		}
+		lines = append(lines, line)
+	}
+	parsed.text = strings.TrimRight(strings.Join(lines, "\n"), "\n")
+	return parsed
+}
+
+func parseTeamAnnotation(line string) (prefix, key, value string, ok bool) {
+	for prefix, kind := range teamAnnotationKinds {
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		key, value, ok = kind.parse(strings.TrimPrefix(line, prefix))
+		if ok {
+			return prefix, key, value, true
+		}
+	}
+	return "", "", "", false
+}
+
+// String writes the description back with the annotations after the text, a
+// block per kind, with kinds sorted by prefix and annotations by key so the
+// description doesn't churn whichever resource last changed it
+func (td teamDescription) String() string {
+	prefixes := make([]string, 0, len(teamAnnotationKinds))
+	for prefix := range teamAnnotationKinds {
+		prefixes = append(prefixes, prefix)
+	}
+	sort.Strings(prefixes)
+
+	blocks := []string{}
+	if td.text != "" {
+		blocks = append(blocks, td.text)
+	}
+	for _, prefix := range prefixes {
+		annotations := td.annotations[prefix]
+		keys := make([]string, 0, len(annotations))
+		for key := range annotations {
+			keys = append(keys, key)
+		}
+		if len(keys) == 0 {
+			continue
+		}
+		sort.Strings(keys)
+
+		lines := make([]string, 0, len(keys))
+		for _, key := range keys {
+			lines = append(lines, prefix+" "+teamAnnotationKinds[prefix].format(key, annotations[key]))
+		}
+		blocks = append(blocks, strings.Join(lines, "\n"))
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// getTeamAnnotations returns the team's annotations of one kind by key
+func getTeamAnnotations(c *oncall.Client, teamName, prefix string) (map[string]string, error) {
+	t, err := getTeam(c, teamName)
+	if err != nil {
+		return nil, err
+	}
+	return parseTeamDescription(t.Description).annotations[prefix], nil
+}
+
+// updateTeamAnnotations lets update change the team's annotations of one kind
+// in place, and writes the description back if that changed it
+func updateTeamAnnotations(c *oncall.Client, teamName, prefix string, update func(annotations map[string]string) error) error {
+	unlock := teamDescriptionLocks.lock(teamName)
+	defer unlock()
+
+	t, err := getTeam(c, teamName)
+	if err != nil {
+		return err
+	}
+	parsed := parseTeamDescription(t.Description)
+	err = update(parsed.annotations[prefix])
+	if err != nil {
+		return err
+	}
+
+	description := parsed.String()
+	if description == t.Description {
+		return nil
+	}
+	traceLog("Going to update the %s annotations of team %s", prefix, teamName)
+	_, err = c.Put("/api/v0/teams/"+url.PathEscape(teamName), teamExtras{Description: &description}, nil)
+	return errors.Wrapf(err, "Updating the %s annotations of team %s", strings.TrimSuffix(prefix, ":"), teamName)
+}
+
+// teamCalendarURL is the team's calendar page in the oncall UI
+func teamCalendarURL(endpoint, teamName string) string {
+	return fmt.Sprintf("%s/team/%s/calendar", endpoint, url.PathEscape(teamName))
+}
+
+// teamICalURL is the iCal feed of the team's on call events, limited to the
+// role if one is given
+func teamICalURL(endpoint, teamName, role string) string {
+	u := fmt.Sprintf("%s/api/v0/teams/%s/ical", endpoint, url.PathEscape(teamName))
+	if role != "" {
+		u += "?roles=" + url.QueryEscape(role)
+	}
+	return u
+}
+
+// archiveTeam takes a team out of use without deleting it, so its calendar
+// history is kept for audits. The team is renamed out of the way, then its
+// rosters and admins are removed. It returns the team's new name.
+func archiveTeam(c *oncall.Client, name string, now time.Time) (string, error) {
+	existing, err := c.GetTeam(name)
+	if err != nil {
+		return "", errors.Wrapf(err, "Fetching team %s to archive it", name)
+	}
+
+	archivedName := fmt.Sprintf("%s-archived-%d", name, now.Unix())
+	existing.TeamConfig.Name = archivedName
+	traceLog("Going to archive team %s as %s", name, archivedName)
+	_, err = c.UpdateTeam(name, existing.TeamConfig)
+	if err != nil {
+		return "", errors.Wrapf(err, "Renaming team %s to %s to archive it", name, archivedName)
+	}
+
+	rosters, err := c.GetRosters(archivedName)
+	if err != nil {
+		return archivedName, errors.Wrapf(err, "Fetching rosters of archived team %s", archivedName)
+	}
+	for _, roster := range rosters {
+		traceLog("Going to remove roster %s from archived team %s", roster, archivedName)
+		err = c.DeleteRoster(archivedName, roster)
+		if err != nil {
+			return archivedName, errors.Wrapf(err, "Removing roster %s from archived team %s", roster, archivedName)
+		}
+	}
+
+	// Admins go last, as the provider's own user may need to be one to do the rest
+	err = c.SetTeamAdmins(archivedName, []string{})
+	return archivedName, errors.Wrapf(err, "Removing admins from archived team %s", archivedName)
+}
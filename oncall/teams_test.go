@@ -0,0 +1,297 @@
+package oncall
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+)
+
+func Test_teamURLs(t *testing.T) {
+	tests := []struct {
+		name         string
+		team         string
+		role         string
+		wantCalendar string
+		wantICal     string
+	}{
+		{
+			name:         "Team",
+			team:         "platform",
+			wantCalendar: "https://oncall.example.com/team/platform/calendar",
+			wantICal:     "https://oncall.example.com/api/v0/teams/platform/ical",
+		},
+		{
+			name:         "Role of a team with spaces in its name",
+			team:         "site reliability",
+			role:         "primary",
+			wantCalendar: "https://oncall.example.com/team/site%20reliability/calendar",
+			wantICal:     "https://oncall.example.com/api/v0/teams/site%20reliability/ical?roles=primary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := teamCalendarURL("https://oncall.example.com", tt.team); got != tt.wantCalendar {
+				t.Errorf("teamCalendarURL() = %s, want %s", got, tt.wantCalendar)
+			}
+			if got := teamICalURL("https://oncall.example.com", tt.team, tt.role); got != tt.wantICal {
+				t.Errorf("teamICalURL() = %s, want %s", got, tt.wantICal)
+			}
+		})
+	}
+}
+
+func Test_archiveTeam(t *testing.T) {
+	requests := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v0/teams/team":
+			w.Write([]byte(`{"name": "team", "scheduling_timezone": "UTC"}`))
+		case "GET /api/v0/teams/team-archived-100":
+			w.Write([]byte(`{"name": "team-archived-100", "scheduling_timezone": "UTC"}`))
+		case "GET /api/v0/teams/team-archived-100/rosters":
+			w.Write([]byte(`{"primary": {}}`))
+		case "GET /api/v0/teams/team-archived-100/admins":
+			w.Write([]byte(`["alice"]`))
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	got, err := archiveTeam(c, "team", time.Unix(100, 0))
+	if err != nil {
+		t.Fatalf("archiveTeam() error = %v", err)
+	}
+	if got != "team-archived-100" {
+		t.Errorf("archiveTeam() = %s, want team-archived-100", got)
+	}
+
+	want := []string{
+		"GET /api/v0/teams/team",
+		"PUT /api/v0/teams/team",
+		"GET /api/v0/teams/team-archived-100",
+		"GET /api/v0/teams/team-archived-100/rosters",
+		"DELETE /api/v0/teams/team-archived-100/rosters/primary",
+		"GET /api/v0/teams/team-archived-100/admins",
+		"DELETE /api/v0/teams/team-archived-100/admins/alice",
+	}
+	if !reflect.DeepEqual(requests, want) {
+		t.Errorf("archiveTeam() requests = %v, want %v", requests, want)
+	}
+}
+
+func Test_getTeamIrisEnabled(t *testing.T) {
+	enabled, disabled := apiBool(true), apiBool(false)
+	tests := []struct {
+		name    string
+		body    string
+		want    *apiBool
+		wantErr bool
+	}{
+		{
+			name: "Oncall without iris paging",
+			body: `{"name": "team"}`,
+		},
+		{
+			name: "Enabled as a MySQL tinyint",
+			body: `{"name": "team", "iris_enabled": 1}`,
+			want: &enabled,
+		},
+		{
+			name: "Disabled as a boolean",
+			body: `{"name": "team", "iris_enabled": false}`,
+			want: &disabled,
+		},
+		{
+			name:    "Not a boolean",
+			body:    `{"name": "team", "iris_enabled": "yes"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+
+			got, err := getTeam(c, "team")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getTeam() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got.IrisEnabled, tt.want) {
+				t.Errorf("getTeam() IrisEnabled = %v, want %v", got.IrisEnabled, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getTeamNotifyManagerAfter(t *testing.T) {
+	halfHour, never := 1800, 0
+	tests := []struct {
+		name string
+		body string
+		want *int
+	}{
+		{
+			name: "Oncall without manager escalation",
+			body: `{"name": "team"}`,
+		},
+		{
+			name: "After half an hour",
+			body: `{"name": "team", "notify_manager_after": 1800}`,
+			want: &halfHour,
+		},
+		{
+			name: "Never",
+			body: `{"name": "team", "notify_manager_after": 0}`,
+			want: &never,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+
+			got, err := getTeam(c, "team")
+			if err != nil {
+				t.Fatalf("getTeam() error = %v", err)
+			}
+			if !reflect.DeepEqual(got.NotifyManagerAfter, tt.want) {
+				t.Errorf("getTeam() NotifyManagerAfter = %v, want %v", got.NotifyManagerAfter, tt.want)
+			}
+		})
+	}
+}
+
+func Test_updateTeamExtrasSlackChannelNotifications(t *testing.T) {
+	channel := "#platform-alerts"
+	enabled := true
+	tests := []struct {
+		name     string
+		team     string
+		extras   teamExtras
+		wantPuts []string
+	}{
+		{
+			name:     "Supported",
+			team:     `{"name": "team", "slack_channel_notifications": null}`,
+			extras:   teamExtras{SlackChannelNotifications: &channel},
+			wantPuts: []string{`{"slack_channel_notifications":"#platform-alerts"}`},
+		},
+		{
+			name:     "Not supported",
+			team:     `{"name": "team"}`,
+			extras:   teamExtras{SlackChannelNotifications: &channel},
+			wantPuts: []string{},
+		},
+		{
+			name:     "Not supported with other settings",
+			team:     `{"name": "team"}`,
+			extras:   teamExtras{SlackChannelNotifications: &channel, IrisEnabled: &enabled},
+			wantPuts: []string{`{"iris_enabled":true}`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			puts := []string{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method == http.MethodPut {
+					body, _ := ioutil.ReadAll(r.Body)
+					puts = append(puts, string(body))
+					return
+				}
+				w.Write([]byte(tt.team))
+			}))
+			defer server.Close()
+
+			c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+			if err != nil {
+				t.Fatalf("oncall.New() error = %v", err)
+			}
+
+			err = updateTeamExtras(c, "team", tt.extras)
+			if err != nil {
+				t.Fatalf("updateTeamExtras() error = %v", err)
+			}
+			if !reflect.DeepEqual(puts, tt.wantPuts) {
+				t.Errorf("updateTeamExtras() sent %v, want %v", puts, tt.wantPuts)
+			}
+		})
+	}
+}
+
+func Test_updateTeamAnnotationsKeepsOrder(t *testing.T) {
+	description := "Keeps the lights on\n\n" +
+		"schedule-description: platform/primary EU covers nights\n\n" +
+		"slack-usergroup: primary @platform-oncall\nslack-usergroup: secondary @platform-backup"
+	puts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			json.NewEncoder(w).Encode(map[string]interface{}{"name": "team", "description": description})
+		case "PUT":
+			puts++
+			body := map[string]string{}
+			json.NewDecoder(r.Body).Decode(&body)
+			description = body["description"]
+		}
+	}))
+	defer server.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: server.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+
+	// Setting an annotation to what it is already leaves the description be
+	err = setTeamSlackUsergroup(c, "team", "primary", "@platform-oncall")
+	if err != nil {
+		t.Fatalf("setTeamSlackUsergroup() error = %v", err)
+	}
+	if puts != 0 {
+		t.Errorf("setTeamSlackUsergroup() updated the team %d times, want none", puts)
+	}
+
+	// A new annotation goes in its place rather than after the last one set
+	err = setTeamSlackUsergroup(c, "team", "oncall", "@platform-all")
+	if err != nil {
+		t.Fatalf("setTeamSlackUsergroup() error = %v", err)
+	}
+	want := "Keeps the lights on\n\n" +
+		"schedule-description: platform/primary EU covers nights\n\n" +
+		"slack-usergroup: oncall @platform-all\nslack-usergroup: primary @platform-oncall\nslack-usergroup: secondary @platform-backup"
+	if description != want {
+		t.Errorf("setTeamSlackUsergroup() description = %q, want %q", description, want)
+	}
+
+	err = setScheduleDescription(c, "team", "platform", "primary", "primary", "EU covers nights")
+	if err != nil {
+		t.Fatalf("setScheduleDescription() error = %v", err)
+	}
+	if description != want {
+		t.Errorf("setScheduleDescription() description = %q, want %q", description, want)
+	}
+}
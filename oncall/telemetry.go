@@ -0,0 +1,473 @@
+package oncall
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/pkg/errors"
+)
+
+const (
+	telemetryServiceName   = "terraform-provider-oncall"
+	telemetryExportTimeout = 5 * time.Second
+
+	// Operations finishing within telemetryExportDelay of each other are
+	// exported together, at most telemetryExportBatch spans per request
+	telemetryExportDelay = time.Second
+	telemetryExportBatch = 1000
+	// telemetryMaxSpans bounds the spans waiting on a slow collector, any
+	// more are dropped
+	telemetryMaxSpans = 20000
+
+	// OTLP span kinds and status codes
+	otlpSpanKindInternal = 1
+	otlpSpanKindClient   = 3
+	otlpStatusError      = 2
+
+	otlpTemporalityCumulative = 2
+)
+
+// telemetryDurationBounds are the histogram buckets for api call latency, in milliseconds
+var telemetryDurationBounds = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// telemetry collects spans and metrics for calls to oncall and exports them to
+// an OTLP/HTTP collector as JSON. Exports run in the background so a slow or
+// missing collector never holds up an operation. A nil *telemetry records
+// nothing.
+type telemetry struct {
+	endpoint   string
+	httpClient *http.Client
+	start      time.Time
+	// exportQueued wakes the exporter, holding at most one wake up so the
+	// operations finishing while it exports are coalesced into one export
+	exportQueued chan struct{}
+
+	mu           sync.Mutex
+	spans        []otlpSpan
+	droppedSpans int
+	apiCalls     map[apiCallKey]*apiCallStats
+	retries      map[string]int64
+}
+
+// apiCallKey is the set of metric attributes api calls are aggregated by
+type apiCallKey struct {
+	resourceType string
+	method       string
+	statusCode   int
+	failed       bool
+}
+
+type apiCallStats struct {
+	count        int64
+	durationSum  float64
+	bucketCounts []int64
+}
+
+// telemetryOperation is a single resource operation (e.g. oncall_team create),
+// it is carried in the context so api calls can be attributed to it
+type telemetryOperation struct {
+	t            *telemetry
+	resourceType string
+	traceID      string
+	spanID       string
+}
+
+type telemetryOperationKey struct{}
+
+func newTelemetry(endpoint string) *telemetry {
+	if endpoint == "" {
+		return nil
+	}
+	t := &telemetry{
+		endpoint:     strings.TrimSuffix(endpoint, "/"),
+		httpClient:   &http.Client{Timeout: telemetryExportTimeout},
+		start:        time.Now(),
+		exportQueued: make(chan struct{}, 1),
+		apiCalls:     map[apiCallKey]*apiCallStats{},
+		retries:      map[string]int64{},
+	}
+	go t.exporter()
+
+	telemetries.Lock()
+	telemetries.all = append(telemetries.all, t)
+	telemetries.Unlock()
+	return t
+}
+
+// telemetries are the telemetry of every configured provider, so what they
+// haven't exported yet can be before the provider exits
+var telemetries = struct {
+	sync.Mutex
+	all []*telemetry
+}{}
+
+// FlushTelemetry exports the spans and metrics still waiting on the export
+// delay, it is called as the provider exits as Terraform is done with it
+func FlushTelemetry() {
+	telemetries.Lock()
+	defer telemetries.Unlock()
+	for _, t := range telemetries.all {
+		t.export()
+	}
+}
+
+// exportOnStop exports once Terraform stops the provider, e.g. when an apply
+// is interrupted, so the telemetry of the operations it cut short isn't lost
+func (t *telemetry) exportOnStop(ctx context.Context) {
+	stop, ok := schema.StopContext(ctx)
+	if t == nil || !ok {
+		return
+	}
+	go func() {
+		<-stop.Done()
+		t.export()
+	}()
+}
+
+// exporter exports whenever an operation finishes, waiting a moment first to
+// batch up the operations Terraform runs in parallel
+func (t *telemetry) exporter() {
+	for range t.exportQueued {
+		time.Sleep(telemetryExportDelay)
+		t.export()
+	}
+}
+
+// queueExport asks the exporter to export soon, without waiting for it
+func (t *telemetry) queueExport() {
+	select {
+	case t.exportQueued <- struct{}{}:
+	default:
+	}
+}
+
+func telemetryOperationFrom(ctx context.Context) *telemetryOperation {
+	op, _ := ctx.Value(telemetryOperationKey{}).(*telemetryOperation)
+	return op
+}
+
+func instrumentOperation(resourceType, operation string, f func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics) func(context.Context, *schema.ResourceData, interface{}) diag.Diagnostics {
+	if f == nil {
+		return nil
+	}
+	return func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		meta, ok := m.(*providerMeta)
+		if !ok || meta.telemetry == nil {
+			return f(ctx, d, m)
+		}
+
+		op := &telemetryOperation{
+			t:            meta.telemetry,
+			resourceType: resourceType,
+			traceID:      randomHex(16),
+			spanID:       randomHex(8),
+		}
+		start := time.Now()
+		diags := f(context.WithValue(ctx, telemetryOperationKey{}, op), d, m)
+
+		span := otlpSpan{
+			TraceID:           op.traceID,
+			SpanID:            op.spanID,
+			Name:              resourceType + "." + operation,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: fmt.Sprint(start.UnixNano()),
+			EndTimeUnixNano:   fmt.Sprint(time.Now().UnixNano()),
+			Attributes: []otlpAttribute{
+				otlpString("terraform.resource_type", resourceType),
+				otlpString("terraform.operation", operation),
+				otlpString("terraform.resource_id", d.Id()),
+			},
+		}
+		for _, dg := range diags {
+			if dg.Severity == diag.Error {
+				span.Status = &otlpStatus{Code: otlpStatusError, Message: dg.Summary}
+				break
+			}
+		}
+		meta.telemetry.addSpan(span)
+		meta.telemetry.queueExport()
+		return diags
+	}
+}
+
+// telemetryRoundTripper records every call to oncall against the operation
+// making it, for telemetry and for the hints given on errors
+type telemetryRoundTripper struct {
+	proxied http.RoundTripper
+}
+
+func (trt telemetryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := trt.proxied.RoundTrip(req)
+	recordAPICall(req.Context(), req, resp, err, start)
+	recordFailedCall(req.Context(), req, resp)
+	return resp, err
+}
+
+// recordAPICall records a single request to oncall against the operation in ctx
+func recordAPICall(ctx context.Context, req *http.Request, resp *http.Response, err error, start time.Time) {
+	op := telemetryOperationFrom(ctx)
+	if op == nil {
+		return
+	}
+
+	end := time.Now()
+	key := apiCallKey{
+		resourceType: op.resourceType,
+		method:       req.Method,
+		failed:       err != nil,
+	}
+	span := otlpSpan{
+		TraceID:           op.traceID,
+		SpanID:            randomHex(8),
+		ParentSpanID:      op.spanID,
+		Name:              req.Method + " " + req.URL.Path,
+		Kind:              otlpSpanKindClient,
+		StartTimeUnixNano: fmt.Sprint(start.UnixNano()),
+		EndTimeUnixNano:   fmt.Sprint(end.UnixNano()),
+		Attributes: []otlpAttribute{
+			otlpString("terraform.resource_type", op.resourceType),
+			otlpString("http.method", req.Method),
+			otlpString("http.target", req.URL.Path),
+		},
+	}
+	if resp != nil {
+		key.statusCode = resp.StatusCode
+		key.failed = key.failed || resp.StatusCode >= 400
+		span.Attributes = append(span.Attributes, otlpInt("http.status_code", int64(resp.StatusCode)))
+	}
+	if key.failed {
+		msg := ""
+		if err != nil {
+			msg = err.Error()
+		}
+		span.Status = &otlpStatus{Code: otlpStatusError, Message: msg}
+	}
+
+	op.t.mu.Lock()
+	defer op.t.mu.Unlock()
+	op.t.bufferSpan(span)
+	stats, ok := op.t.apiCalls[key]
+	if !ok {
+		stats = &apiCallStats{bucketCounts: make([]int64, len(telemetryDurationBounds)+1)}
+		op.t.apiCalls[key] = stats
+	}
+	ms := float64(end.Sub(start)) / float64(time.Millisecond)
+	stats.count++
+	stats.durationSum += ms
+	stats.bucketCounts[sort.SearchFloat64s(telemetryDurationBounds, ms)]++
+}
+
+// recordRetry counts a retried call to oncall against the operation in ctx
+func recordRetry(ctx context.Context) {
+	op := telemetryOperationFrom(ctx)
+	if op == nil {
+		return
+	}
+	op.t.mu.Lock()
+	defer op.t.mu.Unlock()
+	op.t.retries[op.resourceType]++
+}
+
+func (t *telemetry) addSpan(span otlpSpan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.bufferSpan(span)
+}
+
+// bufferSpan keeps the span for the next export, t.mu must be held
+func (t *telemetry) bufferSpan(span otlpSpan) {
+	if len(t.spans) >= telemetryMaxSpans {
+		t.droppedSpans++
+		return
+	}
+	t.spans = append(t.spans, span)
+}
+
+// export sends the spans collected since the last export, in batches, and the
+// current metrics to the collector. Failures are logged and the spans dropped.
+func (t *telemetry) export() {
+	t.mu.Lock()
+	spans := t.spans
+	t.spans = nil
+	dropped := t.droppedSpans
+	t.droppedSpans = 0
+	metrics := t.metrics()
+	t.mu.Unlock()
+
+	if dropped > 0 {
+		warnLog("Dropped %d spans waiting on the collector at %s", dropped, t.endpoint)
+	}
+
+	resource := otlpResource{Attributes: []otlpAttribute{otlpString("service.name", telemetryServiceName)}}
+	scope := otlpScope{Name: telemetryServiceName}
+
+	for len(spans) > 0 {
+		batch := spans
+		if len(batch) > telemetryExportBatch {
+			batch = batch[:telemetryExportBatch]
+		}
+		spans = spans[len(batch):]
+
+		err := t.post("/v1/traces", map[string]interface{}{
+			"resourceSpans": []interface{}{map[string]interface{}{
+				"resource":   resource,
+				"scopeSpans": []interface{}{map[string]interface{}{"scope": scope, "spans": batch}},
+			}},
+		})
+		if err != nil {
+			warnLog("Exporting traces to %s: %s", t.endpoint, err)
+			break
+		}
+	}
+
+	err := t.post("/v1/metrics", map[string]interface{}{
+		"resourceMetrics": []interface{}{map[string]interface{}{
+			"resource":     resource,
+			"scopeMetrics": []interface{}{map[string]interface{}{"scope": scope, "metrics": metrics}},
+		}},
+	})
+	if err != nil {
+		warnLog("Exporting metrics to %s: %s", t.endpoint, err)
+	}
+}
+
+// metrics builds the cumulative metrics, t.mu must be held
+func (t *telemetry) metrics() []interface{} {
+	startTime, now := fmt.Sprint(t.start.UnixNano()), fmt.Sprint(time.Now().UnixNano())
+
+	requests, durations, retries := []interface{}{}, []interface{}{}, []interface{}{}
+	for key, stats := range t.apiCalls {
+		attributes := []otlpAttribute{
+			otlpString("terraform.resource_type", key.resourceType),
+			otlpString("http.method", key.method),
+			otlpInt("http.status_code", int64(key.statusCode)),
+			otlpBool("error", key.failed),
+		}
+		requests = append(requests, map[string]interface{}{
+			"attributes":        attributes,
+			"startTimeUnixNano": startTime,
+			"timeUnixNano":      now,
+			"asInt":             fmt.Sprint(stats.count),
+		})
+		bucketCounts := make([]string, 0, len(stats.bucketCounts))
+		for _, c := range stats.bucketCounts {
+			bucketCounts = append(bucketCounts, fmt.Sprint(c))
+		}
+		durations = append(durations, map[string]interface{}{
+			"attributes":        attributes,
+			"startTimeUnixNano": startTime,
+			"timeUnixNano":      now,
+			"count":             fmt.Sprint(stats.count),
+			"sum":               stats.durationSum,
+			"bucketCounts":      bucketCounts,
+			"explicitBounds":    telemetryDurationBounds,
+		})
+	}
+	for resourceType, count := range t.retries {
+		retries = append(retries, map[string]interface{}{
+			"attributes":        []otlpAttribute{otlpString("terraform.resource_type", resourceType)},
+			"startTimeUnixNano": startTime,
+			"timeUnixNano":      now,
+			"asInt":             fmt.Sprint(count),
+		})
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"name":        "oncall.api.requests",
+			"description": "Requests made to the oncall API",
+			"unit":        "{request}",
+			"sum":         map[string]interface{}{"dataPoints": requests, "aggregationTemporality": otlpTemporalityCumulative, "isMonotonic": true},
+		},
+		map[string]interface{}{
+			"name":        "oncall.api.duration",
+			"description": "Latency of requests made to the oncall API",
+			"unit":        "ms",
+			"histogram":   map[string]interface{}{"dataPoints": durations, "aggregationTemporality": otlpTemporalityCumulative},
+		},
+		map[string]interface{}{
+			"name":        "oncall.api.retries",
+			"description": "Calls to the oncall API which were retried",
+			"unit":        "{retry}",
+			"sum":         map[string]interface{}{"dataPoints": retries, "aggregationTemporality": otlpTemporalityCumulative, "isMonotonic": true},
+		},
+	}
+}
+
+func (t *telemetry) post(path string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return errors.Wrap(err, "Encoding payload")
+	}
+
+	resp, err := t.httpClient.Post(t.endpoint+path, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Collector responded with %s", resp.Status)
+	}
+	return nil
+}
+
+func randomHex(bytes int) string {
+	b := make([]byte, bytes)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// The OTLP JSON encoding, only the parts that are used here
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpAttribute struct {
+	Key   string                 `json:"key"`
+	Value map[string]interface{} `json:"value"`
+}
+
+func otlpString(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]interface{}{"stringValue": value}}
+}
+
+func otlpInt(key string, value int64) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]interface{}{"intValue": fmt.Sprint(value)}}
+}
+
+func otlpBool(key string, value bool) otlpAttribute {
+	return otlpAttribute{Key: key, Value: map[string]interface{}{"boolValue": value}}
+}
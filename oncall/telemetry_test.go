@@ -0,0 +1,148 @@
+package oncall
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bushelpowered/oncall-client-go/oncall"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+)
+
+func Test_instrumentOperation(t *testing.T) {
+	oncallServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[]`))
+	}))
+	defer oncallServer.Close()
+
+	var mu sync.Mutex
+	exported := map[string]string{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		exported[r.URL.Path] = string(body)
+		mu.Unlock()
+	}))
+	defer collector.Close()
+
+	c, err := oncall.New(&http.Client{}, oncall.Config{Endpoint: oncallServer.URL, AuthMethod: oncall.AuthMethodAPI}, &DefaultLogger{})
+	if err != nil {
+		t.Fatalf("oncall.New() error = %v", err)
+	}
+	meta := &providerMeta{client: c, telemetry: newTelemetry(collector.URL + "/")}
+
+	read := instrumentOperation("oncall_team", "read", func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		_, err := m.(*providerMeta).clientFor(ctx).GetTeams()
+		return diagFromErrf(err, "Getting teams")
+	})
+	d := resourceTeam().TestResourceData()
+	d.SetId("my-team")
+	diags := read(context.Background(), d, meta)
+	if diags.HasError() {
+		t.Fatalf("read() = %v", diags)
+	}
+
+	// The export happens in the background once the operation is done
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		n := len(exported)
+		mu.Unlock()
+		if n == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	traces := exported["/v1/traces"]
+	for _, want := range []string{`"name":"oncall_team.read"`, `"name":"GET /api/v0/teams"`, `"parentSpanId"`} {
+		if !strings.Contains(traces, want) {
+			t.Errorf("Exported traces %s do not contain %s", traces, want)
+		}
+	}
+	metrics := exported["/v1/metrics"]
+	for _, want := range []string{`"name":"oncall.api.requests"`, `"asInt":"1"`, `"stringValue":"oncall_team"`} {
+		if !strings.Contains(metrics, want) {
+			t.Errorf("Exported metrics %s do not contain %s", metrics, want)
+		}
+	}
+}
+
+func Test_instrumentOperationSlowCollector(t *testing.T) {
+	unblock := make(chan struct{})
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer collector.Close()
+	defer close(unblock)
+
+	meta := &providerMeta{telemetry: newTelemetry(collector.URL)}
+	read := instrumentOperation("oncall_team", "read", func(ctx context.Context, d *schema.ResourceData, m interface{}) diag.Diagnostics {
+		return nil
+	})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		read(context.Background(), resourceTeam().TestResourceData(), meta)
+	}
+	if elapsed := time.Since(start); elapsed > telemetryExportDelay {
+		t.Errorf("Operations took %s with a collector that doesn't respond, want them not to wait on it", elapsed)
+	}
+}
+
+func Test_telemetryExportBatches(t *testing.T) {
+	var mu sync.Mutex
+	batches := 0
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/traces" {
+			mu.Lock()
+			batches++
+			mu.Unlock()
+		}
+	}))
+	defer collector.Close()
+
+	tm := newTelemetry(collector.URL)
+	for i := 0; i < telemetryMaxSpans+1; i++ {
+		tm.addSpan(otlpSpan{Name: "span"})
+	}
+	tm.export()
+
+	if want := telemetryMaxSpans / telemetryExportBatch; batches != want {
+		t.Errorf("export() sent %d batches of traces, want %d", batches, want)
+	}
+	if tm.droppedSpans != 0 || len(tm.spans) != 0 {
+		t.Errorf("export() left %d spans and %d dropped, want none", len(tm.spans), tm.droppedSpans)
+	}
+}
+
+func Test_telemetryExportOnStop(t *testing.T) {
+	exported := make(chan string, 2)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		exported <- r.URL.Path
+	}))
+	defer collector.Close()
+
+	tm := newTelemetry(collector.URL)
+	stop, cancel := context.WithCancel(context.Background())
+	tm.exportOnStop(context.WithValue(context.Background(), schema.StopContextKey, stop))
+	tm.addSpan(otlpSpan{Name: "span"})
+
+	cancel()
+	select {
+	case path := <-exported:
+		if path != "/v1/traces" {
+			t.Errorf("Exported %s first, want the traces", path)
+		}
+	case <-time.After(telemetryExportDelay / 2):
+		t.Errorf("Nothing exported when the provider was stopped")
+	}
+}
@@ -0,0 +1,80 @@
+package oncall
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// transportSettings tune how connections to oncall are reused. Large applies
+// make hundreds of requests, and with Go's default of 2 idle connections per
+// host most of them open a new connection, which can exhaust the source ports
+// of a NAT in between.
+type transportSettings struct {
+	maxIdleConns int
+	idleTimeout  time.Duration
+	http2        bool
+	// clientCert is presented to oncall for mutual TLS, e.g. behind a
+	// service mesh that only lets in clients with a certificate
+	clientCert *tls.Certificate
+}
+
+// newTransport is http.DefaultTransport with the connection pool tuned. Every
+// request goes to the same oncall host (or its fallbacks), so the idle
+// connection limit applies per host as well as overall.
+func newTransport(settings transportSettings) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = settings.maxIdleConns
+	transport.MaxIdleConnsPerHost = settings.maxIdleConns
+	transport.IdleConnTimeout = settings.idleTimeout
+	if !settings.http2 {
+		// A non-nil, empty TLSNextProto is how net/http is told not to
+		// negotiate HTTP/2
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+	if settings.clientCert != nil {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{*settings.clientCert}
+	}
+	return transport
+}
+
+// loadClientCertificate loads the client certificate and key, each given as
+// PEM or as the path of a PEM file. Nil if neither is set.
+func loadClientCertificate(cert, key string) (*tls.Certificate, error) {
+	if cert == "" && key == "" {
+		return nil, nil
+	}
+	if cert == "" || key == "" {
+		return nil, errors.New("The client certificate and key must be set together")
+	}
+
+	certPEM, err := readPEM(cert)
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading client certificate")
+	}
+	keyPEM, err := readPEM(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Reading client key")
+	}
+	pair, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, errors.Wrap(err, "Loading client certificate")
+	}
+	return &pair, nil
+}
+
+// readPEM is the value itself if it is PEM, otherwise the file it names
+func readPEM(value string) ([]byte, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "-----BEGIN") {
+		return []byte(value), nil
+	}
+	return ioutil.ReadFile(value)
+}
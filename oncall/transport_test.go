@@ -0,0 +1,176 @@
+package oncall
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_newTransport(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.Proto))
+	}))
+	server.EnableHTTP2 = true
+	server.StartTLS()
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		settings  transportSettings
+		wantProto string
+	}{
+		{
+			name:      "HTTP/2",
+			settings:  transportSettings{maxIdleConns: 20, idleTimeout: time.Minute, http2: true},
+			wantProto: "HTTP/2.0",
+		},
+		{
+			name:      "HTTP/1.1 only",
+			settings:  transportSettings{maxIdleConns: 5, idleTimeout: 30 * time.Second},
+			wantProto: "HTTP/1.1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := newTransport(tt.settings)
+			if transport.MaxIdleConns != tt.settings.maxIdleConns || transport.MaxIdleConnsPerHost != tt.settings.maxIdleConns {
+				t.Errorf("newTransport() idle connections = %d, %d per host, want %d", transport.MaxIdleConns, transport.MaxIdleConnsPerHost, tt.settings.maxIdleConns)
+			}
+			if transport.IdleConnTimeout != tt.settings.idleTimeout {
+				t.Errorf("newTransport() idle timeout = %s, want %s", transport.IdleConnTimeout, tt.settings.idleTimeout)
+			}
+
+			// Trust the test server's certificate
+			transport.TLSClientConfig = server.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+			resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			defer resp.Body.Close()
+			if resp.Proto != tt.wantProto {
+				t.Errorf("Get() protocol = %s, want %s", resp.Proto, tt.wantProto)
+			}
+		})
+	}
+}
+
+// testClientCertificate generates a self signed client certificate and key as PEM
+func testClientCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("ecdsa.GenerateKey() error = %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "terraform"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("x509.MarshalECPrivateKey() error = %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func Test_loadClientCertificate(t *testing.T) {
+	certPEM, keyPEM := testClientCertificate(t)
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	if err := ioutil.WriteFile(certFile, []byte(certPEM), 0600); err != nil {
+		t.Fatalf("Writing certificate: %s", err)
+	}
+	if err := ioutil.WriteFile(keyFile, []byte(keyPEM), 0600); err != nil {
+		t.Fatalf("Writing key: %s", err)
+	}
+	_, otherKeyPEM := testClientCertificate(t)
+
+	tests := []struct {
+		name     string
+		cert     string
+		key      string
+		wantCert bool
+		wantErr  bool
+	}{
+		{name: "Not set"},
+		{name: "PEM", cert: certPEM, key: keyPEM, wantCert: true},
+		{name: "Files", cert: certFile, key: keyFile, wantCert: true},
+		{name: "PEM certificate and key file", cert: certPEM, key: keyFile, wantCert: true},
+		{name: "Only a certificate", cert: certPEM, wantErr: true},
+		{name: "Key of another certificate", cert: certPEM, key: otherKeyPEM, wantErr: true},
+		{name: "Missing file", cert: filepath.Join(dir, "missing.crt"), key: keyFile, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := loadClientCertificate(tt.cert, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadClientCertificate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if (got != nil) != tt.wantCert {
+				t.Errorf("loadClientCertificate() = %v, want a certificate %v", got, tt.wantCert)
+			}
+		})
+	}
+}
+
+func Test_newTransportClientCertificate(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	certPEM, keyPEM := testClientCertificate(t)
+	clientCert, err := loadClientCertificate(certPEM, keyPEM)
+	if err != nil {
+		t.Fatalf("loadClientCertificate() error = %v", err)
+	}
+
+	for _, withCert := range []bool{false, true} {
+		settings := transportSettings{maxIdleConns: 1, idleTimeout: time.Minute}
+		if withCert {
+			settings.clientCert = clientCert
+		}
+		transport := newTransport(settings)
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		// Trust the test server's certificate
+		transport.TLSClientConfig.RootCAs = server.Client().Transport.(*http.Transport).TLSClientConfig.RootCAs
+
+		resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+		if !withCert {
+			if err == nil {
+				resp.Body.Close()
+				t.Errorf("Get() without a client certificate succeeded, want it refused")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Get() with a client certificate error = %v", err)
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "terraform" {
+			t.Errorf("Get() presented %q, want the terraform certificate", body)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package oncall
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"golang.org/x/text/unicode/norm"
+)
+
+// zeroWidthRunes are invisible characters that come along when usernames are
+// copied out of chat, and which oncall treats as part of the name
+var zeroWidthRunes = strings.NewReplacer(
+	"\u200b", "", // zero width space
+	"\u200c", "", // zero width non-joiner
+	"\u200d", "", // zero width joiner
+	"\u2060", "", // word joiner
+	"\ufeff", "", // byte order mark
+)
+
+// normalizeUsername removes surrounding whitespace and zero width characters
+// from a username, and puts it in unicode normal form C as oncall stores it
+func normalizeUsername(username string) string {
+	return norm.NFC.String(strings.TrimFunc(zeroWidthRunes.Replace(username), unicode.IsSpace))
+}
+
+// validateUsername warns when a username is changed by normalizing it, so the
+// change isn't a surprise, and fails if nothing is left of it
+func validateUsername(val interface{}, path cty.Path) diag.Diagnostics {
+	username := val.(string)
+	normalized := normalizeUsername(username)
+	switch {
+	case normalized == "":
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Error,
+				Summary:       fmt.Sprintf("Username %q is empty", username),
+				Detail:        "It only contains whitespace or invisible characters.",
+				AttributePath: path,
+			},
+		}
+	case normalized != username:
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity:      diag.Warning,
+				Summary:       fmt.Sprintf("Username %q is used as %q", username, normalized),
+				Detail:        "Whitespace and invisible characters, e.g. from copying the username out of chat, are removed from usernames. Update the configuration to the normalized username to silence this warning.",
+				AttributePath: path,
+			},
+		}
+	}
+	return nil
+}
+
+func usernameStateFunc(val interface{}) string {
+	return normalizeUsername(val.(string))
+}
+
+// hashUsername hashes usernames in sets by their normalized form, so that a
+// username differing only by whitespace isn't planned as a change
+func hashUsername(val interface{}) int {
+	return schema.HashString(normalizeUsername(val.(string)))
+}
+
+// usernameElem is the schema of the usernames in a list or set
+func usernameElem() *schema.Schema {
+	return &schema.Schema{
+		Type:             schema.TypeString,
+		ValidateDiagFunc: validateUsername,
+		StateFunc:        usernameStateFunc,
+	}
+}
@@ -0,0 +1,96 @@
+package oncall
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/terraform"
+)
+
+func Test_normalizeUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     string
+		wantSev  diag.Severity
+		wantDiag bool
+	}{
+		{
+			name:     "Already normal",
+			username: "alice",
+			want:     "alice",
+		},
+		{
+			name:     "Trailing whitespace",
+			username: "alice \t",
+			want:     "alice",
+			wantSev:  diag.Warning,
+			wantDiag: true,
+		},
+		{
+			name:     "Zero width space from chat",
+			username: "\u200balice\u200b",
+			want:     "alice",
+			wantSev:  diag.Warning,
+			wantDiag: true,
+		},
+		{
+			name:     "Decomposed accent",
+			username: "jose\u0301",
+			want:     "jos\u00e9",
+			wantSev:  diag.Warning,
+			wantDiag: true,
+		},
+		{
+			name:     "Only invisible characters",
+			username: " \ufeff ",
+			want:     "",
+			wantSev:  diag.Error,
+			wantDiag: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeUsername(tt.username); got != tt.want {
+				t.Errorf("normalizeUsername() = %q, want %q", got, tt.want)
+			}
+			diags := validateUsername(tt.username, cty.Path{})
+			if (len(diags) > 0) != tt.wantDiag || (tt.wantDiag && diags[0].Severity != tt.wantSev) {
+				t.Errorf("validateUsername() = %v, want severity %v", diags, tt.wantSev)
+			}
+		})
+	}
+}
+
+func Test_usernameSetDiff(t *testing.T) {
+	r := resourceTeam()
+	r.CustomizeDiff = nil
+
+	state := &terraform.InstanceState{
+		ID: "team",
+		Attributes: map[string]string{
+			"id":                        "team",
+			teamFieldName:               "team",
+			teamFieldSchedulingTimezone: "US/Central",
+			teamFieldArchived:           "false",
+			teamFieldAdmins + ".#":      "1",
+			teamFieldAdmins + "." + strconv.Itoa(hashUsername("alice")): "alice",
+		},
+	}
+
+	diff, err := r.Diff(context.Background(), state, terraform.NewResourceConfigRaw(map[string]interface{}{
+		teamFieldName:   "team",
+		teamFieldAdmins: []interface{}{"alice\u200b "},
+	}), nil)
+	if err != nil {
+		t.Fatalf("Diff() error = %v", err)
+	}
+	if diff != nil {
+		for k, a := range diff.Attributes {
+			t.Errorf("Diff() planned %s: %q => %q", k, a.Old, a.New)
+		}
+	}
+}
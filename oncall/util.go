@@ -1,10 +1,12 @@
 package oncall
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"strings"
+	"unicode"
 
 	"github.com/bushelpowered/oncall-client-go/oncall"
 	"github.com/hashicorp/go-cty/cty"
@@ -17,6 +19,15 @@ func diagFromErrf(err error, fmtString string, values ...interface{}) diag.Diagn
 	if err == nil {
 		return nil
 	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return diag.Diagnostics{
+			diag.Diagnostic{
+				Severity: diag.Error,
+				Summary:  fmt.Sprintf(fmtString, values...) + ": cancelled",
+				Detail:   "The operation was cancelled before oncall finished it, so it may have only been partially applied. Run it again to converge.\n\n" + err.Error(),
+			},
+		}
+	}
 	return diag.FromErr(errors.Wrapf(err, fmtString, values...))
 }
 
@@ -57,11 +68,56 @@ func validateStringSliceContains(slice []string) func(interface{}, cty.Path) dia
 	}
 }
 
-var traceLog = DefaultLogger{}.Trace
-var debugLog = DefaultLogger{}.Debug
-var infoLog = DefaultLogger{}.Info
-var warnLog = DefaultLogger{}.Warn
-var errorLog = DefaultLogger{}.Error
+// maxNameLength is the longest team or roster name oncall accepts
+const maxNameLength = 80
+
+// validateName checks that a team or roster name is one oncall accepts, and
+// that it can be used in URLs and in the IDs of this provider's resources
+func validateName(val interface{}, path cty.Path) diag.Diagnostics {
+	name := val.(string)
+	problem := ""
+	switch {
+	case len(name) > maxNameLength:
+		problem = fmt.Sprintf("is %d characters long, oncall accepts at most %d", len(name), maxNameLength)
+	case strings.Contains(name, "/"):
+		problem = "contains a slash, which oncall can't route to and which separates the parts of IDs"
+	case strings.TrimSpace(name) != name:
+		problem = "starts or ends with whitespace"
+	case strings.IndexFunc(name, unicode.IsControl) != -1:
+		problem = "contains control characters"
+	}
+	if problem == "" {
+		return nil
+	}
+	return diag.Diagnostics{
+		diag.Diagnostic{
+			Severity:      diag.Error,
+			Summary:       fmt.Sprintf("Name %q %s", name, problem),
+			AttributePath: path,
+		},
+	}
+}
+
+// IDs join names with slashes, so any in a name (e.g. of an existing roster
+// created outside of terraform) are escaped, along with the escape character
+var (
+	idPartEscaper   = strings.NewReplacer("%", "%25", "/", "%2F")
+	idPartUnescaper = strings.NewReplacer("%2F", "/", "%2f", "/", "%25", "%")
+)
+
+func escapeIDPart(part string) string {
+	return idPartEscaper.Replace(part)
+}
+
+func unescapeIDPart(part string) string {
+	return idPartUnescaper.Replace(part)
+}
+
+var traceLog = DefaultLogger{}.Tracef
+var debugLog = DefaultLogger{}.Debugf
+var infoLog = DefaultLogger{}.Infof
+var warnLog = DefaultLogger{}.Warnf
+var errorLog = DefaultLogger{}.Errorf
 
 type DefaultLogger struct {
 	fields map[string]interface{}
@@ -77,7 +133,7 @@ func (l DefaultLogger) leveledLog(level string, values ...interface{}) {
 }
 
 func (l DefaultLogger) leveledLogf(level string, format string, values ...interface{}) {
-	prefix := fmt.Sprintf("[%s] Oncall Provider: %+v", strings.ToUpper(level), l.fields)
+	prefix := fmt.Sprintf("[%s] Oncall Provider: %+v ", strings.ToUpper(level), l.fields)
 	fmt.Fprintf(os.Stderr, prefix+format+"\n", values...)
 }
 
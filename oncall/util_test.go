@@ -0,0 +1,56 @@
+package oncall
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+)
+
+func Test_validateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr bool
+	}{
+		{name: "Plain", value: "platform-sre"},
+		{name: "Spaces", value: "Platform SRE"},
+		{name: "Longest", value: strings.Repeat("a", maxNameLength)},
+		{name: "Too long", value: strings.Repeat("a", maxNameLength+1), wantErr: true},
+		{name: "Slash", value: "platform/sre", wantErr: true},
+		{name: "Trailing space", value: "platform ", wantErr: true},
+		{name: "Control character", value: "plat\tform", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diags := validateName(tt.value, cty.GetAttrPath("name")); diags.HasError() != tt.wantErr {
+				t.Errorf("validateName() = %v, wantErr %v", diags, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_rosterIDEscaping(t *testing.T) {
+	tests := []struct {
+		name   string
+		team   string
+		roster string
+		wantID string
+	}{
+		{name: "Plain", team: "platform", roster: "sre", wantID: "platform/sre"},
+		{name: "Slash", team: "platform", roster: "sre/dba", wantID: "platform/sre%2Fdba"},
+		{name: "Percent", team: "100%", roster: "sre%2F", wantID: "100%25/sre%252F"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := getRosterID(tt.team, tt.roster)
+			if id != tt.wantID {
+				t.Errorf("getRosterID() = %q, want %q", id, tt.wantID)
+			}
+			team, roster, err := parseRosterID(id)
+			if err != nil || team != tt.team || roster != tt.roster {
+				t.Errorf("parseRosterID(%q) = %q, %q, %v, want %q, %q", id, team, roster, err, tt.team, tt.roster)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package oncall
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// parseWeekStart reads the day oncall's weeks start on. Schedule events start
+// in seconds from the start of the week, which upstream oncall counts from
+// Sunday 00:00 but some instances count from Monday 00:00 as in ISO weeks.
+func parseWeekStart(day string) (time.Weekday, error) {
+	for i, d := range daysOfWeek {
+		if strings.EqualFold(d, day) {
+			return time.Weekday(i), nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("%q is not a valid day name", day)
+}
+
+// weekStartFor is the day the provider's oncall instance starts weeks on,
+// Sunday when there is no provider configuration, e.g. in tests
+func weekStartFor(m interface{}) time.Weekday {
+	if meta, ok := m.(*providerMeta); ok {
+		return meta.weekStart
+	}
+	return time.Sunday
+}
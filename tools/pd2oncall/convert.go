@@ -0,0 +1,350 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	daySeconds  = 24 * 60 * 60
+	weekSeconds = 7 * daySeconds
+
+	// maxNameLength is the longest team or roster name oncall accepts
+	maxNameLength = 80
+)
+
+// escalationRoles are the oncall roles the levels of an escalation policy are
+// mapped to, oncall has no roles for further levels
+var escalationRoles = []string{"primary", "secondary"}
+
+type oncallTeam struct {
+	resource  string
+	name      string
+	timezone  string
+	source    string
+	notes     []string
+	rosters   []*oncallRoster
+	schedules []oncallSchedule
+}
+
+type oncallRoster struct {
+	resource string
+	name     string
+	layerID  string
+	members  []string
+}
+
+type oncallSchedule struct {
+	resource string
+	roster   *oncallRoster
+	role     string
+	source   string
+	notes    []string
+	shifts   []oncallShift
+}
+
+type oncallShift struct {
+	day       time.Weekday
+	startTime string
+	duration  int
+}
+
+// converter maps PagerDuty objects onto oncall teams, keeping track of the
+// names it has used so every resource gets its own
+type converter struct {
+	now       time.Time
+	users     map[string]pdReference
+	schedules map[string]pdSchedule
+	resources map[string]bool
+	warnings  []string
+}
+
+// convert maps each escalation policy to a team, with the schedules of its
+// first and second levels as the team's primary and secondary schedules.
+// Schedules no policy escalates to get a team of their own as primary.
+// Oncall has no layers, so each layer of a schedule becomes its own roster.
+func convert(export pdExport, now time.Time) ([]*oncallTeam, []string, error) {
+	cv := converter{
+		now:       now,
+		users:     map[string]pdReference{},
+		schedules: map[string]pdSchedule{},
+		resources: map[string]bool{},
+	}
+	for _, u := range export.Users {
+		cv.users[u.ID] = u
+	}
+	for _, s := range export.Schedules {
+		cv.schedules[s.ID] = s
+	}
+
+	teams := []*oncallTeam{}
+	used := map[string]bool{}
+	for _, policy := range export.EscalationPolicies {
+		team := cv.newTeam(policy.Name, fmt.Sprintf("escalation policy %q (%s)", policy.Name, policy.ID))
+		for level, rule := range policy.Rules {
+			if level >= len(escalationRoles) {
+				cv.warnf("Escalation policy %q has %d levels, oncall only has roles for the first %d", policy.Name, len(policy.Rules), len(escalationRoles))
+				break
+			}
+			if level > 0 {
+				team.notes = append(team.notes, fmt.Sprintf("PagerDuty escalated to %s after %d minutes, set up the escalation in the team's iris_plan", escalationRoles[level], policy.Rules[level-1].DelayMinutes))
+			}
+			for _, target := range rule.Targets {
+				if target.Type != "schedule_reference" && target.Type != "schedule" {
+					cv.warnf("Escalation policy %q escalates to %s %s directly, only schedules are converted", policy.Name, target.Type, targetName(target))
+					continue
+				}
+				sched, ok := cv.schedules[target.ID]
+				if !ok {
+					cv.warnf("Escalation policy %q escalates to schedule %s, which is not in the export", policy.Name, targetName(target))
+					continue
+				}
+				if err := cv.addSchedule(team, sched, escalationRoles[level]); err != nil {
+					return nil, cv.warnings, err
+				}
+				used[sched.ID] = true
+			}
+		}
+		teams = append(teams, team)
+	}
+
+	for _, sched := range export.Schedules {
+		if used[sched.ID] {
+			continue
+		}
+		team := cv.newTeam(sched.Name, fmt.Sprintf("schedule %q (%s), which no escalation policy in the export escalates to", sched.Name, sched.ID))
+		if err := cv.addSchedule(team, sched, escalationRoles[0]); err != nil {
+			return nil, cv.warnings, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, cv.warnings, nil
+}
+
+func (cv *converter) warnf(format string, args ...interface{}) {
+	cv.warnings = append(cv.warnings, fmt.Sprintf(format, args...))
+}
+
+func (cv *converter) newTeam(name, source string) *oncallTeam {
+	return &oncallTeam{
+		resource: cv.resourceName(name),
+		name:     oncallName(name),
+		source:   source,
+	}
+}
+
+// addSchedule adds a roster and schedule for each current layer of the
+// PagerDuty schedule to the team
+func (cv *converter) addSchedule(team *oncallTeam, sched pdSchedule, role string) error {
+	if team.timezone == "" {
+		team.timezone = sched.TimeZone
+	} else if sched.TimeZone != team.timezone {
+		cv.warnf("Schedule %q is in %s but team %s schedules in %s, check its shift times", sched.Name, sched.TimeZone, team.name, team.timezone)
+	}
+
+	layers := []pdLayer{}
+	for _, layer := range sched.Layers {
+		if layer.End != "" {
+			end, err := time.Parse(time.RFC3339, layer.End)
+			if err == nil && end.Before(cv.now) {
+				cv.warnf("Layer %q of schedule %q ended at %s, not converting it", layer.Name, sched.Name, layer.End)
+				continue
+			}
+		}
+		layers = append(layers, layer)
+	}
+
+	for _, layer := range layers {
+		name := sched.Name
+		if len(layers) > 1 {
+			name = fmt.Sprintf("%s %s", sched.Name, layer.Name)
+		}
+		source := fmt.Sprintf("schedule %q (%s)", sched.Name, sched.ID)
+		if len(layers) > 1 {
+			source = fmt.Sprintf("layer %q of schedule %q (%s)", layer.Name, sched.Name, sched.ID)
+		}
+
+		roster := cv.roster(team, name, layer)
+		shifts, notes, err := layerShifts(layer)
+		if err != nil {
+			return errors.Wrapf(err, "Converting layer %q of schedule %q", layer.Name, sched.Name)
+		}
+		if len(layers) > 1 {
+			notes = append(notes, "PagerDuty layers override the ones below them, oncall puts the members of every roster on call for the role, check the shifts don't overlap")
+		}
+		team.schedules = append(team.schedules, oncallSchedule{
+			resource: cv.resourceName(fmt.Sprintf("%s %s %s", team.resource, role, name)),
+			roster:   roster,
+			role:     role,
+			source:   source,
+			notes:    notes,
+			shifts:   shifts,
+		})
+	}
+	return nil
+}
+
+// roster is the team's roster for the layer, added the first time the layer
+// is seen, as a schedule can be escalated to at more than one level
+func (cv *converter) roster(team *oncallTeam, name string, layer pdLayer) *oncallRoster {
+	for _, r := range team.rosters {
+		if r.layerID == layer.ID {
+			return r
+		}
+	}
+	roster := &oncallRoster{
+		resource: cv.resourceName(fmt.Sprintf("%s %s", team.resource, name)),
+		name:     oncallName(name),
+		layerID:  layer.ID,
+	}
+	for _, u := range layer.Users {
+		roster.members = append(roster.members, cv.username(u.User))
+	}
+	team.rosters = append(team.rosters, roster)
+	return roster
+}
+
+// username is the oncall username of a PagerDuty user, the local part of
+// their email
+func (cv *converter) username(ref pdReference) string {
+	email := ref.Email
+	if user, ok := cv.users[ref.ID]; ok && user.Email != "" {
+		email = user.Email
+	}
+	if i := strings.Index(email, "@"); i > 0 {
+		return strings.ToLower(email[:i])
+	}
+	name := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(targetName(ref)), "."), ".")
+	cv.warnf("No email for user %s, using %q as their username. Include the users in the export to use their emails", targetName(ref), name)
+	return name
+}
+
+// layerShifts turns a layer into the shifts of a week. Oncall hands all the
+// shifts of a week off together, so layers that hand off more often than
+// weekly can't be converted as they are, and get a note saying so.
+func layerShifts(layer pdLayer) ([]oncallShift, []string, error) {
+	start, err := time.Parse(time.RFC3339, layer.RotationVirtualStart)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "Parsing rotation_virtual_start")
+	}
+	turn := layer.TurnLengthSeconds
+	if turn <= 0 {
+		return nil, nil, fmt.Errorf("Invalid rotation_turn_length_seconds %d", turn)
+	}
+
+	notes := []string{}
+	if turn%weekSeconds != 0 {
+		notes = append(notes, fmt.Sprintf("PagerDuty handed off every %s, oncall hands off every week with these shifts, or split them into one schedule per shift", formatDuration(turn)))
+	} else if turn > weekSeconds && len(layer.Restrictions) > 0 {
+		notes = append(notes, fmt.Sprintf("PagerDuty handed off every %s, these shifts hand off every week", formatDuration(turn)))
+	}
+
+	if len(layer.Restrictions) == 0 {
+		duration := turn
+		if turn%weekSeconds != 0 {
+			duration = weekSeconds
+		}
+		return []oncallShift{{
+			day:       start.Weekday(),
+			startTime: formatTimeOfDay(start.Hour(), start.Minute(), start.Second()),
+			duration:  duration,
+		}}, notes, nil
+	}
+
+	shifts := []oncallShift{}
+	for _, r := range layer.Restrictions {
+		startTime, err := parseTimeOfDay(r.StartTimeOfDay)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch r.Type {
+		case pdRestrictionDaily:
+			for day := time.Sunday; day <= time.Saturday; day++ {
+				shifts = append(shifts, oncallShift{day: day, startTime: startTime, duration: r.DurationSeconds})
+			}
+		case pdRestrictionWeekly:
+			// PagerDuty numbers days from 1 for Monday to 7 for Sunday
+			shifts = append(shifts, oncallShift{day: time.Weekday(r.StartDayOfWeek % 7), startTime: startTime, duration: r.DurationSeconds})
+		default:
+			return nil, nil, fmt.Errorf("Unknown restriction type %q", r.Type)
+		}
+	}
+	return shifts, notes, nil
+}
+
+func parseTimeOfDay(s string) (string, error) {
+	t, err := time.Parse("15:04:05", s)
+	if err != nil {
+		t, err = time.Parse("15:04", s)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "Parsing start_time_of_day %q", s)
+	}
+	return formatTimeOfDay(t.Hour(), t.Minute(), t.Second()), nil
+}
+
+// formatTimeOfDay formats a time as the provider's start_time does, HH:MM
+// unless there are seconds
+func formatTimeOfDay(hour, min, sec int) string {
+	if sec != 0 {
+		return fmt.Sprintf("%02d:%02d:%02d", hour, min, sec)
+	}
+	return fmt.Sprintf("%02d:%02d", hour, min)
+}
+
+// formatDuration formats seconds in the provider's duration shorthand, e.g.
+// 7d, 8h or 1h30m
+func formatDuration(seconds int) string {
+	if seconds%daySeconds == 0 {
+		return fmt.Sprintf("%dd", seconds/daySeconds)
+	}
+	out := ""
+	for _, unit := range []struct {
+		suffix  string
+		seconds int
+	}{{"h", 3600}, {"m", 60}, {"s", 1}} {
+		if n := seconds / unit.seconds; n > 0 {
+			out += fmt.Sprintf("%d%s", n, unit.suffix)
+			seconds -= n * unit.seconds
+		}
+	}
+	return out
+}
+
+var nonIdentifier = regexp.MustCompile(`[^a-z0-9]+`)
+
+// resourceName is a unique terraform resource name for a PagerDuty name
+func (cv *converter) resourceName(name string) string {
+	base := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if base == "" || (base[0] >= '0' && base[0] <= '9') {
+		base = "pd_" + base
+	}
+	resource := base
+	for i := 2; cv.resources[resource]; i++ {
+		resource = fmt.Sprintf("%s_%d", base, i)
+	}
+	cv.resources[resource] = true
+	return resource
+}
+
+// oncallName is a PagerDuty name as a team or roster name oncall accepts
+func oncallName(name string) string {
+	name = strings.TrimSpace(strings.ReplaceAll(name, "/", "-"))
+	if len(name) > maxNameLength {
+		name = strings.TrimSpace(name[:maxNameLength])
+	}
+	return name
+}
+
+func targetName(ref pdReference) string {
+	for _, name := range []string{ref.Summary, ref.Name, ref.ID} {
+		if name != "" {
+			return name
+		}
+	}
+	return "unknown"
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func Test_layerShifts(t *testing.T) {
+	tests := []struct {
+		name      string
+		layer     pdLayer
+		want      []oncallShift
+		wantNotes int
+		wantErr   bool
+	}{
+		{
+			name:  "Weekly",
+			layer: pdLayer{RotationVirtualStart: "2024-01-01T09:00:00-06:00", TurnLengthSeconds: weekSeconds},
+			want:  []oncallShift{{day: time.Monday, startTime: "09:00", duration: weekSeconds}},
+		},
+		{
+			name:  "Two weeks",
+			layer: pdLayer{RotationVirtualStart: "2024-01-07T17:30:15Z", TurnLengthSeconds: 2 * weekSeconds},
+			want:  []oncallShift{{day: time.Sunday, startTime: "17:30:15", duration: 2 * weekSeconds}},
+		},
+		{
+			name:      "Daily hand offs",
+			layer:     pdLayer{RotationVirtualStart: "2024-01-01T09:00:00Z", TurnLengthSeconds: daySeconds},
+			want:      []oncallShift{{day: time.Monday, startTime: "09:00", duration: weekSeconds}},
+			wantNotes: 1,
+		},
+		{
+			name: "Weekly restrictions",
+			layer: pdLayer{RotationVirtualStart: "2024-01-01T09:00:00Z", TurnLengthSeconds: weekSeconds, Restrictions: []pdRestriction{
+				{Type: pdRestrictionWeekly, StartDayOfWeek: 5, StartTimeOfDay: "17:00:00", DurationSeconds: 3 * daySeconds},
+				{Type: pdRestrictionWeekly, StartDayOfWeek: 7, StartTimeOfDay: "08:00:00", DurationSeconds: 3600},
+			}},
+			want: []oncallShift{{day: time.Friday, startTime: "17:00", duration: 3 * daySeconds}, {day: time.Sunday, startTime: "08:00", duration: 3600}},
+		},
+		{
+			name: "Daily restriction",
+			layer: pdLayer{RotationVirtualStart: "2024-01-01T09:00:00Z", TurnLengthSeconds: weekSeconds, Restrictions: []pdRestriction{
+				{Type: pdRestrictionDaily, StartTimeOfDay: "22:00:00", DurationSeconds: 8 * 3600},
+			}},
+			want: []oncallShift{
+				{day: time.Sunday, startTime: "22:00", duration: 8 * 3600},
+				{day: time.Monday, startTime: "22:00", duration: 8 * 3600},
+				{day: time.Tuesday, startTime: "22:00", duration: 8 * 3600},
+				{day: time.Wednesday, startTime: "22:00", duration: 8 * 3600},
+				{day: time.Thursday, startTime: "22:00", duration: 8 * 3600},
+				{day: time.Friday, startTime: "22:00", duration: 8 * 3600},
+				{day: time.Saturday, startTime: "22:00", duration: 8 * 3600},
+			},
+		},
+		{
+			name:    "Bad start",
+			layer:   pdLayer{RotationVirtualStart: "Monday", TurnLengthSeconds: weekSeconds},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, notes, err := layerShifts(tt.layer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("layerShifts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("layerShifts() = %+v, want %+v", got, tt.want)
+			}
+			if len(notes) != tt.wantNotes {
+				t.Errorf("layerShifts() notes = %v, want %d", notes, tt.wantNotes)
+			}
+		})
+	}
+}
+
+func Test_formatDuration(t *testing.T) {
+	for seconds, want := range map[int]string{weekSeconds: "7d", 8 * 3600: "8h", 5400: "1h30m", 90: "1m30s"} {
+		if got := formatDuration(seconds); got != want {
+			t.Errorf("formatDuration(%d) = %q, want %q", seconds, got, want)
+		}
+	}
+}
+
+func Test_hclString(t *testing.T) {
+	if got, want := hclString(`Dan "${Admin}" 100%{x}`), `"Dan \"$${Admin}\" 100%%{x}"`; got != want {
+		t.Errorf("hclString() = %s, want %s", got, want)
+	}
+}
+
+// Test_convert converts testdata/export.json, checking the output against
+// testdata/export.tf
+func Test_convert(t *testing.T) {
+	export, err := readExports([]string{"testdata/export.json"})
+	if err != nil {
+		t.Fatalf("readExports() error = %v", err)
+	}
+	teams, warnings, err := convert(export, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("convert() error = %v", err)
+	}
+	if len(warnings) != 4 {
+		t.Errorf("convert() warnings = %v, want the ended layer, direct user, third level and missing email", warnings)
+	}
+
+	got := &bytes.Buffer{}
+	renderTeams(got, teams)
+	want, err := ioutil.ReadFile("testdata/export.tf")
+	if err != nil {
+		t.Fatalf("Reading testdata/export.tf: %s", err)
+	}
+	if got.String() != string(want) {
+		t.Errorf("renderTeams() =\n%s\nwant\n%s", got, want)
+	}
+}
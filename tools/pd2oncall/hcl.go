@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// hclBlock is a block of configuration, rendered the way terraform fmt would
+// lay it out
+type hclBlock struct {
+	comments []string
+	header   string
+	attrs    []hclAttr
+	blocks   []hclBlock
+}
+
+type hclAttr struct {
+	name string
+	// value is the expression as it is written, e.g. quoted for strings
+	value string
+}
+
+func (b hclBlock) render(w io.Writer, indent string) {
+	for _, c := range b.comments {
+		fmt.Fprintf(w, "%s# %s\n", indent, c)
+	}
+	fmt.Fprintf(w, "%s%s {\n", indent, b.header)
+
+	width := 0
+	for _, a := range b.attrs {
+		if len(a.name) > width {
+			width = len(a.name)
+		}
+	}
+	for _, a := range b.attrs {
+		fmt.Fprintf(w, "%s  %-*s = %s\n", indent, width, a.name, a.value)
+	}
+	for _, nested := range b.blocks {
+		fmt.Fprintln(w)
+		nested.render(w, indent+"  ")
+	}
+	fmt.Fprintf(w, "%s}\n", indent)
+}
+
+// hclString quotes a string, escaping what terraform would interpolate
+func hclString(s string) string {
+	quoted := strconv.Quote(s)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
+
+// renderTeams writes the configuration of the teams, each team followed by
+// its rosters and schedules
+func renderTeams(w io.Writer, teams []*oncallTeam) {
+	blocks := []hclBlock{}
+	for _, team := range teams {
+		attrs := []hclAttr{{"name", hclString(team.name)}}
+		if team.timezone != "" {
+			attrs = append(attrs, hclAttr{"scheduling_timezone", hclString(team.timezone)})
+		}
+		attrs = append(attrs, hclAttr{"admins", "[]"})
+		blocks = append(blocks, hclBlock{
+			comments: append([]string{"Converted from PagerDuty " + team.source}, todos(team.notes)...),
+			header:   fmt.Sprintf("resource \"oncall_team\" %s", strconv.Quote(team.resource)),
+			attrs:    attrs,
+		})
+
+		for _, roster := range team.rosters {
+			members := []hclBlock{}
+			for i, member := range roster.members {
+				members = append(members, hclBlock{
+					header: "member",
+					attrs:  []hclAttr{{"name", hclString(member)}, {"order", strconv.Itoa(i + 1)}},
+				})
+			}
+			blocks = append(blocks, hclBlock{
+				header: fmt.Sprintf("resource \"oncall_roster\" %s", strconv.Quote(roster.resource)),
+				attrs: []hclAttr{
+					{"team", fmt.Sprintf("oncall_team.%s.name", team.resource)},
+					{"name", hclString(roster.name)},
+				},
+				blocks: members,
+			})
+		}
+
+		for _, sched := range team.schedules {
+			shifts := []hclBlock{}
+			for _, shift := range sched.shifts {
+				shifts = append(shifts, hclBlock{
+					header: "shift",
+					attrs: []hclAttr{
+						{"start_day_of_week", hclString(shift.day.String())},
+						{"start_time", hclString(shift.startTime)},
+						{"duration", hclString(formatDuration(shift.duration))},
+					},
+				})
+			}
+			blocks = append(blocks, hclBlock{
+				comments: append([]string{"Converted from PagerDuty " + sched.source}, todos(sched.notes)...),
+				header:   fmt.Sprintf("resource \"oncall_advanced_schedule\" %s", strconv.Quote(sched.resource)),
+				attrs: []hclAttr{
+					{"roster_id", fmt.Sprintf("oncall_roster.%s.roster_id", sched.roster.resource)},
+					{"role", hclString(sched.role)},
+				},
+				blocks: shifts,
+			})
+		}
+	}
+
+	for i, b := range blocks {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		b.render(w, "")
+	}
+}
+
+func todos(notes []string) []string {
+	out := make([]string, 0, len(notes))
+	for _, n := range notes {
+		out = append(out, "TODO: "+n)
+	}
+	return out
+}
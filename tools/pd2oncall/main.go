@@ -0,0 +1,60 @@
+// pd2oncall converts PagerDuty schedules and escalation policies, saved from
+// PagerDuty's REST API, into oncall_team, oncall_roster and
+// oncall_advanced_schedule configuration for this provider.
+//
+//	go run ./tools/pd2oncall -o oncall.tf policies.json schedules.json users.json
+//
+// Each file holds a response of the API, e.g. GET /schedules/{id},
+// GET /escalation_policies or GET /users. What can't be converted as it is,
+// such as daily hand offs, is left as TODO comments in the output and
+// reported on stderr.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+func main() {
+	output := flag.String("o", "", "File to write the configuration to instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [-o file] export.json...\n\nConverts PagerDuty schedule and escalation policy exports into oncall provider configuration.\n\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if err := run(flag.Args(), *output); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(paths []string, output string) error {
+	export, err := readExports(paths)
+	if err != nil {
+		return err
+	}
+	teams, warnings, err := convert(export, time.Now())
+	for _, w := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+	if err != nil {
+		return err
+	}
+
+	out := &bytes.Buffer{}
+	renderTeams(out, teams)
+	if output == "" {
+		_, err = os.Stdout.Write(out.Bytes())
+		return err
+	}
+	return ioutil.WriteFile(output, out.Bytes(), 0644)
+}
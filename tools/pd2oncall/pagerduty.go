@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// pdExport is a PagerDuty API response saved to a file, either a single
+// schedule or escalation policy, a list of them, or both. Users can be
+// included to look up the emails of the users of schedule layers.
+type pdExport struct {
+	Schedule           *pdSchedule          `json:"schedule"`
+	Schedules          []pdSchedule         `json:"schedules"`
+	EscalationPolicy   *pdEscalationPolicy  `json:"escalation_policy"`
+	EscalationPolicies []pdEscalationPolicy `json:"escalation_policies"`
+	Users              []pdReference        `json:"users"`
+}
+
+type pdSchedule struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	TimeZone string    `json:"time_zone"`
+	Layers   []pdLayer `json:"schedule_layers"`
+}
+
+type pdLayer struct {
+	ID                   string          `json:"id"`
+	Name                 string          `json:"name"`
+	End                  string          `json:"end"`
+	RotationVirtualStart string          `json:"rotation_virtual_start"`
+	TurnLengthSeconds    int             `json:"rotation_turn_length_seconds"`
+	Users                []pdLayerUser   `json:"users"`
+	Restrictions         []pdRestriction `json:"restrictions"`
+}
+
+type pdLayerUser struct {
+	User pdReference `json:"user"`
+}
+
+// pdReference is a reference to another PagerDuty object, or the object
+// itself for users, which have an email
+type pdReference struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Summary string `json:"summary"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+}
+
+const (
+	pdRestrictionDaily  = "daily_restriction"
+	pdRestrictionWeekly = "weekly_restriction"
+)
+
+type pdRestriction struct {
+	Type            string `json:"type"`
+	StartDayOfWeek  int    `json:"start_day_of_week"`
+	StartTimeOfDay  string `json:"start_time_of_day"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+type pdEscalationPolicy struct {
+	ID    string             `json:"id"`
+	Name  string             `json:"name"`
+	Rules []pdEscalationRule `json:"escalation_rules"`
+}
+
+type pdEscalationRule struct {
+	DelayMinutes int           `json:"escalation_delay_in_minutes"`
+	Targets      []pdReference `json:"targets"`
+}
+
+// readExports reads and merges the exports in the given files
+func readExports(paths []string) (pdExport, error) {
+	merged := pdExport{}
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return merged, errors.Wrapf(err, "Reading %s", path)
+		}
+		export := pdExport{}
+		if err := json.Unmarshal(data, &export); err != nil {
+			return merged, errors.Wrapf(err, "Parsing %s", path)
+		}
+		if export.Schedule != nil {
+			merged.Schedules = append(merged.Schedules, *export.Schedule)
+		}
+		if export.EscalationPolicy != nil {
+			merged.EscalationPolicies = append(merged.EscalationPolicies, *export.EscalationPolicy)
+		}
+		merged.Schedules = append(merged.Schedules, export.Schedules...)
+		merged.EscalationPolicies = append(merged.EscalationPolicies, export.EscalationPolicies...)
+		merged.Users = append(merged.Users, export.Users...)
+	}
+	return merged, nil
+}
@@ -0,0 +1,86 @@
+# Converted from PagerDuty escalation policy "Platform" (PEP1)
+# TODO: PagerDuty escalated to secondary after 30 minutes, set up the escalation in the team's iris_plan
+resource "oncall_team" "platform" {
+  name                = "Platform"
+  scheduling_timezone = "America/Chicago"
+  admins              = []
+}
+
+resource "oncall_roster" "platform_platform_primary" {
+  team = oncall_team.platform.name
+  name = "Platform Primary"
+
+  member {
+    name  = "alice"
+    order = 1
+  }
+
+  member {
+    name  = "bjones"
+    order = 2
+  }
+}
+
+resource "oncall_roster" "platform_platform_business_hours" {
+  team = oncall_team.platform.name
+  name = "Platform Business Hours"
+
+  member {
+    name  = "bjones"
+    order = 1
+  }
+}
+
+# Converted from PagerDuty schedule "Platform Primary" (PS1)
+resource "oncall_advanced_schedule" "platform_primary_platform_primary" {
+  roster_id = oncall_roster.platform_platform_primary.roster_id
+  role      = "primary"
+
+  shift {
+    start_day_of_week = "Monday"
+    start_time        = "09:00"
+    duration          = "7d"
+  }
+}
+
+# Converted from PagerDuty schedule "Platform Business Hours" (PS2)
+# TODO: PagerDuty handed off every 1d, oncall hands off every week with these shifts, or split them into one schedule per shift
+resource "oncall_advanced_schedule" "platform_secondary_platform_business_hours" {
+  roster_id = oncall_roster.platform_platform_business_hours.roster_id
+  role      = "secondary"
+
+  shift {
+    start_day_of_week = "Monday"
+    start_time        = "09:00"
+    duration          = "8h"
+  }
+}
+
+# Converted from PagerDuty schedule "Databases" (PS3), which no escalation policy in the export escalates to
+resource "oncall_team" "databases" {
+  name                = "Databases"
+  scheduling_timezone = "UTC"
+  admins              = []
+}
+
+resource "oncall_roster" "databases_databases" {
+  team = oncall_team.databases.name
+  name = "Databases"
+
+  member {
+    name  = "dan.admin"
+    order = 1
+  }
+}
+
+# Converted from PagerDuty schedule "Databases" (PS3)
+resource "oncall_advanced_schedule" "databases_primary_databases" {
+  roster_id = oncall_roster.databases_databases.roster_id
+  role      = "primary"
+
+  shift {
+    start_day_of_week = "Sunday"
+    start_time        = "17:30"
+    duration          = "14d"
+  }
+}